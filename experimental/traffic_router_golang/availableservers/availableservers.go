@@ -23,8 +23,11 @@ package availableservers
 import (
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
@@ -57,6 +60,44 @@ func (a *AvailableServers) Get(ds tc.DeliveryServiceName, cg tc.CacheGroupName)
 	return cs, nil
 }
 
+// Filter returns the given caches which are available for the given Delivery Service, in any Cache Group, in the order given. This is used to route to specific caches, such as those of a deep coverage zone.
+func (a *AvailableServers) Filter(ds tc.DeliveryServiceName, caches []tc.CacheName) []tc.CacheName {
+	a.m.RLock()
+	s := *a.p
+	a.m.RUnlock()
+
+	available := map[tc.CacheName]struct{}{}
+	for _, cs := range (*s)[ds] {
+		for _, c := range cs {
+			available[c] = struct{}{}
+		}
+	}
+
+	filtered := []tc.CacheName{}
+	for _, c := range caches {
+		if _, ok := available[c]; ok {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// Deep returns the available caches of the deep coverage zone containing the given IP, for a Delivery Service with the given deep caching type, and the name of the deep zone. Deep returns false if the Delivery Service doesn't always use deep caching, the IP isn't in a deep coverage zone, or none of the zone's caches are available, in which case the client should be routed to a Cache Group.
+func (a *AvailableServers) Deep(ds tc.DeliveryServiceName, deepCachingType *tc.DeepCachingType, deepCZ coveragezone.CoverageZone, ip net.IP) ([]tc.CacheName, tc.CacheGroupName, bool) {
+	if deepCachingType == nil || *deepCachingType != tc.DeepCachingTypeAlways || deepCZ == nil {
+		return nil, "", false
+	}
+	zone, ok := deepCZ.Zone(ip)
+	if !ok {
+		return nil, "", false
+	}
+	caches := a.Filter(ds, zone.Caches)
+	if len(caches) == 0 {
+		return nil, "", false
+	}
+	return caches, zone.Name, true
+}
+
 func (a *AvailableServers) Set(m AvailableServersMap) {
 	a.m.Lock()
 	defer a.m.Unlock()
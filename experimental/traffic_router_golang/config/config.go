@@ -33,10 +33,12 @@ import (
 
 type Cfg struct {
 	Port                  uint     `json:"port"`
+	DNSPort               uint     `json:"dns_port"`
 	Monitors              []*URL   `json:"monitors"`
 	ReqTimeout            Duration `json:"request_timeout_ms"`
 	CRConfigInterval      Duration `json:"crconfig_poll_interval_ms"`
 	CRStatesInterval      Duration `json:"crstates_poll_interval_ms"`
+	SteeringInterval      Duration `json:"steering_poll_interval_ms"`
 	CDN                   string   `json:"cdn"`
 	TrafficOpsURI         *URL     `json:"traffic_ops_uri"`
 	TrafficOpsUser        string   `json:"traffic_ops_user"`
@@ -45,6 +47,8 @@ type Cfg struct {
 	TrafficOpsClientCache bool     `json:"traffic_ops_client_cache"`
 	TrafficOpsTimeout     Duration `json:"traffic_ops_timeout_ms"`
 	CoverageZoneFile      string   `json:"coverage_zone_file"`
+	CoverageZoneInterval  Duration `json:"coverage_zone_poll_interval_ms"`
+	DeepCoverageZoneFile  string   `json:"deep_coverage_zone_file"`
 	LogLocations
 }
 
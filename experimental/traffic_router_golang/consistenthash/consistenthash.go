@@ -0,0 +1,241 @@
+package consistenthash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"crypto/md5"
+	"errors"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// DefaultHashCount is the number of hashes of a cache without a hashCount in the CRConfig. This matches the hashCount Traffic Ops generates for the default weight and weight multiplier.
+const DefaultHashCount = 999
+
+// Hasher selects caches for requests by consistent hash, with the same hashes and hash strings as Traffic Router.
+//
+// Each cache has hashCount hashes, of its hashId. A request is routed to the cache with a hash closest to the hash of the request's hash string. Because a cache's distance to a request doesn't depend on any other cache, a request is only routed to a different cache when its cache becomes unavailable, and requests to available caches stay on them when other caches become unavailable.
+//
+// A Hasher must not be modified after creation, and is safe for use by multiple goroutines.
+type Hasher struct {
+	// cacheHashes is the sorted hashes of each cache.
+	cacheHashes map[tc.CacheName][]float64
+	dses        map[tc.DeliveryServiceName]dsHash
+}
+
+// dsHash is the consistent hash configuration of a delivery service.
+type dsHash struct {
+	// regex is the consistentHashRegex, or nil if the delivery service has none.
+	regex *regexp.Regexp
+	// queryParams is the set of consistentHashQueryParams.
+	queryParams map[string]struct{}
+}
+
+// New creates a Hasher from the caches and delivery services of the given CRConfig. Delivery services with an invalid consistentHashRegex are hashed by the full request path, and their errors are returned, but the returned Hasher is always usable.
+func New(crc *tc.CRConfig) (*Hasher, []error) {
+	errs := []error{}
+	h := &Hasher{
+		cacheHashes: make(map[tc.CacheName][]float64, len(crc.ContentServers)),
+		dses:        make(map[tc.DeliveryServiceName]dsHash, len(crc.DeliveryServices)),
+	}
+	for name, srv := range crc.ContentServers {
+		hashID := name
+		if srv.HashId != nil && *srv.HashId != "" {
+			hashID = *srv.HashId
+		}
+		hashCount := DefaultHashCount
+		if srv.HashCount != nil {
+			hashCount = *srv.HashCount
+		}
+		h.cacheHashes[tc.CacheName(name)] = Hashes(hashID, hashCount)
+	}
+	for name, ds := range crc.DeliveryServices {
+		dsh := dsHash{queryParams: make(map[string]struct{}, len(ds.ConsistentHashQueryParams))}
+		if ds.ConsistentHashRegex != nil && *ds.ConsistentHashRegex != "" {
+			regex, err := regexp.Compile(*ds.ConsistentHashRegex)
+			if err != nil {
+				errs = append(errs, errors.New("delivery service '"+name+"' consistentHashRegex '"+*ds.ConsistentHashRegex+"': "+err.Error()))
+			} else {
+				dsh.regex = regex
+			}
+		}
+		for _, param := range ds.ConsistentHashQueryParams {
+			dsh.queryParams[param] = struct{}{}
+		}
+		h.dses[tc.DeliveryServiceName(name)] = dsh
+	}
+	return h, errs
+}
+
+// Select returns the cache of the given caches which the given request path and raw query string of the given delivery service hashes to. Caches must be the available caches; unavailable caches only change the cache of requests that hashed to them. Returns false if caches is empty.
+func (h *Hasher) Select(ds tc.DeliveryServiceName, caches []tc.CacheName, path string, rawQuery string) (tc.CacheName, bool) {
+	return h.SelectHash(caches, Hash(h.PathHashString(ds, path)+h.QueryHashString(ds, rawQuery)))
+}
+
+// PathHashString returns the part of the hash string of a request to the given delivery service from the request path. See HashString.
+func (h *Hasher) PathHashString(ds tc.DeliveryServiceName, path string) string {
+	return PatternBasedHashString(h.dses[ds].regex, path)
+}
+
+// QueryHashString returns the part of the hash string of a request to the given delivery service from the request's raw query string. See HashString.
+//
+// Steering delivery services hash requests to their targets with the path hash string of the steering delivery service, and the query hash string of the target.
+func (h *Hasher) QueryHashString(ds tc.DeliveryServiceName, rawQuery string) string {
+	return significantQueryParams(h.dses[ds].queryParams, rawQuery)
+}
+
+// SelectHash returns the cache of the given caches with a hash closest to the given hash. Caches without hashes, i.e. with a hashCount of 0 or not in the Hasher's CRConfig, are only selected if no cache has hashes. Ties are broken by cache name, so the result doesn't depend on the order of caches. Returns false if caches is empty.
+func (h *Hasher) SelectHash(caches []tc.CacheName, hash float64) (tc.CacheName, bool) {
+	best := tc.CacheName("")
+	bestDelta := 0.0
+	bestHashed := false
+	for _, cache := range caches {
+		hashes := h.cacheHashes[cache]
+		if len(hashes) == 0 {
+			if !bestHashed && (best == "" || cache < best) {
+				best = cache
+			}
+			continue
+		}
+		delta := ClosestDelta(hashes, hash)
+		if !bestHashed || delta < bestDelta || (delta == bestDelta && cache < best) {
+			best = cache
+			bestDelta = delta
+			bestHashed = true
+		}
+	}
+	return best, best != ""
+}
+
+// OrderHash returns the given caches ordered by the distance of their closest hash to the given hash, so the first is the cache SelectHash returns. Caches without hashes are last, by name. Like SelectHash, ties are broken by cache name, and a cache only moves in the order when caches before it become unavailable.
+func (h *Hasher) OrderHash(caches []tc.CacheName, hash float64) []tc.CacheName {
+	deltas := make(map[tc.CacheName]float64, len(caches))
+	for _, cache := range caches {
+		if hashes := h.cacheHashes[cache]; len(hashes) > 0 {
+			deltas[cache] = ClosestDelta(hashes, hash)
+		}
+	}
+	ordered := append([]tc.CacheName{}, caches...)
+	sort.Slice(ordered, func(i, j int) bool {
+		iDelta, iHashed := deltas[ordered[i]]
+		jDelta, jHashed := deltas[ordered[j]]
+		if iHashed != jHashed {
+			return iHashed
+		}
+		if iHashed && iDelta != jDelta {
+			return iDelta < jDelta
+		}
+		return ordered[i] < ordered[j]
+	})
+	return ordered
+}
+
+// Hash returns the hash of the given string, which is the MD5 of the string as a number, as Traffic Router hashes.
+func Hash(s string) float64 {
+	sum := md5.Sum([]byte(s))
+	f, _ := new(big.Float).SetInt(new(big.Int).SetBytes(sum[:])).Float64()
+	return f
+}
+
+// Hashes returns the sorted hashes of a hashable, such as a cache, with the given hash ID and hash count.
+func Hashes(hashID string, hashCount int) []float64 {
+	hashes := make([]float64, 0, hashCount)
+	for i := 0; i < hashCount; i++ {
+		hashes = append(hashes, Hash(hashID+"--"+strconv.Itoa(i)))
+	}
+	sort.Float64s(hashes)
+	return hashes
+}
+
+// ClosestDelta returns the absolute difference between hash and the closest of the given sorted hashes, which must not be empty.
+func ClosestDelta(hashes []float64, hash float64) float64 {
+	i := sort.SearchFloat64s(hashes, hash)
+	delta := -1.0
+	if i < len(hashes) {
+		delta = hashes[i] - hash
+	}
+	if i > 0 && (delta < 0 || hash-hashes[i-1] < delta) {
+		delta = hash - hashes[i-1]
+	}
+	return delta
+}
+
+// HashString returns the string to hash for a request with the given path and raw query string, for a delivery service with the given consistentHashRegex and consistentHashQueryParams.
+//
+// The string is the PatternBasedHashString of the path, followed by the query parameters in queryParams, URI-decoded, sorted, and concatenated as 'key=value'. If the query string can't be decoded, no query parameters are used.
+func HashString(regex *regexp.Regexp, queryParams map[string]struct{}, path string, rawQuery string) string {
+	return PatternBasedHashString(regex, path) + significantQueryParams(queryParams, rawQuery)
+}
+
+// PatternBasedHashString returns the part of the request path to hash, for the given consistentHashRegex. If the regex is nil, or doesn't match, or has no capture groups, the full path is returned. Otherwise, the capture groups of the first match are concatenated. This is the same as Traffic Router, and the result of the Traffic Ops consistenthash endpoint.
+func PatternBasedHashString(regex *regexp.Regexp, path string) string {
+	if regex == nil {
+		return path
+	}
+	match := regex.FindStringSubmatch(path)
+	if len(match) < 2 {
+		return path
+	}
+	return strings.Join(match[1:], "")
+}
+
+// significantQueryParams returns the query parameters of the raw query string in queryParams, URI-decoded, sorted, and concatenated as 'key=value'.
+func significantQueryParams(queryParams map[string]struct{}, rawQuery string) string {
+	if rawQuery == "" || len(queryParams) == 0 {
+		return ""
+	}
+	params := []string{}
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		parts := strings.Split(param, "=")
+		for i, part := range parts {
+			decoded, err := url.QueryUnescape(part)
+			if err != nil {
+				return ""
+			}
+			parts[i] = decoded
+		}
+		if _, ok := queryParams[parts[0]]; ok {
+			params = append(params, strings.Join(parts, "="))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(uniqueSorted(params), "")
+}
+
+// uniqueSorted removes duplicates from the given sorted strings, in place.
+func uniqueSorted(strs []string) []string {
+	unique := strs[:0]
+	for i, s := range strs {
+		if i == 0 || s != strs[i-1] {
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
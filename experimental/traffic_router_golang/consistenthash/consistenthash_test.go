@@ -0,0 +1,193 @@
+package consistenthash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestPatternBasedHashString(t *testing.T) {
+	tests := []struct {
+		regex    string
+		path     string
+		expected string
+	}{
+		{"", "/some/path/file.m3u8", "/some/path/file.m3u8"},
+		{`/.*?(/.*?/).*?(m3u8)`, "/test/path/asset.m3u8", "/path/m3u8"},
+		{`/.*?(/.*?/).*?(m3u8)`, "/test/path/asset.ts", "/test/path/asset.ts"},
+		{`/path/`, "/test/path/asset.m3u8", "/test/path/asset.m3u8"},
+		{`^/([^/]+)/`, "/asset/segment1.ts", "asset"},
+	}
+	for _, test := range tests {
+		regex := (*regexp.Regexp)(nil)
+		if test.regex != "" {
+			regex = regexp.MustCompile(test.regex)
+		}
+		if actual := PatternBasedHashString(regex, test.path); actual != test.expected {
+			t.Errorf("PatternBasedHashString(%q, %q) expected: %q, actual: %q", test.regex, test.path, test.expected, actual)
+		}
+	}
+}
+
+func TestHashStringQueryParams(t *testing.T) {
+	params := map[string]struct{}{"format": {}, "quality": {}}
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"", "/a"},
+		{"other=1", "/a"},
+		{"quality=hd&format=dash&other=1", "/aformat=dashquality=hd"},
+		{"format=dash&quality=hd", "/aformat=dashquality=hd"},
+		{"format=a%20b", "/aformat=a b"},
+		{"format=%zz", "/a"},
+	}
+	for _, test := range tests {
+		if actual := HashString(nil, params, "/a", test.query); actual != test.expected {
+			t.Errorf("HashString query %q expected: %q, actual: %q", test.query, test.expected, actual)
+		}
+	}
+}
+
+func testCRConfig(numCaches int) *tc.CRConfig {
+	crc := &tc.CRConfig{
+		ContentServers:   map[string]tc.CRConfigTrafficOpsServer{},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{},
+	}
+	hashCount := 100
+	for i := 0; i < numCaches; i++ {
+		crc.ContentServers["cache"+strconv.Itoa(i)] = tc.CRConfigTrafficOpsServer{HashCount: &hashCount}
+	}
+	regex := `^/([^/]+)/`
+	crc.DeliveryServices["ds"] = tc.CRConfigDeliveryService{ConsistentHashRegex: &regex}
+	return crc
+}
+
+func TestSelectStable(t *testing.T) {
+	h, errs := New(testCRConfig(10))
+	if len(errs) != 0 {
+		t.Fatalf("New expected: no errors, actual: %v", errs)
+	}
+
+	all := []tc.CacheName{}
+	for i := 0; i < 10; i++ {
+		all = append(all, tc.CacheName("cache"+strconv.Itoa(i)))
+	}
+
+	// requests whose hash strings are the same must go to the same cache
+	first, _ := h.Select("ds", all, "/asset/segment1.ts", "")
+	if second, _ := h.Select("ds", all, "/asset/segment2.ts", ""); first != second {
+		t.Errorf("Select with same consistentHashRegex match expected: same cache, actual: %v and %v", first, second)
+	}
+
+	selected := map[string]tc.CacheName{}
+	counts := map[tc.CacheName]int{}
+	for i := 0; i < 1000; i++ {
+		path := "/asset" + strconv.Itoa(i) + "/file"
+		cache, ok := h.Select("ds", all, path, "")
+		if !ok {
+			t.Fatalf("Select expected: a cache, actual: none")
+		}
+		selected[path] = cache
+		counts[cache]++
+	}
+	if len(counts) != len(all) {
+		t.Errorf("Select 1000 paths expected: all %v caches used, actual: %v", len(all), counts)
+	}
+
+	// removing caches must only move requests for the removed caches
+	unavailable := map[tc.CacheName]struct{}{"cache3": {}, "cache7": {}}
+	available := []tc.CacheName{}
+	for i := len(all) - 1; i >= 0; i-- { // reverse order, which must not matter
+		if _, ok := unavailable[all[i]]; !ok {
+			available = append(available, all[i])
+		}
+	}
+	for path, prev := range selected {
+		cache, _ := h.Select("ds", available, path, "")
+		if _, ok := unavailable[cache]; ok {
+			t.Fatalf("Select expected: available cache, actual: unavailable %v", cache)
+		}
+		if _, ok := unavailable[prev]; !ok && cache != prev {
+			t.Errorf("Select %v after other caches became unavailable expected: %v, actual: %v", path, prev, cache)
+		}
+	}
+}
+
+func TestSelectNoHashes(t *testing.T) {
+	h, _ := New(testCRConfig(2))
+	if _, ok := h.Select("ds", nil, "/a/b", ""); ok {
+		t.Errorf("Select with no caches expected: false, actual: true")
+	}
+	if cache, _ := h.Select("ds", []tc.CacheName{"unknown-b", "cache1", "unknown-a"}, "/a/b", ""); cache != "cache1" {
+		t.Errorf("Select expected: cache with hashes, actual: %v", cache)
+	}
+	if cache, _ := h.Select("ds", []tc.CacheName{"unknown-b", "unknown-a"}, "/a/b", ""); cache != "unknown-a" {
+		t.Errorf("Select with no hashes expected: first by name, actual: %v", cache)
+	}
+}
+
+func TestOrderHash(t *testing.T) {
+	h, _ := New(testCRConfig(5))
+	caches := []tc.CacheName{"unknown", "cache0", "cache1", "cache2", "cache3", "cache4"}
+	hash := Hash("edge.dnsds.cdn.example.com")
+
+	ordered := h.OrderHash(caches, hash)
+	if len(ordered) != len(caches) {
+		t.Fatalf("OrderHash expected: %v caches, actual: %v", len(caches), ordered)
+	}
+	if selected, _ := h.SelectHash(caches, hash); ordered[0] != selected {
+		t.Errorf("OrderHash expected: first %v, the SelectHash cache, actual: %v", selected, ordered[0])
+	}
+	if ordered[len(ordered)-1] != "unknown" {
+		t.Errorf("OrderHash expected: cache without hashes last, actual: %v", ordered)
+	}
+
+	// removing a cache must not reorder the others
+	available := []tc.CacheName{}
+	for _, cache := range caches {
+		if cache != ordered[1] {
+			available = append(available, cache)
+		}
+	}
+	expected := append(append([]tc.CacheName{}, ordered[:1]...), ordered[2:]...)
+	if actual := h.OrderHash(available, hash); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("OrderHash after %v became unavailable expected: %v, actual: %v", ordered[1], expected, actual)
+	}
+}
+
+func TestNewInvalidRegex(t *testing.T) {
+	crc := testCRConfig(1)
+	regex := `(`
+	crc.DeliveryServices["bad"] = tc.CRConfigDeliveryService{ConsistentHashRegex: &regex}
+	h, errs := New(crc)
+	if len(errs) != 1 {
+		t.Fatalf("New with invalid regex expected: 1 error, actual: %v", errs)
+	}
+	if _, ok := h.Select("bad", []tc.CacheName{"cache0"}, "/a/b", ""); !ok {
+		t.Errorf("Select for delivery service with invalid regex expected: cache, actual: none")
+	}
+}
@@ -25,81 +25,137 @@ import (
 	"errors"
 	"io/ioutil"
 	"net"
+	"sort"
 
+	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
 // TODO put in lib/go-tc
 type JSONCoverageZones struct {
 	CoverageZones map[tc.CacheGroupName]JSONCoverageZoneCacheGroup `json:"coverageZones"`
-	CustomerName  string                                           `json:"customerName"`
-	Revision      string                                           `json:"revision"` // TODO change to Time with Marshal func?
+	// DeepCoverageZones is the zones of a deep coverage zone file, whose zones have Caches.
+	DeepCoverageZones map[tc.CacheGroupName]JSONCoverageZoneCacheGroup `json:"deepCoverageZones"`
+	CustomerName      string                                           `json:"customerName"`
+	Revision          string                                           `json:"revision"` // TODO change to Time with Marshal func?
 }
 
 type JSONCoverageZoneCacheGroup struct {
 	Coordinates tc.CRConfigLatitudeLongitude // TODO rename CRConfigLatitudeLongitude
 	Network     []string                     // TODO change to IPNet with Unmarshal func
 	Network6    []string
+	// Caches is the caches of a deep coverage zone, which clients in the zone are routed to directly for deep caching delivery services.
+	Caches []tc.CacheName
 }
 
-type CoverageZone interface {
-	Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool)
+// Zone is a coverage zone, which is usually a cachegroup, or the caches of a deep coverage zone.
+type Zone struct {
+	Name   tc.CacheGroupName
+	Pos    tc.CRConfigLatitudeLongitude
+	Caches []tc.CacheName
 }
 
-type netPos struct {
-	net *net.IPNet
-	pos tc.CRConfigLatitudeLongitude
+// CoverageZone is the interface that wraps the Get and Zone methods.
+//
+// Get returns the coordinates of the coverage zone of the most specific network containing the given IP, or false if no network contains it.
+//
+// Zone returns the coverage zone of the most specific network containing the given IP, or false if no network contains it.
+type CoverageZone interface {
+	Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool)
+	Zone(ip net.IP) (Zone, bool)
 }
 
+// coverageZone finds the zones of IPs by longest-prefix match, with a trie for IPv4 networks and one for IPv6.
 type coverageZone struct {
-	nets  []netPos
-	net6s []netPos
+	nets  trie
+	net6s trie
 }
 
 func (c *coverageZone) Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool) {
-	nets := []netPos(nil)
-	if ip.To4() != nil {
-		nets = c.nets
-	} else {
-		nets = c.net6s
+	zone, ok := c.Zone(ip)
+	return zone.Pos, ok
+}
+
+func (c *coverageZone) Zone(ip net.IP) (Zone, bool) {
+	key := ipKey(ip)
+	nets := &c.net6s
+	if len(key) == net.IPv4len {
+		nets = &c.nets
+	} else if len(key) != net.IPv6len {
+		return Zone{}, false
 	}
-	for _, netPos := range nets {
-		if netPos.net.Contains(ip) {
-			return netPos.pos, true
-		}
+	zone, ok := nets.Get(key)
+	if !ok {
+		return Zone{}, false
+	}
+	return *zone, true
+}
+
+// insert inserts the given network of the given zone. If the network is already in another zone, it's logged, and the zone inserted first is kept.
+func (c *coverageZone) insert(cidr string, zone *Zone) error {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+	bits, _ := network.Mask.Size()
+	key := ipKey(network.IP)
+	nets := &c.net6s
+	if len(key) == net.IPv4len {
+		nets = &c.nets
 	}
-	return tc.CRConfigLatitudeLongitude{}, false
+	if prev := nets.Insert(key, bits, zone); prev != nil && prev != zone {
+		log.Warnln("coverage zone network '" + cidr + "' is in both '" + string(prev.Name) + "' and '" + string(zone.Name) + "', using '" + string(prev.Name) + "'")
+		nets.Insert(key, bits, prev)
+	}
+	return nil
 }
 
 func New(jcz JSONCoverageZones) (CoverageZone, error) {
 	c := coverageZone{}
-	for cg, jcg := range jcz.CoverageZones {
-		for _, cidr := range jcg.Network {
-			_, net, err := net.ParseCIDR(cidr)
-			if err != nil {
-				return nil, errors.New("error parsing cachegroup '" + string(cg) + "' Network '" + cidr + ": " + err.Error())
-			}
-			c.nets = append(c.nets, netPos{net: net, pos: jcg.Coordinates})
+	for _, jczs := range []map[tc.CacheGroupName]JSONCoverageZoneCacheGroup{jcz.CoverageZones, jcz.DeepCoverageZones} {
+		// insert in name order, so a network in multiple zones is always in the same one.
+		names := make([]string, 0, len(jczs))
+		for cg, _ := range jczs {
+			names = append(names, string(cg))
 		}
-		for _, cidr := range jcg.Network6 {
-			_, network, err := net.ParseCIDR(cidr)
-			if err != nil {
-				return nil, errors.New("error parsing cachegroup '" + string(cg) + "' Network6 '" + cidr + ": " + err.Error())
+		sort.Strings(names)
+
+		for _, name := range names {
+			cg := tc.CacheGroupName(name)
+			jcg := jczs[cg]
+			zone := &Zone{Name: cg, Pos: jcg.Coordinates, Caches: jcg.Caches}
+			for _, cidr := range jcg.Network {
+				if err := c.insert(cidr, zone); err != nil {
+					return nil, errors.New("error parsing cachegroup '" + string(cg) + "' Network '" + cidr + ": " + err.Error())
+				}
+			}
+			for _, cidr := range jcg.Network6 {
+				if err := c.insert(cidr, zone); err != nil {
+					return nil, errors.New("error parsing cachegroup '" + string(cg) + "' Network6 '" + cidr + ": " + err.Error())
+				}
 			}
-			c.net6s = append(c.net6s, netPos{net: network, pos: jcg.Coordinates})
 		}
 	}
 	return &c, nil
 }
 
+// Parse parses the given coverage zone file contents, which may be a coverage zone or deep coverage zone file.
+func Parse(bts []byte) (CoverageZone, error) {
+	jcz := JSONCoverageZones{}
+	if err := json.Unmarshal(bts, &jcz); err != nil {
+		return nil, errors.New("unmarshalling JSON: " + err.Error())
+	}
+	return New(jcz)
+}
+
 func Load(filename string) (CoverageZone, error) {
 	f, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, errors.New("reading file '" + filename + "':" + err.Error())
 	}
-	jcz := JSONCoverageZones{}
-	if err := json.Unmarshal(f, &jcz); err != nil {
-		return nil, errors.New("unmarshalling JSON '" + filename + "':" + err.Error())
+	cz, err := Parse(f)
+	if err != nil {
+		return nil, errors.New("parsing file '" + filename + "':" + err.Error())
 	}
-	return New(jcz)
+	return cz, nil
 }
@@ -0,0 +1,175 @@
+package coveragezone
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestCoverageZoneLongestPrefix(t *testing.T) {
+	jcz := JSONCoverageZones{CoverageZones: map[tc.CacheGroupName]JSONCoverageZoneCacheGroup{
+		"wide": {
+			Coordinates: tc.CRConfigLatitudeLongitude{Lat: 1, Lon: 1},
+			Network:     []string{"10.0.0.0/8", "192.168.0.0/16"},
+			Network6:    []string{"2001:db8::/32"},
+		},
+		"narrow": {
+			Coordinates: tc.CRConfigLatitudeLongitude{Lat: 2, Lon: 2},
+			Network:     []string{"10.1.0.0/16"},
+			Network6:    []string{"2001:db8:1::/48"},
+		},
+		"narrowest": {
+			Coordinates: tc.CRConfigLatitudeLongitude{Lat: 3, Lon: 3},
+			Network:     []string{"10.1.2.0/24", "10.1.2.3/32"},
+			Network6:    []string{"2001:db8:1:2::/64"},
+		},
+	}}
+
+	cz, err := New(jcz)
+	if err != nil {
+		t.Fatalf("New expected: nil error, actual: %v", err)
+	}
+
+	tests := []struct {
+		ip       string
+		expected tc.CacheGroupName
+	}{
+		{"10.0.0.1", "wide"},
+		{"10.255.255.255", "wide"},
+		{"10.1.0.1", "narrow"},
+		{"10.1.3.1", "narrow"},
+		{"10.1.2.1", "narrowest"},
+		{"10.1.2.3", "narrowest"},
+		{"192.168.1.1", "wide"},
+		{"::ffff:10.1.2.1", "narrowest"},
+		{"2001:db8::1", "wide"},
+		{"2001:db8:1::1", "narrow"},
+		{"2001:db8:1:2::1", "narrowest"},
+		{"2001:db8:1:3::1", "narrow"},
+		{"11.0.0.1", ""},
+		{"192.169.0.1", ""},
+		{"2001:db9::1", ""},
+		{"::1", ""},
+	}
+	for _, test := range tests {
+		ip := net.ParseIP(test.ip)
+		zone, ok := cz.Zone(ip)
+		if test.expected == "" {
+			if ok {
+				t.Errorf("Zone(%v) expected: not found, actual: %v", test.ip, zone.Name)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("Zone(%v) expected: %v, actual: not found", test.ip, test.expected)
+			continue
+		}
+		if zone.Name != test.expected {
+			t.Errorf("Zone(%v) expected: %v, actual: %v", test.ip, test.expected, zone.Name)
+		}
+		expectedPos := jcz.CoverageZones[test.expected].Coordinates
+		if pos, _ := cz.Get(ip); pos.Lat != expectedPos.Lat || pos.Lon != expectedPos.Lon {
+			t.Errorf("Get(%v) expected: %+v, actual: %+v", test.ip, expectedPos, pos)
+		}
+	}
+}
+
+func TestCoverageZoneDuplicateNetwork(t *testing.T) {
+	jcz := JSONCoverageZones{CoverageZones: map[tc.CacheGroupName]JSONCoverageZoneCacheGroup{
+		"b": {Network: []string{"10.0.0.0/8"}},
+		"a": {Network: []string{"10.0.0.0/8"}},
+	}}
+	for i := 0; i < 10; i++ {
+		cz, err := New(jcz)
+		if err != nil {
+			t.Fatalf("New expected: nil error, actual: %v", err)
+		}
+		if zone, _ := cz.Zone(net.ParseIP("10.0.0.1")); zone.Name != "a" {
+			t.Fatalf("Zone with duplicate network expected: a, actual: '%v'", zone.Name)
+		}
+	}
+}
+
+func TestCoverageZoneInvalidNetwork(t *testing.T) {
+	jcz := JSONCoverageZones{CoverageZones: map[tc.CacheGroupName]JSONCoverageZoneCacheGroup{
+		"a": {Network: []string{"10.0.0.0/33"}},
+	}}
+	if _, err := New(jcz); err == nil {
+		t.Errorf("New with invalid network expected: error, actual: nil")
+	}
+}
+
+func TestParseDeepCoverageZone(t *testing.T) {
+	cz, err := Parse([]byte(`{
+  "deepCoverageZones": {
+    "deep-a": {
+      "network": ["10.2.0.0/16"],
+      "network6": ["2001:db8:2::/48"],
+      "caches": ["edge-a-1", "edge-a-2"]
+    }
+  },
+  "customerName": "Kabletown",
+  "revision": "Tue Jul 25 11:25:35 MDT 2018"
+}`))
+	if err != nil {
+		t.Fatalf("Parse expected: nil error, actual: %v", err)
+	}
+
+	for _, ip := range []string{"10.2.3.4", "2001:db8:2::1"} {
+		zone, ok := cz.Zone(net.ParseIP(ip))
+		if !ok {
+			t.Fatalf("Zone(%v) expected: deep-a, actual: not found", ip)
+		}
+		if zone.Name != "deep-a" {
+			t.Errorf("Zone(%v) expected: deep-a, actual: %v", ip, zone.Name)
+		}
+		if len(zone.Caches) != 2 || zone.Caches[0] != "edge-a-1" || zone.Caches[1] != "edge-a-2" {
+			t.Errorf("Zone(%v) caches expected: [edge-a-1 edge-a-2], actual: %v", ip, zone.Caches)
+		}
+	}
+	if _, ok := cz.Zone(net.ParseIP("10.3.0.1")); ok {
+		t.Errorf("Zone(10.3.0.1) expected: not found, actual: found")
+	}
+}
+
+func TestThs(t *testing.T) {
+	ths := NewThs()
+	ip := net.ParseIP("10.0.0.1")
+	if _, ok := ths.Zone(ip); ok {
+		t.Fatalf("Zone before Set expected: not found, actual: found")
+	}
+
+	for _, name := range []tc.CacheGroupName{"a", "b"} {
+		cz, err := New(JSONCoverageZones{CoverageZones: map[tc.CacheGroupName]JSONCoverageZoneCacheGroup{
+			name: {Network: []string{"10.0.0.0/8"}},
+		}})
+		if err != nil {
+			t.Fatalf("New expected: nil error, actual: %v", err)
+		}
+		ths.Set(cz)
+		if zone, _ := ths.Zone(ip); zone.Name != name {
+			t.Errorf("Zone after Set expected: %v, actual: '%v'", name, zone.Name)
+		}
+	}
+}
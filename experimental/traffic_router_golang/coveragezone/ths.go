@@ -0,0 +1,68 @@
+package coveragezone
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net"
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// Ths provides threadsafe access to a CoverageZone, which may be replaced with Set while other goroutines are looking up IPs, e.g. when the coverage zone file is reloaded. Ths itself implements CoverageZone, using the CoverageZone most recently Set. Before Set is called, no IP is in any zone.
+type Ths struct {
+	v *CoverageZone
+	m *sync.RWMutex
+}
+
+func NewThs() Ths {
+	v := CoverageZone(nil)
+	return Ths{m: &sync.RWMutex{}, v: &v}
+}
+
+func (t Ths) Set(v CoverageZone) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	*t.v = v
+}
+
+// CoverageZone returns the CoverageZone most recently Set, or nil if Set has never been called.
+func (t Ths) CoverageZone() CoverageZone {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return *t.v
+}
+
+func (t Ths) Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool) {
+	cz := t.CoverageZone()
+	if cz == nil {
+		return tc.CRConfigLatitudeLongitude{}, false
+	}
+	return cz.Get(ip)
+}
+
+func (t Ths) Zone(ip net.IP) (Zone, bool) {
+	cz := t.CoverageZone()
+	if cz == nil {
+		return Zone{}, false
+	}
+	return cz.Zone(ip)
+}
@@ -0,0 +1,123 @@
+package coveragezone
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net"
+)
+
+// trie is a binary Patricia trie of IP networks, for longest-prefix matching. Paths without branches are compressed into a single node, so lookups take at most as many steps as there are distinct prefix lengths along the path, and never more than the number of bits in the address.
+// All keys of a trie must be the same length, either 4 bytes for IPv4 or 16 for IPv6.
+type trie struct {
+	root *trieNode
+}
+
+type trieNode struct {
+	// ip is the node's network address. Only the first `bits` bits are meaningful.
+	ip   []byte
+	bits int
+	// zone is the zone of the network, or nil if this node is only a branch.
+	zone  *Zone
+	child [2]*trieNode
+}
+
+// Insert inserts the given network. If the network already exists, its zone is replaced, and the previous zone is returned.
+func (t *trie) Insert(ip []byte, bits int, zone *Zone) *Zone {
+	n := &t.root
+	for {
+		if *n == nil {
+			*n = &trieNode{ip: ip, bits: bits, zone: zone}
+			return nil
+		}
+		node := *n
+		common := commonPrefixLen(ip, node.ip, minInt(bits, node.bits))
+		if common < node.bits {
+			// the new network diverges from, or contains, this node: split it.
+			branch := &trieNode{ip: ip, bits: common}
+			branch.child[bitAt(node.ip, common)] = node
+			if common == bits {
+				branch.zone = zone
+			} else {
+				branch.child[bitAt(ip, common)] = &trieNode{ip: ip, bits: bits, zone: zone}
+			}
+			*n = branch
+			return nil
+		}
+		if bits == node.bits {
+			prev := node.zone
+			node.zone = zone
+			return prev
+		}
+		n = &node.child[bitAt(ip, node.bits)]
+	}
+}
+
+// Get returns the zone of the most specific network containing the given IP, or false if no network contains it.
+func (t *trie) Get(ip []byte) (*Zone, bool) {
+	found := (*Zone)(nil)
+	n := t.root
+	for n != nil {
+		if commonPrefixLen(ip, n.ip, n.bits) < n.bits {
+			break
+		}
+		if n.zone != nil {
+			found = n.zone
+		}
+		if n.bits == len(ip)*8 {
+			break
+		}
+		n = n.child[bitAt(ip, n.bits)]
+	}
+	return found, found != nil
+}
+
+// commonPrefixLen returns the number of leading bits a and b have in common, up to max.
+func commonPrefixLen(a []byte, b []byte, max int) int {
+	i := 0
+	for ; i+8 <= max && a[i/8] == b[i/8]; i += 8 {
+	}
+	for ; i < max; i++ {
+		if bitAt(a, i) != bitAt(b, i) {
+			break
+		}
+	}
+	return i
+}
+
+// bitAt returns the bit of ip at the given index, where 0 is the most significant bit of the first byte.
+func bitAt(ip []byte, i int) int {
+	return int(ip[i/8]>>(7-uint(i%8))) & 1
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// ipKey returns the trie key of the given IP: 4 bytes for IPv4, including IPv4-mapped IPv6 addresses, else 16.
+func ipKey(ip net.IP) []byte {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip.To16()
+}
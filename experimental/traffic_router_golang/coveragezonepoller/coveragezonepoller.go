@@ -0,0 +1,71 @@
+package coveragezonepoller
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
+)
+
+// Start fetches the coverage zone file, and re-fetches it every interval, replacing the coverage zone when the file changes. Requests being served with the returned Ths use the previous coverage zone until the new one is fully built, so reloading never drops requests. If a new file is invalid, it's logged and the previous coverage zone continues to be used.
+//
+// Start returns an error if the initial coverage zone can't be fetched or parsed, but still continues polling, and the returned Ths is usable.
+func Start(fetcher fetch.Fetcher, interval time.Duration) (coveragezone.Ths, error) {
+	ths := coveragezone.NewThs()
+	prevBts := []byte{}
+
+	get := func() error {
+		newBts, err := fetcher.Fetch()
+		if err != nil {
+			return errors.New("fetching: " + err.Error())
+		}
+		if bytes.Equal(newBts, prevBts) {
+			return nil
+		}
+		cz, err := coveragezone.Parse(newBts)
+		if err != nil {
+			return errors.New("not using invalid new coverage zone: " + err.Error())
+		}
+		ths.Set(cz)
+		prevBts = newBts
+		fmt.Println("INFO coverage zone set new")
+		return nil
+	}
+
+	initialErr := get()
+
+	if interval > 0 {
+		go func() {
+			for {
+				time.Sleep(interval)
+				if err := get(); err != nil {
+					fmt.Println("ERROR coverage zone " + err.Error())
+				}
+			}
+		}()
+	}
+	return ths, initialErr
+}
@@ -29,10 +29,10 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/consistenthash"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigregex"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
-	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/nextcache"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
@@ -73,21 +73,12 @@ func createCGSearcher(crc *tc.CRConfig) (cgsrch.Ths, error) {
 	return ths, nil
 }
 
-// createNextCacher creates and returns a NextCacher, which can be used to get the next cache to use for each Delivery Service (e.g. via round-robin, consistent hash, etc).
-func createNextCacher(crc *tc.CRConfig) nextcache.NextCacher {
-	dses := make([]tc.DeliveryServiceName, 0, len(crc.DeliveryServices))
-	for ds, _ := range crc.DeliveryServices {
-		dses = append(dses, tc.DeliveryServiceName(ds))
-	}
-	return nextcache.New(dses)
-}
-
 // TODO implement HTTP poller
-func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfigregex.Ths, cgsrch.Ths, nextcache.Ths, error) {
+func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfigregex.Ths, cgsrch.Ths, consistenthash.Ths, error) {
 	thsCrcRgx := crconfigregex.NewThs()
 	thsCrc := crconfig.NewThs()
 	thsCGSearcher := cgsrch.NewThs()
-	thsNextCacher := nextcache.NewThs()
+	thsHasher := consistenthash.NewThs()
 	prevBts := []byte{}
 	prevCrc := (*tc.CRConfig)(nil)
 
@@ -124,9 +115,12 @@ func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfi
 		if err != nil {
 			fmt.Println("ERROR not using invalid new CRConfig: failed to create Cachegroup searcher: " + err.Error())
 		}
-		nextCacher := createNextCacher(crc)
+		hasher, errs := consistenthash.New(crc)
+		for _, err := range errs {
+			fmt.Println("ERROR CRConfig consistent hash, using full request path: " + err.Error())
+		}
 
-		thsNextCacher.Set(nextCacher)
+		thsHasher.Set(hasher)
 		thsCGSearcher.Set(cgSearcher)
 		thsCrc.Set(crc)
 		thsCrcRgx.Set(&crcRgx)
@@ -143,5 +137,5 @@ func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfi
 			get()
 		}
 	}()
-	return thsCrc, thsCrcRgx, thsCGSearcher, thsNextCacher, nil
+	return thsCrc, thsCrcRgx, thsCGSearcher, thsHasher, nil
 }
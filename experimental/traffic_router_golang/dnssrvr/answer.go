@@ -0,0 +1,427 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/consistenthash"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigregex"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// TODO config, share with httpsrvr
+var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.579244, Lon: -104.934282}
+
+// Handler answers DNS queries from the CRConfig and the available servers. It is safe for use by multiple goroutines.
+type Handler struct {
+	crc        crconfig.Ths
+	regexes    crconfigregex.Ths
+	availSrvrs availableservers.AvailableServers
+	cgSrch     cgsrch.Ths
+	hasher     consistenthash.Ths
+	cz         coveragezone.CoverageZone
+	deepCZ     coveragezone.CoverageZone
+
+	// zones is created from zonesCRC, and recreated when the CRConfig changes.
+	zones    Zones
+	zonesCRC *tc.CRConfig
+	zonesM   *sync.Mutex
+}
+
+func NewHandler(
+	crc crconfig.Ths,
+	regexes crconfigregex.Ths,
+	availSrvrs availableservers.AvailableServers,
+	cgSrch cgsrch.Ths,
+	hasher consistenthash.Ths,
+	cz coveragezone.CoverageZone,
+	deepCZ coveragezone.CoverageZone,
+) *Handler {
+	return &Handler{
+		crc:        crc,
+		regexes:    regexes,
+		availSrvrs: availSrvrs,
+		cgSrch:     cgSrch,
+		hasher:     hasher,
+		cz:         cz,
+		deepCZ:     deepCZ,
+		zonesM:     &sync.Mutex{},
+	}
+}
+
+// getZones returns the Zones of the given CRConfig, creating them if the CRConfig changed since the last call.
+func (h *Handler) getZones(crc *tc.CRConfig) Zones {
+	h.zonesM.Lock()
+	defer h.zonesM.Unlock()
+	if h.zonesCRC != crc {
+		h.zones = NewZones(crc)
+		h.zonesCRC = crc
+	}
+	return h.zones
+}
+
+// Answer returns the response to the given query, from the given client IP.
+func (h *Handler) Answer(query dnsmessage.Message, clientIP net.IP) dnsmessage.Message {
+	resp := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               query.ID,
+			Response:         true,
+			OpCode:           query.OpCode,
+			RecursionDesired: query.RecursionDesired,
+		},
+		Questions: query.Questions,
+	}
+	if query.OpCode != 0 {
+		resp.RCode = dnsmessage.RCodeNotImplemented
+		return resp
+	}
+	if len(query.Questions) != 1 {
+		resp.RCode = dnsmessage.RCodeFormatError
+		return resp
+	}
+	q := query.Questions[0]
+	if q.Class != dnsmessage.ClassINET && q.Class != dnsmessage.ClassANY {
+		resp.RCode = dnsmessage.RCodeRefused
+		return resp
+	}
+
+	crc := (*tc.CRConfig)(h.crc.Get())
+	if crc == nil {
+		log.Errorln("DNS query for '" + q.Name.String() + "' but no CRConfig, returning SERVFAIL")
+		resp.RCode = dnsmessage.RCodeServerFailure
+		return resp
+	}
+	zones := h.getZones(crc)
+
+	name := normalizeName(q.Name.String())
+	apex, apexDS, ok := zones.Zone(name)
+	if !ok {
+		log.Infoln("DNS query for '" + name + "' not in any zone, returning REFUSED")
+		resp.RCode = dnsmessage.RCodeRefused
+		return resp
+	}
+	resp.Authoritative = true
+
+	apexDSData := (*tc.CRConfigDeliveryService)(nil)
+	if ds, ok := crc.DeliveryServices[string(apexDS)]; ok {
+		apexDSData = &ds
+	}
+	r := responder{msg: &resp, zones: zones, apex: apex, apexDS: apexDSData}
+
+	if name == apex {
+		switch q.Type {
+		case dnsmessage.TypeSOA:
+			r.answerSOA()
+		case dnsmessage.TypeNS:
+			r.answerNS()
+		default:
+			r.noData()
+		}
+		return resp
+	}
+
+	if entries, ok := zones.Static[name]; ok {
+		r.answerStatic(name, q.Type, entries)
+		return resp
+	}
+
+	fqdnParts := strings.SplitN(name, ".", 3)
+	if len(fqdnParts) < 3 {
+		r.nxDomain()
+		return resp
+	}
+	subsubdomain, subdomain, domain := fqdnParts[0], fqdnParts[1], fqdnParts[2]
+	dsName, ok := (*crconfigregex.Regexes)(h.regexes.Get()).DeliveryService(domain, subdomain, subsubdomain)
+	if !ok {
+		log.Infoln("DNS query for '" + name + "' has no match, returning NXDOMAIN")
+		r.nxDomain()
+		return resp
+	}
+	ds, ok := crc.DeliveryServices[string(dsName)]
+	if !ok {
+		log.Errorln("DNS query for '" + name + "' matched '" + string(dsName) + "' which isn't in the CRConfig, returning SERVFAIL")
+		resp.RCode = dnsmessage.RCodeServerFailure
+		return resp
+	}
+
+	if dsProtocol(ds) != DSProtocolDNS {
+		// HTTP delivery services are routed by this router's HTTP server, so their names resolve to the routers.
+		r.answerAddrs(name, q.Type, &ds, zones.RouterIPs, zones.RouterIP6s)
+		return resp
+	}
+
+	if ds.RoutingName != nil && *ds.RoutingName != "" && !strings.EqualFold(*ds.RoutingName, subsubdomain) {
+		log.Infoln("DNS query for '" + name + "' matched '" + string(dsName) + "' but not its routing name '" + *ds.RoutingName + "', returning NXDOMAIN")
+		r.nxDomain()
+		return resp
+	}
+
+	if q.Type != dnsmessage.TypeA && q.Type != dnsmessage.TypeAAAA && q.Type != dnsmessage.TypeALL {
+		r.noData()
+		return resp
+	}
+
+	ips, ip6s, err := h.cacheIPs(crc, dsName, ds, name, clientIP)
+	if err != nil {
+		log.Errorln("DNS query for '" + name + "' from " + clientIP.String() + " ds '" + string(dsName) + "' returning SERVFAIL: " + err.Error())
+		resp.RCode = dnsmessage.RCodeServerFailure
+		return resp
+	}
+	log.Infof("DNS query for '%v' from %v matched '%v' returning %v %v\n", name, clientIP, dsName, ips, ip6s)
+	r.answerAddrs(name, q.Type, &ds, ips, ip6s)
+	return resp
+}
+
+// cacheIPs returns the IPv4 and IPv6 addresses of the available caches of the given delivery service for the client (see servers), up to the delivery service's maxDnsIpsForLocation. IPv6 addresses are only returned if the delivery service has IPv6 routing enabled.
+// The caches are ordered by consistent hash of the queried name, like Traffic Router, so a name keeps resolving to the same caches until they become unavailable.
+func (h *Handler) cacheIPs(crc *tc.CRConfig, dsName tc.DeliveryServiceName, ds tc.CRConfigDeliveryService, name string, clientIP net.IP) ([]net.IP, []net.IP, error) {
+	srvrs, err := h.servers(dsName, ds, clientIP)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hasher := (*consistenthash.Hasher)(h.hasher.Get()); hasher != nil {
+		srvrs = hasher.OrderHash(srvrs, consistenthash.Hash(name))
+	}
+
+	maxIPs := len(srvrs)
+	if ds.MaxDNSIPsForLocation != nil && *ds.MaxDNSIPsForLocation > 0 && *ds.MaxDNSIPsForLocation < maxIPs {
+		maxIPs = *ds.MaxDNSIPsForLocation
+	}
+	ip6Enabled := ds.IP6RoutingEnabled != nil && *ds.IP6RoutingEnabled
+
+	ips := []net.IP{}
+	ip6s := []net.IP{}
+	for i := 0; i < len(srvrs) && (len(ips) < maxIPs || len(ip6s) < maxIPs); i++ {
+		srvr, ok := crc.ContentServers[string(srvrs[i])]
+		if !ok {
+			continue
+		}
+		if srvr.Ip != nil && len(ips) < maxIPs {
+			if ip := net.ParseIP(*srvr.Ip).To4(); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+		if ip6Enabled && srvr.Ip6 != nil && len(ip6s) < maxIPs {
+			if ip := parseIP6(*srvr.Ip6); ip != nil {
+				ip6s = append(ip6s, ip)
+			}
+		}
+	}
+	return ips, ip6s, nil
+}
+
+// servers returns the available caches of the given delivery service for the client: those of the client's deep coverage zone, if the delivery service uses deep caching and the client is in a deep coverage zone with available caches, otherwise those of the cachegroup nearest the client.
+func (h *Handler) servers(dsName tc.DeliveryServiceName, ds tc.CRConfigDeliveryService, clientIP net.IP) ([]tc.CacheName, error) {
+	if srvrs, zone, ok := h.availSrvrs.Deep(dsName, ds.DeepCachingType, h.deepCZ, clientIP); ok {
+		log.Infoln("DNS request from " + clientIP.String() + " ds '" + string(dsName) + "' in deep coverage zone '" + string(zone) + "'")
+		return srvrs, nil
+	}
+
+	pos, ok := h.cz.Get(clientIP)
+	if !ok {
+		pos = DefaultPos
+		log.Warnln("DNS request from " + clientIP.String() + " not found, using default")
+	}
+
+	cgSrch := h.cgSrch.Get()
+	if cgSrch == nil {
+		return nil, fmt.Errorf("no cachegroup searcher")
+	}
+	cgDat, ok := cgSrch.Nearest(pos.Lat, pos.Lon)
+	if !ok {
+		return nil, fmt.Errorf("no nearest cachegroup (should only happen if there are no cachegroups)")
+	}
+	cg := tc.CacheGroupName(cgDat.Obj)
+
+	srvrs, err := h.availSrvrs.Get(dsName, cg)
+	if err != nil {
+		return nil, fmt.Errorf("getting available servers for cachegroup '%v': %v", cg, err)
+	}
+	if len(srvrs) == 0 {
+		return nil, fmt.Errorf("no available servers in cachegroup '%v'", cg)
+	}
+
+	return srvrs, nil
+}
+
+// responder adds records to a response in a zone.
+type responder struct {
+	msg    *dnsmessage.Message
+	zones  Zones
+	apex   string
+	apexDS *tc.CRConfigDeliveryService
+}
+
+func (r responder) answerSOA() {
+	r.msg.Answers = append(r.msg.Answers, r.soa())
+}
+
+func (r responder) answerNS() {
+	ttl := r.zones.TTL(r.apexDS, "NS", DefaultNSTTL)
+	for _, nsName := range r.zones.NSNames {
+		r.msg.Answers = append(r.msg.Answers, resource(r.apex, dnsmessage.TypeNS, ttl, &dnsmessage.NSResource{NS: mustName(nsName)}))
+	}
+	for _, nsName := range r.zones.NSNames {
+		for _, ip := range r.zones.RouterAddrs[nsName] {
+			r.msg.Additionals = append(r.msg.Additionals, addrResource(nsName, ttl, ip))
+		}
+	}
+}
+
+// answerAddrs answers the given A or AAAA query with the given addresses, or both for ANY queries. If there are no addresses of the query type, the answer is empty.
+func (r responder) answerAddrs(name string, qType dnsmessage.Type, ds *tc.CRConfigDeliveryService, ips []net.IP, ip6s []net.IP) {
+	if qType == dnsmessage.TypeA || qType == dnsmessage.TypeALL {
+		ttl := r.zones.TTL(ds, "A", DefaultTTL)
+		for _, ip := range ips {
+			r.msg.Answers = append(r.msg.Answers, addrResource(name, ttl, ip))
+		}
+	}
+	if qType == dnsmessage.TypeAAAA || qType == dnsmessage.TypeALL {
+		ttl := r.zones.TTL(ds, "AAAA", DefaultTTL)
+		for _, ip := range ip6s {
+			r.msg.Answers = append(r.msg.Answers, addrResource(name, ttl, ip))
+		}
+	}
+	if len(r.msg.Answers) == 0 {
+		r.noData()
+	}
+}
+
+// answerStatic answers the given query with the given static DNS entries of its name. If there's a CNAME entry and no entry of the query type, the CNAME is returned.
+func (r responder) answerStatic(name string, qType dnsmessage.Type, entries []tc.CRConfigStaticDNSEntry) {
+	cnames := []dnsmessage.Resource{}
+	for _, entry := range entries {
+		rr, ok := staticResource(name, entry)
+		if !ok {
+			log.Warnln("DNS static entry '" + name + "' type '" + entry.Type + "' value '" + entry.Value + "' invalid, skipping")
+			continue
+		}
+		if rr.Header.Type == qType || qType == dnsmessage.TypeALL {
+			r.msg.Answers = append(r.msg.Answers, rr)
+		} else if rr.Header.Type == dnsmessage.TypeCNAME {
+			cnames = append(cnames, rr)
+		}
+	}
+	if len(r.msg.Answers) == 0 {
+		r.msg.Answers = cnames
+	}
+	if len(r.msg.Answers) == 0 {
+		r.noData()
+	}
+}
+
+// noData makes the response an empty answer, with the zone's SOA, for names which exist but have no records of the query type.
+func (r responder) noData() {
+	r.msg.Authorities = append(r.msg.Authorities, r.soa())
+}
+
+func (r responder) nxDomain() {
+	r.msg.RCode = dnsmessage.RCodeNameError
+	r.msg.Authorities = append(r.msg.Authorities, r.soa())
+}
+
+func (r responder) soa() dnsmessage.Resource {
+	nsName := r.apex
+	if len(r.zones.NSNames) > 0 {
+		nsName = r.zones.NSNames[0]
+	}
+	return resource(r.apex, dnsmessage.TypeSOA, r.zones.TTL(r.apexDS, "SOA", DefaultSOATTL), &dnsmessage.SOAResource{
+		NS:      mustName(nsName),
+		MBox:    mustName(r.zones.SOA.MBox),
+		Serial:  r.zones.SOA.Serial,
+		Refresh: r.zones.SOA.Refresh,
+		Retry:   r.zones.SOA.Retry,
+		Expire:  r.zones.SOA.Expire,
+		MinTTL:  r.zones.SOA.Minimum,
+	})
+}
+
+// staticResource returns the resource record of the given static DNS entry. Returns false if the entry's type isn't supported, or its value is invalid for its type.
+func staticResource(name string, entry tc.CRConfigStaticDNSEntry) (dnsmessage.Resource, bool) {
+	ttl := uint32(DefaultTTL)
+	if entry.TTL >= 0 {
+		ttl = uint32(entry.TTL)
+	}
+	switch strings.ToUpper(entry.Type) {
+	case "A":
+		ip := net.ParseIP(entry.Value).To4()
+		if ip == nil {
+			return dnsmessage.Resource{}, false
+		}
+		return addrResource(name, ttl, ip), true
+	case "AAAA":
+		ip := parseIP6(entry.Value)
+		if ip == nil {
+			return dnsmessage.Resource{}, false
+		}
+		return addrResource(name, ttl, ip), true
+	case "CNAME":
+		target, err := dnsmessage.NewName(normalizeName(entry.Value) + ".")
+		if err != nil {
+			return dnsmessage.Resource{}, false
+		}
+		return resource(name, dnsmessage.TypeCNAME, ttl, &dnsmessage.CNAMEResource{CNAME: target}), true
+	case "TXT":
+		return resource(name, dnsmessage.TypeTXT, ttl, &dnsmessage.TXTResource{TXT: []string{entry.Value}}), true
+	default:
+		return dnsmessage.Resource{}, false
+	}
+}
+
+// addrResource returns an A record for IPv4 addresses, or an AAAA record for IPv6.
+func addrResource(name string, ttl uint32, ip net.IP) dnsmessage.Resource {
+	if ip4 := ip.To4(); ip4 != nil {
+		a := dnsmessage.AResource{}
+		copy(a.A[:], ip4)
+		return resource(name, dnsmessage.TypeA, ttl, &a)
+	}
+	aaaa := dnsmessage.AAAAResource{}
+	copy(aaaa.AAAA[:], ip.To16())
+	return resource(name, dnsmessage.TypeAAAA, ttl, &aaaa)
+}
+
+func resource(name string, rrType dnsmessage.Type, ttl uint32, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(name), Type: rrType, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   body,
+	}
+}
+
+// mustName returns the DNS name of the given normalized name. If the name is invalid, the error is logged and the empty name returned, which will fail to pack, and the response will be SERVFAIL.
+func mustName(name string) dnsmessage.Name {
+	n, err := dnsmessage.NewName(name + ".")
+	if err != nil {
+		log.Errorln("creating DNS name '" + name + "': " + err.Error())
+	}
+	return n
+}
@@ -0,0 +1,228 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// MinUDPSize is the largest UDP response to clients which don't send an EDNS0 OPT record.
+const MinUDPSize = 512
+
+// MaxUDPSize is the largest UDP response, regardless of the client's EDNS0 payload size.
+const MaxUDPSize = 4096
+
+// TCPTimeout is how long a TCP connection may be idle before it's closed.
+const TCPTimeout = 10 * time.Second
+
+// Server is an authoritative DNS server, serving UDP and TCP.
+type Server struct {
+	h   *Handler
+	udp net.PacketConn
+	tcp net.Listener
+}
+
+// Start starts serving DNS on the given port, over both UDP and TCP.
+func Start(h *Handler, port uint) (*Server, error) {
+	addr := ":" + strconv.Itoa(int(port))
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, errors.New("listening on UDP " + addr + ": " + err.Error())
+	}
+	tcp, err := net.Listen("tcp", addr)
+	if err != nil {
+		udp.Close()
+		return nil, errors.New("listening on TCP " + addr + ": " + err.Error())
+	}
+	return Serve(h, udp, tcp), nil
+}
+
+// Serve starts serving DNS on the given UDP and TCP listeners, which are closed when the Server is closed.
+func Serve(h *Handler, udp net.PacketConn, tcp net.Listener) *Server {
+	s := &Server{h: h, udp: udp, tcp: tcp}
+	go s.serveUDP()
+	go s.serveTCP()
+	return s
+}
+
+// Close stops serving, and closes the listeners.
+func (s *Server) Close() error {
+	udpErr := s.udp.Close()
+	tcpErr := s.tcp.Close()
+	if udpErr != nil {
+		return errors.New("closing UDP: " + udpErr.Error())
+	}
+	if tcpErr != nil {
+		return errors.New("closing TCP: " + tcpErr.Error())
+	}
+	return nil
+}
+
+func (s *Server) serveUDP() {
+	buf := make([]byte, MaxUDPSize)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			log.Infoln("DNS UDP listener stopped: " + err.Error())
+			return
+		}
+		req := make([]byte, n)
+		copy(req, buf[:n])
+		go func() {
+			clientIP := net.IP(nil)
+			if udpAddr, ok := addr.(*net.UDPAddr); ok {
+				clientIP = udpAddr.IP
+			}
+			resp, err := s.handle(req, clientIP, true)
+			if err != nil {
+				log.Errorln("DNS UDP request from " + addr.String() + ": " + err.Error())
+				return
+			}
+			if _, err := s.udp.WriteTo(resp, addr); err != nil {
+				log.Errorln("DNS UDP writing response to " + addr.String() + ": " + err.Error())
+			}
+		}()
+	}
+}
+
+func (s *Server) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			log.Infoln("DNS TCP listener stopped: " + err.Error())
+			return
+		}
+		go s.serveTCPConn(conn)
+	}
+}
+
+// serveTCPConn serves queries on the given TCP connection until it's closed or idle for TCPTimeout. Each message is prefixed with its 2-byte length, per RFC 1035 section 4.2.2.
+func (s *Server) serveTCPConn(conn net.Conn) {
+	defer conn.Close()
+	clientIP := net.IP(nil)
+	if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		clientIP = tcpAddr.IP
+	}
+	for {
+		conn.SetDeadline(time.Now().Add(TCPTimeout))
+		lenBts := make([]byte, 2)
+		if _, err := io.ReadFull(conn, lenBts); err != nil {
+			return // client closed, or idle
+		}
+		req := make([]byte, binary.BigEndian.Uint16(lenBts))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			log.Errorln("DNS TCP reading request from " + conn.RemoteAddr().String() + ": " + err.Error())
+			return
+		}
+		resp, err := s.handle(req, clientIP, false)
+		if err != nil {
+			log.Errorln("DNS TCP request from " + conn.RemoteAddr().String() + ": " + err.Error())
+			return
+		}
+		respLen := make([]byte, 2)
+		binary.BigEndian.PutUint16(respLen, uint16(len(resp)))
+		if _, err := conn.Write(append(respLen, resp...)); err != nil {
+			log.Errorln("DNS TCP writing response to " + conn.RemoteAddr().String() + ": " + err.Error())
+			return
+		}
+	}
+}
+
+// handle parses the given request, and returns the packed response. If the response is over UDP, and larger than the client's maximum size, it's truncated.
+// Returns an error if the request can't be parsed enough to respond.
+func (s *Server) handle(reqBts []byte, clientIP net.IP, isUDP bool) ([]byte, error) {
+	req := dnsmessage.Message{}
+	if err := req.Unpack(reqBts); err != nil {
+		return nil, errors.New("unpacking request: " + err.Error())
+	}
+	if req.Response {
+		return nil, errors.New("request is a response")
+	}
+
+	resp := s.h.Answer(req, clientIP)
+
+	maxSize := MaxUDPSize
+	if isUDP {
+		maxSize = udpSize(req)
+	}
+	if opt, ok := optResource(req); ok {
+		resp.Additionals = append(resp.Additionals, opt)
+	}
+
+	respBts, err := resp.Pack()
+	if err != nil {
+		log.Errorln("DNS packing response for " + fmt.Sprintf("%+v", req.Questions) + ", returning SERVFAIL: " + err.Error())
+		resp = dnsmessage.Message{Header: dnsmessage.Header{ID: req.ID, Response: true, OpCode: req.OpCode, RCode: dnsmessage.RCodeServerFailure}, Questions: req.Questions}
+		return resp.Pack()
+	}
+	if !isUDP || len(respBts) <= maxSize {
+		return respBts, nil
+	}
+
+	resp.Truncated = true
+	resp.Answers = nil
+	resp.Authorities = nil
+	resp.Additionals = nil
+	return resp.Pack()
+}
+
+// udpSize returns the largest UDP response the client of the given request accepts.
+func udpSize(req dnsmessage.Message) int {
+	for _, rr := range req.Additionals {
+		if rr.Header.Type != dnsmessage.TypeOPT {
+			continue
+		}
+		size := int(rr.Header.Class)
+		if size < MinUDPSize {
+			return MinUDPSize
+		}
+		if size > MaxUDPSize {
+			return MaxUDPSize
+		}
+		return size
+	}
+	return MinUDPSize
+}
+
+// optResource returns the EDNS0 OPT record to respond with, if the request had one.
+func optResource(req dnsmessage.Message) (dnsmessage.Resource, bool) {
+	for _, rr := range req.Additionals {
+		if rr.Header.Type != dnsmessage.TypeOPT {
+			continue
+		}
+		opt := dnsmessage.Resource{Body: &dnsmessage.OPTResource{}}
+		if err := opt.Header.SetEDNS0(MaxUDPSize, dnsmessage.RCodeSuccess, false); err != nil {
+			return dnsmessage.Resource{}, false
+		}
+		return opt, true
+	}
+	return dnsmessage.Resource{}, false
+}
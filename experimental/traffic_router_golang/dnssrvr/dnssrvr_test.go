@@ -0,0 +1,299 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/consistenthash"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigregex"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+func boolPtr(b bool) *bool    { return &b }
+
+func testCRConfig() *tc.CRConfig {
+	date := int64(1559237772)
+	online := tc.CRConfigRouterStatus(tc.CacheStatusOnline)
+	return &tc.CRConfig{
+		Config: map[string]interface{}{
+			"domain_name": "cdn.example.com",
+			"soa":         map[string]interface{}{"admin": "traffic_ops", "minimum": "60"},
+			"ttls":        map[string]interface{}{"NS": "600"},
+		},
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edge1": {CacheGroup: strPtr("cg1"), Ip: strPtr("192.0.2.1"), Ip6: strPtr("2001:db8::1/64")},
+			"edge2": {CacheGroup: strPtr("cg1"), Ip: strPtr("192.0.2.2"), Ip6: strPtr("2001:db8::2/64")},
+			"edge3": {CacheGroup: strPtr("cg1"), Ip: strPtr("192.0.2.3"), Ip6: strPtr("2001:db8::3/64")},
+		},
+		ContentRouters: map[string]tc.CRConfigRouter{
+			"tr1": {FQDN: strPtr("tr1.example.net"), IP: strPtr("198.51.100.1"), IP6: strPtr("2001:db8:1::1"), ServerStatus: &online},
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"dns-ds": {
+				MatchSets:            []*tc.MatchSet{{Protocol: "DNS", MatchList: []tc.MatchList{{MatchType: "HOST", Regex: `.*\.dnsds\..*`}}}},
+				Domains:              []string{"dnsds.cdn.example.com"},
+				RoutingName:          strPtr("edge"),
+				TTL:                  intPtr(42),
+				MaxDNSIPsForLocation: intPtr(2),
+				IP6RoutingEnabled:    boolPtr(true),
+				StaticDNSEntries: []tc.CRConfigStaticDNSEntry{
+					{Name: "static", TTL: 60, Type: "A", Value: "203.0.113.1"},
+					{Name: "alias", TTL: 60, Type: "CNAME", Value: "origin.example.org."},
+				},
+			},
+			"http-ds": {
+				MatchSets: []*tc.MatchSet{{Protocol: "HTTP", MatchList: []tc.MatchList{{MatchType: "HOST", Regex: `.*\.httpds\..*`}}}},
+				Domains:   []string{"httpds.cdn.example.com"},
+				TTL:       intPtr(43),
+			},
+		},
+		EdgeLocations: map[string]tc.CRConfigLatitudeLongitude{
+			"cg1": {Lat: 39.5, Lon: -104.9},
+		},
+		Stats: tc.CRConfigStats{DateUnixSeconds: &date},
+	}
+}
+
+// startTestServer starts a DNS server on localhost with the testCRConfig, returning the server and its UDP and TCP addresses.
+func startTestServer(t *testing.T) (*Server, map[string]string) {
+	crc := testCRConfig()
+
+	crcThs := crconfig.NewThs()
+	crcThs.Set(crc)
+
+	regexes, err := crconfigregex.Get(crc)
+	if err != nil {
+		t.Fatalf("creating regexes: %v", err)
+	}
+	regexThs := crconfigregex.NewThs()
+	regexThs.Set(&regexes)
+
+	cgSearcher, err := cgsrch.Create(crc)
+	if err != nil {
+		t.Fatalf("creating cachegroup searcher: %v", err)
+	}
+	cgThs := cgsrch.NewThs()
+	cgThs.Set(cgSearcher)
+
+	hasher, errs := consistenthash.New(crc)
+	if len(errs) != 0 {
+		t.Fatalf("creating consistent hasher: %v", errs)
+	}
+	hasherThs := consistenthash.NewThs()
+	hasherThs.Set(hasher)
+
+	availSrvrs := availableservers.New()
+	availSrvrs.Set(availableservers.AvailableServersMap{
+		"dns-ds": {"cg1": {"edge1", "edge2", "edge3"}},
+	})
+
+	cz, err := coveragezone.New(coveragezone.JSONCoverageZones{})
+	if err != nil {
+		t.Fatalf("creating coverage zone: %v", err)
+	}
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening on UDP: %v", err)
+	}
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening on TCP: %v", err)
+	}
+	h := NewHandler(crcThs, regexThs, availSrvrs, cgThs, hasherThs, cz, coveragezone.NewThs())
+	return Serve(h, udp, tcp), map[string]string{"udp": udp.LocalAddr().String(), "tcp": tcp.Addr().String()}
+}
+
+// query sends the given query to the server at addr with an in-process client, over UDP or TCP, and returns the response.
+func query(t *testing.T, network string, addr string, name string, qType dnsmessage.Type) dnsmessage.Message {
+	req := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 4242, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qType, Class: dnsmessage.ClassINET}},
+	}
+	reqBts, err := req.Pack()
+	if err != nil {
+		t.Fatalf("packing query: %v", err)
+	}
+
+	conn, err := net.DialTimeout(network, addr, time.Second)
+	if err != nil {
+		t.Fatalf("dialing %v %v: %v", network, addr, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	respBts := []byte{}
+	if network == "tcp" {
+		lenBts := make([]byte, 2)
+		binary.BigEndian.PutUint16(lenBts, uint16(len(reqBts)))
+		if _, err := conn.Write(append(lenBts, reqBts...)); err != nil {
+			t.Fatalf("writing query: %v", err)
+		}
+		if _, err := io.ReadFull(conn, lenBts); err != nil {
+			t.Fatalf("reading response length: %v", err)
+		}
+		respBts = make([]byte, binary.BigEndian.Uint16(lenBts))
+		if _, err := io.ReadFull(conn, respBts); err != nil {
+			t.Fatalf("reading response: %v", err)
+		}
+	} else {
+		if _, err := conn.Write(reqBts); err != nil {
+			t.Fatalf("writing query: %v", err)
+		}
+		buf := make([]byte, MaxUDPSize)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("reading response: %v", err)
+		}
+		respBts = buf[:n]
+	}
+
+	resp := dnsmessage.Message{}
+	if err := resp.Unpack(respBts); err != nil {
+		t.Fatalf("unpacking response: %v", err)
+	}
+	if resp.ID != req.ID {
+		t.Errorf("response ID expected %v actual %v", req.ID, resp.ID)
+	}
+	return resp
+}
+
+func TestDNS(t *testing.T) {
+	srv, addrs := startTestServer(t)
+	defer srv.Close()
+	addr := addrs["udp"]
+
+	for _, network := range []string{"udp", "tcp"} {
+		// DNS delivery service A records are available caches, limited to maxDnsIpsForLocation, with the ccrDnsTtl
+		resp := query(t, network, addrs[network], "edge.dnsds.cdn.example.com.", dnsmessage.TypeA)
+		if resp.RCode != dnsmessage.RCodeSuccess || !resp.Authoritative {
+			t.Fatalf("%v DNS DS A expected authoritative success, actual rcode %v authoritative %v", network, resp.RCode, resp.Authoritative)
+		}
+		if len(resp.Answers) != 2 {
+			t.Fatalf("%v DNS DS A expected 2 answers (maxDnsIpsForLocation), actual %v", network, len(resp.Answers))
+		}
+		for _, rr := range resp.Answers {
+			a, ok := rr.Body.(*dnsmessage.AResource)
+			if !ok {
+				t.Fatalf("%v DNS DS A expected A record, actual %T", network, rr.Body)
+			}
+			if ip := net.IP(a.A[:]); !ip.Equal(net.ParseIP("192.0.2.1")) && !ip.Equal(net.ParseIP("192.0.2.2")) && !ip.Equal(net.ParseIP("192.0.2.3")) {
+				t.Errorf("%v DNS DS A expected a cache IP, actual %v", network, ip)
+			}
+			if rr.Header.TTL != 42 {
+				t.Errorf("%v DNS DS A expected TTL 42, actual %v", network, rr.Header.TTL)
+			}
+		}
+
+		resp = query(t, network, addrs[network], "edge.dnsds.cdn.example.com.", dnsmessage.TypeAAAA)
+		if len(resp.Answers) != 2 {
+			t.Fatalf("%v DNS DS AAAA expected 2 answers, actual %v", network, len(resp.Answers))
+		}
+		if _, ok := resp.Answers[0].Body.(*dnsmessage.AAAAResource); !ok {
+			t.Errorf("%v DNS DS AAAA expected AAAA record, actual %T", network, resp.Answers[0].Body)
+		}
+	}
+
+	// caches are selected by consistent hash of the name, so the same name resolves to the same caches
+	first := query(t, "udp", addr, "edge.dnsds.cdn.example.com.", dnsmessage.TypeA)
+	for i := 0; i < 3; i++ {
+		resp := query(t, "udp", addr, "edge.dnsds.cdn.example.com.", dnsmessage.TypeA)
+		if !reflect.DeepEqual(resp.Answers, first.Answers) {
+			t.Errorf("DNS DS A repeated query expected same answers %+v, actual %+v", first.Answers, resp.Answers)
+		}
+	}
+
+	// names of DNS delivery services other than the routing name don't exist
+	resp := query(t, "udp", addr, "notedge.dnsds.cdn.example.com.", dnsmessage.TypeA)
+	if resp.RCode != dnsmessage.RCodeNameError {
+		t.Errorf("DNS DS wrong routing name expected NXDOMAIN, actual %v", resp.RCode)
+	}
+	if len(resp.Authorities) != 1 || resp.Authorities[0].Header.Type != dnsmessage.TypeSOA {
+		t.Errorf("NXDOMAIN expected SOA authority, actual %+v", resp.Authorities)
+	}
+
+	// HTTP delivery services resolve to the routers
+	resp = query(t, "udp", addr, "tr.httpds.cdn.example.com.", dnsmessage.TypeA)
+	if len(resp.Answers) != 1 {
+		t.Fatalf("HTTP DS A expected 1 answer, actual %v", len(resp.Answers))
+	}
+	if a, ok := resp.Answers[0].Body.(*dnsmessage.AResource); !ok || !net.IP(a.A[:]).Equal(net.ParseIP("198.51.100.1")) {
+		t.Errorf("HTTP DS A expected router IP 198.51.100.1, actual %+v", resp.Answers[0].Body)
+	}
+
+	// static DNS entries
+	resp = query(t, "udp", addr, "static.dnsds.cdn.example.com.", dnsmessage.TypeA)
+	if len(resp.Answers) != 1 || resp.Answers[0].Header.TTL != 60 {
+		t.Fatalf("static A expected 1 answer with TTL 60, actual %+v", resp.Answers)
+	}
+	if a, ok := resp.Answers[0].Body.(*dnsmessage.AResource); !ok || !net.IP(a.A[:]).Equal(net.ParseIP("203.0.113.1")) {
+		t.Errorf("static A expected 203.0.113.1, actual %+v", resp.Answers[0].Body)
+	}
+	resp = query(t, "udp", addr, "alias.dnsds.cdn.example.com.", dnsmessage.TypeA)
+	if len(resp.Answers) != 1 {
+		t.Fatalf("static CNAME expected 1 answer, actual %+v", resp.Answers)
+	}
+	if cname, ok := resp.Answers[0].Body.(*dnsmessage.CNAMEResource); !ok || cname.CNAME.String() != "origin.example.org." {
+		t.Errorf("static CNAME expected origin.example.org., actual %+v", resp.Answers[0].Body)
+	}
+
+	// SOA and NS
+	resp = query(t, "udp", addr, "dnsds.cdn.example.com.", dnsmessage.TypeSOA)
+	if len(resp.Answers) != 1 {
+		t.Fatalf("SOA expected 1 answer, actual %+v", resp.Answers)
+	}
+	if soa, ok := resp.Answers[0].Body.(*dnsmessage.SOAResource); !ok {
+		t.Errorf("SOA expected SOA record, actual %T", resp.Answers[0].Body)
+	} else if soa.NS.String() != "tr1.example.net." || soa.MBox.String() != "traffic_ops.cdn.example.com." || soa.Serial != 1559237772 || soa.MinTTL != 60 || soa.Refresh != DefaultSOARefresh {
+		t.Errorf("SOA expected ns tr1.example.net. mbox traffic_ops.cdn.example.com. serial 1559237772 minimum 60, actual %+v", soa)
+	}
+	resp = query(t, "udp", addr, "cdn.example.com.", dnsmessage.TypeNS)
+	if len(resp.Answers) != 1 || resp.Answers[0].Header.TTL != 600 {
+		t.Fatalf("NS expected 1 answer with TTL 600, actual %+v", resp.Answers)
+	}
+	if ns, ok := resp.Answers[0].Body.(*dnsmessage.NSResource); !ok || ns.NS.String() != "tr1.example.net." {
+		t.Errorf("NS expected tr1.example.net., actual %+v", resp.Answers[0].Body)
+	}
+	if len(resp.Additionals) != 2 {
+		t.Errorf("NS expected 2 glue records, actual %+v", resp.Additionals)
+	}
+
+	// names outside the CDN are refused
+	resp = query(t, "udp", addr, "www.example.org.", dnsmessage.TypeA)
+	if resp.RCode != dnsmessage.RCodeRefused || resp.Authoritative {
+		t.Errorf("name outside CDN expected non-authoritative REFUSED, actual rcode %v authoritative %v", resp.RCode, resp.Authoritative)
+	}
+}
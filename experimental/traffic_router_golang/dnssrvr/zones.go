@@ -0,0 +1,235 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const DefaultTTL = 3600
+const DefaultNSTTL = 3600
+const DefaultSOATTL = 86400
+
+// Defaults of the CRConfig config `soa` object, which are the same as the Java Traffic Router.
+const DefaultSOAAdmin = "traffic_ops"
+const DefaultSOARefresh = 28800
+const DefaultSOARetry = 7200
+const DefaultSOAExpire = 604800
+const DefaultSOAMinimum = 30
+
+const DSProtocolDNS = "DNS"
+
+// Zones is the DNS data of a CRConfig which doesn't change per request: the zones this router is authoritative for, their SOA and NS records, the routers' own addresses, and the static DNS entries.
+// All names are lower-case, and without a trailing dot.
+type Zones struct {
+	CDNDomain string
+	// Apexes is the name of each zone this router is authoritative for, and its delivery service. The CDN domain itself has an empty delivery service name.
+	Apexes map[string]tc.DeliveryServiceName
+	// NSNames are the names of the routers, which are the NS records of every zone.
+	NSNames []string
+	// RouterIPs and RouterIP6s are the IPv4 and IPv6 addresses of the routers, which are served for HTTP delivery services, and as glue for NS records.
+	RouterIPs  []net.IP
+	RouterIP6s []net.IP
+	// RouterAddrs is the addresses of each router name in NSNames.
+	RouterAddrs map[string][]net.IP
+	// Static is the static DNS entries, by name.
+	Static map[string][]tc.CRConfigStaticDNSEntry
+	SOA    SOA
+	// TTLs is the CRConfig config `ttls` object, which are the defaults for delivery services without TTLs.
+	TTLs map[string]string
+}
+
+// SOA is the data of the SOA record of every zone.
+type SOA struct {
+	MBox    string
+	Serial  uint32
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	Minimum uint32
+}
+
+// NewZones creates the Zones of the given CRConfig.
+func NewZones(crc *tc.CRConfig) Zones {
+	z := Zones{
+		Apexes:      map[string]tc.DeliveryServiceName{},
+		RouterAddrs: map[string][]net.IP{},
+		Static:      map[string][]tc.CRConfigStaticDNSEntry{},
+		TTLs:        configStrMap(crc.Config, "ttls"),
+	}
+
+	if domain, ok := crc.Config["domain_name"].(string); ok {
+		z.CDNDomain = normalizeName(domain)
+		z.Apexes[z.CDNDomain] = ""
+	}
+
+	for dsName, ds := range crc.DeliveryServices {
+		for _, domain := range ds.Domains {
+			domain = normalizeName(domain)
+			z.Apexes[domain] = tc.DeliveryServiceName(dsName)
+			for _, entry := range ds.StaticDNSEntries {
+				name := normalizeName(entry.Name) + "." + domain
+				z.Static[name] = append(z.Static[name], entry)
+			}
+		}
+	}
+
+	for _, router := range crc.ContentRouters {
+		if router.FQDN == nil || *router.FQDN == "" {
+			continue
+		}
+		if router.ServerStatus != nil && *router.ServerStatus != tc.CRConfigRouterStatus(tc.CacheStatusOnline) && *router.ServerStatus != tc.CRConfigRouterStatus(tc.CacheStatusReported) {
+			continue
+		}
+		name := normalizeName(*router.FQDN)
+		z.NSNames = append(z.NSNames, name)
+		if router.IP != nil {
+			if ip := net.ParseIP(*router.IP).To4(); ip != nil {
+				z.RouterIPs = append(z.RouterIPs, ip)
+				z.RouterAddrs[name] = append(z.RouterAddrs[name], ip)
+			}
+		}
+		if router.IP6 != nil {
+			if ip := parseIP6(*router.IP6); ip != nil {
+				z.RouterIP6s = append(z.RouterIP6s, ip)
+				z.RouterAddrs[name] = append(z.RouterAddrs[name], ip)
+			}
+		}
+	}
+	sort.Strings(z.NSNames)
+
+	soa := configStrMap(crc.Config, "soa")
+	admin := DefaultSOAAdmin
+	if val, ok := soa["admin"]; ok && val != "" {
+		admin = normalizeName(val)
+	}
+	if !strings.Contains(admin, ".") && z.CDNDomain != "" {
+		admin += "." + z.CDNDomain
+	}
+	z.SOA = SOA{
+		MBox:    admin,
+		Refresh: configUint32(soa, "refresh", DefaultSOARefresh),
+		Retry:   configUint32(soa, "retry", DefaultSOARetry),
+		Expire:  configUint32(soa, "expire", DefaultSOAExpire),
+		Minimum: configUint32(soa, "minimum", DefaultSOAMinimum),
+	}
+	if crc.Stats.DateUnixSeconds != nil {
+		z.SOA.Serial = uint32(*crc.Stats.DateUnixSeconds)
+	}
+	return z
+}
+
+// Zone returns the apex of the zone containing the given name, and its delivery service, or false if this router isn't authoritative for the name.
+func (z Zones) Zone(name string) (string, tc.DeliveryServiceName, bool) {
+	for {
+		if ds, ok := z.Apexes[name]; ok {
+			return name, ds, true
+		}
+		dot := strings.Index(name, ".")
+		if dot < 0 {
+			return "", "", false
+		}
+		name = name[dot+1:]
+	}
+}
+
+// TTL returns the TTL in seconds of the given record type of the given delivery service, from the delivery service's `ttl` (ccrDnsTtl) for A and AAAA records, then its `ttls`, then the CRConfig config `ttls`, then the given default.
+func (z Zones) TTL(ds *tc.CRConfigDeliveryService, recordType string, defaultTTL uint32) uint32 {
+	if ds != nil {
+		if ds.TTL != nil && *ds.TTL >= 0 && (recordType == "A" || recordType == "AAAA") {
+			return uint32(*ds.TTL)
+		}
+		if ds.TTLs != nil {
+			ttl := (*string)(nil)
+			switch recordType {
+			case "A":
+				ttl = ds.TTLs.ASeconds
+			case "AAAA":
+				ttl = ds.TTLs.AAAASeconds
+			case "NS":
+				ttl = ds.TTLs.NSSeconds
+			case "SOA":
+				ttl = ds.TTLs.SOASeconds
+			}
+			if ttl != nil {
+				if val, err := strconv.ParseUint(*ttl, 10, 32); err == nil {
+					return uint32(val)
+				}
+			}
+		}
+	}
+	return configUint32(z.TTLs, recordType, defaultTTL)
+}
+
+// dsProtocol returns the protocol of the given delivery service, DNS or HTTP.
+func dsProtocol(ds tc.CRConfigDeliveryService) string {
+	for _, matchSet := range ds.MatchSets {
+		if matchSet != nil && matchSet.Protocol != "" {
+			return matchSet.Protocol
+		}
+	}
+	return ""
+}
+
+// configStrMap returns the object with the given key in the CRConfig config, which is a map of strings, such as `soa` and `ttls`. Returns an empty map if the key doesn't exist or isn't an object.
+func configStrMap(config map[string]interface{}, key string) map[string]string {
+	m := map[string]string{}
+	obj, ok := config[key].(map[string]interface{})
+	if !ok {
+		return m
+	}
+	for k, v := range obj {
+		if s, ok := v.(string); ok {
+			m[k] = s
+		}
+	}
+	return m
+}
+
+func configUint32(m map[string]string, key string, defaultVal uint32) uint32 {
+	val, err := strconv.ParseUint(m[key], 10, 32)
+	if err != nil {
+		return defaultVal
+	}
+	return uint32(val)
+}
+
+// normalizeName returns the given DNS name lower-case and without a trailing dot.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// parseIP6 parses an IPv6 address, which in the CRConfig may have a prefix length, like `2001:db8::1/64`. Returns nil if the address isn't IPv6.
+func parseIP6(s string) net.IP {
+	if slash := strings.Index(s, "/"); slash >= 0 {
+		s = s[:slash]
+	}
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() != nil {
+		return nil
+	}
+	return ip
+}
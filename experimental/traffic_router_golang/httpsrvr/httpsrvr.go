@@ -21,17 +21,22 @@ package httpsrvr
  */
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/consistenthash"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigregex"
-	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/nextcache"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/steering"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
@@ -44,13 +49,31 @@ var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.579244, Lon: -104.934282}
 // TODO config
 const UseXForwardedFor = true
 
-func getHandler(
-	regexes crconfigregex.Ths,
-	availSrvrs availableservers.AvailableServers,
-	cgSrchThs cgsrch.Ths,
-	nextCacherThs nextcache.Ths,
-	cz coveragezone.CoverageZone,
-) http.HandlerFunc {
+// router holds the data used to route requests.
+type router struct {
+	crc        crconfig.Ths
+	regexes    crconfigregex.Ths
+	availSrvrs availableservers.AvailableServers
+	cgSrch     cgsrch.Ths
+	hasher     consistenthash.Ths
+	steering   steering.Ths
+	cz         coveragezone.CoverageZone
+	deepCZ     coveragezone.CoverageZone
+}
+
+// selection is the cache selected for a request to a delivery service.
+type selection struct {
+	ds       tc.DeliveryServiceName
+	cache    tc.CacheName
+	cachePos tc.CRConfigLatitudeLongitude
+}
+
+// MultiLocations is the response to a CLIENT_STEERING request, with the URLs of each target, in the order the client should use them.
+type MultiLocations struct {
+	Locations []string `json:"locations"`
+}
+
+func getHandler(rt router) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// host := r.Header.Get("Host")
 
@@ -68,7 +91,7 @@ func getHandler(
 
 		fmt.Println("DEBUG request '" + r.Host + "' split ssd '" + subsubdomain + "' sd '" + subdomain + "' d '" + domain + "'")
 
-		dsRegexes := (*crconfigregex.Regexes)(regexes.Get())
+		dsRegexes := (*crconfigregex.Regexes)(rt.regexes.Get())
 
 		dsName, ok := dsRegexes.DeliveryService(domain, subdomain, subsubdomain)
 		if !ok {
@@ -99,69 +122,198 @@ func getHandler(
 			return
 		}
 
-		pos, ok := cz.Get(ip)
-		if !ok {
-			pos = DefaultPos
-			log.Warnln("request from" + r.RemoteAddr + " IP " + ip.String() + " not found, using default")
+		hasher := (*consistenthash.Hasher)(rt.hasher.Get())
+		if hasher == nil {
+			// should never happen
+			fmt.Println("ERROR request '" + r.Host + "' ds '" + string(dsName) + "' has no consistent hasher, returning 500")
+			w.WriteHeader(http.StatusInternalServerError) // TODO better code?
+			return
 		}
-		log.Infof("LATLON: Request from"+r.RemoteAddr+" IP "+ip.String()+" got %+v\n", pos)
 
-		cgSrch := cgSrchThs.Get()
-		cgDat, ok := cgSrch.Nearest(pos.Lat, pos.Lon)
-		if !ok {
-			fmt.Println("ERROR request from" + r.RemoteAddr + " has no nearest cachegroup (should only happen if there are no cachegroups)")
-			w.WriteHeader(http.StatusInternalServerError)
+		if st, ok := rt.steering.Get()[dsName]; ok {
+			rt.serveSteering(w, r, st, hasher, ip)
 			return
 		}
-		cg := tc.CacheGroupName(cgDat.Obj)
 
-		srvrs, err := availSrvrs.Get(dsName, cg)
+		sel, code, err := rt.selectCache(dsName, hasher, ip, hasher.PathHashString(dsName, r.URL.Path), r.URL.RawQuery)
 		if err != nil {
-			fmt.Println("EVENT request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' failed to get available servers, returning 404: " + err.Error())
-			w.WriteHeader(http.StatusNotFound)
+			fmt.Println("EVENT request '" + r.Host + "' ds '" + string(dsName) + "' " + err.Error() + ", returning " + strconv.Itoa(code))
+			w.WriteHeader(code)
 			return
 		}
 
-		fmt.Printf("DEBUG GOT AVAILABLE SERVERS %+v\n", srvrs)
+		w.Header().Add("Location", cacheURL(sel.cache, subdomain+"."+domain, r.URL))
+		w.WriteHeader(http.StatusFound)
+	}
+}
 
-		if len(srvrs) == 0 {
-			fmt.Println("EVENT request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' no available servers, returning 500")
-			w.WriteHeader(http.StatusInternalServerError) // TODO better code?
-			return
+// serveSteering serves a request to the given STEERING or CLIENT_STEERING delivery service.
+//
+// A STEERING request is redirected to the first target with an available cache, unless its path matches a filter, in which case it's redirected to the filter's delivery service. A CLIENT_STEERING request gets a MultiLocations with a cache of each target with an available cache, ordered by geo distance if the targets have origin coordinates.
+func (rt router) serveSteering(w http.ResponseWriter, r *http.Request, st *steering.Steering, hasher *consistenthash.Hasher, ip net.IP) {
+	crc := (*tc.CRConfig)(rt.crc.Get())
+	if crc == nil {
+		// should never happen
+		fmt.Println("ERROR request '" + r.Host + "' steering ds '" + string(st.DeliveryService) + "' has no CRConfig, returning 500")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Targets use the consistent hash regex of the steering delivery service.
+	pathHash := hasher.PathHashString(st.DeliveryService, r.URL.Path)
+
+	if !st.ClientSteering {
+		if bypass, ok := st.Bypass(r.URL.Path); ok {
+			if _, ok := crc.DeliveryServices[string(bypass)]; ok { // the bypass might not be in the CRConfig yet; until then, use the targets.
+				sel, code, err := rt.selectCache(bypass, hasher, ip, pathHash, r.URL.RawQuery)
+				if err != nil {
+					fmt.Println("EVENT request '" + r.Host + "' steering ds '" + string(st.DeliveryService) + "' filter ds '" + string(bypass) + "' " + err.Error() + ", returning " + strconv.Itoa(code))
+					w.WriteHeader(code)
+					return
+				}
+				w.Header().Add("Location", cacheURL(sel.cache, dsDomain(crc, bypass), r.URL))
+				w.WriteHeader(http.StatusFound)
+				return
+			}
 		}
+	}
 
-		nextCacher := nextCacherThs.Get()
-		nextSrvrI, ok := nextCacher.NextCache(dsName)
-		if !ok {
-			// should never happen
-			fmt.Println("ERROR request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' not found in Nextcacher, returning 500")
-			w.WriteHeader(http.StatusInternalServerError) // TODO better code?
+	results := []steering.Result{}
+	for _, target := range st.Order(consistenthash.Hash(pathHash + hasher.QueryHashString(st.DeliveryService, r.URL.RawQuery))) {
+		if _, ok := crc.DeliveryServices[string(target.DeliveryService)]; !ok {
+			continue // the target might not be in the CRConfig yet
+		}
+		sel, _, err := rt.selectCache(target.DeliveryService, hasher, ip, pathHash, r.URL.RawQuery)
+		if err != nil {
+			log.Infoln("request '" + r.Host + "' steering ds '" + string(st.DeliveryService) + "' target '" + string(target.DeliveryService) + "' " + err.Error() + ", skipping")
+			continue
+		}
+		if !st.ClientSteering {
+			w.Header().Add("Location", cacheURL(sel.cache, dsDomain(crc, sel.ds), r.URL))
+			w.WriteHeader(http.StatusFound)
 			return
 		}
+		results = append(results, steering.Result{Target: target, Cache: sel.cache, CachePos: sel.cachePos})
+	}
+
+	if len(results) == 0 {
+		fmt.Println("EVENT request '" + r.Host + "' steering ds '" + string(st.DeliveryService) + "' no targets with available servers, returning 404")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	steering.GeoSort(results, rt.clientPos(ip))
+
+	locations := MultiLocations{Locations: make([]string, 0, len(results))}
+	for _, result := range results {
+		locations.Locations = append(locations.Locations, cacheURL(result.Cache, dsDomain(crc, result.Target.DeliveryService), r.URL))
+	}
+	bts, err := json.Marshal(locations)
+	if err != nil {
+		fmt.Println("ERROR request '" + r.Host + "' steering ds '" + string(st.DeliveryService) + "' marshalling locations: " + err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bts)
+}
+
+// clientPos returns the coverage zone position of the given client IP, or DefaultPos if the IP isn't in the coverage zone.
+func (rt router) clientPos(ip net.IP) tc.CRConfigLatitudeLongitude {
+	pos, ok := rt.cz.Get(ip)
+	if !ok {
+		pos = DefaultPos
+		log.Warnln("request from IP " + ip.String() + " not found, using default")
+	}
+	return pos
+}
 
-		srvr := srvrs[nextSrvrI%uint64(len(srvrs))]
+// selectCache selects the cache of the given delivery service for a request from the given IP, with the given path hash string and raw query. If no cache can be selected, the HTTP status code to return and an error are returned.
+func (rt router) selectCache(dsName tc.DeliveryServiceName, hasher *consistenthash.Hasher, ip net.IP, pathHash string, rawQuery string) (selection, int, error) {
+	crc := (*tc.CRConfig)(rt.crc.Get())
 
-		newURL := string(srvr) + "." + subdomain + "." + domain + r.URL.Path
-		if r.URL.RawQuery != "" {
-			newURL += "?" + r.URL.RawQuery
+	deepCachingType := (*tc.DeepCachingType)(nil)
+	if crc != nil {
+		deepCachingType = crc.DeliveryServices[string(dsName)].DeepCachingType
+	}
+
+	srvrs, cg, deep := rt.availSrvrs.Deep(dsName, deepCachingType, rt.deepCZ, ip)
+	pos := tc.CRConfigLatitudeLongitude{}
+	if deep {
+		log.Infoln("request from IP " + ip.String() + " ds '" + string(dsName) + "' in deep coverage zone '" + string(cg) + "'")
+		pos, _ = rt.deepCZ.Get(ip)
+	} else {
+		pos = rt.clientPos(ip)
+		log.Infof("LATLON: Request from IP "+ip.String()+" got %+v\n", pos)
+
+		cgSrch := rt.cgSrch.Get()
+		if cgSrch == nil {
+			return selection{}, http.StatusInternalServerError, errors.New("no cachegroup searcher")
 		}
+		cgDat, ok := cgSrch.Nearest(pos.Lat, pos.Lon)
+		if !ok {
+			return selection{}, http.StatusInternalServerError, errors.New("has no nearest cachegroup (should only happen if there are no cachegroups)")
+		}
+		cg = tc.CacheGroupName(cgDat.Obj)
+		pos = tc.CRConfigLatitudeLongitude{Lat: cgDat.Lat, Lon: cgDat.Lon}
 
-		w.Header().Add("Location", newURL)
-		w.WriteHeader(http.StatusFound)
+		err := error(nil)
+		srvrs, err = rt.availSrvrs.Get(dsName, cg)
+		if err != nil {
+			return selection{}, http.StatusNotFound, errors.New("with cg '" + string(cg) + "' failed to get available servers: " + err.Error())
+		}
+	}
+
+	fmt.Printf("DEBUG GOT AVAILABLE SERVERS %+v\n", srvrs)
+
+	if len(srvrs) == 0 {
+		return selection{}, http.StatusInternalServerError, errors.New("with cg '" + string(cg) + "' no available servers") // TODO better code?
+	}
+
+	srvr, _ := hasher.SelectHash(srvrs, consistenthash.Hash(pathHash+hasher.QueryHashString(dsName, rawQuery)))
+	return selection{ds: dsName, cache: srvr, cachePos: pos}, http.StatusOK, nil
+}
+
+// dsDomain returns the domain of the given delivery service, which its caches are subdomains of.
+func dsDomain(crc *tc.CRConfig, ds tc.DeliveryServiceName) string {
+	if domains := crc.DeliveryServices[string(ds)].Domains; len(domains) > 0 {
+		return domains[0]
+	}
+	return string(ds)
+}
+
+// cacheURL returns the URL of the given request on the given cache, in the given delivery service domain.
+func cacheURL(cache tc.CacheName, domain string, reqURL *url.URL) string {
+	u := "http://" + string(cache) + "." + domain + reqURL.Path
+	if reqURL.RawQuery != "" {
+		u += "?" + reqURL.RawQuery
 	}
+	return u
 }
 
 func Start(
+	crc crconfig.Ths,
 	regexes crconfigregex.Ths,
 	availableServers availableservers.AvailableServers,
 	cgSrch cgsrch.Ths,
-	nextCacher nextcache.Ths,
+	hasher consistenthash.Ths,
+	steerings steering.Ths,
 	cz coveragezone.CoverageZone,
+	deepCZ coveragezone.CoverageZone,
 	port uint,
 ) *http.Server {
 	srvr := http.Server{}
 	srvr.Addr = ":" + strconv.Itoa(int(port))
-	srvr.Handler = getHandler(regexes, availableServers, cgSrch, nextCacher, cz)
+	srvr.Handler = getHandler(router{
+		crc:        crc,
+		regexes:    regexes,
+		availSrvrs: availableServers,
+		cgSrch:     cgSrch,
+		hasher:     hasher,
+		steering:   steerings,
+		cz:         cz,
+		deepCZ:     deepCZ,
+	})
 	go func() {
 		err := srvr.ListenAndServe()
 		if err != nil {
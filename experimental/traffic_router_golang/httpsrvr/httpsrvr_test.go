@@ -0,0 +1,177 @@
+package httpsrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/consistenthash"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigregex"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/steering"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func testDS(name string) tc.CRConfigDeliveryService {
+	return tc.CRConfigDeliveryService{
+		Domains:   []string{name + ".mycdn.example.net"},
+		MatchSets: []*tc.MatchSet{{MatchList: []tc.MatchList{{Regex: `.*\.` + name + `\..*`}}}},
+	}
+}
+
+func testRouter(t *testing.T, tcSteerings []tc.Steering) router {
+	crc := &tc.CRConfig{
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{"edge-a": {}, "edge-b": {}, "edge-c": {}},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"steer":    testDS("steer"),
+			"target-a": testDS("target-a"),
+			"target-b": testDS("target-b"),
+			"bypass":   testDS("bypass"),
+		},
+		EdgeLocations: map[string]tc.CRConfigLatitudeLongitude{"cg1": {Lat: 39.7, Lon: -104.9}},
+	}
+	crcThs := crconfig.NewThs()
+	crcThs.Set(crc)
+
+	regexes, err := crconfigregex.Get(crc)
+	if err != nil {
+		t.Fatalf("creating regexes: %v", err)
+	}
+	regexThs := crconfigregex.NewThs()
+	regexThs.Set(&regexes)
+
+	cgSearcher, err := cgsrch.Create(crc)
+	if err != nil {
+		t.Fatalf("creating cachegroup searcher: %v", err)
+	}
+	cgThs := cgsrch.NewThs()
+	cgThs.Set(cgSearcher)
+
+	hasher, _ := consistenthash.New(crc)
+	hasherThs := consistenthash.NewThs()
+	hasherThs.Set(hasher)
+
+	availSrvrs := availableservers.New()
+	availSrvrs.Set(availableservers.AvailableServersMap{
+		"target-a": {"cg1": {"edge-a"}},
+		"target-b": {"cg1": {"edge-b"}},
+		"bypass":   {"cg1": {"edge-c"}},
+	})
+
+	steerings, errs := steering.New(tcSteerings)
+	if len(errs) != 0 {
+		t.Fatalf("creating steering: %v", errs)
+	}
+	steeringThs := steering.NewThs()
+	steeringThs.Set(steering.ThsT(steerings))
+
+	cz, err := coveragezone.New(coveragezone.JSONCoverageZones{})
+	if err != nil {
+		t.Fatalf("creating coverage zone: %v", err)
+	}
+
+	return router{
+		crc:        crcThs,
+		regexes:    regexThs,
+		availSrvrs: availSrvrs,
+		cgSrch:     cgThs,
+		hasher:     hasherThs,
+		steering:   steeringThs,
+		cz:         cz,
+		deepCZ:     coveragezone.NewThs(),
+	}
+}
+
+func request(rt router, path string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "http://tr.steer.mycdn.example.net"+path, nil)
+	r.RemoteAddr = "192.0.2.1:12345"
+	w := httptest.NewRecorder()
+	getHandler(rt)(w, r)
+	return w
+}
+
+func TestSteering(t *testing.T) {
+	rt := testRouter(t, []tc.Steering{{
+		DeliveryService: "steer",
+		Targets: []tc.SteeringSteeringTarget{
+			{DeliveryService: "target-b", Order: 2},
+			{DeliveryService: "target-a", Order: 1},
+			{DeliveryService: "not-in-crconfig", Order: -1},
+		},
+		Filters: []tc.SteeringFilter{{DeliveryService: "bypass", Pattern: `/bypass/.*`}},
+	}})
+
+	w := request(rt, "/foo/bar?q=1")
+	if w.Code != http.StatusFound {
+		t.Fatalf("steering request expected: %v, actual: %v", http.StatusFound, w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "http://edge-a.target-a.mycdn.example.net/foo/bar?q=1" {
+		t.Errorf("steering request expected: first target in order, actual: %v", loc)
+	}
+
+	w = request(rt, "/bypass/bar")
+	if loc := w.Header().Get("Location"); loc != "http://edge-c.bypass.mycdn.example.net/bypass/bar" {
+		t.Errorf("steering request matching filter expected: filter delivery service, actual: %v", loc)
+	}
+
+	// targets without available caches are skipped
+	rt.availSrvrs.Set(availableservers.AvailableServersMap{"target-b": {"cg1": {"edge-b"}}})
+	w = request(rt, "/foo/bar")
+	if loc := w.Header().Get("Location"); loc != "http://edge-b.target-b.mycdn.example.net/foo/bar" {
+		t.Errorf("steering request with unavailable target expected: next target, actual: %v", loc)
+	}
+}
+
+func TestClientSteering(t *testing.T) {
+	rt := testRouter(t, []tc.Steering{{
+		DeliveryService: "steer",
+		ClientSteering:  true,
+		Targets: []tc.SteeringSteeringTarget{
+			{DeliveryService: "target-b", Order: 2},
+			{DeliveryService: "target-a", Order: 1},
+		},
+		Filters: []tc.SteeringFilter{{DeliveryService: "bypass", Pattern: `/bypass/.*`}},
+	}})
+
+	w := request(rt, "/bypass/bar")
+	if w.Code != http.StatusOK {
+		t.Fatalf("client steering request expected: %v, actual: %v", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("client steering request Content-Type expected: application/json, actual: %v", ct)
+	}
+	locations := MultiLocations{}
+	if err := json.Unmarshal(w.Body.Bytes(), &locations); err != nil {
+		t.Fatalf("client steering response expected: MultiLocations JSON, actual: %v", err)
+	}
+	expected := []string{"http://edge-a.target-a.mycdn.example.net/bypass/bar", "http://edge-b.target-b.mycdn.example.net/bypass/bar"}
+	if len(locations.Locations) != len(expected) || locations.Locations[0] != expected[0] || locations.Locations[1] != expected[1] {
+		t.Errorf("client steering response expected: %v, actual: %v", expected, locations.Locations)
+	}
+}
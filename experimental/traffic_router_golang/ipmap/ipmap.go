@@ -64,3 +64,12 @@ func (i *ipmap) Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool) {
 	}
 	return tc.CRConfigLatitudeLongitude{Lat: ll.Lat, Lon: ll.Lon}, true
 }
+
+// Zone takes an IP and returns a Zone with the Latitude and Longitude.
+func (i *ipmap) Zone(ip net.IP) (coveragezone.Zone, bool) {
+	pos, ok := i.Get(ip)
+	if !ok {
+		return coveragezone.Zone{}, false
+	}
+	return coveragezone.Zone{Pos: pos}, true
+}
@@ -0,0 +1,214 @@
+package steering
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/consistenthash"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// Steerings is the steering delivery services, from the Traffic Ops steering endpoint.
+type Steerings map[tc.DeliveryServiceName]*Steering
+
+// Steering is a STEERING or CLIENT_STEERING delivery service, which routes requests to its target delivery services.
+type Steering struct {
+	DeliveryService tc.DeliveryServiceName
+	ClientSteering  bool
+	Targets         []Target
+	Filters         []Filter
+}
+
+// Target is a steering target, with the hashes of its weight.
+type Target struct {
+	tc.SteeringSteeringTarget
+	hashes []float64
+}
+
+// Filter is a steering filter. Requests whose path matches Pattern bypass the steering targets, and are routed to DeliveryService.
+type Filter struct {
+	DeliveryService tc.DeliveryServiceName
+	Pattern         *regexp.Regexp
+}
+
+// New creates Steerings from the given Traffic Ops steering objects. Filters with invalid patterns are omitted, and their errors returned.
+func New(tcSteerings []tc.Steering) (Steerings, []error) {
+	errs := []error{}
+	steerings := make(Steerings, len(tcSteerings))
+	for _, tcSt := range tcSteerings {
+		st := &Steering{
+			DeliveryService: tcSt.DeliveryService,
+			ClientSteering:  tcSt.ClientSteering,
+			Targets:         make([]Target, 0, len(tcSt.Targets)),
+			Filters:         make([]Filter, 0, len(tcSt.Filters)),
+		}
+		for _, tcTarget := range tcSt.Targets {
+			st.Targets = append(st.Targets, Target{
+				SteeringSteeringTarget: tcTarget,
+				hashes:                 consistenthash.Hashes(string(tcTarget.DeliveryService), int(tcTarget.Weight)),
+			})
+		}
+		for _, tcFilter := range tcSt.Filters {
+			// Traffic Router filter patterns must match the entire path.
+			pattern, err := regexp.Compile(`^(?:` + tcFilter.Pattern + `)$`)
+			if err != nil {
+				errs = append(errs, errors.New("steering '"+string(tcSt.DeliveryService)+"' filter '"+tcFilter.Pattern+"': "+err.Error()))
+				continue
+			}
+			st.Filters = append(st.Filters, Filter{DeliveryService: tcFilter.DeliveryService, Pattern: pattern})
+		}
+		steerings[tcSt.DeliveryService] = st
+	}
+	return steerings, errs
+}
+
+// Bypass returns the delivery service of the first filter matching the given request path, or false if no filter matches.
+func (s *Steering) Bypass(path string) (tc.DeliveryServiceName, bool) {
+	for _, filter := range s.Filters {
+		if filter.Pattern.MatchString(path) {
+			return filter.DeliveryService, true
+		}
+	}
+	return "", false
+}
+
+// Order returns the targets in the order requests with the given hash should use them.
+//
+// Targets without a weight and with a negative order are first, from the order closest to zero. Then targets with a weight, by consistent hash, so requests are spread over them in proportion to their weights, and requests with the same hash use the same target. Then targets without a weight, by ascending order. Ties are broken by delivery service name.
+func (s *Steering) Order(hash float64) []Target {
+	targets := make([]Target, len(s.Targets))
+	copy(targets, s.Targets)
+
+	deltas := make(map[tc.DeliveryServiceName]float64, len(targets))
+	for _, target := range targets {
+		if len(target.hashes) > 0 {
+			deltas[target.DeliveryService] = consistenthash.ClosestDelta(target.hashes, hash)
+		}
+	}
+
+	group := func(t Target) int {
+		switch {
+		case len(t.hashes) == 0 && t.Order < 0:
+			return 0
+		case len(t.hashes) > 0:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		a, b := targets[i], targets[j]
+		if ga, gb := group(a), group(b); ga != gb {
+			return ga < gb
+		}
+		switch group(a) {
+		case 0:
+			if a.Order != b.Order {
+				return a.Order > b.Order
+			}
+		case 1:
+			if da, db := deltas[a.DeliveryService], deltas[b.DeliveryService]; da != db {
+				return da < db
+			}
+		case 2:
+			if a.Order != b.Order {
+				return a.Order < b.Order
+			}
+		}
+		return a.DeliveryService < b.DeliveryService
+	})
+	return targets
+}
+
+// Result is a steering target, and the cache selected for a request to it.
+type Result struct {
+	Target   Target
+	Cache    tc.CacheName
+	CachePos tc.CRConfigLatitudeLongitude
+}
+
+// HasGeo returns whether the target has the coordinates of its primary origin, from a geo-ordered or geo-weighted steering target.
+func (t Target) HasGeo() bool {
+	return t.Latitude != nil && t.Longitude != nil
+}
+
+// GeoSort sorts the given results by the distance from the client, through the result's cache, to the target's primary origin, shortest first. Results whose targets have no origin coordinates are last. Results with the same cache location and origin are sorted by geoOrder. Then the results are stably sorted by order, preserving the order of Order. If no target has origin coordinates, the results are not changed.
+func GeoSort(results []Result, clientPos tc.CRConfigLatitudeLongitude) {
+	hasGeo := false
+	for _, result := range results {
+		if result.Target.HasGeo() {
+			hasGeo = true
+			break
+		}
+	}
+	if !hasGeo {
+		return
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Target.HasGeo() != b.Target.HasGeo() {
+			return a.Target.HasGeo()
+		}
+		if !a.Target.HasGeo() {
+			return false
+		}
+		if a.CachePos.Lat == b.CachePos.Lat && a.CachePos.Lon == b.CachePos.Lon && *a.Target.Latitude == *b.Target.Latitude && *a.Target.Longitude == *b.Target.Longitude {
+			return geoOrder(a.Target) < geoOrder(b.Target)
+		}
+		clientToCacheA := distance(clientPos.Lat, clientPos.Lon, a.CachePos.Lat, a.CachePos.Lon)
+		clientToCacheB := distance(clientPos.Lat, clientPos.Lon, b.CachePos.Lat, b.CachePos.Lon)
+		totalA := clientToCacheA + distance(a.CachePos.Lat, a.CachePos.Lon, *a.Target.Latitude, *a.Target.Longitude)
+		totalB := clientToCacheB + distance(b.CachePos.Lat, b.CachePos.Lon, *b.Target.Latitude, *b.Target.Longitude)
+		if totalA != totalB {
+			return totalA < totalB
+		}
+		return clientToCacheA < clientToCacheB
+	})
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Target.Order < results[j].Target.Order
+	})
+}
+
+func geoOrder(t Target) int {
+	if t.GeoOrder == nil {
+		return 0
+	}
+	return *t.GeoOrder
+}
+
+// EarthRadiusKM is the mean radius of the Earth, in kilometers.
+const EarthRadiusKM = 6371.0
+
+// distance returns the great-circle distance in kilometers between the given coordinates, in degrees.
+func distance(latA float64, lonA float64, latB float64, lonB float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(latB - latA)
+	dLon := rad(lonB - lonA)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(latA))*math.Cos(rad(latB))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKM * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,150 @@
+package steering
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/consistenthash"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func targetNames(targets []Target) []tc.DeliveryServiceName {
+	names := []tc.DeliveryServiceName{}
+	for _, target := range targets {
+		names = append(names, target.DeliveryService)
+	}
+	return names
+}
+
+func TestOrder(t *testing.T) {
+	steerings, errs := New([]tc.Steering{{
+		DeliveryService: "steering",
+		Targets: []tc.SteeringSteeringTarget{
+			{DeliveryService: "order-2", Order: 2},
+			{DeliveryService: "order-neg-2", Order: -2},
+			{DeliveryService: "weight-a", Weight: 100},
+			{DeliveryService: "order-1", Order: 1},
+			{DeliveryService: "order-neg-1", Order: -1},
+			{DeliveryService: "weight-b", Weight: 100},
+		},
+	}})
+	if len(errs) != 0 {
+		t.Fatalf("New expected: no errors, actual: %v", errs)
+	}
+	st := steerings["steering"]
+
+	counts := map[tc.DeliveryServiceName]int{}
+	for i := 0; i < 1000; i++ {
+		hash := consistenthash.Hash("/path/" + strconv.Itoa(i))
+		names := targetNames(st.Order(hash))
+		if len(names) != 6 {
+			t.Fatalf("Order expected: 6 targets, actual: %v", names)
+		}
+		if names[0] != "order-neg-1" || names[1] != "order-neg-2" || names[4] != "order-1" || names[5] != "order-2" {
+			t.Fatalf("Order expected: [order-neg-1 order-neg-2 weight weight order-1 order-2], actual: %v", names)
+		}
+		counts[names[2]]++
+
+		if again := targetNames(st.Order(hash)); again[2] != names[2] {
+			t.Fatalf("Order with the same hash expected: same order, actual: %v and %v", names, again)
+		}
+	}
+	if counts["weight-a"] < 300 || counts["weight-b"] < 300 {
+		t.Errorf("Order with equal weights expected: requests spread over weighted targets, actual: %v", counts)
+	}
+}
+
+func TestBypass(t *testing.T) {
+	steerings, errs := New([]tc.Steering{{
+		DeliveryService: "steering",
+		Filters: []tc.SteeringFilter{
+			{DeliveryService: "bypass-a", Pattern: `.*/force-to-a/.*`},
+			{DeliveryService: "invalid", Pattern: `(`},
+			{DeliveryService: "bypass-b", Pattern: `/b/.*`},
+		},
+	}})
+	if len(errs) != 1 {
+		t.Errorf("New with invalid filter expected: 1 error, actual: %v", errs)
+	}
+	st := steerings["steering"]
+
+	tests := []struct {
+		path     string
+		expected tc.DeliveryServiceName
+	}{
+		{"/foo/force-to-a/bar", "bypass-a"},
+		{"/b/foo", "bypass-b"},
+		{"/a/b/foo", ""}, // patterns must match the entire path
+		{"/foo", ""},
+	}
+	for _, test := range tests {
+		ds, ok := st.Bypass(test.path)
+		if test.expected == "" && ok {
+			t.Errorf("Bypass(%v) expected: no match, actual: %v", test.path, ds)
+		} else if test.expected != "" && ds != test.expected {
+			t.Errorf("Bypass(%v) expected: %v, actual: %v", test.path, test.expected, ds)
+		}
+	}
+}
+
+func floatPtr(f float64) *float64 { return &f }
+func intPtr(i int) *int           { return &i }
+
+func TestGeoSort(t *testing.T) {
+	// the client and caches are in Denver
+	denver := tc.CRConfigLatitudeLongitude{Lat: 39.7, Lon: -104.9}
+	geoTarget := func(ds tc.DeliveryServiceName, lat float64, lon float64, geoOrder int) Target {
+		return Target{SteeringSteeringTarget: tc.SteeringSteeringTarget{DeliveryService: ds, Latitude: floatPtr(lat), Longitude: floatPtr(lon), GeoOrder: intPtr(geoOrder)}}
+	}
+	results := []Result{
+		{Target: Target{SteeringSteeringTarget: tc.SteeringSteeringTarget{DeliveryService: "no-geo"}}, CachePos: denver},
+		{Target: geoTarget("new-york", 40.7, -74.0, 0), CachePos: denver},
+		{Target: geoTarget("chicago-2", 41.9, -87.6, 2), CachePos: denver},
+		{Target: geoTarget("chicago-1", 41.9, -87.6, 1), CachePos: denver},
+		{Target: geoTarget("boulder", 40.0, -105.3, 0), CachePos: denver},
+	}
+	GeoSort(results, denver)
+
+	actual := []tc.DeliveryServiceName{}
+	for _, result := range results {
+		actual = append(actual, result.Target.DeliveryService)
+	}
+	expected := []tc.DeliveryServiceName{"boulder", "chicago-1", "chicago-2", "new-york", "no-geo"}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("GeoSort expected: %v, actual: %v", expected, actual)
+		}
+	}
+}
+
+func TestGeoSortNoGeo(t *testing.T) {
+	results := []Result{
+		{Target: Target{SteeringSteeringTarget: tc.SteeringSteeringTarget{DeliveryService: "b", Order: 2}}},
+		{Target: Target{SteeringSteeringTarget: tc.SteeringSteeringTarget{DeliveryService: "a", Order: 1}}},
+	}
+	GeoSort(results, tc.CRConfigLatitudeLongitude{})
+	if results[0].Target.DeliveryService != "b" {
+		t.Errorf("GeoSort without geo targets expected: unchanged, actual: %v %v", results[0].Target.DeliveryService, results[1].Target.DeliveryService)
+	}
+}
@@ -1,4 +1,4 @@
-package nextcache
+package steering
 
 /*
  * Licensed to the Apache Software Foundation (ASF) under one
@@ -20,4 +20,4 @@ package nextcache
  *
  */
 
-type ThsT NextCacher
+type ThsT Steerings
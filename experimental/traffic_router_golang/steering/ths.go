@@ -1,4 +1,4 @@
-package nextcache
+package steering
 
 /*
  * Licensed to the Apache Software Foundation (ASF) under one
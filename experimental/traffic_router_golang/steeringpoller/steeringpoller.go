@@ -0,0 +1,79 @@
+package steeringpoller
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/steering"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// Start fetches the steering delivery services, which the fetcher must return as a JSON array of Traffic Ops steering objects, and re-fetches them every interval, replacing them when they change. If new steering data is invalid, it's logged and the previous steering continues to be used.
+//
+// Start returns an error if the initial steering can't be fetched or parsed, but still continues polling, and the returned Ths is usable.
+func Start(fetcher fetch.Fetcher, interval time.Duration) (steering.Ths, error) {
+	ths := steering.NewThs()
+	ths.Set(steering.ThsT(steering.Steerings{}))
+	prevBts := []byte{}
+
+	get := func() error {
+		newBts, err := fetcher.Fetch()
+		if err != nil {
+			return errors.New("fetching: " + err.Error())
+		}
+		if bytes.Equal(newBts, prevBts) {
+			return nil
+		}
+		tcSteerings := []tc.Steering{}
+		if err := json.Unmarshal(newBts, &tcSteerings); err != nil {
+			return errors.New("not using invalid new steering: unmarshalling: " + err.Error())
+		}
+		steerings, errs := steering.New(tcSteerings)
+		for _, err := range errs {
+			fmt.Println("ERROR steering, omitting invalid filter: " + err.Error())
+		}
+		ths.Set(steering.ThsT(steerings))
+		prevBts = newBts
+		fmt.Println("INFO steering set new")
+		return nil
+	}
+
+	initialErr := get()
+
+	if interval > 0 {
+		go func() {
+			for {
+				time.Sleep(interval)
+				if err := get(); err != nil {
+					fmt.Println("ERROR steering " + err.Error())
+				}
+			}
+		}()
+	}
+	return ths, initialErr
+}
@@ -21,9 +21,13 @@ package toutil
  */
 
 import (
+	"encoding/json"
 	"errors"
+	"sort"
 	"strconv"
 
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
+
 	to "github.com/apache/trafficcontrol/traffic_ops/client"
 )
 
@@ -59,3 +63,21 @@ func GetMonitorURIs(toc *to.Session, cdn string) ([]string, error) {
 	}
 	return monitors, nil
 }
+
+// NewSteeringFetcher returns a Fetcher which fetches the steering delivery services from Traffic Ops, as a JSON array sorted by delivery service, so the fetched bytes only change when the steering changes.
+func NewSteeringFetcher(toc *to.Session) fetch.Fetcher {
+	return steeringFetcher{toc: toc}
+}
+
+type steeringFetcher struct {
+	toc *to.Session
+}
+
+func (f steeringFetcher) Fetch() ([]byte, error) {
+	steerings, _, err := f.toc.Steering()
+	if err != nil {
+		return nil, errors.New("getting steering from Traffic Ops: " + err.Error())
+	}
+	sort.Slice(steerings, func(i, j int) bool { return steerings[i].DeliveryService < steerings[j].DeliveryService })
+	return json.Marshal(steerings)
+}
@@ -30,10 +30,13 @@ import (
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/config"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezonepoller"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigpoller"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crstatespoller"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/dnssrvr"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/httpsrvr"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/steeringpoller"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/toutil"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
@@ -63,12 +66,21 @@ func main() {
 
 	log.Infof("Starting with config %+v\n", cfg)
 
-	cz, err := coveragezone.Load(cfg.CoverageZoneFile)
+	cz, err := coveragezonepoller.Start(fetch.NewFile(cfg.CoverageZoneFile), time.Duration(cfg.CoverageZoneInterval))
 	if err != nil {
 		fmt.Println("Error loading coverage zone file '" + cfg.CoverageZoneFile + "': " + err.Error())
 		os.Exit(1)
 	}
 
+	deepCZ := coveragezone.NewThs()
+	if cfg.DeepCoverageZoneFile != "" {
+		deepCZ, err = coveragezonepoller.Start(fetch.NewFile(cfg.DeepCoverageZoneFile), time.Duration(cfg.CoverageZoneInterval))
+		if err != nil {
+			fmt.Println("Error loading deep coverage zone file '" + cfg.DeepCoverageZoneFile + "': " + err.Error())
+			os.Exit(1)
+		}
+	}
+
 	toURIStr := (*url.URL)(cfg.TrafficOpsURI).String()
 	log.Infof("TO URI Str: " + toURIStr + "\n")
 
@@ -95,7 +107,7 @@ func main() {
 	// crconfigFetcher := fetch.NewFile("./crconfig.json")
 	// crstatesFetcher := fetch.NewFile("./crstates.json")
 
-	thsCRConfig, thsCRConfigRegexes, thsCGSearcher, thsNextCacher, err := crconfigpoller.Start(crconfigFetcher, time.Duration(cfg.CRConfigInterval))
+	thsCRConfig, thsCRConfigRegexes, thsCGSearcher, thsHasher, err := crconfigpoller.Start(crconfigFetcher, time.Duration(cfg.CRConfigInterval))
 	if err != nil {
 		fmt.Println("Could not get initial CRConfig: ", err)
 	}
@@ -105,7 +117,19 @@ func main() {
 		fmt.Println("Could not get initial CRStates from: ", err)
 	}
 
-	httpsrvr.Start(thsCRConfigRegexes, availableServers, thsCGSearcher, thsNextCacher, cz, cfg.Port)
+	thsSteering, err := steeringpoller.Start(toutil.NewSteeringFetcher(toClient), time.Duration(cfg.SteeringInterval))
+	if err != nil {
+		fmt.Println("Could not get initial steering: ", err)
+	}
+
+	httpsrvr.Start(thsCRConfig, thsCRConfigRegexes, availableServers, thsCGSearcher, thsHasher, thsSteering, cz, deepCZ, cfg.Port)
+
+	if cfg.DNSPort != 0 {
+		dnsHandler := dnssrvr.NewHandler(thsCRConfig, thsCRConfigRegexes, availableServers, thsCGSearcher, thsHasher, cz, deepCZ)
+		if _, err := dnssrvr.Start(dnsHandler, cfg.DNSPort); err != nil {
+			fmt.Println("Could not start DNS server: ", err)
+		}
+	}
 
 	// debug
 	for {
@@ -167,7 +167,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	reqID := atomic.AddUint64(&h.requestID, 1)
 	pluginContext := copyPluginContext(h.pluginContext) // must give each request a copy, because they can modify in parallel
 	srvrData := cachedata.SrvrData{h.hostname, h.port, h.scheme}
-	onReqData := plugin.OnRequestData{W: w, R: r, Stats: h.stats, StatRules: h.remapper.StatRules(), HTTPConns: h.httpConns, HTTPSConns: h.httpsConns, InterfaceName: h.interfaceName, SrvrData: srvrData, RequestID: reqID}
+	onReqData := plugin.OnRequestData{W: w, R: r, Stats: h.stats, StatRules: h.remapper.StatRules(), Rules: h.remapper.Rules(), HTTPConns: h.httpConns, HTTPSConns: h.httpsConns, InterfaceName: h.interfaceName, SrvrData: srvrData, RequestID: reqID}
 	stop := h.plugins.OnRequest(h.remapper.PluginCfg(), pluginContext, onReqData)
 	if stop {
 		return
@@ -233,11 +233,21 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	cache := remappingProducer.Cache()
 
 	var reqHost *string
+	objKey := cacheKey // the key of the cached object, which is the variant key if it varies
 	cacheObj, ok := cache.Get(cacheKey)
+	if ok && cacheObj.IsVaryIndex() {
+		objKey = rfc.VariantKey(cacheKey, cacheObj.Vary, reqHeader)
+		log.Debugf("cache.Handler.ServeHTTP: '%v' varies on %v, getting variant '%v' (reqid %v)\n", cacheKey, cacheObj.Vary, objKey, reqID)
+		cacheObj, ok = cache.Get(objKey)
+	}
 	if !ok {
 		log.Debugf("cache.Handler.ServeHTTP: '%v' not in cache (reqid %v)\n", cacheKey, reqID)
 		beforeParentRequestData := plugin.BeforeParentRequestData{Req: r, RemapRule: remappingProducer.Name()}
 		h.plugins.OnBeforeParentRequest(remappingProducer.PluginCfg(), pluginContext, beforeParentRequestData)
+		if canStream(r, remappingProducer) {
+			h.serveStream(r, retrier, responder, remappingProducer, pluginContext, connectionClose)
+			return
+		}
 		cacheObj, reqHost, err = retrier.Get(r, nil)
 		if err != nil {
 			log.Errorf("retrying get error (in uncached): %v (reqid %v)\n", err, reqID)
@@ -251,15 +261,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		responder.OriginCode = cacheObj.OriginCode
-		// create new pointers, so plugins don't modify the cacheObj
-		codePtr, hdrsPtr, bodyPtr := cacheObj.Code, cacheObj.RespHeaders, cacheObj.Body
-		responder.SetResponse(&codePtr, &hdrsPtr, &bodyPtr, connectionClose)
+		cacheObj, codePtr, hdrsPtr, bodyPtr, err := setCacheObjResponse(r, responder, cache, objKey, cacheObj, remappingProducer.Timeout(), connectionClose) // the object may be chunked, if this request was collapsed with a revalidation
+		if err != nil {
+			log.Errorf("cache.Handler.ServeHTTP: '%v' reading chunked body: %v (reqid %v)\n", objKey, err, reqID)
+			responder.Do()
+			return
+		}
 		responder.OriginReqSuccess = true
 		responder.ProxyStr = cacheObj.ProxyURL
 		if reqHost != nil {
 			responder.ToFQDN = *reqHost
 		}
-		beforeRespData := plugin.BeforeRespondData{Req: r, CacheObj: cacheObj, Code: &codePtr, Hdr: &hdrsPtr, Body: &bodyPtr, RemapRule: remappingProducer.Name()}
+		beforeRespData := plugin.BeforeRespondData{Req: r, CacheObj: cacheObj, Code: codePtr, Hdr: hdrsPtr, Body: bodyPtr, RemapRule: remappingProducer.Name()}
 		h.plugins.OnBeforeRespond(remappingProducer.PluginCfg(), pluginContext, beforeRespData)
 		responder.Do()
 		return
@@ -267,6 +280,9 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	reqHeaders := r.Header
 	canReuseStored := rfc.CanReuseStored(reqHeaders, cacheObj.RespHeaders, reqCacheControl, cacheObj.RespCacheControl, cacheObj.ReqHeaders, cacheObj.ReqRespTime, cacheObj.RespRespTime, h.strictRFC)
+	if cacheObj.Invalidated && canReuseStored == remapdata.ReuseCan {
+		canReuseStored = remapdata.ReuseMustRevalidate // soft-purged objects must be revalidated, even if fresh
+	}
 
 	if canReuseStored != remapdata.ReuseCan { // run the BeforeParentRequest hook for revalidations / ReuseCannot
 		beforeParentRequestData := plugin.BeforeParentRequestData{Req: r, RemapRule: remappingProducer.Name()}
@@ -278,6 +294,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		log.Debugf("cache.Handler.ServeHTTP: '%v' cache hit! (reqid %v)\n", cacheKey, reqID)
 	case remapdata.ReuseCannot:
 		log.Debugf("cache.Handler.ServeHTTP: '%v' can't reuse (reqid %v)\n", cacheKey, reqID)
+		if canStream(r, remappingProducer) {
+			h.serveStream(r, retrier, responder, remappingProducer, pluginContext, connectionClose)
+			return
+		}
 		cacheObj, reqHost, err = retrier.Get(r, nil)
 		if err != nil {
 			log.Errorf("retrying get error (in reuse-cannot): %v (reqid %v)\n", err, reqID)
@@ -303,9 +323,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debugf("cache.Handler.ServeHTTP: '%v' responding with %v (reqid %v)\n", cacheKey, cacheObj.Code, reqID)
 
-	// create new pointers, so plugins don't modify the cacheObj
-	codePtr, hdrsPtr, bodyPtr := cacheObj.Code, cacheObj.RespHeaders, cacheObj.Body
-	responder.SetResponse(&codePtr, &hdrsPtr, &bodyPtr, connectionClose)
+	cacheObj, codePtr, hdrsPtr, bodyPtr, err := setCacheObjResponse(r, responder, cache, objKey, cacheObj, remappingProducer.Timeout(), connectionClose)
+	if err != nil {
+		log.Errorf("cache.Handler.ServeHTTP: '%v' reading chunked body: %v (reqid %v)\n", objKey, err, reqID)
+		responder.Do()
+		return
+	}
 	responder.OriginReqSuccess = true
 	responder.Reuse = canReuseStored
 	responder.OriginCode = cacheObj.OriginCode
@@ -314,7 +337,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if reqHost != nil {
 		responder.ToFQDN = *reqHost
 	}
-	beforeRespData := plugin.BeforeRespondData{Req: r, CacheObj: cacheObj, Code: &codePtr, Hdr: &hdrsPtr, Body: &bodyPtr, RemapRule: remappingProducer.Name()}
+	beforeRespData := plugin.BeforeRespondData{Req: r, CacheObj: cacheObj, Code: codePtr, Hdr: hdrsPtr, Body: bodyPtr, RemapRule: remappingProducer.Name()}
 	h.plugins.OnBeforeRespond(remappingProducer.PluginCfg(), pluginContext, beforeRespData)
 	responder.Do()
 }
@@ -60,7 +60,7 @@ func (r *Retrier) Get(req *http.Request, obj *cacheobj.CacheObj) (*cacheobj.Cach
 			return rfc.CanReuse(r.ReqHdr, r.ReqCacheControl, cacheObj, r.H.strictRFC, true)
 		}
 		getAndCache := func() *cacheobj.CacheObj {
-			return GetAndCache(remapping.Request, remapping.ProxyURL, remapping.CacheKey, remapping.Name, remapping.Request.Header, r.ReqTime, r.H.strictRFC, remapping.Cache, r.H.ruleThrottlers[remapping.Name], obj, remapping.Timeout, retryFailures, remapping.RetryNum, remapping.RetryCodes, remapping.Transport, r.ReqID)
+			return GetAndCache(remapping.Request, remapping.ProxyURL, remapping.CacheKey, remapping.Name, remapping.Request.Header, r.ReqTime, r.H.strictRFC, remapping.Cache, remapping.MaxObjectSize, r.H.ruleThrottlers[remapping.Name], obj, remapping.Timeout, retryFailures, remapping.RetryNum, remapping.RetryCodes, remapping.Transport, r.ReqID)
 		}
 		gotObj, getReqID := r.H.getter.Get(remapping.CacheKey, getAndCache, canReuse, r.ReqID)
 
@@ -104,6 +104,7 @@ const ModifiedSinceHdr = "If-Modified-Since"
 
 // GetAndCache makes a client request for the given `http.Request` and caches it if `CanCache`.
 // THe `ruleThrottler` may be nil, in which case the request will be unthrottled.
+// Objects larger than maxObjectSize are not cached. If maxObjectSize is 0, objects of any size are cached.
 func GetAndCache(
 	req *http.Request,
 	proxyURL *url.URL,
@@ -113,6 +114,7 @@ func GetAndCache(
 	reqTime time.Time,
 	strictRFC bool,
 	cache icache.Cache,
+	maxObjectSize uint64,
 	ruleThrottler thread.Throttler,
 	revalidateObj *cacheobj.CacheObj,
 	timeout time.Duration,
@@ -170,6 +172,10 @@ func GetAndCache(
 			if !rfc.CanCache(req.Method, reqHeader, respCode, respHeader, strictRFC) {
 				return obj // return without caching
 			}
+			if maxObjectSize > 0 && obj.Size > maxObjectSize {
+				log.Debugf("GetAndCache %v size %v exceeds max object size %v, not caching (reqid %v)\n", cacheKey, obj.Size, maxObjectSize, reqID)
+				return obj // return without caching
+			}
 		} else {
 			log.Debugf("GetAndCache revalidating %v len(revalidateObj.Body) %v (reqid %v)\n", cacheKey, len(revalidateObj.Body), reqID)
 			// must copy, because this cache object may be concurrently read by other goroutines
@@ -189,9 +195,10 @@ func GetAndCache(
 				LastModified:     revalidateObj.LastModified,
 				Size:             revalidateObj.Size,
 				HitCount:         revalidateObj.HitCount, // no need to +1 here, the cache Get did that
+				Chunked:          revalidateObj.Chunked,  // the body is still the cached chunks
 			}
 		}
-		cache.Add(cacheKey, obj) // TODO store pointer?
+		addVariant(cache, cacheKey, reqHeader, obj) // TODO store pointer?
 		return obj
 	}
 
@@ -203,3 +210,21 @@ func GetAndCache(
 	ruleThrottler.Throttle(func() { c = get() })
 	return c
 }
+
+// addVariant adds the object to the cache. If the response varies on request headers, it's added under its variant key, and a variant index is added under the key, so requests can find the variant.
+func addVariant(cache icache.Cache, key string, reqHeader http.Header, obj *cacheobj.CacheObj) {
+	variantKey, vary := variant(key, reqHeader, obj.RespHeaders)
+	cache.Add(variantKey, obj)
+	if len(vary) > 0 {
+		cache.Add(key, cacheobj.NewVaryIndex(vary))
+	}
+}
+
+// variant returns the key to store the response under, and the request header names it varies on. If the response doesn't vary, the key is returned unchanged.
+func variant(key string, reqHeader http.Header, respHeader http.Header) (string, []string) {
+	vary, _ := rfc.VaryHeaders(respHeader)
+	if len(vary) == 0 {
+		return key, nil
+	}
+	return rfc.VariantKey(key, vary, reqHeader), vary
+}
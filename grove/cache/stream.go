@@ -0,0 +1,444 @@
+package cache
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/grove/cacheobj"
+	"github.com/apache/trafficcontrol/grove/icache"
+	"github.com/apache/trafficcontrol/grove/plugin"
+	"github.com/apache/trafficcontrol/grove/remap"
+	"github.com/apache/trafficcontrol/grove/rfc"
+	"github.com/apache/trafficcontrol/grove/thread"
+	"github.com/apache/trafficcontrol/grove/web"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+)
+
+// StreamReadSize is the size in bytes of each read from the parent, when streaming.
+const StreamReadSize = 32 * 1024
+
+// StreamBufferChunks is the number of reads of StreamReadSize to buffer for a slow client, before reading from the parent waits for the client.
+const StreamBufferChunks = 64
+
+// canStream returns whether the given request may be streamed. Range requests are never streamed, because range plugins need the whole object.
+func canStream(r *http.Request, remappingProducer *remap.RemappingProducer) bool {
+	return remappingProducer.Stream() && r.Method == http.MethodGet && r.Header.Get("Range") == ""
+}
+
+// serveStream requests the object from the parent, and streams it to the client as it arrives, while caching it if it's cacheable.
+//
+// The parent request holds the rule's throttle until the parent body is read, and the client is sent the rest of the buffered body after it's released. A client slower than the parent still holds the throttle while StreamBufferChunks are buffered, until it's abandoned after the rule timeout. Unlike cache misses which aren't streamed, concurrent requests for the same object are not collapsed into a single parent request.
+func (h *Handler) serveStream(r *http.Request, retrier *Retrier, responder *Responder, remappingProducer *remap.RemappingProducer, pluginContext map[string]*interface{}, connectionClose bool) {
+	ruleThrottler := h.ruleThrottlers[remappingProducer.Name()]
+	if ruleThrottler == nil {
+		log.Errorf("rule %v not in ruleThrottlers map. Requesting with no origin limit! (reqid %v)\n", remappingProducer.Name(), retrier.ReqID)
+		ruleThrottler = thread.NewNoThrottler()
+	}
+	release := holdThrottle(ruleThrottler)
+	defer release()
+	h.stream(r, retrier, responder, remappingProducer, pluginContext, connectionClose, release)
+}
+
+// holdThrottle waits for the throttler, and returns a func to release it, which may be called more than once. This allows releasing the throttle before the throttled work returns.
+func holdThrottle(throttler thread.Throttler) func() {
+	acquired := make(chan struct{})
+	released := make(chan struct{})
+	go throttler.Throttle(func() {
+		close(acquired)
+		<-released
+	})
+	<-acquired
+	once := sync.Once{}
+	return func() { once.Do(func() { close(released) }) }
+}
+
+// stream requests and streams the object. The given parentDone is called when the parent body has been read, or won't be, before the client has necessarily been sent all of it.
+func (h *Handler) stream(r *http.Request, retrier *Retrier, responder *Responder, remappingProducer *remap.RemappingProducer, pluginContext map[string]*interface{}, connectionClose bool, parentDone func()) {
+	reqID := retrier.ReqID
+	parentResp, err := retrier.getStream(r)
+	if err != nil {
+		parentDone()
+		log.Errorf("streaming get error: %v (reqid %v)\n", err, reqID)
+		*responder.ResponseCode = CodeConnectFailure
+		responder.OriginConnectFailed = true
+		responder.Do()
+		return
+	}
+	bodyDone := func() {
+		parentResp.resp.Body.Close()
+		parentDone()
+	}
+	defer bodyDone()
+
+	obj := parentResp.cacheObj(retrier.ReqHdr)
+	cacher := (*streamCacher)(nil)
+	if _, failure := parentResp.remapping.RetryCodes[obj.Code]; !failure && rfc.CanCache(r.Method, retrier.ReqHdr, obj.Code, obj.RespHeaders, h.strictRFC) {
+		cacher = newStreamCacher(parentResp.remapping.Cache, parentResp.remapping.CacheKey, parentResp.remapping.Request.Header, obj.RespHeaders, parentResp.remapping.MaxObjectSize, parentResp.resp.ContentLength, reqID)
+	}
+
+	responder.OriginCode = obj.OriginCode
+	responder.OriginReqSuccess = true
+	responder.ProxyStr = obj.ProxyURL
+	responder.ToFQDN = parentResp.remapping.Request.URL.Host
+
+	// create new pointers, so plugins don't modify the cacheObj. The body is nil, because it hasn't been received yet.
+	codePtr, hdrsPtr, bodyPtr := obj.Code, obj.RespHeaders, []byte(nil)
+	beforeRespData := plugin.BeforeRespondData{Req: r, CacheObj: obj, Code: &codePtr, Hdr: &hdrsPtr, Body: &bodyPtr, RemapRule: remappingProducer.Name()}
+	h.plugins.OnBeforeRespond(remappingProducer.PluginCfg(), pluginContext, beforeRespData)
+
+	responder.ResponseCode = &codePtr
+	responder.F = func() (uint64, error) {
+		if bodyPtr != nil {
+			// a plugin replaced the body, so the parent's body isn't sent, and isn't cached, since it isn't read.
+			bodyDone()
+			return web.Respond(responder.W, codePtr, hdrsPtr, bodyPtr, connectionClose)
+		}
+		bytesSent, bytesRead, complete, err := streamBody(responder.W, responder.Conn, codePtr, hdrsPtr, connectionClose, parentResp.resp.Body, parentResp.remapping.Timeout, cacher, bodyDone)
+		responder.OriginBytes = bytesRead
+		if cacher != nil {
+			cacher.finish(obj, complete)
+		}
+		return bytesSent, err
+	}
+	responder.Do()
+}
+
+// streamBody writes the code and headers to the client, and then writes the body as it's read from the parent, also writing it to the cacher if it isn't nil. Returns the bytes sent to the client, the bytes read from the parent, whether the entire parent body was read, and any error.
+//
+// If bodyDone isn't nil, it's called when the body is finished being read, before the rest of the buffered body is sent to the client.
+//
+// Reads from the parent don't wait for the client until StreamBufferChunks are buffered. If the conn is not nil, a client which doesn't accept a write within the clientTimeout is abandoned, and the parent continues to be read to fill the cache.
+//
+// If the parent body fails before it ends, the client conn is closed, so the client can see the body is incomplete.
+func streamBody(w http.ResponseWriter, conn *web.InterceptConn, code int, hdr http.Header, connectionClose bool, body io.Reader, clientTimeout time.Duration, cacher *streamCacher, bodyDone func()) (uint64, uint64, bool, error) {
+	dH := w.Header()
+	web.CopyHeaderTo(hdr, &dH)
+	if connectionClose {
+		dH.Add("Connection", "close")
+	}
+	w.WriteHeader(code)
+	web.TryFlush(w)
+
+	chunks := make(chan []byte, StreamBufferChunks)
+	clientDone := make(chan struct{})
+	writerDone := make(chan struct{})
+	bytesSent := uint64(0)
+	clientErr := error(nil)
+	go func() {
+		defer close(writerDone)
+		for chunk := range chunks {
+			if clientErr != nil {
+				continue // drain, so the parent reader never blocks on an abandoned client
+			}
+			if conn != nil && clientTimeout > 0 {
+				conn.SetWriteDeadline(time.Now().Add(clientTimeout))
+			}
+			bytesWritten, err := w.Write(chunk)
+			bytesSent += uint64(bytesWritten)
+			if err == nil {
+				web.TryFlush(w)
+				continue
+			}
+			clientErr = errors.New("writing to client: " + err.Error())
+			close(clientDone)
+		}
+	}()
+
+	bytesRead := uint64(0)
+	parentErr := error(nil)
+	for {
+		buf := make([]byte, StreamReadSize) // must be new for every read, because the client writer may not have written the last one yet
+		n, err := body.Read(buf)
+		if n > 0 {
+			bytesRead += uint64(n)
+			if cacher != nil {
+				cacher.write(buf[:n])
+			}
+			select {
+			case chunks <- buf[:n]:
+			case <-clientDone:
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			parentErr = errors.New("reading body: " + err.Error())
+			break
+		}
+		select {
+		case <-clientDone:
+			if cacher == nil || cacher.failed {
+				parentErr = errors.New("client abandoned, and not caching") // stop reading the parent, nothing needs the rest of the body
+			}
+		default:
+		}
+		if parentErr != nil {
+			break
+		}
+	}
+	if bodyDone != nil {
+		bodyDone()
+	}
+	close(chunks)
+	<-writerDone
+
+	if conn != nil && clientTimeout > 0 {
+		conn.SetWriteDeadline(time.Time{}) // don't leave our deadline on the conn for the next request
+	}
+	if parentErr != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		if clientErr != nil {
+			return bytesSent, bytesRead, false, errors.New(clientErr.Error() + ", " + parentErr.Error())
+		}
+		return bytesSent, bytesRead, false, parentErr
+	}
+	return bytesSent, bytesRead, true, clientErr
+}
+
+// streamResp is a parent response, whose body hasn't been read yet.
+type streamResp struct {
+	resp        *http.Response
+	remapping   remap.Remapping
+	reqTime     time.Time
+	reqRespTime time.Time
+}
+
+// cacheObj returns a cache object for the response, with a nil body.
+func (p *streamResp) cacheObj(reqHeader http.Header) *cacheobj.CacheObj {
+	proxyURLStr := ""
+	if p.remapping.ProxyURL != nil {
+		proxyURLStr = p.remapping.ProxyURL.Host
+	}
+	respRespTime, ok := web.GetHTTPDate(p.resp.Header, "Date")
+	if !ok {
+		log.Errorf("request %v returned no Date header - RFC Violation! Using local response timestamp\n", p.remapping.Request.URL.String())
+		respRespTime = p.reqRespTime // if no Date was returned using the client response time simulates latency 0
+	}
+	lastModified, ok := web.GetHTTPDate(p.resp.Header, "Last-Modified")
+	if !ok {
+		lastModified = respRespTime
+	}
+	return cacheobj.New(reqHeader, nil, p.resp.StatusCode, p.resp.StatusCode, proxyURLStr, p.resp.Header, p.reqTime, p.reqRespTime, respRespTime, lastModified)
+}
+
+// getStream makes the parent request for req, retrying according to the RemappingProducer, and returns the first success, or the last failure. The response body has not been read, and must be closed by the caller.
+func (r *Retrier) getStream(req *http.Request) (*streamResp, error) {
+	last := (*streamResp)(nil)
+	lastErr := errors.New("remapping producer allows no requests") // should never be returned
+	for {
+		remapping, lastAttempt, err := r.RemappingProducer.GetNext(req)
+		if err == remap.ErrNoMoreRetries {
+			if last != nil {
+				return last, nil
+			}
+			return nil, lastErr
+		} else if err != nil {
+			return nil, err
+		}
+
+		remapping.Request.Header.Del(ModifiedSinceHdr)
+		reqTime := time.Now()
+		resp, err := remapping.Transport.RoundTrip(remapping.Request)
+		reqRespTime := time.Now()
+		if err != nil {
+			log.Errorf("Parent error for URI %v %v %v cacheKey %v rule %v error %v (reqid %v)\n", remapping.Request.URL.Scheme, remapping.Request.URL.Host, remapping.Request.URL.EscapedPath(), remapping.CacheKey, remapping.Name, err, r.ReqID)
+			lastErr = errors.New("request error: " + err.Error())
+			continue
+		}
+		if last != nil {
+			last.resp.Body.Close()
+		}
+		last = &streamResp{resp: resp, remapping: remapping, reqTime: reqTime, reqRespTime: reqRespTime}
+		if _, failure := remapping.RetryCodes[resp.StatusCode]; failure && !lastAttempt {
+			continue
+		}
+		return last, nil
+	}
+}
+
+// streamCacher caches a streamed object as it arrives.
+//
+// Objects are buffered in memory up to the cache's chunk threshold, after which they're written in chunks, if the cache is an icache.ChunkCache. If an object exceeds the max object size, or is larger than the threshold and can't be written in chunks, caching is abandoned.
+type streamCacher struct {
+	cache     icache.Cache
+	key       string // the key to store the object under, which is the variant key if the response varies
+	indexKey  string // the key to store the variant index under, if the response varies
+	vary      []string
+	maxSize   uint64
+	threshold uint64
+	buf       []byte
+	writer    icache.ChunkWriter
+	size      uint64
+	failed    bool
+	reqID     uint64
+}
+
+// newStreamCacher creates a streamCacher. The contentLength may be -1 if it's unknown.
+func newStreamCacher(cache icache.Cache, key string, reqHeader http.Header, respHeader http.Header, maxSize uint64, contentLength int64, reqID uint64) *streamCacher {
+	variantKey, vary := variant(key, reqHeader, respHeader)
+	s := &streamCacher{cache: cache, key: variantKey, indexKey: key, vary: vary, maxSize: maxSize, threshold: math.MaxUint64, reqID: reqID}
+	if chunkCache, ok := cache.(icache.ChunkCache); ok {
+		s.threshold = chunkCache.ChunkThreshold()
+	}
+	if maxSize > 0 && contentLength > 0 && uint64(contentLength) > maxSize {
+		s.abort("content length exceeds max object size")
+	}
+	return s
+}
+
+func (s *streamCacher) write(p []byte) {
+	if s.failed {
+		return
+	}
+	s.size += uint64(len(p))
+	if s.maxSize > 0 && s.size > s.maxSize {
+		s.abort("size exceeds max object size")
+		return
+	}
+	if s.writer != nil {
+		if _, err := s.writer.Write(p); err != nil {
+			s.abort("writing chunks: " + err.Error())
+		}
+		return
+	}
+	s.buf = append(s.buf, p...)
+	if s.size <= s.threshold {
+		return
+	}
+	chunkCache, ok := s.cache.(icache.ChunkCache)
+	if !ok {
+		s.abort("size exceeds chunk threshold, but cache does not support chunks") // should never happen, the threshold is only set for chunk caches
+		return
+	}
+	writer, err := chunkCache.NewChunkWriter(s.key)
+	if err != nil {
+		s.abort("creating chunk writer: " + err.Error())
+		return
+	}
+	s.writer = writer
+	buf := s.buf
+	s.buf = nil
+	if _, err := s.writer.Write(buf); err != nil {
+		s.abort("writing chunks: " + err.Error())
+	}
+}
+
+func (s *streamCacher) abort(reason string) {
+	log.Debugf("streamCacher not caching '%v': %v (reqid %v)\n", s.key, reason, s.reqID)
+	s.failed = true
+	s.buf = nil
+	if s.writer != nil {
+		s.writer.Abort()
+		s.writer = nil
+	}
+}
+
+// finish caches the object, with the bytes written as its body. The given obj must have a nil body, and must not be used by the caller afterwards. If !complete, the body wasn't entirely received, and the object is not cached.
+func (s *streamCacher) finish(obj *cacheobj.CacheObj, complete bool) {
+	if !complete {
+		if !s.failed {
+			s.abort("body incomplete")
+		}
+		return
+	}
+	if s.failed {
+		return
+	}
+	if s.writer != nil {
+		if err := s.writer.Commit(obj); err != nil {
+			log.Errorf("streamCacher committing '%v': %v (reqid %v)\n", s.key, err, s.reqID)
+			return
+		}
+	} else {
+		obj.Body = s.buf
+		obj.Size = obj.ComputeSize()
+		s.cache.Add(s.key, obj)
+	}
+	if len(s.vary) > 0 {
+		s.cache.Add(s.indexKey, cacheobj.NewVaryIndex(s.vary))
+	}
+}
+
+// setCacheObjResponse sets the response of the responder to the given object, like Responder.SetResponse, with new pointers, so plugins don't modify the object. The body of a Chunked object is streamed from the cache, which stores it under the given key. Returns the object to give plugins, and the pointers for them to modify.
+//
+// Range requests for Chunked objects are given a copy of the object with its whole body instead, because range plugins need the whole body.
+//
+// If the object is Chunked and its body can't be read, e.g. because it was removed since it was gotten, the responder's code is set to an error, and an error is returned.
+func setCacheObjResponse(r *http.Request, responder *Responder, cache icache.Cache, key string, obj *cacheobj.CacheObj, clientTimeout time.Duration, connectionClose bool) (*cacheobj.CacheObj, *int, *http.Header, *[]byte, error) {
+	body := io.Reader(nil)
+	if obj.Chunked {
+		err := error(nil)
+		if obj, body, err = chunkedBody(r, cache, key, obj); err != nil {
+			*responder.ResponseCode = http.StatusInternalServerError
+			return nil, nil, nil, nil, err
+		}
+	}
+	code, hdrs, bodyBytes := obj.Code, obj.RespHeaders, obj.Body
+	if body == nil {
+		responder.SetResponse(&code, &hdrs, &bodyBytes, connectionClose)
+		return obj, &code, &hdrs, &bodyBytes, nil
+	}
+	responder.ResponseCode = &code
+	responder.F = func() (uint64, error) {
+		if responder.Req.Method == http.MethodHead || !codeAllowsBody(code) {
+			return web.Respond(responder.W, code, hdrs, nil, connectionClose)
+		}
+		if bodyBytes != nil {
+			return web.Respond(responder.W, code, hdrs, bodyBytes, connectionClose) // a plugin replaced the body
+		}
+		bytesSent, _, _, err := streamBody(responder.W, responder.Conn, code, hdrs, connectionClose, body, clientTimeout, nil, nil)
+		return bytesSent, err
+	}
+	return obj, &code, &hdrs, &bodyBytes, nil
+}
+
+// chunkedBody returns a reader of the body of the given Chunked object, stored under the given key. Range requests are instead returned a copy of the object with the whole body, and a nil reader.
+func chunkedBody(r *http.Request, cache icache.Cache, key string, obj *cacheobj.CacheObj) (*cacheobj.CacheObj, io.Reader, error) {
+	chunkCache, ok := cache.(icache.ChunkCache)
+	if !ok {
+		return nil, nil, errors.New("object is chunked, but cache does not support chunks") // should never happen
+	}
+	body, ok := chunkCache.NewChunkReader(key)
+	if !ok {
+		return nil, nil, errors.New("object chunks not found")
+	}
+	if r.Header.Get("Range") == "" {
+		return obj, body, nil
+	}
+	bts, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, nil, errors.New("reading chunks: " + err.Error())
+	}
+	withBody := *obj // must copy, because this cache object may be concurrently read by other goroutines
+	withBody.Body = bts
+	withBody.Chunked = false
+	return &withBody, nil, nil
+}
+
+// codeAllowsBody returns whether a response with the given code may have a body.
+func codeAllowsBody(code int) bool {
+	return code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified
+}
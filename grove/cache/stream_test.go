@@ -0,0 +1,134 @@
+package cache
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/grove/cacheobj"
+	"github.com/apache/trafficcontrol/grove/memcache"
+	"github.com/apache/trafficcontrol/grove/thread"
+)
+
+func TestStreamBody(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), StreamReadSize*3+7)
+	tests := []struct {
+		name    string
+		maxSize uint64
+		cached  bool
+	}{
+		{"no max", 0, true},
+		{"under max", uint64(len(body)), true},
+		{"over max", uint64(len(body)) - 1, false},
+	}
+
+	for _, test := range tests {
+		cache := memcache.New(uint64(len(body)) * 10)
+		cacher := newStreamCacher(cache, "key", http.Header{}, http.Header{}, test.maxSize, -1, 0)
+		w := httptest.NewRecorder()
+		sent, read, complete, err := streamBody(w, nil, http.StatusOK, http.Header{"Foo": {"bar"}}, false, bytes.NewReader(body), time.Second, cacher, nil)
+		if err != nil {
+			t.Fatalf("%v streamBody error expected nil, actual %v", test.name, err)
+		}
+		if !complete {
+			t.Errorf("%v streamBody complete expected true, actual false", test.name)
+		}
+		if sent != uint64(len(body)) || read != uint64(len(body)) {
+			t.Errorf("%v streamBody expected sent and read %v, actual %v %v", test.name, len(body), sent, read)
+		}
+		if !bytes.Equal(w.Body.Bytes(), body) || w.Header().Get("Foo") != "bar" {
+			t.Errorf("%v streamBody client response expected body len %v header bar, actual %v '%v'", test.name, len(body), w.Body.Len(), w.Header().Get("Foo"))
+		}
+
+		obj := cacheobj.New(nil, nil, http.StatusOK, http.StatusOK, "", http.Header{}, time.Now(), time.Now(), time.Now(), time.Now())
+		cacher.finish(obj, complete)
+		cachedObj, ok := cache.Peek("key")
+		if ok != test.cached {
+			t.Fatalf("%v cached expected %v, actual %v", test.name, test.cached, ok)
+		}
+		if ok && !bytes.Equal(cachedObj.Body, body) {
+			t.Errorf("%v cached body expected len %v, actual %v", test.name, len(body), len(cachedObj.Body))
+		}
+	}
+}
+
+// failingReader returns its body, and then an error rather than EOF.
+type failingReader struct {
+	r io.Reader
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestStreamBodyIncomplete(t *testing.T) {
+	cache := memcache.New(1024 * 1024)
+	cacher := newStreamCacher(cache, "key", http.Header{}, http.Header{}, 0, -1, 0)
+	w := httptest.NewRecorder()
+	_, _, complete, err := streamBody(w, nil, http.StatusOK, http.Header{}, false, failingReader{bytes.NewReader([]byte("partial"))}, time.Second, cacher, nil)
+	if err == nil {
+		t.Errorf("streamBody error expected not nil, actual nil")
+	}
+	if complete {
+		t.Errorf("streamBody complete expected false, actual true")
+	}
+	cacher.finish(cacheobj.New(nil, nil, http.StatusOK, http.StatusOK, "", http.Header{}, time.Now(), time.Now(), time.Now(), time.Now()), complete)
+	if _, ok := cache.Peek("key"); ok {
+		t.Errorf("incomplete body expected not cached, actual cached")
+	}
+}
+
+func TestStreamBodyDone(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), StreamReadSize*2)
+	done := 0
+	w := httptest.NewRecorder()
+	if _, _, _, err := streamBody(w, nil, http.StatusOK, http.Header{}, false, bytes.NewReader(body), time.Second, nil, func() { done++ }); err != nil {
+		t.Fatalf("streamBody error expected nil, actual %v", err)
+	}
+	if done != 1 {
+		t.Errorf("streamBody bodyDone calls expected 1, actual %v", done)
+	}
+}
+
+func TestHoldThrottle(t *testing.T) {
+	throttler := thread.NewThrottler(1)
+	release := holdThrottle(throttler)
+
+	acquired := make(chan struct{})
+	go throttler.Throttle(func() { close(acquired) })
+	select {
+	case <-acquired:
+		t.Fatalf("throttle expected held, actual acquired by another request")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	release() // releasing twice must be safe, stream and serveStream both release
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Errorf("throttle expected released, actual still held")
+	}
+}
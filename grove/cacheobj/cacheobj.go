@@ -35,6 +35,21 @@ type CacheObj struct {
 	LastModified     time.Time // the origin LastModified if it exists, or Date if it doesn't
 	Size             uint64
 	HitCount         uint64 // the number of times this object was hit
+	Invalidated      bool   // whether this object was soft-purged, and must be revalidated before it's reused
+	// Chunked is whether this object's body is stored in chunks by an icache.ChunkCache. Chunked objects are returned with a nil Body, which must be read with the cache's NewChunkReader, so the whole body is never held in memory.
+	Chunked bool
+	// Vary is the request header names the origin varies on, if this object is a variant index rather than a response. Responses with a Vary header are stored under their variant key, and a variant index under the cache key, to know which request headers select the variant.
+	Vary []string
+}
+
+// NewVaryIndex creates a variant index object, for responses varying on the given request header names.
+func NewVaryIndex(vary []string) *CacheObj {
+	return &CacheObj{Vary: vary, ReqTime: time.Now(), HitCount: 1}
+}
+
+// IsVaryIndex returns whether this object is a variant index, rather than a response.
+func (c CacheObj) IsVaryIndex() bool {
+	return len(c.Vary) > 0
 }
 
 // ComputeSize computes the size of the given CacheObj. This computation is expensive, as the headers must be iterated over. Thus, the size should be computed once and stored, not computed on-the-fly for every new request for the cached object.
@@ -61,6 +61,9 @@ type Config struct {
 	CacheFiles           map[string][]CacheFile `json:"cache_files"`
 	// FileMemBytes is the amount of memory to use as an LRU in front of each name in CacheFiles, that is, each named group of files. E.g. if there are 10 files, the amount of memory used will be 10*FileMemBytes+CacheSizeBytes.
 	FileMemBytes int `json:"file_mem_bytes"`
+
+	// FileMemMaxObjectBytes is the size of the largest object to store in the memory LRU in front of each name in CacheFiles. Larger objects are only stored on disk, and when streamed, are written to disk in chunks as they arrive, and never held entirely in memory. If 0, the default, all objects are stored in memory.
+	FileMemMaxObjectBytes int `json:"file_mem_max_object_bytes"`
 }
 
 type CacheFile struct {
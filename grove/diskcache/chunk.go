@@ -0,0 +1,281 @@
+package diskcache
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
+	"sync/atomic"
+
+	"github.com/apache/trafficcontrol/grove/cacheobj"
+	"github.com/apache/trafficcontrol/grove/icache"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+
+	bolt "github.com/coreos/bbolt"
+)
+
+// ChunkBucketName is the bucket which stores the bodies of objects written with a ChunkWriter.
+//
+// For each such object, the key followed by a null byte stores the committed generation, and the key, null byte, generation, and chunk index store each chunk. Generations allow a new body to be written while the old one is still being served, and an aborted or failed writer to never corrupt a committed body.
+const ChunkBucketName = "c"
+
+// ChunkSize is the size in bytes of each chunk stored by a ChunkWriter. Only one chunk per object is held in memory while writing.
+const ChunkSize = 1024 * 1024
+
+const chunkGenLen = 8
+const chunkIdxLen = 8
+
+// ChunkWriter writes an object's body to a DiskCache in chunks of ChunkSize, as it arrives. It implements icache.ChunkWriter.
+type ChunkWriter struct {
+	c      *DiskCache
+	key    string
+	gen    []byte
+	prefix []byte // the prefix of all of this writer's chunk keys
+	buf    []byte
+	chunks uint64
+	size   uint64
+}
+
+// NewChunkWriter returns a writer to store the object with the given key in chunks. The object isn't visible to Get until the writer is committed, and any existing object is served until then.
+func (c *DiskCache) NewChunkWriter(key string) (icache.ChunkWriter, error) {
+	gen := make([]byte, chunkGenLen)
+	binary.BigEndian.PutUint64(gen, atomic.AddUint64(&c.chunkGen, 1))
+	prefix := append(chunkGenKey(key), gen...)
+	return &ChunkWriter{c: c, key: key, gen: gen, prefix: prefix}, nil
+}
+
+// ChunkThreshold returns 0, because the DiskCache holds no objects in memory, and may store any object in chunks.
+func (c *DiskCache) ChunkThreshold() uint64 { return 0 }
+
+func (w *ChunkWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= ChunkSize {
+		if err := w.putChunk(w.buf[:ChunkSize]); err != nil {
+			return 0, err
+		}
+		w.buf = append([]byte(nil), w.buf[ChunkSize:]...) // copy, so the written chunk isn't retained
+	}
+	return len(p), nil
+}
+
+func (w *ChunkWriter) putChunk(chunk []byte) error {
+	key := make([]byte, len(w.prefix)+chunkIdxLen)
+	copy(key, w.prefix)
+	binary.BigEndian.PutUint64(key[len(w.prefix):], w.chunks)
+	err := w.c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(ChunkBucketName))
+		if b == nil {
+			return errors.New("chunk bucket does not exist")
+		}
+		return b.Put(key, chunk)
+	})
+	if err != nil {
+		return errors.New("writing chunk: " + err.Error())
+	}
+	w.chunks++
+	w.size += uint64(len(chunk))
+	return nil
+}
+
+// Commit writes any remaining buffered bytes, and stores the object record, making the object visible. If the object was removed while being written, e.g. by a purge or Add, the object is not stored and an error is returned.
+func (w *ChunkWriter) Commit(obj *cacheobj.CacheObj) error {
+	if len(w.buf) > 0 {
+		if err := w.putChunk(w.buf); err != nil {
+			w.Abort()
+			return err
+		}
+		w.buf = nil
+	}
+
+	val := *obj
+	val.Body = nil
+	val.Size = w.size
+	buf := bytes.Buffer{}
+	if err := gob.NewEncoder(&buf).Encode(val); err != nil {
+		w.Abort()
+		return errors.New("encoding cache object: " + err.Error())
+	}
+	valBytes := buf.Bytes()
+
+	err := w.c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketName))
+		cb := tx.Bucket([]byte(ChunkBucketName))
+		if b == nil || cb == nil {
+			return errors.New("bucket does not exist")
+		}
+		if chunks := len(prefixKeys(cb, w.prefix)); uint64(chunks) != w.chunks {
+			return errors.New("object was removed while being written")
+		}
+		genKey := chunkGenKey(w.key)
+		if oldGen := cb.Get(genKey); oldGen != nil && !bytes.Equal(oldGen, w.gen) {
+			if err := deletePrefix(cb, append(genKey, oldGen...)); err != nil {
+				return errors.New("deleting old chunks: " + err.Error())
+			}
+		}
+		if err := cb.Put(genKey, w.gen); err != nil {
+			return errors.New("writing generation: " + err.Error())
+		}
+		return b.Put([]byte(w.key), valBytes)
+	})
+	if err != nil {
+		w.Abort()
+		return errors.New("committing '" + w.key + "': " + err.Error())
+	}
+
+	size := uint64(len(valBytes)) + w.size
+	oldSize := w.c.lru.Add(w.key, size)
+	newSizeBytes := atomic.AddUint64(&w.c.sizeBytes, size-oldSize) // unsigned overflow subtracts if the old object was larger
+	if newSizeBytes > w.c.maxSizeBytes {
+		go w.c.gc(newSizeBytes)
+	}
+	log.Debugf("DiskCache ChunkWriter Commit SUCCESS key '%+v' chunks '%+v' size '%+v'\n", w.key, w.chunks, size)
+	return nil
+}
+
+// Abort deletes all chunks written.
+func (w *ChunkWriter) Abort() {
+	w.buf = nil
+	err := w.c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(ChunkBucketName))
+		if b == nil {
+			return errors.New("chunk bucket does not exist")
+		}
+		return deletePrefix(b, w.prefix)
+	})
+	if err != nil {
+		log.Errorln("DiskCache ChunkWriter aborting '" + w.key + "': " + err.Error())
+	}
+}
+
+// chunkGenKey returns the key of the committed generation of the given object key. It is also the prefix of all chunks of the object.
+func chunkGenKey(key string) []byte {
+	return append([]byte(key), 0)
+}
+
+// chunkObjKey returns the object key of the given chunk key, and whether it is a chunk key. Generation keys return false.
+func chunkObjKey(chunkKey []byte) (string, bool) {
+	sepPos := len(chunkKey) - chunkIdxLen - chunkGenLen - 1
+	if sepPos < 0 || chunkKey[sepPos] != 0 {
+		return "", false
+	}
+	return string(chunkKey[:sepPos]), true
+}
+
+// ChunkReader reads the committed body of an object stored in chunks. Each chunk is read in its own transaction, so a slow reader never holds a transaction open, and only one chunk is held in memory at a time.
+type ChunkReader struct {
+	c      *DiskCache
+	genKey []byte
+	gen    []byte
+	idx    uint64
+	buf    []byte
+}
+
+// NewChunkReader returns a reader of the committed body of the given key, and whether the key's body is stored in chunks.
+func (c *DiskCache) NewChunkReader(key string) (io.Reader, bool) {
+	genKey := chunkGenKey(key)
+	gen := []byte(nil)
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(ChunkBucketName))
+		if b == nil {
+			return errors.New("chunk bucket does not exist")
+		}
+		if v := b.Get(genKey); v != nil {
+			gen = append([]byte(nil), v...) // must copy, bolt values are only valid for the life of the transaction
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorln("DiskCache.NewChunkReader getting '" + key + "': " + err.Error())
+		return nil, false
+	}
+	if gen == nil {
+		return nil, false
+	}
+	return &ChunkReader{c: c, genKey: genKey, gen: gen}, true
+}
+
+func (r *ChunkReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// next reads the next chunk into buf. Returns io.EOF if there are no more chunks, or an error if the object's committed generation changed since the reader was created.
+func (r *ChunkReader) next() error {
+	key := make([]byte, len(r.genKey)+chunkGenLen+chunkIdxLen)
+	copy(key, r.genKey)
+	copy(key[len(r.genKey):], r.gen)
+	binary.BigEndian.PutUint64(key[len(r.genKey)+chunkGenLen:], r.idx)
+	return r.c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(ChunkBucketName))
+		if b == nil {
+			return errors.New("chunk bucket does not exist")
+		}
+		if gen := b.Get(r.genKey); !bytes.Equal(gen, r.gen) {
+			return errors.New("object was replaced or removed while being read")
+		}
+		chunk := b.Get(key)
+		if chunk == nil {
+			return io.EOF
+		}
+		r.buf = append(r.buf[:0], chunk...) // must copy, bolt values are only valid for the life of the transaction
+		r.idx++
+		return nil
+	})
+}
+
+// isChunked returns whether the given key's body is stored in chunks.
+func isChunked(tx *bolt.Tx, key string) bool {
+	b := tx.Bucket([]byte(ChunkBucketName))
+	return b != nil && b.Get(chunkGenKey(key)) != nil
+}
+
+// deleteChunks deletes all chunks of the given key, both committed and being written.
+func deleteChunks(tx *bolt.Tx, key string) error {
+	b := tx.Bucket([]byte(ChunkBucketName))
+	if b == nil {
+		return errors.New("chunk bucket does not exist")
+	}
+	return deletePrefix(b, chunkGenKey(key))
+}
+
+// prefixKeys returns all keys in the bucket starting with the given prefix.
+func prefixKeys(b *bolt.Bucket, prefix []byte) [][]byte {
+	keys := [][]byte{}
+	cursor := b.Cursor()
+	for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	return keys
+}
+
+// deletePrefix deletes all keys in the bucket starting with the given prefix. Keys are collected before deleting, because deleting while iterating a bolt cursor skips keys.
+func deletePrefix(b *bolt.Bucket, prefix []byte) error {
+	for _, k := range prefixKeys(b, prefix) {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package diskcache
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/grove/cacheobj"
+)
+
+func TestChunkWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grove-diskcache-test")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := New(filepath.Join(dir, "cache.db"), 100*ChunkSize)
+	if err != nil {
+		t.Fatalf("creating disk cache: %v", err)
+	}
+	defer c.Close()
+
+	key := "GET:http://example.net/big"
+	body := bytes.Repeat([]byte("0123456789"), ChunkSize/4) // 2.5 chunks
+	obj := cacheobj.New(nil, nil, http.StatusOK, http.StatusOK, "", http.Header{}, time.Now(), time.Now(), time.Now(), time.Now())
+
+	w, err := c.NewChunkWriter(key)
+	if err != nil {
+		t.Fatalf("NewChunkWriter error expected nil, actual %v", err)
+	}
+	for i := 0; i < len(body); i += 1000 {
+		end := i + 1000
+		if end > len(body) {
+			end = len(body)
+		}
+		w.Write(body[i:end])
+	}
+	if _, ok := c.Peek(key); ok {
+		t.Errorf("Peek before Commit expected not found, actual found")
+	}
+	if err := w.Commit(obj); err != nil {
+		t.Fatalf("Commit error expected nil, actual %v", err)
+	}
+
+	got, ok := c.Peek(key)
+	if !ok {
+		t.Fatalf("Peek after Commit expected found, actual not found")
+	}
+	if !got.Chunked || got.Body != nil {
+		t.Errorf("Peek expected chunked with nil body, actual chunked %v body len %v", got.Chunked, len(got.Body))
+	}
+	if got := readChunked(t, c, key); !bytes.Equal(got, body) {
+		t.Errorf("NewChunkReader body expected len %v, actual len %v", len(body), len(got))
+	}
+	if got.Size != uint64(len(body)) {
+		t.Errorf("Peek size expected %v, actual %v", len(body), got.Size)
+	}
+	if c.Size() <= uint64(len(body)) {
+		t.Errorf("cache size expected > %v, actual %v", len(body), c.Size())
+	}
+
+	// a writer whose object is removed while writing must not commit
+	w, _ = c.NewChunkWriter(key)
+	w.Write(body)
+	c.Remove(key)
+	if err := w.Commit(obj); err == nil {
+		t.Errorf("Commit after Remove expected error, actual nil")
+	}
+	if _, ok := c.Peek(key); ok {
+		t.Errorf("Peek after Remove expected not found, actual found")
+	}
+
+	// adding a whole object must replace a chunked one
+	w, _ = c.NewChunkWriter(key)
+	w.Write(body)
+	w.Commit(obj)
+	small := cacheobj.New(nil, []byte("small"), http.StatusOK, http.StatusOK, "", http.Header{}, time.Now(), time.Now(), time.Now(), time.Now())
+	c.Add(key, small)
+	if got, ok := c.Peek(key); !ok || string(got.Body) != "small" || got.Chunked {
+		t.Errorf("Peek after Add expected body 'small', actual %v %v", ok, len(got.Body))
+	}
+	if _, ok := c.NewChunkReader(key); ok {
+		t.Errorf("NewChunkReader after Add expected not chunked, actual chunked")
+	}
+
+	c.Remove(key)
+	if c.Size() != 0 {
+		t.Errorf("cache size after Remove expected 0, actual %v", c.Size())
+	}
+}
+
+func readChunked(t *testing.T, c *DiskCache, key string) []byte {
+	r, ok := c.NewChunkReader(key)
+	if !ok {
+		t.Fatalf("NewChunkReader '%v' expected chunked, actual not chunked", key)
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading chunks of '%v' error expected nil, actual %v", key, err)
+	}
+	return body
+}
+
+func TestChunkReaderReplaced(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grove-diskcache-test")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := New(filepath.Join(dir, "cache.db"), 100*ChunkSize)
+	if err != nil {
+		t.Fatalf("creating disk cache: %v", err)
+	}
+	defer c.Close()
+
+	key := "GET:http://example.net/big"
+	body := bytes.Repeat([]byte("0123456789"), ChunkSize/4) // 2.5 chunks
+	obj := cacheobj.New(nil, nil, http.StatusOK, http.StatusOK, "", http.Header{}, time.Now(), time.Now(), time.Now(), time.Now())
+	w, _ := c.NewChunkWriter(key)
+	w.Write(body)
+	if err := w.Commit(obj); err != nil {
+		t.Fatalf("Commit error expected nil, actual %v", err)
+	}
+
+	// a reader must not mix the chunks of two bodies
+	r, ok := c.NewChunkReader(key)
+	if !ok {
+		t.Fatalf("NewChunkReader expected chunked, actual not chunked")
+	}
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("Read error expected nil, actual %v", err)
+	}
+	w, _ = c.NewChunkWriter(key)
+	w.Write(body)
+	w.Commit(obj)
+	if _, err := ioutil.ReadAll(r); err == nil {
+		t.Errorf("reading replaced object error expected not nil, actual nil")
+	}
+
+	// re-adding the record of a chunked object, e.g. after revalidation, must keep its chunks
+	got, _ := c.Peek(key)
+	got.RespHeaders = http.Header{"Date": {"revalidated"}}
+	c.Add(key, got)
+	if got, ok := c.Peek(key); !ok || !got.Chunked || got.RespHeaders.Get("Date") != "revalidated" {
+		t.Errorf("Peek after re-adding chunked record expected chunked revalidated, actual found %v", ok)
+	}
+	if got := readChunked(t, c, key); !bytes.Equal(got, body) {
+		t.Errorf("body after re-adding chunked record expected len %v, actual len %v", len(body), len(got))
+	}
+
+	// re-adding the record of a chunked object which was removed must not resurrect it
+	c.Remove(key)
+	c.Add(key, got)
+	if _, ok := c.Peek(key); ok {
+		t.Errorf("Peek after re-adding removed chunked record expected not found, actual found")
+	}
+}
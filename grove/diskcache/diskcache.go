@@ -34,6 +34,7 @@ type DiskCache struct {
 	sizeBytes    uint64
 	maxSizeBytes uint64
 	lru          *lru.LRU
+	chunkGen     uint64 // Atomic - DO NOT access or modify without atomic operations
 }
 
 const BucketName = "b"
@@ -48,13 +49,17 @@ func New(path string, cacheSizeBytes uint64) (*DiskCache, error) {
 		if _, err := tx.CreateBucketIfNotExists([]byte(BucketName)); err != nil {
 			return errors.New("creating bucket: " + err.Error())
 		}
+		if _, err := tx.CreateBucketIfNotExists([]byte(ChunkBucketName)); err != nil {
+			return errors.New("creating chunk bucket: " + err.Error())
+		}
 		return nil
 	})
 	if err != nil {
 		return nil, errors.New("creating bucket for database '" + path + "': " + err.Error())
 	}
 
-	return &DiskCache{db: db, maxSizeBytes: cacheSizeBytes, lru: lru.NewLRU(), sizeBytes: 0}, nil
+	// chunk generations start at the current time, so writers after a restart never collide with orphaned chunks from before it.
+	return &DiskCache{db: db, maxSizeBytes: cacheSizeBytes, lru: lru.NewLRU(), sizeBytes: 0, chunkGen: uint64(time.Now().UnixNano())}, nil
 }
 
 // ResetAfterRestart rebuilds the LRU with an arbirtrary order and sets sizeBytes. This seems crazy, but it is better than doing nothing, sice gc is based on the LRU and sizeBytes. In the future, we may want to periodically sync the LRU to disk, but we'll still need to iterate over all keys in the disk DB to avoid orphaning objects.
@@ -72,6 +77,15 @@ func (c *DiskCache) ResetAfterRestart() {
 			size += len(v)
 		}
 
+		// TODO delete orphaned chunks, from writers which never committed or aborted before a restart
+		chunkCursor := tx.Bucket([]byte(ChunkBucketName)).Cursor()
+		for k, v := chunkCursor.First(); k != nil; k, v = chunkCursor.Next() {
+			if key, ok := chunkObjKey(k); ok {
+				c.lru.AddSize(key, int64(len(v)))
+			}
+			size += len(v)
+		}
+
 		atomic.AddUint64(&c.sizeBytes, uint64(size))
 		log.Infof("Cache recovery from disk for %s done (%d bytes). ", c.db.Path(), c.sizeBytes)
 		return nil
@@ -86,6 +100,10 @@ func (c *DiskCache) ResetAfterRestart() {
 func (c *DiskCache) Add(key string, val *cacheobj.CacheObj) bool {
 	log.Debugf("DiskCache Add CALLED key '%+v' size '%+v'\n", key, val.Size)
 	eviction := false
+	if val.Chunked {
+		c.updateRecord(key, val) // e.g. a revalidated object, whose body is the existing chunks
+		return eviction
+	}
 
 	buf := bytes.Buffer{}
 	if err := gob.NewEncoder(&buf).Encode(val); err != nil {
@@ -99,6 +117,9 @@ func (c *DiskCache) Add(key string, val *cacheobj.CacheObj) bool {
 		if b == nil {
 			return errors.New("bucket does not exist")
 		}
+		if err := deleteChunks(tx, key); err != nil {
+			return err // the old object was stored in chunks, which must not be mistaken for the new object's body
+		}
 		return b.Put([]byte(key), valBytes)
 	})
 	if err != nil {
@@ -106,9 +127,9 @@ func (c *DiskCache) Add(key string, val *cacheobj.CacheObj) bool {
 		return eviction
 	}
 
-	c.lru.Add(key, uint64(len(valBytes)))
+	oldSizeBytes := c.lru.Add(key, uint64(len(valBytes)))
 
-	newSizeBytes := atomic.AddUint64(&c.sizeBytes, uint64(len(valBytes)))
+	newSizeBytes := atomic.AddUint64(&c.sizeBytes, uint64(len(valBytes))-oldSizeBytes) // unsigned overflow subtracts if the old object was larger, e.g. if it was stored in chunks
 	if newSizeBytes > c.maxSizeBytes {
 		go c.gc(newSizeBytes)
 	}
@@ -137,7 +158,9 @@ func (c *DiskCache) gc(cacheSizeBytes uint64) {
 				return errors.New("bucket does not exist")
 			}
 			b.Delete([]byte(key))
-
+			if err := deleteChunks(tx, key); err != nil {
+				return err
+			}
 			return b.Delete([]byte(key))
 		})
 		if err != nil {
@@ -161,17 +184,22 @@ func (c *DiskCache) Get(key string) (*cacheobj.CacheObj, bool) {
 
 }
 
-// Peek takes a key, and returns its value, and whether it was found, without changing the lru-ness or hitcount
+// Peek takes a key, and returns its value, and whether it was found, without changing the lru-ness or hitcount.
+// The body of objects stored in chunks is not read: they're returned Chunked, with a nil Body, which must be read with NewChunkReader.
 func (c *DiskCache) Peek(key string) (*cacheobj.CacheObj, bool) {
 	log.Debugln("DiskCache.Get key '" + key + "'")
 	valBytes := []byte(nil)
+	chunked := false
 
 	err := c.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(BucketName))
 		if b == nil {
 			return errors.New("bucket does not exist")
 		}
-		valBytes = b.Get([]byte(key))
+		if v := b.Get([]byte(key)); v != nil {
+			valBytes = append([]byte(nil), v...) // must copy, bolt values are only valid for the life of the transaction
+		}
+		chunked = isChunked(tx, key)
 		return nil
 	})
 	if err != nil {
@@ -190,11 +218,85 @@ func (c *DiskCache) Peek(key string) (*cacheobj.CacheObj, bool) {
 		log.Errorln("DiskCache.Peek decoding '" + key + "' from cache: " + err.Error())
 		return nil, false
 	}
+	val.Chunked = chunked
 
 	log.Debugln("DiskCache.Peek key '" + key + "' CACHE HIT")
 	return &val, true
 }
 
+// Remove removes the key from the cache. Returns whether the key existed.
+func (c *DiskCache) Remove(key string) bool {
+	existed := false
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketName))
+		if b == nil {
+			return errors.New("bucket does not exist")
+		}
+		existed = b.Get([]byte(key)) != nil
+		if err := deleteChunks(tx, key); err != nil {
+			return err
+		}
+		return b.Delete([]byte(key))
+	})
+	if err != nil {
+		log.Errorln("DiskCache.Remove removing '" + key + "' from cache: " + err.Error())
+		return false
+	}
+	if sizeBytes, inLRU := c.lru.Remove(key); inLRU {
+		atomic.AddUint64(&c.sizeBytes, ^uint64(sizeBytes-1)) // subtract sizeBytes
+	}
+	return existed
+}
+
+// Invalidate marks the object of the key as invalidated, so it must be revalidated before it's reused. Returns whether the key existed.
+// The body of objects stored in chunks is left as-is, and only the object record is rewritten.
+func (c *DiskCache) Invalidate(key string) bool {
+	val, found := c.Peek(key)
+	if !found {
+		return false
+	}
+	val.Invalidated = true
+	return c.updateRecord(key, val)
+}
+
+// updateRecord rewrites the record of the existing object of the key, without changing the body of objects stored in chunks. If the object was removed, or if val is Chunked and the object's chunks were removed, it isn't resurrected. Returns whether the record was rewritten.
+func (c *DiskCache) updateRecord(key string, val *cacheobj.CacheObj) bool {
+	buf := bytes.Buffer{}
+	if err := gob.NewEncoder(&buf).Encode(val); err != nil {
+		log.Errorln("DiskCache.updateRecord encoding cache object: " + err.Error())
+		return false
+	}
+	valBytes := buf.Bytes()
+
+	oldLen := -1
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketName))
+		if b == nil {
+			return errors.New("bucket does not exist")
+		}
+		oldBytes := b.Get([]byte(key))
+		if oldBytes == nil || (val.Chunked && !isChunked(tx, key)) {
+			return nil // removed since it was read; don't resurrect it
+		}
+		oldLen = len(oldBytes)
+		return b.Put([]byte(key), valBytes)
+	})
+	if err != nil {
+		log.Errorln("DiskCache.updateRecord updating '" + key + "' in database: " + err.Error())
+		return false
+	}
+	if oldLen < 0 {
+		return false
+	}
+
+	// only the record changed, so only its size difference applies, e.g. chunks aren't changed.
+	sizeChange := int64(len(valBytes)) - int64(oldLen)
+	if _, ok := c.lru.AddSize(key, sizeChange); ok {
+		atomic.AddUint64(&c.sizeBytes, uint64(sizeChange)) // unsigned overflow subtracts if the new record is smaller
+	}
+	return true
+}
+
 func (c *DiskCache) Size() uint64 {
 	return atomic.LoadUint64(&c.sizeBytes)
 }
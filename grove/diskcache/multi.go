@@ -16,9 +16,11 @@ package diskcache
 
 import (
 	"errors"
+	"io"
 
 	"github.com/apache/trafficcontrol/grove/cacheobj"
 	"github.com/apache/trafficcontrol/grove/config"
+	"github.com/apache/trafficcontrol/grove/icache"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 
@@ -66,6 +68,32 @@ func (c *MultiDiskCache) Peek(key string) (*cacheobj.CacheObj, bool) {
 	return (*c)[i].Peek(key)
 }
 
+func (c *MultiDiskCache) Remove(key string) bool {
+	i := c.keyIdx(key)
+	log.Debugf("MultiDiskCache.Remove key '%+v' mapped to %+v\n", key, i)
+	return (*c)[i].Remove(key)
+}
+
+func (c *MultiDiskCache) Invalidate(key string) bool {
+	i := c.keyIdx(key)
+	log.Debugf("MultiDiskCache.Invalidate key '%+v' mapped to %+v\n", key, i)
+	return (*c)[i].Invalidate(key)
+}
+
+func (c *MultiDiskCache) NewChunkWriter(key string) (icache.ChunkWriter, error) {
+	i := c.keyIdx(key)
+	log.Debugf("MultiDiskCache.NewChunkWriter key '%+v' mapped to %+v\n", key, i)
+	return (*c)[i].NewChunkWriter(key)
+}
+
+func (c *MultiDiskCache) ChunkThreshold() uint64 { return 0 }
+
+func (c *MultiDiskCache) NewChunkReader(key string) (io.Reader, bool) {
+	i := c.keyIdx(key)
+	log.Debugf("MultiDiskCache.NewChunkReader key '%+v' mapped to %+v\n", key, i)
+	return (*c)[i].NewChunkReader(key)
+}
+
 func (c *MultiDiskCache) Size() uint64 {
 	sum := uint64(0)
 	for _, cache := range *c {
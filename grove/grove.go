@@ -81,7 +81,7 @@ func main() {
 	}
 	log.Init(eventW, errW, warnW, infoW, debugW)
 
-	caches, err := createCaches(cfg.CacheFiles, uint64(cfg.FileMemBytes), uint64(cfg.CacheSizeBytes))
+	caches, err := createCaches(cfg.CacheFiles, uint64(cfg.FileMemBytes), uint64(cfg.FileMemMaxObjectBytes), uint64(cfg.CacheSizeBytes))
 	if err != nil {
 		log.Errorln("starting service: creating caches: " + err.Error())
 		os.Exit(1)
@@ -380,8 +380,8 @@ func loadCerts(rules []remapdata.RemapRule) ([]tls.Certificate, error) {
 	return certs, nil
 }
 
-// createCaches creates the caches specified in the config. The nameFiles is the map of names to groups of files, nameMemBytes is the amount of memory to use for each named group, and memCacheBytes is the amount of memory to use for the default memory cache.
-func createCaches(nameFiles map[string][]config.CacheFile, nameMemBytes uint64, memCacheBytes uint64) (map[string]icache.Cache, error) {
+// createCaches creates the caches specified in the config. The nameFiles is the map of names to groups of files, nameMemBytes is the amount of memory to use for each named group, nameMemMaxObjBytes is the largest object to store in each named group's memory, and memCacheBytes is the amount of memory to use for the default memory cache.
+func createCaches(nameFiles map[string][]config.CacheFile, nameMemBytes uint64, nameMemMaxObjBytes uint64, memCacheBytes uint64) (map[string]icache.Cache, error) {
 	caches := map[string]icache.Cache{}
 	caches[""] = memcache.New(memCacheBytes) // default empty names to the mem cache
 
@@ -390,7 +390,7 @@ func createCaches(nameFiles map[string][]config.CacheFile, nameMemBytes uint64,
 		if err != nil {
 			return nil, errors.New("creating cache '" + name + "': " + err.Error())
 		}
-		caches[name] = tiercache.New(memcache.New(nameMemBytes), multiDiskCache)
+		caches[name] = tiercache.New(memcache.New(nameMemBytes), multiDiskCache, nameMemMaxObjBytes)
 	}
 
 	return caches, nil
@@ -398,6 +398,7 @@ func createCaches(nameFiles map[string][]config.CacheFile, nameMemBytes uint64,
 
 func cachesChanged(oldCfg, newCfg config.Config) bool {
 	return oldCfg.FileMemBytes == newCfg.FileMemBytes &&
+		oldCfg.FileMemMaxObjectBytes == newCfg.FileMemMaxObjectBytes &&
 		oldCfg.CacheSizeBytes != newCfg.CacheSizeBytes &&
 		!reflect.DeepEqual(oldCfg.CacheFiles, newCfg.CacheFiles)
 }
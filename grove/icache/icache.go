@@ -15,6 +15,8 @@ package icache
 */
 
 import (
+	"io"
+
 	"github.com/apache/trafficcontrol/grove/cacheobj"
 )
 
@@ -28,4 +30,28 @@ type Cache interface {
 	Keys() []string
 	Size() uint64
 	Close()
+	// Remove removes the key from the cache. Returns whether the key existed.
+	Remove(key string) bool
+	// Invalidate marks the object of the key as invalidated, so it must be revalidated with the origin before it's reused. Returns whether the key existed.
+	Invalidate(key string) bool
+}
+
+// ChunkCache is a Cache which can store an object's body as it arrives, in chunks, without ever holding the whole body in memory.
+type ChunkCache interface {
+	Cache
+	// NewChunkWriter returns a writer to store the object with the given key. The object isn't visible until the writer is committed.
+	NewChunkWriter(key string) (ChunkWriter, error)
+	// ChunkThreshold returns the object size in bytes above which objects should be written with a ChunkWriter, rather than held in memory and Added. If 0, all objects may be written in chunks.
+	ChunkThreshold() uint64
+	// NewChunkReader returns a reader of the body of the object with the given key, which must be Chunked, and whether the key's body is stored in chunks. The reader returns an error if the object is replaced or removed while being read.
+	NewChunkReader(key string) (io.Reader, bool)
+}
+
+// ChunkWriter writes an object's body to a ChunkCache as it arrives. Exactly one of Commit or Abort must be called when the body is finished.
+type ChunkWriter interface {
+	io.Writer
+	// Commit stores the given object, with the bytes written as its body. The Body of the given obj is ignored.
+	Commit(obj *cacheobj.CacheObj) error
+	// Abort discards the bytes written.
+	Abort()
 }
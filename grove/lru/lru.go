@@ -63,6 +63,31 @@ func (c *LRU) RemoveOldest() (string, uint64, bool) {
 	return obj.key, obj.size, true
 }
 
+// Remove removes the key from the LRU. Returns the size of the removed key, and whether it existed.
+func (c *LRU) Remove(key string) (uint64, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	elem, ok := c.lElems[key]
+	if !ok {
+		return 0, false
+	}
+	c.l.Remove(elem)
+	delete(c.lElems, key)
+	return elem.Value.(*listObj).size, true
+}
+
+// AddSize adds the given bytes, which may be negative, to the size of the key, without changing its recently-used-ness. Returns the new size, and whether the key existed. If the key doesn't exist, it is not added.
+func (c *LRU) AddSize(key string, bytes int64) (uint64, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	elem, ok := c.lElems[key]
+	if !ok {
+		return 0, false
+	}
+	elem.Value.(*listObj).size += uint64(bytes) // unsigned overflow subtracts negative bytes
+	return elem.Value.(*listObj).size, true
+}
+
 // Keys returns a string array of the keys
 func (c *LRU) Keys() []string {
 	c.m.RLock()
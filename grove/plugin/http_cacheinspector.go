@@ -121,6 +121,10 @@ func cacheinspect(icfg interface{}, d OnRequestData) bool {
 			w.Write([]byte(fmt.Sprintf("  RespRespTime:                 %v\n", cacheObject.RespRespTime)))
 			w.Write([]byte(fmt.Sprintf("  LastModified:                 %v\n", cacheObject.LastModified)))
 			w.Write([]byte(fmt.Sprintf("  HitCount:                     %v\n", cacheObject.HitCount)))
+			if cacheObject.IsVaryIndex() {
+				w.Write([]byte(fmt.Sprintf("  Vary:                         %s\n", strings.Join(cacheObject.Vary, ","))))
+				w.Write([]byte(fmt.Sprintf("  Variants:                     %d\n", variantCounts(d.Stats.CacheKeys(cacheToDisplay))[keyArr[0]])))
+			}
 		} else {
 			w.Write([]byte("Not Found"))
 		}
@@ -140,11 +144,17 @@ func cacheinspect(icfg interface{}, d OnRequestData) bool {
 			w.Write([]byte(fmt.Sprintf("<a name=%s></a>", cName)))
 			w.Write([]byte(fmt.Sprintf("\n\n<b>*** Cache \"%s\" ***</b>\n", cName)))
 			keys := d.Stats.CacheKeys(cName)
+			variants := variantCounts(keys)
+			numVariants := 0
+			for _, count := range variants {
+				numVariants += count
+			}
 			size, _ := d.Stats.CacheSizeByName(cName)
 			capacity, _ := d.Stats.CacheCapacityByName(cName)
 			w.Write([]byte(fmt.Sprintf("\n  * Size of in use cache:      %s \n", bytefmt.ByteSize(size))))
 			w.Write([]byte(fmt.Sprintf("  * Cache capacity:            %s \n", bytefmt.ByteSize(capacity))))
 			w.Write([]byte(fmt.Sprintf("  * Number of elements in LRU: %d\n", len(keys))))
+			w.Write([]byte(fmt.Sprintf("  * Number of Vary variants:   %d\n", numVariants)))
 			// tail is how much from the top of the LRU to display, top of the LRU is most recently used. head is the other side.
 			head := 100
 			tail := 100
@@ -171,7 +181,7 @@ func cacheinspect(icfg interface{}, d OnRequestData) bool {
 				w.Write([]byte(fmt.Sprintf("showing only first %d and last %d:\n\n", head, tail)))
 			}
 
-			w.Write([]byte(fmt.Sprintf("<b>            #    Code      Size                   Age              FreshFor    HitCount      Key</b>\n")))
+			w.Write([]byte(fmt.Sprintf("<b>            #    Code      Size                   Age              FreshFor    HitCount  Variants      Key</b>\n")))
 			for i, key := range keys {
 				if (doSearch && !strings.Contains(key, searchArr[0])) || !doSearch && (i >= tail && i < len(keys)-head) {
 					continue
@@ -180,8 +190,8 @@ func cacheinspect(icfg interface{}, d OnRequestData) bool {
 				cacheObject, _ := d.Stats.CachePeek(key, cName)
 				age := time.Now().Sub(cacheObject.ReqRespTime)
 				freshFor := rfc.FreshFor(cacheObject.RespHeaders, cacheObject.RespCacheControl, cacheObject.ReqRespTime, cacheObject.RespRespTime)
-				w.Write([]byte(fmt.Sprintf("     %8d%8d%10s%22v%22v%12d      <a href=\"http://%s%s?key=%s&cache=%s\">%s</a>\n",
-					i, cacheObject.Code, bytefmt.ByteSize(cacheObject.Size), age, freshFor, cacheObject.HitCount, req.Host, CacheStatsEndpoint, url.QueryEscape(key), cName, key)))
+				w.Write([]byte(fmt.Sprintf("     %8d%8d%10s%22v%22v%12d%10d      <a href=\"http://%s%s?key=%s&cache=%s\">%s</a>\n",
+					i, cacheObject.Code, bytefmt.ByteSize(cacheObject.Size), age, freshFor, cacheObject.HitCount, variants[key], req.Host, CacheStatsEndpoint, url.QueryEscape(key), cName, key)))
 			}
 
 		}
@@ -195,3 +205,14 @@ func cacheinspect(icfg interface{}, d OnRequestData) bool {
 
 	return true
 }
+
+// variantCounts returns the number of Vary variants of each key with variants in the given keys.
+func variantCounts(keys []string) map[string]int {
+	counts := map[string]int{}
+	for _, key := range keys {
+		if baseKey, ok := rfc.VariantBaseKey(key); ok {
+			counts[baseKey]++
+		}
+	}
+	return counts
+}
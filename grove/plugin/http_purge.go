@@ -0,0 +1,235 @@
+package plugin
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/grove/icache"
+	"github.com/apache/trafficcontrol/grove/remapdata"
+	"github.com/apache/trafficcontrol/grove/rfc"
+	"github.com/apache/trafficcontrol/grove/web"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+)
+
+func init() {
+	AddPlugin(10000, Funcs{load: purgeLoad, onRequest: purge})
+}
+
+// PurgeEndpoint is our reserved path
+const PurgeEndpoint = "/_purge"
+
+// MethodPurge is the non-standard method conventionally used by caches for purge requests. POST is also accepted.
+const MethodPurge = "PURGE"
+
+// PurgeConfig is the global config of the http_purge plugin. If Token is not empty, requests must include the header `Authorization: Bearer <token>`.
+type PurgeConfig struct {
+	Token string `json:"token"`
+}
+
+// PurgeResp is the response body of a successful purge request.
+type PurgeResp struct {
+	Purged int  `json:"purged"`
+	Soft   bool `json:"soft"`
+}
+
+func purgeLoad(b json.RawMessage) interface{} {
+	cfg := PurgeConfig{}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		log.Errorln("http_purge loading config, unmarshalling JSON: " + err.Error())
+		return nil
+	}
+	log.Debugf("http_purge: load success\n")
+	return &cfg
+}
+
+func purge(icfg interface{}, d OnRequestData) bool {
+	if !strings.HasPrefix(d.R.URL.Path, PurgeEndpoint) {
+		log.Debugln("plugin onrequest http_purge returning, not in path '" + d.R.URL.Path + "'")
+		return false
+	}
+
+	log.Debugf("plugin onrequest http_purge calling\n")
+
+	reqTime := time.Now()
+	w := d.W
+	req := d.R
+
+	respCode, respBody := purgeResp(icfg, d)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(respCode)
+	w.Write(respBody)
+
+	clientIP, _ := web.GetClientIPPort(req)
+	now := time.Now()
+	log.EventRaw(atsEventLogStr(now, clientIP, d.Hostname, req.Host, d.Port, "-", d.Scheme, req.URL.String(), req.Method, req.Proto, respCode, now.Sub(reqTime), uint64(len(respBody)), 0, 0, true, true, getCacheHitStr(true, false), "-", "-", req.UserAgent(), req.Header.Get("X-Money-Trace"), 1))
+	return true
+}
+
+// purgeResp performs the purge request, and returns the HTTP code and body to respond with.
+func purgeResp(icfg interface{}, d OnRequestData) (int, []byte) {
+	ip, err := web.GetIP(d.R)
+	if err != nil {
+		log.Errorln("http_purge failed to get IP: " + err.Error())
+		return purgeErrResp(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	if !d.StatRules.Allowed(ip) {
+		log.Debugln("http_purge IP " + ip.String() + " FORBIDDEN")
+		return purgeErrResp(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+	}
+	if cfg, ok := icfg.(*PurgeConfig); ok && cfg != nil && cfg.Token != "" {
+		token := strings.TrimPrefix(d.R.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+			log.Debugln("http_purge IP " + ip.String() + " UNAUTHORIZED")
+			return purgeErrResp(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		}
+	}
+	if d.R.Method != http.MethodPost && d.R.Method != MethodPurge {
+		return purgeErrResp(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+	}
+
+	params := d.R.URL.Query()
+	key := params.Get("key")
+	ruleName := params.Get("rule")
+	prefix, hasPrefix := params["prefix"]
+	regexStr, hasRegex := params["regex"]
+	soft := params.Get("soft") == "true"
+
+	caches := []icache.Cache{}
+	to := ""
+	if ruleName != "" {
+		rule, ok := findRule(d.Rules, ruleName)
+		if !ok {
+			return purgeErrResp(http.StatusNotFound, "rule '"+ruleName+"' not found")
+		}
+		if rule.Cache == nil || len(rule.To) == 0 {
+			return purgeErrResp(http.StatusBadRequest, "rule '"+ruleName+"' has no cache")
+		}
+		caches = append(caches, rule.Cache)
+		to = rule.To[0].URL
+	} else {
+		caches = ruleCaches(d.Rules)
+	}
+
+	matches := (func(string) bool)(nil)
+	switch {
+	case key != "":
+		if hasPrefix || hasRegex {
+			return purgeErrResp(http.StatusBadRequest, "only one of key, prefix, or regex may be given")
+		}
+		matches = func(k string) bool {
+			baseKey, _ := rfc.VariantBaseKey(k) // purging a key also purges all its variants
+			return baseKey == key
+		}
+	case hasPrefix && hasRegex:
+		return purgeErrResp(http.StatusBadRequest, "only one of key, prefix, or regex may be given")
+	case hasPrefix || hasRegex:
+		if ruleName == "" {
+			return purgeErrResp(http.StatusBadRequest, "prefix and regex purges require a rule")
+		}
+		if hasPrefix {
+			matches = func(k string) bool {
+				path, ok := keyPath(k, to)
+				return ok && strings.HasPrefix(path, prefix[0])
+			}
+			break
+		}
+		re, err := regexp.Compile(regexStr[0])
+		if err != nil {
+			return purgeErrResp(http.StatusBadRequest, "invalid regex: "+err.Error())
+		}
+		matches = func(k string) bool {
+			path, ok := keyPath(k, to)
+			return ok && re.MatchString(path)
+		}
+	default:
+		return purgeErrResp(http.StatusBadRequest, "one of key, prefix, or regex must be given")
+	}
+
+	purged := 0
+	for _, cache := range caches {
+		for _, k := range cache.Keys() {
+			if !matches(k) {
+				continue
+			}
+			if soft {
+				if cache.Invalidate(k) {
+					purged++
+				}
+			} else if cache.Remove(k) {
+				purged++
+			}
+		}
+	}
+	log.Infof("http_purge IP %v purged %v objects (key '%v' rule '%v' prefix %v regex %v soft %v)\n", ip, purged, key, ruleName, prefix, regexStr, soft)
+
+	bts, err := json.Marshal(PurgeResp{Purged: purged, Soft: soft})
+	if err != nil {
+		log.Errorln("http_purge marshalling response: " + err.Error())
+		return purgeErrResp(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	return http.StatusOK, bts
+}
+
+func purgeErrResp(code int, msg string) (int, []byte) {
+	bts, _ := json.Marshal(map[string]string{"error": msg})
+	return code, bts
+}
+
+func findRule(rules []remapdata.RemapRule, name string) (remapdata.RemapRule, bool) {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return remapdata.RemapRule{}, false
+}
+
+// ruleCaches returns the distinct caches of the given rules. Multiple rules may share the same cache.
+func ruleCaches(rules []remapdata.RemapRule) []icache.Cache {
+	seen := map[icache.Cache]struct{}{}
+	caches := []icache.Cache{}
+	for _, rule := range rules {
+		if rule.Cache == nil {
+			continue
+		}
+		if _, ok := seen[rule.Cache]; ok {
+			continue
+		}
+		seen[rule.Cache] = struct{}{}
+		caches = append(caches, rule.Cache)
+	}
+	return caches
+}
+
+// keyPath returns the part of the cache key after the method and the rule's To URL, i.e. the path and query. Variant keys return the path of the key they vary from. Returns false if the key isn't for the given To URL.
+func keyPath(key string, to string) (string, bool) {
+	key, _ = rfc.VariantBaseKey(key)
+	i := strings.Index(key, ":")
+	if i == -1 {
+		return "", false
+	}
+	uri := key[i+1:]
+	if !strings.HasPrefix(uri, to) {
+		return "", false
+	}
+	return uri[len(to):], true
+}
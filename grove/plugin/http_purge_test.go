@@ -0,0 +1,89 @@
+package plugin
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/grove/cacheobj"
+	"github.com/apache/trafficcontrol/grove/memcache"
+	"github.com/apache/trafficcontrol/grove/remapdata"
+)
+
+func TestPurge(t *testing.T) {
+	cache := memcache.New(1024 * 1024)
+	keys := []string{
+		"GET:http://org.example.net/foo/a.png",
+		"GET:http://org.example.net/foo/b.jpg",
+		"GET:http://org.example.net/bar/c.png",
+		"GET:http://other.example.net/foo/d.png",
+		"GET:http://org.example.net/bar/c.png#vary:Accept-Encoding=gzip",
+	}
+	newCache := func() {
+		for _, key := range keys {
+			cache.Remove(key)
+			cache.Add(key, cacheobj.New(nil, []byte("body"), 200, 200, "", http.Header{}, time.Now(), time.Now(), time.Now(), time.Time{}))
+		}
+	}
+	rules := []remapdata.RemapRule{
+		{RemapRuleBase: remapdata.RemapRuleBase{Name: "org"}, To: []remapdata.RemapRuleTo{{RemapRuleToBase: remapdata.RemapRuleToBase{URL: "http://org.example.net"}}}, Cache: cache},
+	}
+
+	tests := []struct {
+		query     string
+		token     string
+		code      int
+		remaining []string
+		invalid   []string
+	}{
+		{"key=GET:http://org.example.net/foo/a.png", "tok", http.StatusOK, []string{keys[1], keys[2], keys[3], keys[4]}, nil},
+		{"key=GET:http://org.example.net/bar/c.png", "tok", http.StatusOK, []string{keys[0], keys[1], keys[3]}, nil},
+		{"rule=org&prefix=/foo/", "tok", http.StatusOK, []string{keys[2], keys[3], keys[4]}, nil},
+		{"rule=org&regex=\\.png$", "tok", http.StatusOK, []string{keys[1], keys[3]}, nil},
+		{"rule=org&prefix=/foo/&soft=true", "tok", http.StatusOK, keys, []string{keys[0], keys[1]}},
+		{"prefix=/foo/", "tok", http.StatusBadRequest, keys, nil},
+		{"rule=nonexistent&prefix=/foo/", "tok", http.StatusNotFound, keys, nil},
+		{"key=GET:http://org.example.net/foo/a.png", "wrong", http.StatusUnauthorized, keys, nil},
+	}
+
+	for _, test := range tests {
+		newCache()
+		req := httptest.NewRequest(MethodPurge, PurgeEndpoint+"?"+test.query, nil)
+		req.Header.Set("Authorization", "Bearer "+test.token)
+		w := httptest.NewRecorder()
+		if !purge(&PurgeConfig{Token: "tok"}, OnRequestData{W: w, R: req, Rules: rules}) {
+			t.Fatalf("purge '%v' expected stop true, actual false", test.query)
+		}
+		if w.Code != test.code {
+			t.Errorf("purge '%v' expected code %v actual %v body '%v'", test.query, test.code, w.Code, w.Body.String())
+		}
+		if len(cache.Keys()) != len(test.remaining) {
+			t.Errorf("purge '%v' expected %v remaining keys actual %+v", test.query, len(test.remaining), cache.Keys())
+		}
+		for _, key := range test.remaining {
+			if _, ok := cache.Peek(key); !ok {
+				t.Errorf("purge '%v' expected key '%v' to remain, actual removed", test.query, key)
+			}
+		}
+		for _, key := range test.invalid {
+			if obj, ok := cache.Peek(key); !ok || !obj.Invalidated {
+				t.Errorf("purge '%v' expected key '%v' invalidated, actual not", test.query, key)
+			}
+		}
+	}
+}
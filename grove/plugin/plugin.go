@@ -66,6 +66,7 @@ type OnRequestData struct {
 	InterfaceName string
 	Stats         stat.Stats
 	StatRules     remapdata.RemapRulesStats
+	Rules         []remapdata.RemapRule
 	HTTPConns     *web.ConnMap
 	HTTPSConns    *web.ConnMap
 	RequestID     uint64
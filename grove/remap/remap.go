@@ -105,6 +105,7 @@ type Remapping struct {
 	RetryNum        int
 	RetryCodes      map[int]struct{}
 	Cache           icache.Cache
+	MaxObjectSize   uint64
 	Transport       *http.Transport
 }
 
@@ -124,6 +125,8 @@ func (p *RemappingProducer) Name() string                      { return p.rule.N
 func (p *RemappingProducer) DSCP() int                         { return p.rule.DSCP }
 func (p *RemappingProducer) PluginCfg() map[string]interface{} { return p.rule.Plugins }
 func (p *RemappingProducer) Cache() icache.Cache               { return p.rule.Cache }
+func (p *RemappingProducer) Stream() bool                      { return p.rule.Stream }
+func (p *RemappingProducer) Timeout() time.Duration            { return *p.rule.Timeout }
 func (p *RemappingProducer) FirstFQDN() string {
 	// TODO verify To is not allowed to be constructed with < 1 element
 	return strings.TrimPrefix(strings.TrimPrefix(p.rule.To[0].URL, "http://"), "https://")
@@ -198,6 +201,7 @@ func (p *RemappingProducer) GetNext(r *http.Request) (Remapping, bool, error) {
 		RetryNum:        *p.rule.RetryNum,
 		RetryCodes:      p.rule.RetryCodes,
 		Cache:           p.rule.Cache,
+		MaxObjectSize:   p.rule.MaxObjectSize,
 		Transport:       transport,
 	}, retryAllowed, nil
 }
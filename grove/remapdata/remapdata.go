@@ -108,6 +108,10 @@ type RemapRuleBase struct {
 	RetryNum               *int                       `json:"retry_num"`
 	DSCP                   int                        `json:"dscp"`
 	PluginsShared          map[string]json.RawMessage `json:"plugins_shared"`
+	// Stream is whether to stream cache misses to the client as they arrive from the parent, rather than after the entire object is received.
+	Stream bool `json:"stream"`
+	// MaxObjectSize is the size in bytes of the largest object to cache. Larger objects are proxied, but not cached. If 0, objects of any size are cached.
+	MaxObjectSize uint64 `json:"max_object_size"`
 }
 
 type RemapRule struct {
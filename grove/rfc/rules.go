@@ -127,6 +127,10 @@ func CanCache(reqMethod string, reqHeaders http.Header, respCode int, respHeader
 	reqCacheControl := web.ParseCacheControl(reqHeaders)
 	respCacheControl := web.ParseCacheControl(respHeaders)
 	log.Debugf("CanCache reqCacheControl %+v respCacheControl %+v\n", reqCacheControl, respCacheControl)
+	if _, varyAll := VaryHeaders(respHeaders); varyAll {
+		log.Debugf("CanCache false: response has Vary *\n") // RFC7234§4.1 a stored Vary * never matches, so it can never be reused
+		return false
+	}
 	return canStoreResponse(respCode, respHeaders, reqCacheControl, respCacheControl, strictRFC) && canStoreAuthenticated(reqCacheControl, respCacheControl)
 }
 
@@ -134,7 +138,7 @@ func CanCache(reqMethod string, reqHeaders http.Header, respCode int, respHeader
 func CanReuseStored(reqHeaders http.Header, respHeaders http.Header, reqCacheControl web.CacheControl, respCacheControl web.CacheControl, respReqHeaders http.Header, respReqTime time.Time, respRespTime time.Time, strictRFC bool) remapdata.Reuse {
 	// TODO: remove allowed_stale, check in cache manager after revalidate fails? (since RFC7234§4.2.4 prohibits serving stale response unless disconnected).
 
-	if !selectedHeadersMatch(reqHeaders, respReqHeaders, strictRFC) {
+	if !selectedHeadersMatch(reqHeaders, respHeaders, respReqHeaders) {
 		log.Debugf("CanReuseStored false - selected headers don't match\n") // debug
 		return remapdata.ReuseCannot
 	}
@@ -340,31 +344,6 @@ func inMaxStale(respHeaders http.Header, respCacheControl web.CacheControl, resp
 	return inMaxStale
 }
 
-// SelectedHeadersMatch checks the constraints in RFC7234§4.1
-// TODO: change caching to key on URL+headers, so multiple requests for the same URL with different vary headers can be cached?
-func selectedHeadersMatch(reqHeaders http.Header, respReqHeaders http.Header, strictRFC bool) bool {
-	varyHeaders, ok := reqHeaders["vary"]
-	if !strictRFC && !ok {
-		return true
-	}
-	if len(varyHeaders) == 0 {
-		return true
-	}
-	varyHeader := varyHeaders[0]
-
-	if varyHeader == "*" {
-		return false
-	}
-	varyHeader = strings.ToLower(varyHeader)
-	varyHeaderHeaders := strings.Split(varyHeader, ",")
-	for _, header := range varyHeaderHeaders {
-		if _, ok := respReqHeaders[header]; !ok {
-			return false
-		}
-	}
-	return true
-}
-
 // HasPragmaNoCache returns whether the given headers have a `pragma: no-cache` which is to be considered per HTTP/1.1. This specifically returns false if `cache-control` exists, even if `pragma: no-cache` exists, per RFC7234§5.4
 func hasPragmaNoCache(reqHeaders http.Header) bool {
 	if _, ok := reqHeaders["Cache-Control"]; ok {
@@ -0,0 +1,96 @@
+package rfc
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// VariantKeySep separates a cache key from the request header values of a variant stored under it. It begins with '#', which never occurs in a request URI, so variant keys never collide with other keys.
+const VariantKeySep = "#vary:"
+
+// VaryHeaders returns the canonical names of the request headers the response varies on per its Vary header, sorted and without duplicates, and whether it varies on `*`, per RFC7231§7.1.4.
+func VaryHeaders(respHeaders http.Header) ([]string, bool) {
+	names := map[string]struct{}{}
+	for _, vary := range respHeaders["Vary"] {
+		for _, name := range strings.Split(vary, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, true
+			}
+			names[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted, false
+}
+
+// normalizeVaryValue returns the given header field values normalized per RFC7234§4.1, combining multiple fields into one, and removing whitespace around list elements, so semantically identical requests select the same variant.
+func normalizeVaryValue(values []string) string {
+	elems := []string{}
+	for _, value := range values {
+		for _, elem := range strings.Split(value, ",") {
+			if elem = strings.Join(strings.Fields(elem), " "); elem != "" {
+				elems = append(elems, elem)
+			}
+		}
+	}
+	return strings.Join(elems, ",")
+}
+
+// VariantKey returns the key to store the variant selected by the given request headers under, for the given cache key and Vary header names, as returned by VaryHeaders.
+func VariantKey(key string, varyHeaders []string, reqHeaders http.Header) string {
+	variant := url.Values{}
+	for _, name := range varyHeaders {
+		variant.Set(name, normalizeVaryValue(reqHeaders[name]))
+	}
+	return key + VariantKeySep + variant.Encode()
+}
+
+// VariantBaseKey returns the cache key the given variant key is stored under, and whether the key is a variant key.
+func VariantBaseKey(key string) (string, bool) {
+	i := strings.Index(key, VariantKeySep)
+	if i == -1 {
+		return key, false
+	}
+	return key[:i], true
+}
+
+// selectedHeadersMatch checks the constraints in RFC7234§4.1, that the request headers nominated by the stored response's Vary header match those of the request which produced the stored response.
+func selectedHeadersMatch(reqHeaders http.Header, respHeaders http.Header, respReqHeaders http.Header) bool {
+	varyHeaders, varyAll := VaryHeaders(respHeaders)
+	if varyAll {
+		return false
+	}
+	for _, name := range varyHeaders {
+		if normalizeVaryValue(reqHeaders[name]) != normalizeVaryValue(respReqHeaders[name]) {
+			return false
+		}
+	}
+	return true
+}
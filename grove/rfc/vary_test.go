@@ -0,0 +1,75 @@
+package rfc
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestVaryHeaders(t *testing.T) {
+	vary, all := VaryHeaders(http.Header{"Vary": {"accept-encoding, User-Agent", "Accept-Encoding"}})
+	if expected := []string{"Accept-Encoding", "User-Agent"}; !reflect.DeepEqual(vary, expected) || all {
+		t.Errorf("VaryHeaders expected %v false, actual %v %v", expected, vary, all)
+	}
+	if _, all := VaryHeaders(http.Header{"Vary": {"Accept-Encoding, *"}}); !all {
+		t.Errorf("VaryHeaders * expected true, actual false")
+	}
+	if vary, all := VaryHeaders(http.Header{}); vary != nil || all {
+		t.Errorf("VaryHeaders no Vary expected nil false, actual %v %v", vary, all)
+	}
+}
+
+func TestVariantKey(t *testing.T) {
+	vary := []string{"Accept-Encoding"}
+	key := "GET:http://example.net/foo"
+	gzip := VariantKey(key, vary, http.Header{"Accept-Encoding": {"gzip, deflate"}})
+	gzipSpaces := VariantKey(key, vary, http.Header{"Accept-Encoding": {"gzip,deflate"}})
+	gzipFields := VariantKey(key, vary, http.Header{"Accept-Encoding": {"gzip", "deflate"}})
+	identity := VariantKey(key, vary, http.Header{})
+
+	if gzip != gzipSpaces || gzip != gzipFields {
+		t.Errorf("VariantKey expected normalized values to match, actual '%v' '%v' '%v'", gzip, gzipSpaces, gzipFields)
+	}
+	if gzip == identity {
+		t.Errorf("VariantKey expected different values to differ, actual both '%v'", gzip)
+	}
+	if baseKey, ok := VariantBaseKey(gzip); !ok || baseKey != key {
+		t.Errorf("VariantBaseKey expected '%v' true, actual '%v' %v", key, baseKey, ok)
+	}
+	if baseKey, ok := VariantBaseKey(key); ok || baseKey != key {
+		t.Errorf("VariantBaseKey of non-variant expected '%v' false, actual '%v' %v", key, baseKey, ok)
+	}
+}
+
+func TestVaryRules(t *testing.T) {
+	respHdr := http.Header{"Vary": {"Accept-Encoding"}}
+	if !selectedHeadersMatch(http.Header{"Accept-Encoding": {"gzip"}}, respHdr, http.Header{"Accept-Encoding": {"gzip"}}) {
+		t.Errorf("selectedHeadersMatch same Accept-Encoding expected true, actual false")
+	}
+	if selectedHeadersMatch(http.Header{"Accept-Encoding": {"br"}}, respHdr, http.Header{"Accept-Encoding": {"gzip"}}) {
+		t.Errorf("selectedHeadersMatch different Accept-Encoding expected false, actual true")
+	}
+	if selectedHeadersMatch(http.Header{}, http.Header{"Vary": {"*"}}, http.Header{}) {
+		t.Errorf("selectedHeadersMatch Vary * expected false, actual true")
+	}
+	if CanCache(http.MethodGet, http.Header{}, http.StatusOK, http.Header{"Vary": {"*"}}, true) {
+		t.Errorf("CanCache Vary * expected false, actual true")
+	}
+	if !CanCache(http.MethodGet, http.Header{}, http.StatusOK, respHdr, true) {
+		t.Errorf("CanCache Vary Accept-Encoding expected true, actual false")
+	}
+}
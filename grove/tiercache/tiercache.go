@@ -15,6 +15,10 @@ package tiercache
 */
 
 import (
+	"errors"
+	"io"
+	"math"
+
 	"github.com/apache/trafficcontrol/grove/cacheobj"
 	"github.com/apache/trafficcontrol/grove/icache"
 
@@ -26,14 +30,22 @@ import (
 // This is more suitable for more less-frequently-requested items, with a separate cache for fewer frequently-requested items.
 //
 // An alternative implementation would be to Add to the first only, and when an object is evicted from the first, then store in the second. That would be more efficient for any given frequency, but less efficient for known infrequent objects.
+//
+// Objects larger than maxFirstObjectBytes are only stored in the second cache, so large objects don't evict many smaller ones from the first.
 type TierCache struct {
-	first  icache.Cache
-	second icache.Cache
+	first               icache.Cache
+	second              icache.Cache
+	maxFirstObjectBytes uint64
+}
+
+// New creates a new TierCache with the given first and second caches to use. Objects larger than maxFirstObjectBytes are only stored in the second cache. If maxFirstObjectBytes is 0, all objects are stored in both.
+func New(first, second icache.Cache, maxFirstObjectBytes uint64) *TierCache {
+	return &TierCache{first: first, second: second, maxFirstObjectBytes: maxFirstObjectBytes}
 }
 
-// New creates a new TierCache with the given first and second caches to use.
-func New(first, second icache.Cache) *TierCache {
-	return &TierCache{first: first, second: second}
+// firstCanStore returns whether an object of the given size may be stored in the first cache.
+func (c *TierCache) firstCanStore(size uint64) bool {
+	return c.maxFirstObjectBytes == 0 || size <= c.maxFirstObjectBytes
 }
 
 // Get returns the object if it's in the first cache. Else, it returns the object from the second cache. Else, false.
@@ -43,7 +55,7 @@ func (c *TierCache) Get(key string) (*cacheobj.CacheObj, bool) {
 	log.Debugf("TierCache.Get '"+key+"' FOUND FIRST: %+v\n", ok)
 	if !ok {
 		v, ok = c.second.Get(key)
-		if ok {
+		if ok && !v.Chunked && c.firstCanStore(v.Size) {
 			// if it was in second but not first, add back to first (LRU behavior)
 			c.first.Add(key, v)
 		}
@@ -62,13 +74,77 @@ func (c *TierCache) Peek(key string) (*cacheobj.CacheObj, bool) {
 	return v, ok
 }
 
-// Add adds to both internal caches. Returns whether either reported an eviction.
+// Add adds to both internal caches, or only the second if the object is too large for the first, or is Chunked. Returns whether either reported an eviction.
 func (c *TierCache) Add(key string, val *cacheobj.CacheObj) bool {
-	aevict := c.first.Add(key, val)
+	aevict := false
+	if !val.Chunked && c.firstCanStore(val.Size) {
+		aevict = c.first.Add(key, val)
+	} else {
+		c.first.Remove(key) // remove any old smaller object, which would otherwise be served instead of the new one
+	}
 	bevict := c.second.Add(key, val)
 	return aevict || bevict
 }
 
+// NewChunkWriter returns a writer to store the object in chunks in the second cache. Returns an error if the second cache isn't an icache.ChunkCache.
+func (c *TierCache) NewChunkWriter(key string) (icache.ChunkWriter, error) {
+	second, ok := c.second.(icache.ChunkCache)
+	if !ok {
+		return nil, errors.New("second cache does not support chunks")
+	}
+	w, err := second.NewChunkWriter(key)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkWriter{ChunkWriter: w, first: c.first, key: key}, nil
+}
+
+// ChunkThreshold returns the maximum size of objects stored in the first cache. Larger objects are only stored in the second, and so don't need held in memory.
+func (c *TierCache) ChunkThreshold() uint64 {
+	if c.maxFirstObjectBytes == 0 {
+		return math.MaxUint64 // all objects are stored in the first, so they must be held in memory anyway
+	}
+	return c.maxFirstObjectBytes
+}
+
+// NewChunkReader returns a reader of the body of an object stored in chunks in the second cache. Objects in the first cache are never stored in chunks.
+func (c *TierCache) NewChunkReader(key string) (io.Reader, bool) {
+	second, ok := c.second.(icache.ChunkCache)
+	if !ok {
+		return nil, false
+	}
+	return second.NewChunkReader(key)
+}
+
+// chunkWriter writes to the second cache, and removes the key from the first on Commit, so the old object isn't served from the first.
+type chunkWriter struct {
+	icache.ChunkWriter
+	first icache.Cache
+	key   string
+}
+
+func (w *chunkWriter) Commit(obj *cacheobj.CacheObj) error {
+	if err := w.ChunkWriter.Commit(obj); err != nil {
+		return err
+	}
+	w.first.Remove(w.key)
+	return nil
+}
+
+// Remove removes the key from both internal caches. Returns whether either contained it.
+func (c *TierCache) Remove(key string) bool {
+	aok := c.first.Remove(key)
+	bok := c.second.Remove(key)
+	return aok || bok
+}
+
+// Invalidate invalidates the key in both internal caches. Returns whether either contained it.
+func (c *TierCache) Invalidate(key string) bool {
+	aok := c.first.Invalidate(key)
+	bok := c.second.Invalidate(key)
+	return aok || bok
+}
+
 // Size returns the size of the second cache. This is because, since all objects are added to both, they are presumed to have the same content, and the second is presumed to be larger.
 //
 // For example, if the first is a memory cache and the second is a disk cache, it's most useful to report the size used on disk.
@@ -38,6 +38,7 @@ type ServerCapability string
 
 type ServerInfo struct {
 	CacheGroupID                  int
+	CacheGroupName                tc.CacheGroupName
 	CDN                           tc.CDNName
 	CDNID                         int
 	DomainName                    string
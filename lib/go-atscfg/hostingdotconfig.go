@@ -67,3 +67,39 @@ func MakeHostingDotConfig(
 	text += `hostname=*   volume=` + strconv.Itoa(diskVolume) + "\n"
 	return text
 }
+
+// HostingConfigTopologyDS is the data needed from a Delivery Service with a Topology, to determine the origins hosted by a server.
+type HostingConfigTopologyDS struct {
+	Name       string
+	OriginFQDN string
+	Type       tc.DSType
+	Active     bool
+	Topology   string
+}
+
+// GetTopologyHostingOrigins returns the origins of the Delivery Services with Topologies which servers in the given cachegroup should host.
+// Servers in the first cache tier of a Topology host LIVE and LIVE_NATNL origins, and servers in other tiers host only active LIVE_NATNL origins, the same as Edges and Mids without Topologies.
+func GetTopologyHostingOrigins(cacheGroup tc.CacheGroupName, dses []HostingConfigTopologyDS, topologies map[string]tc.Topology) []string {
+	origins := []string{}
+	for _, ds := range dses {
+		topology, ok := topologies[ds.Topology]
+		if !ok {
+			continue
+		}
+		placement := GetTopologyPlacement(cacheGroup, topology)
+		if !placement.InTopology {
+			continue
+		}
+		isLive := strings.HasSuffix(string(ds.Type), tc.DSTypeLiveSuffix)
+		isLiveNational := strings.HasSuffix(string(ds.Type), tc.DSTypeLiveNationalSuffix)
+		if placement.IsFirstCacheTier {
+			if !isLive && !isLiveNational {
+				continue
+			}
+		} else if !ds.Active || !isLiveNational {
+			continue
+		}
+		origins = append(origins, ds.OriginFQDN)
+	}
+	return origins
+}
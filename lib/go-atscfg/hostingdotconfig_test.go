@@ -114,3 +114,30 @@ func getFQDNs(origins []string) []string {
 	}
 	return newOrigins
 }
+
+func TestGetTopologyHostingOrigins(t *testing.T) {
+	topologies := map[string]tc.Topology{"mytopology": testTopology()}
+	dses := []HostingConfigTopologyDS{
+		{Name: "live", OriginFQDN: "http://live.example.net", Type: tc.DSTypeHTTPLive, Active: true, Topology: "mytopology"},
+		{Name: "livenatnl", OriginFQDN: "http://livenatnl.example.net", Type: tc.DSTypeHTTPLiveNational, Active: true, Topology: "mytopology"},
+		{Name: "livenatnlinactive", OriginFQDN: "http://livenatnlinactive.example.net", Type: tc.DSTypeDNSLiveNational, Active: false, Topology: "mytopology"},
+		{Name: "http", OriginFQDN: "http://http.example.net", Type: tc.DSTypeHTTP, Active: true, Topology: "mytopology"},
+		{Name: "othertopology", OriginFQDN: "http://othertopology.example.net", Type: tc.DSTypeHTTPLive, Active: true, Topology: "othertopology"},
+	}
+
+	edgeOrigins := GetTopologyHostingOrigins("edge0", dses, topologies)
+	expectedEdgeOrigins := []string{"http://live.example.net", "http://livenatnl.example.net", "http://livenatnlinactive.example.net"}
+	if strings.Join(edgeOrigins, ",") != strings.Join(expectedEdgeOrigins, ",") {
+		t.Errorf("expected first tier origins %+v, actual %+v", expectedEdgeOrigins, edgeOrigins)
+	}
+
+	midOrigins := GetTopologyHostingOrigins("mid0", dses, topologies)
+	expectedMidOrigins := []string{"http://livenatnl.example.net"}
+	if strings.Join(midOrigins, ",") != strings.Join(expectedMidOrigins, ",") {
+		t.Errorf("expected second tier origins %+v, actual %+v", expectedMidOrigins, midOrigins)
+	}
+
+	if origins := GetTopologyHostingOrigins("notintop", dses, topologies); len(origins) != 0 {
+		t.Errorf("expected no origins for cachegroup not in topology, actual %+v", origins)
+	}
+}
@@ -63,6 +63,7 @@ type ParentConfigDS struct {
 	OriginShield    string
 	Type            tc.DSType
 	QStringHandling string
+	Topology        string
 
 	RequiredCapabilities map[ServerCapability]struct{}
 }
@@ -167,6 +168,8 @@ func MakeParentDotConfig(
 	parentConfigDSes []ParentConfigDSTopLevel, // getParentConfigDSTopLevel(cdn) OR getParentConfigDS(server) (TODO determine how to handle non-top missing MSO?)
 	serverParams map[string]string, // getParentConfigServerProfileParams(serverID)
 	parentInfos map[OriginHost][]ParentInfo, // getParentInfo(profileID, parentCachegroupID, secondaryParentCachegroupID)
+	topologies map[string]tc.Topology, // the topologies containing this server's cachegroup
+	topologyParentInfos map[tc.CacheGroupName][]ParentInfo, // MakeTopologyParentInfo, for the cachegroups in topologies
 ) string {
 
 	// parentInfos := makeParentInfo(serverInfo)
@@ -174,13 +177,16 @@ func MakeParentDotConfig(
 	nameVersionStr := GetNameVersionStringFromToolNameAndURL(toToolName, toURL)
 	hdr := HeaderCommentWithTOVersionStr(serverInfo.HostName, nameVersionStr)
 
-	textArr := []string{}
+	textArr := getTopologyParentLines(serverInfo, parentConfigDSes, serverParams, topologies, topologyParentInfos, atsMajorVer)
 	text := ""
 	// TODO put these in separate functions. No if-statement should be this long.
 	if serverInfo.IsTopLevelCache() {
 		uniqueOrigins := map[string]struct{}{}
 
 		for _, ds := range parentConfigDSes {
+			if ds.Topology != "" {
+				continue // delivery services with topologies don't use the server's cachegroup parents
+			}
 			parentQStr := "ignore"
 			if ds.QStringHandling == "" && ds.MSOAlgorithm == tc.AlgorithmConsistentHash && ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp {
 				parentQStr = "consider"
@@ -258,6 +264,9 @@ func MakeParentDotConfig(
 		sort.Sort(ParentConfigDSTopLevelSortByName(parentConfigDSes))
 
 		for _, ds := range parentConfigDSes {
+			if ds.Topology != "" {
+				continue // delivery services with topologies don't use the server's cachegroup parents
+			}
 			parents, secondaryParents := getParentStrs(ds, parentInfos[DeliveryServicesAllParentsKey], atsMajorVer)
 
 			text := ""
@@ -292,27 +301,7 @@ func MakeParentDotConfig(
 			if dsType := tc.DSType(ds.Type); dsType == tc.DSTypeHTTPNoCache || dsType == tc.DSTypeHTTPLive || dsType == tc.DSTypeDNSLive {
 				text += `dest_domain=` + orgURI.Hostname() + ` port=` + orgURI.Port() + ` go_direct=true` + "\n"
 			} else {
-
-				// check for profile psel.qstring_handling.  If this parameter is assigned to the server profile,
-				// then edges will use the qstring handling value specified in the parameter for all profiles.
-
-				// If there is no defined parameter in the profile, then check the delivery service profile.
-				// If psel.qstring_handling exists in the DS profile, then we use that value for the specified DS only.
-				// This is used only if not overridden by a server profile qstring handling parameter.
-
-				// TODO refactor this logic, hard to understand (transliterated from Perl)
-				dsQSH := queryStringHandling
-				if dsQSH == "" {
-					dsQSH = ds.QStringHandling
-				}
-				parentQStr := dsQSH
-				if parentQStr == "" {
-					parentQStr = "ignore"
-				}
-				if ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp && dsQSH == "" {
-					parentQStr = "consider"
-				}
-
+				parentQStr := getParentQStr(ds, queryStringHandling)
 				text += `dest_domain=` + orgURI.Hostname() + ` port=` + orgURI.Port() + ` ` + parents + ` ` + secondaryParents + ` ` + roundRobin + ` ` + goDirect + ` qstring=` + parentQStr + "\n"
 			}
 			textArr = append(textArr, text)
@@ -338,6 +327,30 @@ func MakeParentDotConfig(
 	return text
 }
 
+// getParentQStr returns the qstring= value for the given non-top-level delivery service's parent.config line, given the server profile's psel.qstring_handling parameter.
+func getParentQStr(ds ParentConfigDSTopLevel, queryStringHandling string) string {
+	// check for profile psel.qstring_handling.  If this parameter is assigned to the server profile,
+	// then edges will use the qstring handling value specified in the parameter for all profiles.
+
+	// If there is no defined parameter in the profile, then check the delivery service profile.
+	// If psel.qstring_handling exists in the DS profile, then we use that value for the specified DS only.
+	// This is used only if not overridden by a server profile qstring handling parameter.
+
+	// TODO refactor this logic, hard to understand (transliterated from Perl)
+	dsQSH := queryStringHandling
+	if dsQSH == "" {
+		dsQSH = ds.QStringHandling
+	}
+	parentQStr := dsQSH
+	if parentQStr == "" {
+		parentQStr = "ignore"
+	}
+	if ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp && dsQSH == "" {
+		parentQStr = "consider"
+	}
+	return parentQStr
+}
+
 // getParentStrs returns the parents= and secondary_parents= strings for ATS parent.config lines.
 func getParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMajorVer int) (string, string) {
 	parentInfo := []string{}
@@ -481,6 +494,115 @@ func MakeParentInfo(
 	return parentInfos
 }
 
+// MakeTopologyParentInfo returns the parents in each of the given cachegroups, for delivery services with topologies.
+// The returned ParentInfos are neither primary nor secondary parents, because that depends on the topology.
+func MakeTopologyParentInfo(
+	profileCaches map[ProfileID]ProfileCache, // getServerParentCacheGroupProfiles(tx, server)
+	cacheGroupServers map[tc.CacheGroupName][]CGServer, // the Edge and Mid servers in each cachegroup of the server's topologies
+) map[tc.CacheGroupName][]ParentInfo {
+	parentInfos := map[tc.CacheGroupName][]ParentInfo{}
+	for cacheGroup, servers := range cacheGroupServers {
+		for _, row := range servers {
+			profile := profileCaches[row.ProfileID]
+			if profile.NotAParent {
+				continue
+			}
+			parentInf := ParentInfo{
+				Host:         row.ServerHost,
+				Port:         profile.Port,
+				Domain:       row.Domain,
+				Weight:       profile.Weight,
+				UseIP:        profile.UseIP,
+				Rank:         profile.Rank,
+				IP:           row.ServerIP,
+				Capabilities: row.Capabilities,
+			}
+			if parentInf.Port < 1 {
+				parentInf.Port = row.ServerPort
+			}
+			parentInfos[cacheGroup] = append(parentInfos[cacheGroup], parentInf)
+		}
+	}
+	return parentInfos
+}
+
+// getTopologyParentLines returns the parent.config lines for the given delivery services which have topologies containing the server's cachegroup.
+// Delivery services without topologies are skipped.
+func getTopologyParentLines(
+	server *ServerInfo,
+	dses []ParentConfigDSTopLevel,
+	serverParams map[string]string,
+	topologies map[string]tc.Topology,
+	topologyParentInfos map[tc.CacheGroupName][]ParentInfo,
+	atsMajorVer int,
+) []string {
+	topologyDSes := []ParentConfigDSTopLevel{}
+	for _, ds := range dses {
+		if ds.Topology != "" {
+			topologyDSes = append(topologyDSes, ds)
+		}
+	}
+	sort.Sort(ParentConfigDSTopLevelSortByName(topologyDSes))
+
+	lines := []string{}
+	processedOriginsToDSNames := map[string]tc.DeliveryServiceName{}
+	for _, ds := range topologyDSes {
+		topology, ok := topologies[ds.Topology]
+		if !ok {
+			log.Errorln("parent.config generation: delivery service '" + string(ds.Name) + "' has topology '" + ds.Topology + "' which doesn't contain this server's cachegroup, or doesn't exist: skipping!")
+			continue
+		}
+		placement := GetTopologyPlacement(server.CacheGroupName, topology)
+		if !placement.InTopology {
+			continue
+		}
+
+		orgURI, err := url.Parse(ds.OriginFQDN)
+		if ds.OriginFQDN == "" || err != nil {
+			log.Errorf("parent.config generation: delivery service '%s' has malformed origin URI '%s': skipping! : %v\n", ds.Name, ds.OriginFQDN, err)
+			continue
+		}
+		if existingDS, ok := processedOriginsToDSNames[ds.OriginFQDN]; ok {
+			log.Errorln("parent.config generation: duplicate origin! services '" + string(ds.Name) + "' and '" + string(existingDS) + "' share origin '" + orgURI.Host + "': skipping '" + string(ds.Name) + "'!")
+			continue
+		}
+		processedOriginsToDSNames[ds.OriginFQDN] = ds.Name
+		if orgURI.Port() == "" {
+			if orgURI.Scheme == "http" {
+				orgURI.Host += ":80"
+			} else if orgURI.Scheme == "https" {
+				orgURI.Host += ":443"
+			}
+		}
+
+		if dsType := tc.DSType(ds.Type); placement.IsLastCacheTier || dsType == tc.DSTypeHTTPNoCache || dsType == tc.DSTypeHTTPLive || dsType == tc.DSTypeDNSLive {
+			// The last tier must go direct explicitly, because non-top-level caches have a default rule to their cachegroup parents.
+			lines = append(lines, `dest_domain=`+orgURI.Hostname()+` port=`+orgURI.Port()+` go_direct=true`+"\n")
+			continue
+		}
+
+		parentInfos := []ParentInfo{}
+		for _, parentInfo := range topologyParentInfos[placement.ParentCacheGroup] {
+			parentInfo.PrimaryParent = true
+			parentInfos = append(parentInfos, parentInfo)
+		}
+		if placement.SecondaryParentCacheGroup != "" {
+			for _, parentInfo := range topologyParentInfos[placement.SecondaryParentCacheGroup] {
+				parentInfo.SecondaryParent = true
+				parentInfos = append(parentInfos, parentInfo)
+			}
+		}
+		if len(parentInfos) == 0 {
+			log.Warnln("parent.config generation: delivery service '" + string(ds.Name) + "' topology '" + ds.Topology + "' has no parent servers for this server's cachegroup")
+		}
+
+		parents, secondaryParents := getParentStrs(ds, parentInfos, atsMajorVer)
+		parentQStr := getParentQStr(ds, serverParams[ParentConfigParamQStringHandling])
+		lines = append(lines, `dest_domain=`+orgURI.Hostname()+` port=`+orgURI.Port()+` `+parents+` `+secondaryParents+` round_robin=consistent_hash go_direct=false qstring=`+parentQStr+"\n")
+	}
+	return lines
+}
+
 // unavailableServerRetryResponsesValid returns whether a unavailable_server_retry_responses parameter is valid for an ATS parent rule.
 func unavailableServerRetryResponsesValid(s string) bool {
 	// optimization if param is empty
@@ -95,7 +95,7 @@ func TestMakeParentDotConfig(t *testing.T) {
 		},
 	}
 
-	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos)
+	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos, nil, nil)
 
 	testComment(t, txt, serverName, toolName, toURL)
 
@@ -202,7 +202,7 @@ func TestMakeParentDotConfigCapabilities(t *testing.T) {
 		},
 	}
 
-	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos)
+	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos, nil, nil)
 
 	testComment(t, txt, serverName, toolName, toURL)
 
@@ -314,7 +314,7 @@ func TestMakeParentDotConfigMSOSecondaryParent(t *testing.T) {
 		t.Fatal("server should have been top level, was not; cannot test MSO Secondary Parent")
 	}
 
-	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos)
+	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos, nil, nil)
 
 	testComment(t, txt, serverName, toolName, toURL)
 
@@ -324,3 +324,101 @@ func TestMakeParentDotConfigMSOSecondaryParent(t *testing.T) {
 		t.Errorf("expected secondary parent 'my-parent-1.my-parent-1-domain', actual: '%v'", txt)
 	}
 }
+
+func TestMakeParentDotConfigTopologies(t *testing.T) {
+	atsMajorVer := 7
+	serverName := "myserver"
+	toolName := "myToolName"
+	toURL := "https://myto.example.net"
+
+	parentConfigDSes := []ParentConfigDSTopLevel{
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:            "ds0",
+				QStringIgnore:   tc.QStringIgnoreUseInCacheKeyAndPassUp,
+				OriginFQDN:      "http://ds0.example.net",
+				MultiSiteOrigin: false,
+				Type:            tc.DSTypeHTTP,
+				QStringHandling: "ds0qstringhandling",
+				Topology:        "mytopology",
+			},
+		},
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:            "ds1",
+				QStringIgnore:   tc.QStringIgnoreDrop,
+				OriginFQDN:      "http://ds1.example.net",
+				MultiSiteOrigin: false,
+				Type:            tc.DSTypeDNS,
+				QStringHandling: "ds1qstringhandling",
+				Topology:        "othertopology",
+			},
+		},
+	}
+
+	serverInfo := &ServerInfo{
+		CacheGroupID:   42,
+		CacheGroupName: "edge0",
+		CDN:            "myCDN",
+		CDNID:          43,
+		DomainName:     "serverdomain.example.net",
+		HostName:       "myserver",
+		ID:             44,
+		IP:             "192.168.2.1",
+		ProfileID:      46,
+		ProfileName:    "MyProfileName",
+		Port:           80,
+		Type:           "EDGE",
+	}
+
+	serverParams := map[string]string{
+		ParentConfigParamQStringHandling: "myQStringHandlingParam",
+		ParentConfigParamAlgorithm:       tc.AlgorithmConsistentHash,
+		ParentConfigParamQString:         "myQstringParam",
+	}
+
+	topologies := map[string]tc.Topology{"mytopology": testTopology()}
+
+	topologyParentInfos := map[tc.CacheGroupName][]ParentInfo{
+		"mid0": []ParentInfo{
+			ParentInfo{Host: "my-mid-0", Port: 80, Domain: "my-mid-0-domain", Weight: "1", Rank: 1, IP: "192.168.2.2"},
+		},
+		"mid1": []ParentInfo{
+			ParentInfo{Host: "my-mid-1", Port: 80, Domain: "my-mid-1-domain", Weight: "1", Rank: 1, IP: "192.168.2.3"},
+		},
+	}
+
+	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, nil, topologies, topologyParentInfos)
+
+	testComment(t, txt, serverName, toolName, toURL)
+
+	lines := strings.Split(txt, "\n")
+	ds0Line := ""
+	for _, line := range lines {
+		if strings.HasPrefix(line, "dest_domain=ds0.example.net") {
+			ds0Line = line
+		}
+		if strings.HasPrefix(line, "dest_domain=ds1.example.net") {
+			t.Errorf("expected no parent line for delivery service with topology not containing the server's cachegroup, actual: '%v'", txt)
+		}
+	}
+	if ds0Line == "" {
+		t.Fatalf("expected parent 'dest_domain=ds0.example.net', actual: '%v'", txt)
+	}
+	if !strings.Contains(ds0Line, `parent="my-mid-0.my-mid-0-domain:80|1;"`) {
+		t.Errorf("expected topology primary parent 'my-mid-0', actual: '%v'", ds0Line)
+	}
+	if !strings.Contains(ds0Line, `secondary_parent="my-mid-1.my-mid-1-domain:80|1;"`) {
+		t.Errorf("expected topology secondary parent 'my-mid-1', actual: '%v'", ds0Line)
+	}
+	if !strings.Contains(ds0Line, "go_direct=false") {
+		t.Errorf("expected first tier topology parent 'go_direct=false', actual: '%v'", ds0Line)
+	}
+
+	serverInfo.CacheGroupName = "mid0"
+	serverInfo.Type = "MID"
+	txt = MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, nil, topologies, topologyParentInfos)
+	if !strings.Contains(txt, "dest_domain=ds0.example.net port=80 go_direct=true") {
+		t.Errorf("expected last tier topology parent 'go_direct=true', actual: '%v'", txt)
+	}
+}
@@ -59,6 +59,7 @@ type RemapConfigDSData struct {
 	Protocol                 *int
 	AnonymousBlockingEnabled *bool
 	Active                   bool
+	Topology                 *string
 }
 
 func MakeRemapDotConfig(
@@ -71,13 +72,46 @@ func MakeRemapDotConfig(
 	serverPackageParamData map[string]string, // map[paramName]paramVal for this server, config file 'package'
 	serverInfo *ServerInfo, // ServerInfo for this server
 	remapDSData []RemapConfigDSData,
+	topologies map[string]tc.Topology, // the topologies containing this server's cachegroup
 ) string {
 	hdr := GenericHeaderComment(string(serverName), toToolName, toURL)
-	text := ""
-	if tc.CacheTypeFromString(serverInfo.Type) == tc.CacheTypeMid {
-		text = GetServerConfigRemapDotConfigForMid(atsMajorVersion, dsProfilesCacheKeyConfigParams, serverInfo, remapDSData, hdr)
-	} else {
-		text = GetServerConfigRemapDotConfigForEdge(cacheURLConfigParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapDSData, atsMajorVersion, hdr)
+
+	// Delivery services without topologies get remap rules by the server's type.
+	// Delivery services with topologies get Edge rules on the first tier of the topology, and Mid rules on other tiers.
+	isMid := tc.CacheTypeFromString(serverInfo.Type) == tc.CacheTypeMid
+	edgeDSes := []RemapConfigDSData{}
+	midDSes := []RemapConfigDSData{}
+	for _, ds := range remapDSData {
+		if ds.Topology == nil || *ds.Topology == "" {
+			if isMid {
+				midDSes = append(midDSes, ds)
+			} else {
+				edgeDSes = append(edgeDSes, ds)
+			}
+			continue
+		}
+		topology, ok := topologies[*ds.Topology]
+		if !ok {
+			log.Errorln("remap.config generation: delivery service '" + ds.Name + "' has topology '" + *ds.Topology + "' which doesn't contain this server's cachegroup, or doesn't exist: skipping!")
+			continue
+		}
+		placement := GetTopologyPlacement(serverInfo.CacheGroupName, topology)
+		if !placement.InTopology {
+			continue
+		}
+		if placement.IsFirstCacheTier {
+			edgeDSes = append(edgeDSes, ds)
+		} else {
+			midDSes = append(midDSes, ds)
+		}
+	}
+
+	text := hdr
+	if len(edgeDSes) > 0 || !isMid {
+		text += GetServerConfigRemapDotConfigForEdge(cacheURLConfigParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, edgeDSes, atsMajorVersion, "")
+	}
+	if len(midDSes) > 0 || isMid {
+		text += GetServerConfigRemapDotConfigForMid(atsMajorVersion, dsProfilesCacheKeyConfigParams, serverInfo, midDSes, "")
 	}
 	return text
 }
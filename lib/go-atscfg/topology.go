@@ -0,0 +1,71 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// TopologyPlacement is the placement of a cachegroup in a Delivery Service's Topology.
+type TopologyPlacement struct {
+	// InTopology is whether the cachegroup is in the Topology. Servers in cachegroups not in a Delivery Service's Topology don't serve it.
+	InTopology bool
+	// IsFirstCacheTier is whether the cachegroup is not the parent of any other cachegroup in the Topology. Clients request from the first tier, which gets Edge remap rules.
+	IsFirstCacheTier bool
+	// IsLastCacheTier is whether the cachegroup has no parents in the Topology. The last tier requests from the origin.
+	IsLastCacheTier bool
+	// ParentCacheGroup is the cachegroup's primary parent in the Topology, or empty if it's in the last tier.
+	ParentCacheGroup tc.CacheGroupName
+	// SecondaryParentCacheGroup is the cachegroup's secondary parent in the Topology, or empty if it has none.
+	SecondaryParentCacheGroup tc.CacheGroupName
+}
+
+// GetTopologyPlacement returns the placement of the given cachegroup in the given Topology.
+func GetTopologyPlacement(cacheGroup tc.CacheGroupName, topology tc.Topology) TopologyPlacement {
+	nodeIndex := -1
+	for i, node := range topology.Nodes {
+		if tc.CacheGroupName(node.Cachegroup) == cacheGroup {
+			nodeIndex = i
+			break
+		}
+	}
+	if nodeIndex < 0 {
+		return TopologyPlacement{}
+	}
+
+	placement := TopologyPlacement{InTopology: true, IsFirstCacheTier: true}
+	for _, node := range topology.Nodes {
+		for _, parent := range node.Parents {
+			if parent == nodeIndex {
+				placement.IsFirstCacheTier = false
+			}
+		}
+	}
+
+	parents := topology.Nodes[nodeIndex].Parents
+	placement.IsLastCacheTier = len(parents) == 0
+	if len(parents) > 0 && parents[0] >= 0 && parents[0] < len(topology.Nodes) {
+		placement.ParentCacheGroup = tc.CacheGroupName(topology.Nodes[parents[0]].Cachegroup)
+	}
+	if len(parents) > 1 && parents[1] >= 0 && parents[1] < len(topology.Nodes) {
+		placement.SecondaryParentCacheGroup = tc.CacheGroupName(topology.Nodes[parents[1]].Cachegroup)
+	}
+	return placement
+}
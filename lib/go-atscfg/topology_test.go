@@ -0,0 +1,56 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func testTopology() tc.Topology {
+	return tc.Topology{
+		Name: "mytopology",
+		Nodes: []tc.TopologyNode{
+			{Cachegroup: "edge0", Parents: []int{2, 3}},
+			{Cachegroup: "edge1", Parents: []int{2}},
+			{Cachegroup: "mid0", Parents: []int{}},
+			{Cachegroup: "mid1", Parents: []int{}},
+		},
+	}
+}
+
+func TestGetTopologyPlacement(t *testing.T) {
+	topology := testTopology()
+
+	expecteds := map[tc.CacheGroupName]TopologyPlacement{
+		"edge0":    {InTopology: true, IsFirstCacheTier: true, ParentCacheGroup: "mid0", SecondaryParentCacheGroup: "mid1"},
+		"edge1":    {InTopology: true, IsFirstCacheTier: true, ParentCacheGroup: "mid0"},
+		"mid0":     {InTopology: true, IsLastCacheTier: true},
+		"mid1":     {InTopology: true, IsLastCacheTier: true},
+		"notintop": {},
+	}
+
+	for cacheGroup, expected := range expecteds {
+		if actual := GetTopologyPlacement(cacheGroup, topology); actual != expected {
+			t.Errorf("GetTopologyPlacement cachegroup '%v' expected: %+v, actual: %+v", cacheGroup, expected, actual)
+		}
+	}
+}
@@ -22,6 +22,7 @@ package rfc
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-util"
 )
@@ -32,6 +33,10 @@ const ContentType = "Content-Type"
 const ContentEncoding = "Content-Encoding"
 const ContentTypeTextPlain = "text/plain"
 const AcceptEncoding = "Accept-Encoding"
+const ETag = "ETag"
+const LastModified = "Last-Modified"
+const IfNoneMatch = "If-None-Match"
+const IfModifiedSince = "If-Modified-Since"
 
 // AcceptsGzip returns whether r accepts gzip encoding, per RFC7231§5.3.4.
 func AcceptsGzip(r *http.Request) bool {
@@ -47,3 +52,40 @@ func AcceptsGzip(r *http.Request) bool {
 	}
 	return false
 }
+
+// ETagMatches returns whether the given If-None-Match header value lists etag, per RFC7232§3.2.
+// Entity tags are compared with the weak comparison function, per RFC7232§2.3.2, so "W/" prefixes are ignored.
+func ETagMatches(ifNoneMatch string, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if ifNoneMatch == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified returns whether r is a conditional GET or HEAD which may be answered with 304 Not Modified, for a representation with the given entity tag and last modification time, per RFC7232§6.
+// If-None-Match takes precedence over If-Modified-Since, which is ignored if the request has both. A zero lastModified never satisfies If-Modified-Since.
+// The lastModified of a collection should be zero, unless it changes when a member is removed, because the latest modification time of the remaining members doesn't.
+func NotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if ifNoneMatch := r.Header.Get(IfNoneMatch); ifNoneMatch != "" {
+		return etag != "" && ETagMatches(ifNoneMatch, etag)
+	}
+	ifModifiedSince := r.Header.Get(IfModifiedSince)
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false // per RFC7232§3.3, an invalid date is ignored
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,83 @@
+package rfc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		ifNoneMatch string
+		expected    bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{`abc`, false},
+	}
+	for _, test := range tests {
+		if actual := ETagMatches(test.ifNoneMatch, etag); actual != test.expected {
+			t.Errorf("ETagMatches(%q, %q) expected %v actual %v", test.ifNoneMatch, etag, test.expected, actual)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2019, 11, 20, 12, 0, 0, 500, time.UTC)
+	etag := `"abc"`
+	tests := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		expected bool
+	}{
+		{"unconditional", http.MethodGet, nil, false},
+		{"etag match", http.MethodGet, map[string]string{IfNoneMatch: etag}, true},
+		{"etag mismatch", http.MethodGet, map[string]string{IfNoneMatch: `"xyz"`}, false},
+		{"etag match head", http.MethodHead, map[string]string{IfNoneMatch: etag}, true},
+		{"etag match post", http.MethodPost, map[string]string{IfNoneMatch: etag}, false},
+		{"not modified since", http.MethodGet, map[string]string{IfModifiedSince: lastModified.Format(http.TimeFormat)}, true},
+		{"modified since", http.MethodGet, map[string]string{IfModifiedSince: lastModified.Add(-time.Minute).Format(http.TimeFormat)}, false},
+		{"invalid since", http.MethodGet, map[string]string{IfModifiedSince: "yesterday"}, false},
+		{"etag takes precedence", http.MethodGet, map[string]string{IfNoneMatch: `"xyz"`, IfModifiedSince: lastModified.Format(http.TimeFormat)}, false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/", nil)
+		for k, v := range test.headers {
+			r.Header.Set(k, v)
+		}
+		if actual := NotModified(r, etag, lastModified); actual != test.expected {
+			t.Errorf("NotModified %s expected %v actual %v", test.name, test.expected, actual)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(IfModifiedSince, lastModified.Format(http.TimeFormat))
+	if NotModified(r, etag, time.Time{}) {
+		t.Errorf("NotModified with no last modified time expected false, actual true")
+	}
+}
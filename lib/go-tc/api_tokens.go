@@ -0,0 +1,79 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// APITokensResponse contains the result data from a GET /api_tokens request.
+type APITokensResponse struct {
+	Response []APIToken `json:"response"`
+}
+
+// APITokenCreateResponse contains the result data from a POST /api_tokens request. It's the only response which contains the secret token.
+type APITokenCreateResponse struct {
+	Response APITokenCreated `json:"response"`
+	Alerts
+}
+
+// APIToken is a long-lived, revocable token which authenticates as the user it was issued to, via an "Authorization: Bearer" request header.
+// If Capabilities is not nil, the token is limited to those of the user's capabilities. It still has the user's privilege level, which every route requires as well as its capabilities.
+type APIToken struct {
+	ID           *int       `json:"id" db:"id"`
+	Name         *string    `json:"name" db:"name"`
+	UserID       *int       `json:"userId" db:"tm_user_id"`
+	Username     *string    `json:"username" db:"username"`
+	Capabilities *[]string  `json:"capabilities"`
+	Expires      *time.Time `json:"expires" db:"expires"`
+	LastUsed     *time.Time `json:"lastUsed" db:"last_used"`
+	LastUpdated  *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// APITokenCreated is a newly issued APIToken, with its secret Token.
+type APITokenCreated struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// APITokenRequest is the request body of a POST /api_tokens request. If UserID is nil, the token is issued to the requesting user.
+type APITokenRequest struct {
+	Name         *string    `json:"name"`
+	UserID       *int       `json:"userId"`
+	Capabilities *[]string  `json:"capabilities"`
+	Expires      *time.Time `json:"expires"`
+}
+
+// Validate implements the github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api.ParseValidator interface.
+func (req *APITokenRequest) Validate(tx *sql.Tx) error {
+	return validation.ValidateStruct(req,
+		validation.Field(&req.Name, validation.Required),
+		validation.Field(&req.Expires, validation.By(func(v interface{}) error {
+			if expires, ok := v.(*time.Time); ok && expires != nil && !expires.After(time.Now()) {
+				return errors.New("must be in the future")
+			}
+			return nil
+		})),
+	)
+}
@@ -36,8 +36,14 @@ type CRStatesDeliveryService struct {
 }
 
 // IsAvailable contains whether the given cache or delivery service is available. It is designed for JSON serialization, namely in the Traffic Monitor 1.0 API.
+// For caches, Ipv4Available and Ipv6Available are whether the cache is available over each address family, and IsAvailable is whether it's available over either, so a router may keep serving one family of a cache whose other family is unavailable.
 type IsAvailable struct {
-	IsAvailable bool `json:"isAvailable"`
+	IsAvailable   bool `json:"isAvailable"`
+	Ipv4Available bool `json:"ipv4Available"`
+	Ipv6Available bool `json:"ipv6Available"`
+	// Votes is whether each Traffic Monitor which voted on a cache's availability considered it available, when availability is combined by a quorum of Traffic Monitors.
+	// It is nil for local states, and when availability isn't combined by quorum. The map must not be modified, because copies of an IsAvailable share it.
+	Votes map[TrafficMonitorName]bool `json:"votes,omitempty"`
 }
 
 // NewCRStates creates a new CR states object, initializing pointer members.
@@ -165,6 +165,7 @@ type DeliveryServiceNullable struct {
 	ConsistentHashRegex       *string  `json:"consistentHashRegex"`
 	ConsistentHashQueryParams []string `json:"consistentHashQueryParams"`
 	MaxOriginConnections      *int     `json:"maxOriginConnections" db:"max_origin_connections"`
+	Topology                  *string  `json:"topology" db:"topology"`
 }
 
 type DeliveryServiceNullableV13 struct {
@@ -341,6 +342,9 @@ func (ds *DeliveryServiceNullable) Sanitize() {
 		ds.DeepCachingType = &s
 	}
 	*ds.DeepCachingType = DeepCachingTypeFromString(string(*ds.DeepCachingType))
+	if ds.Topology != nil && strings.TrimSpace(*ds.Topology) == "" {
+		ds.Topology = nil
+	}
 }
 
 func (ds *DeliveryServiceNullable) validateTypeFields(tx *sql.Tx) error {
@@ -390,6 +394,14 @@ func (ds *DeliveryServiceNullable) validateTypeFields(tx *sql.Tx) error {
 			validation.By(requiredIfMatchesTypeName([]string{DNSRegexType, HTTPRegexType}, typeName))),
 		"rangeRequestHandling": validation.Validate(ds.RangeRequestHandling,
 			validation.By(requiredIfMatchesTypeName([]string{DNSRegexType, HTTPRegexType}, typeName))),
+		"topology": validation.Validate(ds,
+			validation.By(func(dsi interface{}) error {
+				ds := dsi.(*DeliveryServiceNullable)
+				if ds.Topology == nil || DSType(typeName).IsHTTP() || DSType(typeName).IsDNS() {
+					return nil
+				}
+				return fmt.Errorf("topology not allowed for '%s' deliveryservice type", typeName)
+			})),
 	}
 	toErrs := tovalidate.ToErrors(errs)
 	if len(toErrs) > 0 {
@@ -77,6 +77,8 @@ const MidTypePrefix = "MID"
 const OriginTypeName = "ORG"
 
 const CacheGroupOriginTypeName = "ORG_LOC"
+const CacheGroupEdgeTypeName = "EDGE_LOC"
+const CacheGroupMidTypeName = "MID_LOC"
 
 const GlobalProfileName = "GLOBAL"
 
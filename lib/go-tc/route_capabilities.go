@@ -0,0 +1,37 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// RouteCapabilitiesResponse contains the result data from a GET /route_capabilities request.
+type RouteCapabilitiesResponse struct {
+	Response []RouteCapability `json:"response"`
+}
+
+// RouteCapability is the authorization required by a single Traffic Ops API route.
+// A user may use the route if their role's privilege level is at least RequiredPrivLevel, or if their role has every one of RequiredCapabilities.
+type RouteCapability struct {
+	ID                   int      `json:"id"`
+	Method               string   `json:"method"`
+	Path                 string   `json:"path"`
+	Version              float64  `json:"version"`
+	Authenticated        bool     `json:"authenticated"`
+	RequiredPrivLevel    int      `json:"requiredPrivLevel"`
+	RequiredCapabilities []string `json:"requiredCapabilities"`
+}
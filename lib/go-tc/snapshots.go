@@ -0,0 +1,128 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+)
+
+// SnapshotHistoryEntry is a single Snapshot of a CDN stored in the Snapshot history, without its content.
+type SnapshotHistoryEntry struct {
+	ID          int       `json:"id" db:"id"`
+	CDN         string    `json:"cdn" db:"cdn"`
+	User        string    `json:"user" db:"username"`
+	LastUpdated TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// SnapshotHistoryResponse contains the result data from a GET /cdns/{cdn}/snapshot/history request.
+type SnapshotHistoryResponse struct {
+	Response []SnapshotHistoryEntry `json:"response"`
+}
+
+// SnapshotHistoryDetail is a Snapshot of a CDN stored in the Snapshot history, including its CRConfig and monitoring content.
+type SnapshotHistoryDetail struct {
+	SnapshotHistoryEntry
+	CRConfig   json.RawMessage `json:"crconfig"`
+	Monitoring json.RawMessage `json:"monitoring"`
+}
+
+// SnapshotHistoryDetailResponse contains the result data from a GET /cdns/{cdn}/snapshot/history/{id} request.
+type SnapshotHistoryDetailResponse struct {
+	Response SnapshotHistoryDetail `json:"response"`
+}
+
+// CRConfigDiffKeys is the keys of a CRConfig object which were added, removed, or changed between two CRConfigs.
+type CRConfigDiffKeys struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// Empty returns whether no keys were added, removed, or changed.
+func (d CRConfigDiffKeys) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// CRConfigDiff is the structured difference between two CRConfigs.
+type CRConfigDiff struct {
+	Config           CRConfigDiffKeys `json:"config"`
+	ContentServers   CRConfigDiffKeys `json:"contentServers"`
+	ContentRouters   CRConfigDiffKeys `json:"contentRouters"`
+	DeliveryServices CRConfigDiffKeys `json:"deliveryServices"`
+	EdgeLocations    CRConfigDiffKeys `json:"edgeLocations"`
+	RouterLocations  CRConfigDiffKeys `json:"trafficRouterLocations"`
+	Monitors         CRConfigDiffKeys `json:"monitors"`
+}
+
+// MonitoringDiff is the structured difference between two monitoring configs.
+// Traffic Servers and Monitors are keyed by host name, Cache Groups and Profiles by name, and Delivery Services by XMLID.
+type MonitoringDiff struct {
+	Config           CRConfigDiffKeys `json:"config"`
+	TrafficServers   CRConfigDiffKeys `json:"trafficServers"`
+	TrafficMonitors  CRConfigDiffKeys `json:"trafficMonitors"`
+	CacheGroups      CRConfigDiffKeys `json:"cacheGroups"`
+	Profiles         CRConfigDiffKeys `json:"profiles"`
+	DeliveryServices CRConfigDiffKeys `json:"deliveryServices"`
+}
+
+// SnapshotDiffDangerType is the type of a dangerous change between two Snapshots.
+type SnapshotDiffDangerType string
+
+const (
+	// SnapshotDiffDangerDSRemoved is a Delivery Service which would be removed from the Snapshot, and hence no longer routed.
+	SnapshotDiffDangerDSRemoved = SnapshotDiffDangerType("deliveryServiceRemoved")
+	// SnapshotDiffDangerDSNoServers is a Delivery Service which had available servers, but would have none.
+	SnapshotDiffDangerDSNoServers = SnapshotDiffDangerType("deliveryServiceLosesAllServers")
+	// SnapshotDiffDangerEdgeLocationNoServers is an Edge Location (Cache Group) which had available servers, but would have none.
+	SnapshotDiffDangerEdgeLocationNoServers = SnapshotDiffDangerType("edgeLocationLosesAllServers")
+	// SnapshotDiffDangerNoRouters is a Snapshot which had Traffic Routers, but would have none.
+	SnapshotDiffDangerNoRouters = SnapshotDiffDangerType("allRoutersRemoved")
+)
+
+// SnapshotDiffDanger is a change between two Snapshots which is likely to cause an outage, and should be reviewed before snapshotting.
+type SnapshotDiffDanger struct {
+	Type    SnapshotDiffDangerType `json:"type"`
+	Name    string                 `json:"name"`
+	Message string                 `json:"message"`
+}
+
+// SnapshotPendingDiff is the difference between the current Snapshot of a CDN and the Snapshot which would be taken now.
+type SnapshotPendingDiff struct {
+	CRConfig   CRConfigDiff         `json:"crconfig"`
+	Monitoring MonitoringDiff       `json:"monitoring"`
+	Dangers    []SnapshotDiffDanger `json:"dangers"`
+}
+
+// SnapshotPendingDiffResponse contains the result data from a GET /cdns/{cdn}/snapshot/new/diff request.
+type SnapshotPendingDiffResponse struct {
+	Response SnapshotPendingDiff `json:"response"`
+}
+
+// SnapshotHistoryDiff is the difference between two Snapshots in the Snapshot history of a CDN.
+type SnapshotHistoryDiff struct {
+	From int          `json:"from"`
+	To   int          `json:"to"`
+	Diff CRConfigDiff `json:"diff"`
+}
+
+// SnapshotHistoryDiffResponse contains the result data from a GET /cdns/{cdn}/snapshot/history/diff request.
+type SnapshotHistoryDiffResponse struct {
+	Response SnapshotHistoryDiff `json:"response"`
+}
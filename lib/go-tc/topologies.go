@@ -0,0 +1,45 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// TopologiesResponse contains the result data from a GET /topologies request.
+type TopologiesResponse struct {
+	Response []Topology `json:"response"`
+}
+
+// TopologyResponse contains the result data from a POST or PUT /topologies request.
+type TopologyResponse struct {
+	Response Topology `json:"response"`
+	Alerts
+}
+
+// Topology is a named graph of cachegroups, which a Delivery Service may use instead of the parent cachegroups of its servers' cachegroups.
+type Topology struct {
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Nodes       []TopologyNode `json:"nodes"`
+	LastUpdated *TimeNoMod     `json:"lastUpdated" db:"last_updated"`
+}
+
+// TopologyNode is a cachegroup in a Topology. Parents are the indexes in the Topology's Nodes of the node's primary parent, and optional secondary parent. A node with no parents requests from the origin.
+type TopologyNode struct {
+	Cachegroup string `json:"cachegroup" db:"cachegroup"`
+	Parents    []int  `json:"parents"`
+}
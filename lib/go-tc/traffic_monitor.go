@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 /*
@@ -98,9 +99,13 @@ type TMParameters struct {
 	HealthPollingURL        string `json:"health.polling.url"`
 	HealthPollingFormat     string `json:"health.polling.format"`
 	HealthPollingType       string `json:"health.polling.type"`
-	HistoryCount            int    `json:"history.count"`
-	MinFreeKbps             int64
-	Thresholds              map[string]HealthThreshold `json:"health_threshold"`
+	// HealthPollingPrometheusStats is the JSON object of the prometheus stats format's metric and label mapping, overriding the Traffic Monitor's configured mapping for caches of this profile.
+	HealthPollingPrometheusStats string `json:"health.polling.prometheus_stats"`
+	HistoryCount                 int    `json:"history.count"`
+	MinFreeKbps                  int64
+	Thresholds                   map[string]HealthThreshold `json:"health_threshold"`
+	// Hysteresis is the profile's default hysteresis, used by every threshold which doesn't set its own.
+	Hysteresis HealthHysteresis `json:"health_hysteresis"`
 }
 
 const DefaultHealthThresholdComparator = "<"
@@ -108,6 +113,95 @@ const DefaultHealthThresholdComparator = "<"
 type HealthThreshold struct {
 	Val        float64
 	Comparator string // TODO change to enum?
+	Hysteresis HealthHysteresis
+}
+
+// HealthHysteresis is how a HealthThreshold damps changes to a cache's availability. The zero value marks a cache unavailable on the first poll exceeding the threshold, and available on the first poll within it.
+type HealthHysteresis struct {
+	// DownCount is the number of consecutive polls which must exceed the threshold to mark the cache unavailable. Values less than 1 are 1.
+	DownCount int
+	// UpCount is the number of consecutive polls which must be within the threshold to mark the cache available again. Values less than 1 are 1.
+	UpCount int
+	// FlapHalfLife is the half-life of the flap penalty. Each time the threshold marks the cache unavailable, the penalty is increased by 1, and the UpCount is multiplied by 2 to the power of the penalty, less 1. If 0, there is no flap penalty.
+	FlapHalfLife time.Duration
+}
+
+// The Profile Parameters which set a HealthHysteresis. Each may be suffixed with `.` and a stat name, e.g. `health.hysteresis.down.loadavg`, to set the hysteresis of that stat's threshold; otherwise, it sets the profile's default.
+const (
+	HealthHysteresisParamPrefix         = "health.hysteresis."
+	HealthHysteresisParamDown           = "down"
+	HealthHysteresisParamUp             = "up"
+	HealthHysteresisParamFlapHalfLifeMS = "flap_half_life_ms"
+)
+
+// setHysteresisParam sets the field of h named by the given health.hysteresis parameter name, without the prefix or stat.
+func setHysteresisParam(h *HealthHysteresis, field string, val int) {
+	switch field {
+	case HealthHysteresisParamDown:
+		h.DownCount = val
+	case HealthHysteresisParamUp:
+		h.UpCount = val
+	case HealthHysteresisParamFlapHalfLifeMS:
+		h.FlapHalfLife = time.Duration(val) * time.Millisecond
+	}
+}
+
+// parseHysteresisParam parses a parameter name with the HealthHysteresisParamPrefix removed, like `down` or `down.loadavg`, and returns the field, and the stat, which is empty for profile defaults. Returns false if the name isn't a hysteresis parameter.
+func parseHysteresisParam(name string) (string, string, bool) {
+	for _, field := range []string{HealthHysteresisParamDown, HealthHysteresisParamUp, HealthHysteresisParamFlapHalfLifeMS} {
+		if name == field {
+			return field, "", true
+		}
+		if strings.HasPrefix(name, field+".") {
+			return field, name[len(field)+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// HealthInterfacesParam is the Profile Parameter which declares the network interfaces Traffic Monitor monitors on the profile's cache servers. Its value is a comma-separated list of interfaces of the form `name[:maxKbps][:optional]`, e.g. `bond0:20000000,eth2:10000000:optional`. Interfaces are required unless marked `optional`.
+const HealthInterfacesParam = "health.interfaces"
+
+// HealthInterfaceOptional marks an interface in a HealthInterfacesParam as not required.
+const HealthInterfaceOptional = "optional"
+
+// ParseTrafficServerInterfaces parses the value of a HealthInterfacesParam. Returns an error if any interface is malformed or duplicated.
+func ParseTrafficServerInterfaces(s string) ([]TrafficServerInterface, error) {
+	interfaces := []TrafficServerInterface{}
+	seen := map[string]struct{}{}
+	for _, infStr := range strings.Split(s, ",") {
+		infStr = strings.TrimSpace(infStr)
+		if infStr == "" {
+			continue
+		}
+		fields := strings.Split(infStr, ":")
+		inf := TrafficServerInterface{Name: strings.TrimSpace(fields[0]), Required: true}
+		if inf.Name == "" {
+			return nil, fmt.Errorf("interface '%s' has no name", infStr)
+		}
+		if _, ok := seen[inf.Name]; ok {
+			return nil, fmt.Errorf("interface '%s' declared more than once", inf.Name)
+		}
+		seen[inf.Name] = struct{}{}
+
+		fields = fields[1:]
+		if len(fields) > 0 && strings.TrimSpace(fields[len(fields)-1]) == HealthInterfaceOptional {
+			inf.Required = false
+			fields = fields[:len(fields)-1]
+		}
+		if len(fields) > 1 {
+			return nil, fmt.Errorf("interface '%s' not of the form `name[:maxKbps][:%s]`", infStr, HealthInterfaceOptional)
+		}
+		if len(fields) == 1 && strings.TrimSpace(fields[0]) != "" { // an empty maxKbps, as in `name::optional`, is omitted
+			maxKbps, err := strconv.ParseInt(strings.TrimSpace(fields[0]), 10, 64)
+			if err != nil || maxKbps < 0 {
+				return nil, fmt.Errorf("interface '%s' max kbps '%s' not a non-negative integer", inf.Name, fields[0])
+			}
+			inf.MaxKbps = maxKbps
+		}
+		interfaces = append(interfaces, inf)
+	}
+	return interfaces, nil
 }
 
 // strToThreshold takes a string like ">=42" and returns a HealthThreshold with a Val of `42` and a Comparator of `">="`. If no comparator exists, `DefaultHealthThresholdComparator` is used. If the string is not of the form "(>|<|)(=|)\d+" an error is returned
@@ -167,6 +261,14 @@ func (params *TMParameters) UnmarshalJSON(bytes []byte) (err error) {
 		}
 	}
 
+	if vi, ok := raw["health.polling.prometheus_stats"]; ok {
+		if v, ok := vi.(string); !ok {
+			return fmt.Errorf("Unmarshalling TMParameters health.polling.prometheus_stats expected string, got %v", vi)
+		} else {
+			params.HealthPollingPrometheusStats = v
+		}
+	}
+
 	if vi, ok := raw["history.count"]; ok {
 		if v, ok := vi.(float64); !ok {
 			return fmt.Errorf("Unmarshalling TMParameters history.count expected integer, got %v", vi)
@@ -188,6 +290,36 @@ func (params *TMParameters) UnmarshalJSON(bytes []byte) (err error) {
 			}
 		}
 	}
+
+	statHysteresisParams := map[string]map[string]int{}
+	for k, v := range raw {
+		if !strings.HasPrefix(k, HealthHysteresisParamPrefix) {
+			continue
+		}
+		field, stat, ok := parseHysteresisParam(k[len(HealthHysteresisParamPrefix):])
+		if !ok {
+			return fmt.Errorf("Unmarshalling TMParameters unknown `%s` parameter '%s'", HealthHysteresisParamPrefix, k)
+		}
+		val, err := strconv.Atoi(fmt.Sprintf("%v", v))
+		if err != nil || val < 0 {
+			return fmt.Errorf("Unmarshalling TMParameters `%s` parameter value not a non-negative integer: '%s' value '%v'", HealthHysteresisParamPrefix, k, v)
+		}
+		if stat == "" {
+			setHysteresisParam(&params.Hysteresis, field, val)
+			continue
+		}
+		if _, ok := statHysteresisParams[stat]; !ok {
+			statHysteresisParams[stat] = map[string]int{}
+		}
+		statHysteresisParams[stat][field] = val
+	}
+	for stat, threshold := range params.Thresholds {
+		threshold.Hysteresis = params.Hysteresis
+		for field, val := range statHysteresisParams[stat] {
+			setHysteresisParam(&threshold.Hysteresis, field, val)
+		}
+		params.Thresholds[stat] = threshold
+	}
 	return nil
 }
 
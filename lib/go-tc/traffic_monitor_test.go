@@ -0,0 +1,113 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTMParametersHysteresis(t *testing.T) {
+	paramsJSON := `{
+		"health.threshold.loadavg": "25.0",
+		"health.threshold.availableBandwidthInKbps": ">1750000",
+		"health.threshold.queryTime": 1000,
+		"health.hysteresis.down": "3",
+		"health.hysteresis.up": 5,
+		"health.hysteresis.up.loadavg": "10",
+		"health.hysteresis.flap_half_life_ms.loadavg": "600000",
+		"health.hysteresis.down.ats.proxy.process.http.current_client_connections": "2"
+	}`
+	params := TMParameters{}
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		t.Fatalf("unmarshalling TMParameters: %v", err)
+	}
+
+	expected := HealthHysteresis{DownCount: 3, UpCount: 5}
+	if params.Hysteresis != expected {
+		t.Errorf("profile hysteresis expected %+v actual %+v", expected, params.Hysteresis)
+	}
+
+	expected = HealthHysteresis{DownCount: 3, UpCount: 10, FlapHalfLife: 10 * time.Minute}
+	if actual := params.Thresholds["loadavg"].Hysteresis; actual != expected {
+		t.Errorf("loadavg hysteresis expected %+v actual %+v", expected, actual)
+	}
+
+	expected = HealthHysteresis{DownCount: 3, UpCount: 5}
+	if actual := params.Thresholds["queryTime"].Hysteresis; actual != expected {
+		t.Errorf("queryTime hysteresis expected %+v actual %+v", expected, actual)
+	}
+
+	if threshold := params.Thresholds["availableBandwidthInKbps"]; threshold.Val != 1750000 || threshold.Comparator != ">" {
+		t.Errorf("availableBandwidthInKbps threshold expected >1750000 actual %+v", threshold)
+	}
+
+	if _, ok := params.Thresholds["ats.proxy.process.http.current_client_connections"]; ok {
+		t.Errorf("hysteresis for a stat without a threshold expected no threshold, actual threshold")
+	}
+}
+
+func TestTMParametersHysteresisInvalid(t *testing.T) {
+	for _, paramsJSON := range []string{
+		`{"health.hysteresis.down": "-1"}`,
+		`{"health.hysteresis.up": "two"}`,
+		`{"health.hysteresis.sideways": "2"}`,
+	} {
+		params := TMParameters{}
+		if err := json.Unmarshal([]byte(paramsJSON), &params); err == nil {
+			t.Errorf("unmarshalling TMParameters %v expected error, actual nil", paramsJSON)
+		}
+	}
+}
+
+func TestParseTrafficServerInterfaces(t *testing.T) {
+	interfaces, err := ParseTrafficServerInterfaces("bond0:20000000, eth2:10000000:optional,eth3,eth4:optional,eth5::optional")
+	if err != nil {
+		t.Fatalf("parsing interfaces: %v", err)
+	}
+	expected := []TrafficServerInterface{
+		{Name: "bond0", MaxKbps: 20000000, Required: true},
+		{Name: "eth2", MaxKbps: 10000000, Required: false},
+		{Name: "eth3", Required: true},
+		{Name: "eth4", Required: false},
+		{Name: "eth5", Required: false},
+	}
+	if len(interfaces) != len(expected) {
+		t.Fatalf("interfaces expected %+v actual %+v", expected, interfaces)
+	}
+	for i, inf := range interfaces {
+		if inf != expected[i] {
+			t.Errorf("interface %d expected %+v actual %+v", i, expected[i], inf)
+		}
+	}
+
+	for _, s := range []string{
+		"bond0:fast",
+		"bond0:-1",
+		"bond0:1000:2000",
+		"bond0,bond0",
+		":1000",
+	} {
+		if _, err := ParseTrafficServerInterfaces(s); err == nil {
+			t.Errorf("parsing interfaces '%v' expected error, actual nil", s)
+		}
+	}
+}
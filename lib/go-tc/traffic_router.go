@@ -143,6 +143,17 @@ type TrafficServer struct {
 	Type             string              `json:"type"`
 	HashID           string              `json:"hashId"`
 	DeliveryServices []tsdeliveryService `json:"deliveryServices,omitempty"` // the deliveryServices key does not exist on mids
+	// Interfaces are the network interfaces Traffic Monitor monitors for health. If empty, only InterfaceName is monitored, at the speed the cache reports.
+	Interfaces []TrafficServerInterface `json:"interfaces,omitempty"`
+}
+
+// TrafficServerInterface is a network interface of a cache server, monitored for health by Traffic Monitor.
+type TrafficServerInterface struct {
+	Name string `json:"name"`
+	// MaxKbps is the interface's maximum bandwidth, in kilobits per second. If 0, the speed the cache reports for the interface is used.
+	MaxKbps int64 `json:"maxKbps,omitempty"`
+	// Required is whether the cache is unavailable when the interface is unhealthy.
+	Required bool `json:"required"`
 }
 
 type tsdeliveryService struct {
@@ -37,6 +37,16 @@ type AstatsSystem struct {
 	LastReload        int    `json:"lastReload"`
 	AstatsLoad        int    `json:"astatsLoad"`
 	NotAvailable      bool   `json:"notAvailable,omitempty"`
+	// Interfaces are the stats of each interface, when more than one is polled. The first is also reported as InfName.
+	Interfaces map[string]AstatsSystemInterface `json:"interfaces,omitempty"`
+}
+
+// AstatsSystemInterface represents the stats of a single network interface returned by the Astats plugin.
+type AstatsSystemInterface struct {
+	// OperState is the interface's Linux operstate, e.g. "up" or "down". It may be empty, if the stats format doesn't have it.
+	OperState  string `json:"operstate,omitempty"`
+	Speed      int    `json:"speed"`
+	ProcNetDev string `json:"proc.net.dev"`
 }
 
 type AStat struct {
@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
@@ -49,6 +50,44 @@ func TestAstats(t *testing.T) {
 	fmt.Printf("Found %v key/val pairs in ats\n", len(aStats.Ats))
 }
 
+func TestAstatsParseInterfaces(t *testing.T) {
+	text := `{ "ats": {
+   "server": "7.1.4"
+  },
+ "system": {
+   "inf.name": "bond0",
+   "inf.speed": 20000,
+   "proc.net.dev": "bond0: 1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0",
+   "interfaces": {
+    "bond0": { "operstate": "up", "speed": 20000, "proc.net.dev": "bond0: 1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0" },
+    "eth2": { "operstate": "down", "speed": 0, "proc.net.dev": "eth2: 3 1 0 0 0 0 0 0 4 1 0 0 0 0 0 0" }
+   },
+   "proc.loadavg": "0.30 0.12 0.21 1/863 1421",
+"something": "here"
+  }
+}
+`
+	err, _, system := astatsParse("cache0", strings.NewReader(text), nil)
+	if err != nil {
+		t.Fatalf("astatsParse error expected nil, actual: %v", err)
+	}
+	if system.InfName != "bond0" || system.InfSpeed != 20000 {
+		t.Errorf("astatsParse inf expected bond0 speed 20000, actual %v speed %v", system.InfName, system.InfSpeed)
+	}
+	expected := map[string]AstatsSystemInterface{
+		"bond0": {OperState: "up", Speed: 20000, ProcNetDev: "bond0: 1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0"},
+		"eth2":  {OperState: "down", Speed: 0, ProcNetDev: "eth2: 3 1 0 0 0 0 0 0 4 1 0 0 0 0 0 0"},
+	}
+	if len(system.Interfaces) != len(expected) {
+		t.Fatalf("astatsParse interfaces expected %+v, actual %+v", expected, system.Interfaces)
+	}
+	for name, inf := range expected {
+		if actual := system.Interfaces[name]; actual != inf {
+			t.Errorf("astatsParse interface %v expected %+v, actual %+v", name, inf, actual)
+		}
+	}
+}
+
 func getMockTODataDSNameDirectMatches() map[tc.DeliveryServiceName]string {
 	return map[tc.DeliveryServiceName]string{
 		"ds0": "ds0.example.invalid",
@@ -103,7 +142,7 @@ func TestAstatsPrecompute(t *testing.T) {
 	infSpeedMbps := 9876554433210
 	system := getMockSystem(infSpeedMbps, outBytes)
 
-	prc := astatsPrecompute(tc.CacheName(cacheName), toData, rawStats, system)
+	prc := astatsPrecompute(tc.CacheName(cacheName), toData, rawStats, system, nil)
 
 	if len(prc.Errors) != 0 {
 		t.Fatalf("astatsPrecompute Errors expected 0, actual: %+v\n", prc.Errors)
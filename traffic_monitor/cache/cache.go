@@ -77,6 +77,8 @@ type Result struct {
 	PollFinished    chan<- uint64
 	PrecomputedData PrecomputedData
 	Available       bool
+	// UsingIPv6 is whether the cache was polled over IPv6, as opposed to IPv4.
+	UsingIPv6 bool
 }
 
 // HasStat returns whether the given stat is in the Result.
@@ -91,13 +93,27 @@ func (result *Result) HasStat(stat string) bool {
 	return false
 }
 
-// Vitals is the vitals data returned from a cache.
+// Vitals is the vitals data returned from a cache. If the cache has monitored Interfaces, the bytes and kbps are the sums of those of its interfaces which haven't failed.
 type Vitals struct {
 	LoadAvg    float64
 	BytesOut   int64
 	BytesIn    int64
 	KbpsOut    int64
 	MaxKbpsOut int64
+	// Interfaces are the vitals of each of the cache's monitored interfaces. It is nil if the server doesn't declare monitored interfaces.
+	Interfaces map[string]InterfaceVitals
+}
+
+// InterfaceVitals is the vitals data of a single monitored network interface of a cache.
+type InterfaceVitals struct {
+	BytesOut   int64
+	BytesIn    int64
+	KbpsOut    int64
+	MaxKbpsOut int64
+	// Required is whether the cache is unavailable when the interface has failed.
+	Required bool
+	// Error is why the interface has failed, such as being missing from the stats, or down. It is nil if the interface is healthy.
+	Error error
 }
 
 // Stat is a generic stat, including the untyped value and the time the stat was taken.
@@ -121,6 +137,46 @@ type Filter interface {
 
 const nsPerMs = 1000000
 
+// InterfaceStatPrefix prefixes the stats of each monitored interface, which are named `interface.<name>.<stat>`. A Threshold on a stat with this prefix and no interface name, like `interface.availableBandwidthInKbps`, is evaluated for each of a cache's required interfaces.
+const InterfaceStatPrefix = "interface."
+
+// InterfaceStatName returns the name of the given stat of the given interface, e.g. `interface.bond0.kbps`.
+func InterfaceStatName(inf string, stat string) string {
+	return InterfaceStatPrefix + inf + "." + stat
+}
+
+type InterfaceStatComputeFunc func(vitals InterfaceVitals) interface{}
+
+// InterfaceComputedStats returns a map of the stats computed by Traffic Monitor for each of a cache's monitored interfaces, mapped to the func to compute them. They are the bandwidth stats of ComputedStats, of the single interface.
+func InterfaceComputedStats() map[string]InterfaceStatComputeFunc {
+	return map[string]InterfaceStatComputeFunc{
+		"availableBandwidthInKbps": func(vitals InterfaceVitals) interface{} {
+			return vitals.MaxKbpsOut - vitals.KbpsOut
+		},
+		"availableBandwidthInMbps": func(vitals InterfaceVitals) interface{} {
+			return (vitals.MaxKbpsOut - vitals.KbpsOut) / 1000
+		},
+		"bandwidth": func(vitals InterfaceVitals) interface{} {
+			return vitals.KbpsOut
+		},
+		"error-string": func(vitals InterfaceVitals) interface{} {
+			if vitals.Error != nil {
+				return vitals.Error.Error()
+			}
+			return "false"
+		},
+		"kbps": func(vitals InterfaceVitals) interface{} {
+			return vitals.KbpsOut
+		},
+		"gbps": func(vitals InterfaceVitals) interface{} {
+			return float64(vitals.KbpsOut) / 1000000.0
+		},
+		"maxKbps": func(vitals InterfaceVitals) interface{} {
+			return vitals.MaxKbpsOut
+		},
+	}
+}
+
 type StatComputeFunc func(resultInfo ResultInfo, serverInfo tc.TrafficServer, serverProfile tc.TMProfile, combinedState tc.IsAvailable) interface{}
 
 // ComputedStats returns a map of cache stats which are computed by Traffic Monitor (rather than returned literally from ATS), mapped to the func to compute them.
@@ -206,13 +262,14 @@ func ComputedStats() map[string]StatComputeFunc {
 }
 
 // Handle handles results fetched from a cache, parsing the raw Reader data and passing it along to a chan for further processing.
-func (handler Handler) Handle(id string, rdr io.Reader, format string, reqTime time.Duration, reqEnd time.Time, reqErr error, pollID uint64, pollFinished chan<- uint64) {
+func (handler Handler) Handle(id string, rdr io.Reader, format string, formatCtx interface{}, reqTime time.Duration, reqEnd time.Time, reqErr error, pollID uint64, usingIPv6 bool, pollFinished chan<- uint64) {
 	log.Debugf("poll %v %v (format '%v') handle start\n", pollID, time.Now(), format)
 	result := Result{
 		ID:           tc.CacheName(id),
 		Time:         reqEnd,
 		RequestTime:  reqTime,
 		PollID:       pollID,
+		UsingIPv6:    usingIPv6,
 		PollFinished: pollFinished,
 	}
 
@@ -232,7 +289,7 @@ func (handler Handler) Handle(id string, rdr io.Reader, format string, reqTime t
 	}
 
 	decodeErr := error(nil)
-	if decodeErr, result.Astats.Ats, result.Astats.System = statDecoder.Parse(result.ID, rdr); decodeErr != nil {
+	if decodeErr, result.Astats.Ats, result.Astats.System = statDecoder.Parse(result.ID, rdr, formatCtx); decodeErr != nil {
 		log.Warnf("%s decode error '%v'\n", id, decodeErr)
 		result.Error = decodeErr
 		handler.resultChan <- result
@@ -249,7 +306,7 @@ func (handler Handler) Handle(id string, rdr io.Reader, format string, reqTime t
 	result.Available = true
 
 	if handler.Precompute() {
-		result.PrecomputedData = statDecoder.Precompute(result.ID, handler.ToData.Get(), result.Astats.Ats, result.Astats.System)
+		result.PrecomputedData = statDecoder.Precompute(result.ID, handler.ToData.Get(), result.Astats.Ats, result.Astats.System, formatCtx)
 	}
 	result.PrecomputedData.Reporting = true
 	result.PrecomputedData.Time = result.Time
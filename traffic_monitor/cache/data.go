@@ -41,6 +41,21 @@ type AvailableStatus struct {
 	UnavailableStat string
 	// Poller is the name of the poller which set this available status
 	Poller string
+	// Thresholds is the hysteresis state of each of the cache's threshold stats. It MUST NOT be modified; to change it, copy it and set the copy.
+	Thresholds map[string]ThresholdStatus
+}
+
+// ThresholdStatus is the hysteresis state of one of a cache's health thresholds.
+type ThresholdStatus struct {
+	// Exceeded is whether this threshold makes the cache unavailable, after hysteresis. It may differ from whether the latest poll exceeded the threshold.
+	Exceeded bool
+	// Violations is the number of consecutive polls which exceeded the threshold.
+	Violations int
+	// Clean is the number of consecutive polls within the threshold, since it was Exceeded.
+	Clean int
+	// FlapPenalty is the flap penalty at FlapPenaltyTime. It decays exponentially from then.
+	FlapPenalty     float64
+	FlapPenaltyTime time.Time
 }
 
 // CacheAvailableStatuses is the available status of each cache.
@@ -106,7 +121,7 @@ func pruneStats(history []ResultStatVal, limit uint64) []ResultStatVal {
 // TODO determine if anything ever needs more than the latest, and if not, change ResultInfo to not be a slice.
 type ResultInfoHistory map[tc.CacheName][]ResultInfo
 
-// ResultInfo contains all the non-stat result info. This includes the cache ID, any errors, the time of the poll, the request time duration, Astats System (Vitals), Poll ID, and Availability.
+// ResultInfo contains all the non-stat result info. This includes the cache ID, any errors, the time of the poll, the request time duration, Astats System (Vitals), Poll ID, Availability, and whether the poll was over IPv6.
 type ResultInfo struct {
 	ID          tc.CacheName
 	Error       error
@@ -116,6 +131,7 @@ type ResultInfo struct {
 	System      AstatsSystem
 	PollID      uint64
 	Available   bool
+	UsingIPv6   bool
 }
 
 func ToInfo(r Result) ResultInfo {
@@ -127,6 +143,7 @@ func ToInfo(r Result) ResultInfo {
 		Vitals:      r.Vitals,
 		PollID:      r.PollID,
 		Available:   r.Available,
+		UsingIPv6:   r.UsingIPv6,
 		System:      r.Astats.System,
 	}
 }
@@ -46,7 +46,7 @@ func init() {
 	AddStatsType("astats", astatsParse, astatsPrecompute)
 }
 
-func astatsParse(cache tc.CacheName, rdr io.Reader) (error, map[string]interface{}, AstatsSystem) {
+func astatsParse(cache tc.CacheName, rdr io.Reader, ctx interface{}) (error, map[string]interface{}, AstatsSystem) {
 	if rdr == nil {
 		log.Warnln(string(cache) + " handle reader nil")
 		return errors.New("handler got nil reader"), nil, AstatsSystem{}
@@ -58,7 +58,7 @@ func astatsParse(cache tc.CacheName, rdr io.Reader) (error, map[string]interface
 	return err, astats.Ats, astats.System
 }
 
-func astatsPrecompute(cache tc.CacheName, toData todata.TOData, rawStats map[string]interface{}, system AstatsSystem) PrecomputedData {
+func astatsPrecompute(cache tc.CacheName, toData todata.TOData, rawStats map[string]interface{}, system AstatsSystem, ctx interface{}) PrecomputedData {
 	stats := map[tc.DeliveryServiceName]*AStat{}
 
 	precomputed := PrecomputedData{}
@@ -37,7 +37,7 @@ func init() {
 	AddStatsType("astats-dsnames", astatsParse, astatsdsnamesPrecompute)
 }
 
-func astatsdsnamesPrecompute(cache tc.CacheName, toData todata.TOData, rawStats map[string]interface{}, system AstatsSystem) PrecomputedData {
+func astatsdsnamesPrecompute(cache tc.CacheName, toData todata.TOData, rawStats map[string]interface{}, system AstatsSystem, ctx interface{}) PrecomputedData {
 	stats := map[tc.DeliveryServiceName]*AStat{}
 	precomputed := PrecomputedData{}
 	var err error
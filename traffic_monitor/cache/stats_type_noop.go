@@ -34,7 +34,7 @@ func init() {
 	AddStatsType(StatsTypeNOOP, noopParse, noopPrecompute)
 }
 
-func noopParse(cache tc.CacheName, r io.Reader) (error, map[string]interface{}, AstatsSystem) {
+func noopParse(cache tc.CacheName, r io.Reader, ctx interface{}) (error, map[string]interface{}, AstatsSystem) {
 	// we need to make a fake system, so the health parse succeeds
 	return nil, map[string]interface{}{}, AstatsSystem{
 		ProcLoadavg: "0.10 0.05 0.05 1/1000 30000",
@@ -44,6 +44,6 @@ func noopParse(cache tc.CacheName, r io.Reader) (error, map[string]interface{},
 	}
 }
 
-func noopPrecompute(cache tc.CacheName, toData todata.TOData, rawStats map[string]interface{}, system AstatsSystem) PrecomputedData {
+func noopPrecompute(cache tc.CacheName, toData todata.TOData, rawStats map[string]interface{}, system AstatsSystem, ctx interface{}) PrecomputedData {
 	return PrecomputedData{DeliveryServiceStats: map[tc.DeliveryServiceName]*AStat{}}
 }
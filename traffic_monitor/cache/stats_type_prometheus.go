@@ -0,0 +1,427 @@
+package cache
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// stats_type_prometheus is a Stats format for caches which publish the Prometheus text exposition format, or OpenMetrics text.
+//
+// Samples are of the form `metric_name{label="value",...} number [timestamp]`.
+//
+// Which metrics and labels are delivery service remap stats, and which are system stats, is configured by the Traffic Monitor config `prometheus_stats`, see config.PrometheusStatsMapping, overridden for caches of a profile by its `health.polling.prometheus_stats` Parameter. It defaults to node_exporter system metrics.
+// The poller gives the mapping of the cache's profile to the Parse and Precompute funcs as their context.
+//
+// Remap stats are put in the raw stats with astats names, of the form `"plugin.remap_stats.remap-label-value.stat-name"`, where `stat-name` is one of `in_bytes`, `out_bytes`, `status_2xx`, `status_3xx`, `status_4xx`, `status_5xx`, summed over any other labels.
+// All other samples are put in the raw stats with their metric name, followed by their labels sorted by name if they have any, e.g. `node_cpu_seconds_total{cpu="0",mode="idle"}`, so they may be used by Parameter Thresholds.
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+	"github.com/apache/trafficcontrol/traffic_monitor/dsdata"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+const StatsTypePrometheus = "prometheus"
+
+func init() {
+	AddStatsType(StatsTypePrometheus, prometheusParse, prometheusPrecompute)
+}
+
+// prometheusSample is a single parsed sample line.
+type prometheusSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// prometheusMapping returns the mapping given as the stats type context, or the default mapping if none was given.
+func prometheusMapping(ctx interface{}) config.PrometheusStatsMapping {
+	if mapping, ok := ctx.(config.PrometheusStatsMapping); ok {
+		return mapping
+	}
+	return config.DefaultPrometheusStatsMapping
+}
+
+func prometheusParse(cache tc.CacheName, rdr io.Reader, ctx interface{}) (error, map[string]interface{}, AstatsSystem) {
+	if rdr == nil {
+		log.Warnln(string(cache) + " handle reader nil")
+		return errors.New("handler got nil reader"), nil, AstatsSystem{}
+	}
+
+	mapping := prometheusMapping(ctx)
+	stats := map[string]interface{}{}
+	loadAvgs := [3]string{"0", "0", "0"}
+	haveLoadAvg := false
+	rxBytes := map[string]float64{}
+	txBytes := map[string]float64{}
+	speeds := map[string]float64{}
+
+	scanner := bufio.NewScanner(rdr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sample, err := prometheusParseSample(line)
+		if err != nil {
+			return errors.New("parsing line '" + line + "': " + err.Error()), nil, AstatsSystem{}
+		}
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			continue // not representable in JSON, and never useful as a stat or Threshold
+		}
+
+		switch sample.Name {
+		case mapping.LoadAvg1Metric:
+			loadAvgs[0] = strconv.FormatFloat(sample.Value, 'f', -1, 64)
+			haveLoadAvg = true
+		case mapping.LoadAvg5Metric:
+			loadAvgs[1] = strconv.FormatFloat(sample.Value, 'f', -1, 64)
+		case mapping.LoadAvg15Metric:
+			loadAvgs[2] = strconv.FormatFloat(sample.Value, 'f', -1, 64)
+		case mapping.InterfaceRxBytesMetric:
+			rxBytes[sample.Labels[mapping.InterfaceLabel]] = sample.Value
+		case mapping.InterfaceTxBytesMetric:
+			txBytes[sample.Labels[mapping.InterfaceLabel]] = sample.Value
+		case mapping.InterfaceSpeedMetric:
+			speeds[sample.Labels[mapping.InterfaceLabel]] = sample.Value
+		}
+
+		if remap, ok := sample.Labels[mapping.RemapLabel]; ok && remap != "" {
+			statName := ""
+			if sample.Name == mapping.StatusMetric {
+				statName = prometheusStatusStatName(sample.Labels[mapping.StatusLabel])
+			} else {
+				statName = mapping.RemapStats[sample.Name]
+			}
+			if statName != "" {
+				rawName := "plugin.remap_stats." + remap + "." + statName
+				sum, _ := stats[rawName].(float64)
+				stats[rawName] = sum + sample.Value
+				continue
+			}
+		}
+
+		stats[prometheusRawStatName(sample)] = sample.Value
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.New("reading: " + err.Error()), nil, AstatsSystem{}
+	}
+
+	system := AstatsSystem{}
+	if haveLoadAvg {
+		system.ProcLoadavg = strings.Join(loadAvgs[:], " ") + " 0/0 0" // runnable/total processes and last pid aren't used
+	}
+
+	system.InfName = mapping.Interface
+	if system.InfName == "" {
+		system.InfName = prometheusBusiestInterface(txBytes)
+	}
+	if system.InfName != "" {
+		if _, ok := txBytes[system.InfName]; ok {
+			system.ProcNetDev = prometheusProcNetDev(system.InfName, rxBytes[system.InfName], txBytes[system.InfName])
+		}
+		system.InfSpeed = int(speeds[system.InfName] * mapping.InterfaceSpeedMbpsMultiplier)
+	}
+	if len(txBytes) > 1 {
+		system.Interfaces = make(map[string]AstatsSystemInterface, len(txBytes))
+		for name, tx := range txBytes {
+			system.Interfaces[name] = AstatsSystemInterface{
+				Speed:      int(speeds[name] * mapping.InterfaceSpeedMbpsMultiplier),
+				ProcNetDev: prometheusProcNetDev(name, rxBytes[name], tx),
+			}
+		}
+	}
+	return nil, stats, system
+}
+
+// prometheusProcNetDev returns a /proc/net/dev line for the given interface, with only the receive bytes, which is the 1st field, and the transmit bytes, which is the 9th.
+func prometheusProcNetDev(name string, rxBytes float64, txBytes float64) string {
+	return fmt.Sprintf("%s:%d 0 0 0 0 0 0 0 %d 0 0 0 0 0 0 0", name, uint64(rxBytes), uint64(txBytes))
+}
+
+// prometheusBusiestInterface returns the interface with the most bytes transmitted, excluding loopback. Ties are broken by name, so the choice is stable.
+func prometheusBusiestInterface(txBytes map[string]float64) string {
+	names := []string{}
+	for name := range txBytes {
+		if name == "lo" || name == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	busiest := ""
+	for _, name := range names {
+		if busiest == "" || txBytes[name] > txBytes[busiest] {
+			busiest = name
+		}
+	}
+	return busiest
+}
+
+// prometheusStatusStatName returns the astats remap stat name for the given status code label value, which may be a code like "200" or a class like "2xx".
+// Returns the empty string for codes astats doesn't count.
+func prometheusStatusStatName(code string) string {
+	if code == "" {
+		return ""
+	}
+	switch code[0] {
+	case '2', '3', '4', '5':
+		return "status_" + code[:1] + "xx"
+	}
+	return ""
+}
+
+// prometheusRawStatName returns the name of the sample in the raw stats: its metric name, followed by its labels sorted by name, if it has any.
+func prometheusRawStatName(sample prometheusSample) string {
+	if len(sample.Labels) == 0 {
+		return sample.Name
+	}
+	labelNames := make([]string, 0, len(sample.Labels))
+	for name := range sample.Labels {
+		labelNames = append(labelNames, name)
+	}
+	sort.Strings(labelNames)
+	labels := make([]string, 0, len(labelNames))
+	for _, name := range labelNames {
+		labels = append(labels, name+"="+strconv.Quote(sample.Labels[name]))
+	}
+	return sample.Name + "{" + strings.Join(labels, ",") + "}"
+}
+
+// prometheusParseSample parses a single sample line, of the form `name{label="value",...} value [timestamp]`.
+func prometheusParseSample(line string) (prometheusSample, error) {
+	sample := prometheusSample{Labels: map[string]string{}}
+
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd == -1 {
+		return sample, errors.New("no value")
+	}
+	sample.Name = line[:nameEnd]
+	if sample.Name == "" {
+		return sample, errors.New("no metric name")
+	}
+	rest := line[nameEnd:]
+
+	if strings.HasPrefix(rest, "{") {
+		labelsEnd := 0
+		err := error(nil)
+		if sample.Labels, labelsEnd, err = prometheusParseLabels(rest); err != nil {
+			return sample, errors.New("parsing labels: " + err.Error())
+		}
+		rest = rest[labelsEnd:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return sample, errors.New("malformed value and timestamp '" + rest + "'")
+	}
+	value, err := prometheusParseValue(fields[0])
+	if err != nil {
+		return sample, errors.New("parsing value '" + fields[0] + "': " + err.Error())
+	}
+	sample.Value = value
+	return sample, nil
+}
+
+// prometheusParseLabels parses the labels at the start of str, which must begin with '{'. Returns the labels, and the index in str after the closing '}'.
+func prometheusParseLabels(str string) (map[string]string, int, error) {
+	labels := map[string]string{}
+	i := 1 // skip '{'
+	for {
+		for i < len(str) && (str[i] == ' ' || str[i] == ',') {
+			i++
+		}
+		if i >= len(str) {
+			return nil, 0, errors.New("unterminated labels")
+		}
+		if str[i] == '}' {
+			return labels, i + 1, nil
+		}
+
+		nameEnd := strings.IndexAny(str[i:], "= \t")
+		if nameEnd == -1 {
+			return nil, 0, errors.New("label with no value")
+		}
+		name := str[i : i+nameEnd]
+		i += nameEnd
+		for i < len(str) && (str[i] == ' ' || str[i] == '\t') {
+			i++
+		}
+		if i+1 >= len(str) || str[i] != '=' || str[i+1] != '"' {
+			return nil, 0, errors.New("label '" + name + "' value not quoted")
+		}
+		i += 2 // skip '="'
+
+		value := strings.Builder{}
+		for {
+			if i >= len(str) {
+				return nil, 0, errors.New("label '" + name + "' value unterminated")
+			}
+			c := str[i]
+			if c == '"' {
+				i++
+				break
+			}
+			if c == '\\' && i+1 < len(str) {
+				i++
+				switch str[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(str[i]) // '\\' and '"'
+				}
+				i++
+				continue
+			}
+			value.WriteByte(c)
+			i++
+		}
+		labels[name] = value.String()
+	}
+}
+
+// prometheusParseValue parses a sample value, which is a float, or one of NaN, +Inf, or -Inf.
+func prometheusParseValue(str string) (float64, error) {
+	switch str {
+	case "NaN":
+		return math.NaN(), nil
+	case "+Inf", "Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(str, 64)
+}
+
+func prometheusPrecompute(cache tc.CacheName, toData todata.TOData, rawStats map[string]interface{}, system AstatsSystem, ctx interface{}) PrecomputedData {
+	stats := map[tc.DeliveryServiceName]*AStat{}
+
+	precomputed := PrecomputedData{}
+	var err error
+	if precomputed.OutBytes, err = prometheusOutBytes(system.ProcNetDev, system.InfName); err != nil {
+		precomputed.OutBytes = 0
+		log.Errorf("prometheusPrecompute %s handle precomputing outbytes '%v'\n", cache, err)
+	}
+
+	kbpsInMbps := int64(1000)
+	precomputed.MaxKbps = int64(system.InfSpeed) * kbpsInMbps
+
+	mapping := prometheusMapping(ctx)
+
+	for stat, value := range rawStats {
+		stats, err = prometheusProcessStat(cache, stats, toData, stat, value, mapping)
+		if err != nil && err != dsdata.ErrNotProcessedStat {
+			log.Infof("precomputing cache %v stat %v value %v error %v", cache, stat, value, err)
+			precomputed.Errors = append(precomputed.Errors, err)
+		}
+	}
+	precomputed.DeliveryServiceStats = stats
+	return precomputed
+}
+
+// prometheusOutBytes takes the proc.net.dev string created by prometheusParse, and the interface name, and returns the bytes field
+// NOTE this is superficially duplicated from astatsOutBytes, but they are conceptually different, because the `astats` format changing should not affect the `prometheus` format. They MUST be kept separate.
+func prometheusOutBytes(procNetDev, iface string) (int64, error) {
+	if procNetDev == "" {
+		return 0, fmt.Errorf("procNetDev empty")
+	}
+	if iface == "" {
+		return 0, fmt.Errorf("iface empty")
+	}
+	if !strings.HasPrefix(procNetDev, iface+":") {
+		return 0, fmt.Errorf("interface '%s' not found in proc.net.dev '%s'", iface, procNetDev)
+	}
+	fields := strings.Fields(procNetDev[len(iface)+1:])
+	if len(fields) < 9 {
+		return 0, fmt.Errorf("proc.net.dev iface '%v' unknown format '%s'", iface, procNetDev)
+	}
+	return strconv.ParseInt(fields[8], 10, 64)
+}
+
+// prometheusProcessStat adds the given raw stat to its delivery service's stats, if it's a remap stat. Other stats return dsdata.ErrNotProcessedStat.
+func prometheusProcessStat(server tc.CacheName, stats map[tc.DeliveryServiceName]*AStat, toData todata.TOData, stat string, value interface{}, mapping config.PrometheusStatsMapping) (map[tc.DeliveryServiceName]*AStat, error) {
+	const remapPrefix = "plugin.remap_stats."
+	if !strings.HasPrefix(stat, remapPrefix) {
+		return stats, dsdata.ErrNotProcessedStat
+	}
+	remapStat := stat[len(remapPrefix):]
+	lastDot := strings.LastIndex(remapStat, ".")
+	if lastDot == -1 {
+		return stats, fmt.Errorf("stat '%s' has no remap_stats name part", stat)
+	}
+	remap := remapStat[:lastDot]
+	statName := remapStat[lastDot+1:]
+
+	ds := tc.DeliveryServiceName("")
+	if mapping.RemapLabelIsDSName {
+		ds = tc.DeliveryServiceName(remap)
+		if _, ok := toData.DeliveryServiceTypes[ds]; !ok {
+			return stats, fmt.Errorf("no delivery service match for name '%v' stat '%v'", ds, stat)
+		}
+	} else {
+		// the FQDN is `subsubdomain`.`subdomain`.`domain`. For a HTTP delivery service, `subsubdomain` will be the cache hostname; for a DNS delivery service, it will be `edge`. Then, `subdomain` is the delivery service regex.
+		fqdnParts := strings.SplitN(remap, ".", 3)
+		if len(fqdnParts) < 3 {
+			return stats, fmt.Errorf("stat '%s' remap '%s' is not a delivery service FQDN", stat, remap)
+		}
+		ok := false
+		if ds, ok = toData.DeliveryServiceRegexes.DeliveryService(fqdnParts[2], fqdnParts[1], fqdnParts[0]); !ok || ds == "" {
+			return stats, fmt.Errorf("no delivery service match for fqdn '%v' stat '%v'", remap, stat)
+		}
+	}
+
+	v, ok := value.(float64)
+	if !ok {
+		return stats, fmt.Errorf("stat '%s' value expected float actual '%v' type %T", stat, value, value)
+	}
+
+	dsStat, ok := stats[ds]
+	if !ok {
+		dsStat = &AStat{}
+		stats[ds] = dsStat
+	}
+
+	switch statName {
+	case "in_bytes":
+		dsStat.InBytes += uint64(v)
+	case "out_bytes":
+		dsStat.OutBytes += uint64(v)
+	case "status_2xx":
+		dsStat.Status2xx += uint64(v)
+	case "status_3xx":
+		dsStat.Status3xx += uint64(v)
+	case "status_4xx":
+		dsStat.Status4xx += uint64(v)
+	case "status_5xx":
+		dsStat.Status5xx += uint64(v)
+	default:
+		return stats, fmt.Errorf("unknown remap stat '%s'", statName)
+	}
+	return stats, nil
+}
@@ -0,0 +1,173 @@
+package cache
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+)
+
+const testPrometheusStats = `# HELP node_load1 1m load average.
+# TYPE node_load1 gauge
+node_load1 1.5
+node_load5 0.75
+node_load15 0.25
+# TYPE node_network_receive_bytes_total counter
+node_network_receive_bytes_total{device="bond0"} 123456
+node_network_receive_bytes_total{device="lo"} 999999999
+node_network_transmit_bytes_total{device="bond0"} 7.89e+06
+node_network_transmit_bytes_total{device="lo"} 999999999
+node_network_transmit_bytes_total{device="eth1"} 1000
+node_network_speed_bytes{device="bond0"} 1.25e+09
+trafficserver_remap_in_bytes_total{remap="ds0.example.invalid",method="GET"} 100
+trafficserver_remap_in_bytes_total{remap="ds0.example.invalid",method="HEAD"} 20
+trafficserver_remap_out_bytes_total{remap="ds0.example.invalid"} 5000
+trafficserver_remap_responses_total{remap="ds0.example.invalid",code="200"} 40
+trafficserver_remap_responses_total{remap="ds0.example.invalid",code="206"} 2
+trafficserver_remap_responses_total{remap="ds1.example.invalid",code="503"} 7
+trafficserver_remap_responses_total{remap="ds1.example.invalid",code="1xx"} 3
+proxy_process_http_current_client_connections{label="a \"quoted\", value"} 12 1571616000000
+proxy_process_cache_ram_usage NaN
+# EOF
+`
+
+func TestPrometheusParse(t *testing.T) {
+	err, stats, system := prometheusParse("cache0", strings.NewReader(testPrometheusStats), nil)
+	if err != nil {
+		t.Fatalf("prometheusParse error expected nil, actual: %v", err)
+	}
+
+	expectedStats := map[string]float64{
+		"plugin.remap_stats.ds0.example.invalid.in_bytes":                             120,
+		"plugin.remap_stats.ds0.example.invalid.out_bytes":                            5000,
+		"plugin.remap_stats.ds0.example.invalid.status_2xx":                           42,
+		"plugin.remap_stats.ds1.example.invalid.status_5xx":                           7,
+		`proxy_process_http_current_client_connections{label="a \"quoted\", value"}`:  12,
+		`trafficserver_remap_responses_total{code="1xx",remap="ds1.example.invalid"}`: 3,
+		"node_load1": 1.5,
+	}
+	for name, expected := range expectedStats {
+		if actual, ok := stats[name].(float64); !ok || actual != expected {
+			t.Errorf("stat '%v' expected %v, actual %v", name, expected, stats[name])
+		}
+	}
+	if _, ok := stats["proxy_process_cache_ram_usage"]; ok {
+		t.Errorf("stat with NaN value expected omitted, actual %v", stats["proxy_process_cache_ram_usage"])
+	}
+
+	if system.InfName != "bond0" {
+		t.Errorf("system interface expected busiest non-loopback 'bond0', actual '%v'", system.InfName)
+	}
+	if system.InfSpeed != 10000 {
+		t.Errorf("system interface speed expected 10000 Mbps, actual %v", system.InfSpeed)
+	}
+	if system.ProcLoadavg != "1.5 0.75 0.25 0/0 0" {
+		t.Errorf("system loadavg expected '1.5 0.75 0.25 0/0 0', actual '%v'", system.ProcLoadavg)
+	}
+	if fields := strings.Fields(strings.TrimPrefix(system.ProcNetDev, "bond0:")); len(fields) < 9 || fields[0] != "123456" || fields[8] != "7890000" {
+		t.Errorf("system proc.net.dev expected bond0 receive 123456 transmit 7890000, actual '%v'", system.ProcNetDev)
+	}
+	if inf, ok := system.Interfaces["eth1"]; !ok || inf.ProcNetDev != prometheusProcNetDev("eth1", 0, 1000) || inf.Speed != 0 {
+		t.Errorf("system interface eth1 expected transmit 1000 speed 0, actual %+v", system.Interfaces["eth1"])
+	}
+	if inf := system.Interfaces["bond0"]; inf.ProcNetDev != system.ProcNetDev || inf.Speed != 10000 {
+		t.Errorf("system interface bond0 expected '%v' speed 10000, actual %+v", system.ProcNetDev, inf)
+	}
+}
+
+func TestPrometheusParseMalformed(t *testing.T) {
+	for _, text := range []string{
+		`metric{label="unterminated} 1`,
+		`metric{label=unquoted} 1`,
+		`metric`,
+		`metric one`,
+	} {
+		if err, _, _ := prometheusParse("cache0", strings.NewReader(text), nil); err == nil {
+			t.Errorf("prometheusParse '%v' expected error, actual nil", text)
+		}
+	}
+}
+
+func TestPrometheusPrecompute(t *testing.T) {
+	dsNameFQDNs := getMockTODataDSNameDirectMatches()
+	toData := getMockTOData(dsNameFQDNs)
+
+	err, stats, system := prometheusParse("cache0", strings.NewReader(testPrometheusStats), nil)
+	if err != nil {
+		t.Fatalf("prometheusParse error expected nil, actual: %v", err)
+	}
+
+	prc := prometheusPrecompute(tc.CacheName("cache0"), toData, stats, system, nil)
+	if len(prc.Errors) != 0 {
+		t.Fatalf("prometheusPrecompute Errors expected 0, actual: %+v", prc.Errors)
+	}
+	if prc.OutBytes != 7890000 {
+		t.Errorf("prometheusPrecompute OutBytes expected 7890000, actual %v", prc.OutBytes)
+	}
+	if prc.MaxKbps != 10000000 {
+		t.Errorf("prometheusPrecompute MaxKbps expected 10000000, actual %v", prc.MaxKbps)
+	}
+
+	ds0 := prc.DeliveryServiceStats["ds0"]
+	if ds0 == nil || ds0.InBytes != 120 || ds0.OutBytes != 5000 || ds0.Status2xx != 42 {
+		t.Errorf("prometheusPrecompute ds0 expected in 120 out 5000 2xx 42, actual %+v", ds0)
+	}
+	ds1 := prc.DeliveryServiceStats["ds1"]
+	if ds1 == nil || ds1.Status5xx != 7 {
+		t.Errorf("prometheusPrecompute ds1 expected 5xx 7, actual %+v", ds1)
+	}
+}
+
+func TestPrometheusMappingCtx(t *testing.T) {
+	mapping, err := config.DefaultPrometheusStatsMapping.WithOverrides(`{"remap_label": "ds", "remap_label_is_ds_name": true, "remap_stats": {"ds_bytes_total": "out_bytes"}}`)
+	if err != nil {
+		t.Fatalf("WithOverrides error expected nil, actual: %v", err)
+	}
+	if mapping.StatusMetric != config.DefaultPrometheusStatsMapping.StatusMetric {
+		t.Errorf("WithOverrides fields not given expected default, actual status_metric '%v'", mapping.StatusMetric)
+	}
+	if len(mapping.RemapStats) != 1 || len(config.DefaultPrometheusStatsMapping.RemapStats) != 2 {
+		t.Errorf("WithOverrides remap_stats expected to replace the default without modifying it, actual %v default %v", mapping.RemapStats, config.DefaultPrometheusStatsMapping.RemapStats)
+	}
+
+	toData := getMockTOData(getMockTODataDSNameDirectMatches())
+	toData.DeliveryServiceTypes["ds0"] = tc.DSTypeCategoryHTTP
+
+	text := `ds_bytes_total{ds="ds0"} 42
+trafficserver_remap_out_bytes_total{remap="ds1.example.invalid"} 5000
+`
+	err, stats, system := prometheusParse("cache0", strings.NewReader(text), mapping)
+	if err != nil {
+		t.Fatalf("prometheusParse error expected nil, actual: %v", err)
+	}
+	prc := prometheusPrecompute(tc.CacheName("cache0"), toData, stats, system, mapping)
+	if len(prc.Errors) != 0 {
+		t.Fatalf("prometheusPrecompute Errors expected 0, actual: %+v", prc.Errors)
+	}
+	if ds0 := prc.DeliveryServiceStats["ds0"]; ds0 == nil || ds0.OutBytes != 42 {
+		t.Errorf("prometheusPrecompute with mapping ctx ds0 expected out 42, actual %+v", ds0)
+	}
+	if ds1 := prc.DeliveryServiceStats["ds1"]; ds1 != nil {
+		t.Errorf("prometheusPrecompute with mapping ctx expected default remap metrics ignored, actual ds1 %+v", ds1)
+	}
+}
@@ -52,12 +52,12 @@ type StatsTypeDecoder struct {
 	Precompute StatsTypePrecomputer
 }
 
-// StatsTypeParser takes the bytes returned from the cache's stats endpoint, along with the cache name, and returns the map of raw stats (whose names must be strings, and values may be any primitive type but MUST be float64 if they are used by a Parameter Threshold) and System information.
-type StatsTypeParser func(cache tc.CacheName, r io.Reader) (error, map[string]interface{}, AstatsSystem)
+// StatsTypeParser takes the bytes returned from the cache's stats endpoint, along with the cache name and the context of the stats type configured for the cache's profile, if any, and returns the map of raw stats (whose names must be strings, and values may be any primitive type but MUST be float64 if they are used by a Parameter Threshold) and System information.
+type StatsTypeParser func(cache tc.CacheName, r io.Reader, ctx interface{}) (error, map[string]interface{}, AstatsSystem)
 
-// StatsTypePrecomputer takes the cache name, the time the given stats were received, the Traffic Ops data, and the raw stats and system information created by Parse, and returns the PrecomputedData. Note this will only be called for Stats polls, not Health polls. Note errors should be returned in PrecomputedData.Errors
+// StatsTypePrecomputer takes the cache name, the time the given stats were received, the Traffic Ops data, the raw stats and system information created by Parse, and the same context given to Parse, and returns the PrecomputedData. Note this will only be called for Stats polls, not Health polls. Note errors should be returned in PrecomputedData.Errors
 //
-type StatsTypePrecomputer func(cache tc.CacheName, toData todata.TOData, stats map[string]interface{}, system AstatsSystem) PrecomputedData
+type StatsTypePrecomputer func(cache tc.CacheName, toData todata.TOData, stats map[string]interface{}, system AstatsSystem, ctx interface{}) PrecomputedData
 
 // StatsTypeDecoders holds the functions for parsing cache stats. This is not const, because Go doesn't allow constant maps. This is populated on startup, and MUST NOT be modified after startup.
 var StatsTypeDecoders = map[string]StatsTypeDecoder{}
@@ -20,6 +20,7 @@ package config
  */
 
 import (
+	"errors"
 	"io/ioutil"
 	"time"
 
@@ -54,7 +55,14 @@ type Config struct {
 	HTTPTimeout                  time.Duration `json:"-"`
 	PeerPollingInterval          time.Duration `json:"-"`
 	PeerOptimistic               bool          `json:"peer_optimistic"`
+	PeerConsensus                PeerConsensus `json:"peer_consensus"`
+	PeerQuorum                   int           `json:"peer_quorum"`
+	PeerStreaming                bool          `json:"peer_streaming"`
 	MaxEvents                    uint64        `json:"max_events"`
+	EventStoreDir                string        `json:"event_store_dir"`
+	EventStoreMaxFileBytes       uint64        `json:"event_store_max_file_bytes"`
+	EventStoreMaxFileAge         time.Duration `json:"-"`
+	EventStoreMaxAge             time.Duration `json:"-"`
 	MaxStatHistory               uint64        `json:"max_stat_history"`
 	MaxHealthHistory             uint64        `json:"max_health_history"`
 	HealthFlushInterval          time.Duration `json:"-"`
@@ -75,6 +83,80 @@ type Config struct {
 	CRConfigBackupFile           string        `json:"crconfig_backup_file"`
 	TMConfigBackupFile           string        `json:"tmconfig_backup_file"`
 	TrafficOpsDiskRetryMax       uint64        `json:"-"`
+
+	PrometheusStats PrometheusStatsMapping `json:"prometheus_stats"`
+}
+
+// PeerConsensus is how the health of a cache is combined from the local and peer Traffic Monitors.
+type PeerConsensus string
+
+const (
+	// PeerConsensusOptimistic marks a cache available if it's available locally or on any available peer.
+	PeerConsensusOptimistic = PeerConsensus("optimistic")
+	// PeerConsensusQuorum marks a cache available only if a quorum of the reachable Traffic Monitors, including this one, vote it available.
+	// The quorum is PeerQuorum monitors, or a majority of those reachable if PeerQuorum is 0.
+	// If a majority of the ONLINE Traffic Monitors aren't reachable, for example because of a network partition, or there aren't enough votes for a quorum, the optimistic consensus is used instead.
+	PeerConsensusQuorum = PeerConsensus("quorum")
+)
+
+// PrometheusStatsMapping maps the metrics and labels of caches polled with the "prometheus" stats type to Traffic Monitor's delivery service and system stats.
+type PrometheusStatsMapping struct {
+	// RemapLabel is the label whose value is the remap rule of delivery service metrics. By default, it's the remap rule's FQDN, as in astats.
+	RemapLabel string `json:"remap_label"`
+	// RemapLabelIsDSName is whether the RemapLabel value is the delivery service name (xml_id), rather than the FQDN.
+	RemapLabelIsDSName bool `json:"remap_label_is_ds_name"`
+	// RemapStats maps metric names to the astats remap stat they are, one of in_bytes, out_bytes, status_2xx, status_3xx, status_4xx, or status_5xx.
+	RemapStats map[string]string `json:"remap_stats"`
+	// StatusMetric is a metric of responses per remap rule, labelled with the status code in StatusLabel, counted into the status_Nxx stats.
+	StatusMetric string `json:"status_metric"`
+	StatusLabel  string `json:"status_label"`
+	// LoadAvg1Metric, LoadAvg5Metric, and LoadAvg15Metric are the system load averages.
+	LoadAvg1Metric  string `json:"load_avg_1_metric"`
+	LoadAvg5Metric  string `json:"load_avg_5_metric"`
+	LoadAvg15Metric string `json:"load_avg_15_metric"`
+	// InterfaceLabel is the label of the interface metrics whose value is the interface name.
+	InterfaceLabel string `json:"interface_label"`
+	// Interface is the interface to use for system bandwidth. If empty, the interface with the most bytes transmitted is used.
+	Interface              string `json:"interface"`
+	InterfaceRxBytesMetric string `json:"interface_rx_bytes_metric"`
+	InterfaceTxBytesMetric string `json:"interface_tx_bytes_metric"`
+	InterfaceSpeedMetric   string `json:"interface_speed_metric"`
+	// InterfaceSpeedMbpsMultiplier converts the InterfaceSpeedMetric to megabits per second. The default converts bytes per second.
+	InterfaceSpeedMbpsMultiplier float64 `json:"interface_speed_mbps_multiplier"`
+}
+
+// DefaultPrometheusStatsMapping is the default mapping for the "prometheus" stats type.
+// System metrics are those of the Prometheus node_exporter.
+var DefaultPrometheusStatsMapping = PrometheusStatsMapping{
+	RemapLabel:         "remap",
+	RemapLabelIsDSName: false,
+	RemapStats: map[string]string{
+		"trafficserver_remap_in_bytes_total":  "in_bytes",
+		"trafficserver_remap_out_bytes_total": "out_bytes",
+	},
+	StatusMetric:                 "trafficserver_remap_responses_total",
+	StatusLabel:                  "code",
+	LoadAvg1Metric:               "node_load1",
+	LoadAvg5Metric:               "node_load5",
+	LoadAvg15Metric:              "node_load15",
+	InterfaceLabel:               "device",
+	InterfaceRxBytesMetric:       "node_network_receive_bytes_total",
+	InterfaceTxBytesMetric:       "node_network_transmit_bytes_total",
+	InterfaceSpeedMetric:         "node_network_speed_bytes",
+	InterfaceSpeedMbpsMultiplier: 8.0 / 1000000.0,
+}
+
+// WithOverrides returns the mapping with the fields of the given JSON object, such as a profile's health.polling.prometheus_stats Parameter, replacing its own. As in the config file, a given remap_stats replaces rather than adds to the remap stats.
+func (m PrometheusStatsMapping) WithOverrides(overrides string) (PrometheusStatsMapping, error) {
+	remapStats := m.RemapStats
+	m.RemapStats = nil
+	if err := jsoniter.ConfigFastest.Unmarshal([]byte(overrides), &m); err != nil {
+		return PrometheusStatsMapping{}, err
+	}
+	if m.RemapStats == nil {
+		m.RemapStats = remapStats
+	}
+	return m, nil
 }
 
 func (c Config) ErrorLog() log.LogLocation   { return log.LogLocation(c.LogLocationError) }
@@ -92,6 +174,10 @@ var DefaultConfig = Config{
 	PeerPollingInterval:          5 * time.Second,
 	PeerOptimistic:               true,
 	MaxEvents:                    200,
+	EventStoreDir:                "",
+	EventStoreMaxFileBytes:       10 * 1024 * 1024,
+	EventStoreMaxFileAge:         24 * time.Hour,
+	EventStoreMaxAge:             30 * 24 * time.Hour,
 	MaxStatHistory:               5,
 	MaxHealthHistory:             5,
 	HealthFlushInterval:          200 * time.Millisecond,
@@ -112,6 +198,8 @@ var DefaultConfig = Config{
 	CRConfigBackupFile:           CRConfigBackupFile,
 	TMConfigBackupFile:           TMConfigBackupFile,
 	TrafficOpsDiskRetryMax:       2,
+	PeerConsensus:                PeerConsensusOptimistic,
+	PrometheusStats:              DefaultPrometheusStatsMapping,
 }
 
 // MarshalJSON marshals custom millisecond durations. Aliasing inspired by http://choly.ca/post/go-json-marshalling/
@@ -130,6 +218,8 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		StatBufferIntervalMs           uint64 `json:"stat_buffer_interval_ms"`
 		ServeReadTimeoutMs             uint64 `json:"serve_read_timeout_ms"`
 		ServeWriteTimeoutMs            uint64 `json:"serve_write_timeout_ms"`
+		EventStoreMaxFileAgeMs         uint64 `json:"event_store_max_file_age_ms"`
+		EventStoreMaxAgeMs             uint64 `json:"event_store_max_age_ms"`
 		*Alias
 	}{
 		CacheHealthPollingIntervalMs:   uint64(c.CacheHealthPollingInterval / time.Millisecond),
@@ -141,6 +231,8 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		HealthFlushIntervalMs:          uint64(c.HealthFlushInterval / time.Millisecond),
 		StatFlushIntervalMs:            uint64(c.StatFlushInterval / time.Millisecond),
 		StatBufferIntervalMs:           uint64(c.StatBufferInterval / time.Millisecond),
+		EventStoreMaxFileAgeMs:         uint64(c.EventStoreMaxFileAge / time.Millisecond),
+		EventStoreMaxAgeMs:             uint64(c.EventStoreMaxAge / time.Millisecond),
 		Alias:                          (*Alias)(c),
 	})
 }
@@ -165,6 +257,8 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 		TrafficOpsDiskRetryMax         *uint64 `json:"traffic_ops_disk_retry_max"`
 		CRConfigBackupFile             *string `json:"crconfig_backup_file"`
 		TMConfigBackupFile             *string `json:"tmconfig_backup_file"`
+		EventStoreMaxFileAgeMs         *uint64 `json:"event_store_max_file_age_ms"`
+		EventStoreMaxAgeMs             *uint64 `json:"event_store_max_age_ms"`
 		*Alias
 	}{
 		Alias: (*Alias)(c),
@@ -222,6 +316,12 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	if aux.TMConfigBackupFile != nil {
 		c.TMConfigBackupFile = *aux.TMConfigBackupFile
 	}
+	if aux.EventStoreMaxFileAgeMs != nil {
+		c.EventStoreMaxFileAge = time.Duration(*aux.EventStoreMaxFileAgeMs) * time.Millisecond
+	}
+	if aux.EventStoreMaxAgeMs != nil {
+		c.EventStoreMaxAge = time.Duration(*aux.EventStoreMaxAgeMs) * time.Millisecond
+	}
 	return nil
 }
 
@@ -241,7 +341,18 @@ func Load(fileName string) (Config, error) {
 // LoadBytes loads the given file bytes.
 func LoadBytes(bytes []byte) (Config, error) {
 	cfg := DefaultConfig
+	// The default remap_stats are set after unmarshalling, so configured remap_stats replace rather than add to them.
+	cfg.PrometheusStats.RemapStats = nil
 	json := jsoniter.ConfigFastest // TODO make configurable?
 	err := json.Unmarshal(bytes, &cfg)
+	if cfg.PrometheusStats.RemapStats == nil {
+		cfg.PrometheusStats.RemapStats = DefaultPrometheusStatsMapping.RemapStats
+	}
+	if err == nil && cfg.PeerConsensus != PeerConsensusOptimistic && cfg.PeerConsensus != PeerConsensusQuorum {
+		err = errors.New("invalid peer_consensus '" + string(cfg.PeerConsensus) + "', must be '" + string(PeerConsensusOptimistic) + "' or '" + string(PeerConsensusQuorum) + "'")
+	}
+	if err == nil && cfg.PeerQuorum < 0 {
+		err = errors.New("invalid peer_quorum, must not be negative")
+	}
 	return cfg, err
 }
@@ -21,6 +21,7 @@ package datareq
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
@@ -28,6 +29,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/cache"
 	"github.com/apache/trafficcontrol/traffic_monitor/ds"
 	"github.com/apache/trafficcontrol/traffic_monitor/dsdata"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
@@ -55,6 +57,19 @@ type CacheStatus struct {
 	BandwidthKbps          *float64 `json:"bandwidth_kbps,omitempty"`
 	BandwidthCapacityKbps  *float64 `json:"bandwidth_capacity_kbps,omitempty"`
 	ConnectionCount        *int64   `json:"connection_count,omitempty"`
+	// Interfaces are the statuses of each of the cache's monitored interfaces, as of the latest stat query. They're omitted if the server doesn't declare monitored interfaces.
+	Interfaces map[string]CacheInterfaceStatus `json:"interfaces,omitempty"`
+}
+
+// CacheInterfaceStatus contains summary stat data about a monitored network interface of a cache.
+type CacheInterfaceStatus struct {
+	// Required is whether the cache is unavailable when the interface is unavailable.
+	Required  bool `json:"required"`
+	Available bool `json:"available"`
+	// Status is why the interface is available or unavailable.
+	Status                string   `json:"status"`
+	BandwidthKbps         *float64 `json:"bandwidth_kbps,omitempty"`
+	BandwidthCapacityKbps *float64 `json:"bandwidth_capacity_kbps,omitempty"`
 }
 
 func srvAPICacheStates(
@@ -102,12 +117,14 @@ func createCacheStatuses(
 		}
 
 		loadAverage := 0.0
+		interfaces := map[string]CacheInterfaceStatus(nil)
 		if infoHistory, ok := statInfoHistory[cacheName]; !ok {
 			log.Infof("createCacheStatuses stat info history missing cache %s\n", cacheName)
 		} else if len(infoHistory) < 1 {
 			log.Infof("createCacheStatuses stat info history empty for cache %s\n", cacheName)
 		} else {
 			loadAverage = infoHistory[0].Vitals.LoadAvg
+			interfaces = createCacheInterfaceStatuses(infoHistory[0].Vitals, localCacheStatus[cacheName].Thresholds)
 		}
 
 		healthQueryTime, err := latestQueryTimeMS(cacheName, lastHealthDurations)
@@ -172,11 +189,47 @@ func createCacheStatuses(
 			ConnectionCount:        connections,
 			Status:                 &status,
 			StatusPoller:           &statusPoller,
+			Interfaces:             interfaces,
 		}
 	}
 	return statii
 }
 
+// createCacheInterfaceStatuses returns the status of each monitored interface in the given vitals, using the given threshold states of the cache. Returns nil if the vitals have no interfaces.
+func createCacheInterfaceStatuses(vitals cache.Vitals, thresholds map[string]cache.ThresholdStatus) map[string]CacheInterfaceStatus {
+	if len(vitals.Interfaces) == 0 {
+		return nil
+	}
+	statuses := make(map[string]CacheInterfaceStatus, len(vitals.Interfaces))
+	for name, inf := range vitals.Interfaces {
+		status := CacheInterfaceStatus{Required: inf.Required, Available: true, Status: health.AvailableStr}
+		if inf.Error != nil {
+			status.Available, status.Status = false, health.UnavailableStr+" - "+inf.Error.Error()
+			statuses[name] = status
+			continue
+		}
+		kbps, maxKbps := float64(inf.KbpsOut), float64(inf.MaxKbpsOut)
+		status.BandwidthKbps, status.BandwidthCapacityKbps = &kbps, &maxKbps
+		if stat := exceededInterfaceStat(name, thresholds); stat != "" {
+			status.Available, status.Status = false, health.UnavailableStr+" - "+stat+" exceeded threshold"
+		}
+		statuses[name] = status
+	}
+	return statuses
+}
+
+// exceededInterfaceStat returns the first stat of the given interface, by name, whose threshold is exceeded, or the empty string if none are.
+func exceededInterfaceStat(name string, thresholds map[string]cache.ThresholdStatus) string {
+	prefix := cache.InterfaceStatName(name, "")
+	exceeded := ""
+	for stat, threshold := range thresholds {
+		if threshold.Exceeded && strings.HasPrefix(stat, prefix) && (exceeded == "" || stat < exceeded) {
+			exceeded = stat
+		}
+	}
+	return exceeded
+}
+
 func cacheStatusAndPoller(server tc.CacheName, serverInfo tc.TrafficServer, localCacheStatus cache.AvailableStatuses) (string, string) {
 	switch status := tc.CacheStatusFromString(serverInfo.ServerStatus); status {
 	case tc.CacheStatusAdminDown:
@@ -0,0 +1,145 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package datareq
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+)
+
+// crStatesStreamWriteTimeout is how long a single event write may take, before the client is considered dead.
+const crStatesStreamWriteTimeout = peer.CRStatesStreamHeartbeatInterval * 3
+
+// srvTRStateStream returns a handler which streams the CRStates as Server-Sent Events: a snapshot, followed by the delta of each change, and periodic heartbeats. With the `raw` parameter, this Traffic Monitor's local states are streamed, otherwise the combined states.
+// The connection is hijacked where possible, so the stream isn't closed by the server write timeout. Otherwise, for example over HTTP/2, the stream ends at the write timeout, and clients reconnect.
+func srvTRStateStream(localStream peer.CRStatesStream, combinedStream peer.CRStatesStream) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stream := combinedStream
+		if _, raw := r.URL.Query()["raw"]; raw {
+			stream = localStream
+		}
+
+		sw, err := newStreamWriter(w)
+		if err != nil {
+			log.Errorf("streaming %v: %v\n", r.URL.EscapedPath(), err)
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Write(w, []byte(http.StatusText(http.StatusInternalServerError)), r.URL.EscapedPath())
+			return
+		}
+		defer sw.Close()
+
+		snapshot, events, unsubscribe := stream.Subscribe()
+		defer unsubscribe()
+
+		if err := sw.Write(snapshot); err != nil {
+			log.Infof("streaming %v to %v: writing snapshot: %v\n", r.URL.EscapedPath(), r.RemoteAddr, err)
+			return
+		}
+		seq := snapshot.Seq
+
+		heartbeat := time.NewTicker(peer.CRStatesStreamHeartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					log.Warnf("streaming %v to %v: client too slow, closing stream at %d\n", r.URL.EscapedPath(), r.RemoteAddr, seq)
+					return
+				}
+				if err := sw.Write(event); err != nil {
+					log.Infof("streaming %v to %v: writing delta: %v\n", r.URL.EscapedPath(), r.RemoteAddr, err)
+					return
+				}
+				seq = event.Seq
+			case <-heartbeat.C:
+				if err := sw.Write(peer.CRStatesStreamEvent{Seq: seq, Type: peer.CRStatesStreamEventHeartbeat}); err != nil {
+					log.Infof("streaming %v to %v: writing heartbeat: %v\n", r.URL.EscapedPath(), r.RemoteAddr, err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// streamWriter writes stream events to either a hijacked connection, or a flushable ResponseWriter.
+type streamWriter struct {
+	conn    net.Conn
+	buf     *bufio.Writer
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newStreamWriter writes the stream response headers, and returns a streamWriter for the events.
+func newStreamWriter(w http.ResponseWriter) (*streamWriter, error) {
+	if hijacker, ok := w.(http.Hijacker); ok {
+		conn, buf, err := hijacker.Hijack()
+		if err != nil {
+			return nil, errors.New("hijacking connection: " + err.Error())
+		}
+		sw := &streamWriter{conn: conn, buf: buf.Writer}
+		conn.SetReadDeadline(time.Time{})
+		conn.SetWriteDeadline(time.Now().Add(crStatesStreamWriteTimeout))
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: " + ContentTypeEventStream + "\r\nCache-Control: no-cache\r\nConnection: close\r\n\r\n")
+		if err := buf.Flush(); err != nil {
+			conn.Close()
+			return nil, errors.New("writing headers: " + err.Error())
+		}
+		return sw, nil
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("response writer is neither a hijacker nor a flusher")
+	}
+	w.Header().Set("Content-Type", ContentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &streamWriter{w: w, flusher: flusher}, nil
+}
+
+// Write writes and flushes the given event.
+func (sw *streamWriter) Write(event peer.CRStatesStreamEvent) error {
+	if sw.conn == nil {
+		if err := peer.WriteCRStatesStreamEvent(sw.w, event); err != nil {
+			return err
+		}
+		sw.flusher.Flush()
+		return nil
+	}
+	sw.conn.SetWriteDeadline(time.Now().Add(crStatesStreamWriteTimeout))
+	if err := peer.WriteCRStatesStreamEvent(sw.buf, event); err != nil {
+		return err
+	}
+	return sw.buf.Flush()
+}
+
+// Close closes the hijacked connection, if any. Otherwise, the server closes the response when the handler returns.
+func (sw *streamWriter) Close() {
+	if sw.conn != nil {
+		sw.conn.Close()
+	}
+}
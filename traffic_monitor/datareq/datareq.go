@@ -62,6 +62,8 @@ func MakeDispatchMap(
 	lastStats threadsafe.LastStats,
 	unpolledCaches threadsafe.UnpolledCaches,
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
+	localStream peer.CRStatesStream,
+	combinedStream peer.CRStatesStream,
 ) map[string]http.HandlerFunc {
 
 	// wrap composes all universal wrapper functions. Right now, it's only the UnpolledCheck, but there may be others later. For example, security headers.
@@ -77,14 +79,15 @@ func MakeDispatchMap(
 			bytes, err := srvTRState(params, localStates, combinedStates)
 			return WrapErrCode(errorCount, path, bytes, err)
 		}, ContentTypeJSON)),
+		"/publish/CrStatesStream": wrap(srvTRStateStream(localStream, combinedStream)),
 		"/publish/CacheStats": wrap(WrapParams(func(params url.Values, path string) ([]byte, int) {
 			return srvCacheStats(params, errorCount, path, toData, statResultHistory, statInfoHistory, monitorConfig, combinedStates, statMaxKbpses)
 		}, ContentTypeJSON)),
 		"/publish/DsStats": wrap(WrapParams(func(params url.Values, path string) ([]byte, int) {
 			return srvDSStats(params, errorCount, path, toData, dsStats)
 		}, ContentTypeJSON)),
-		"/publish/EventLog": wrap(WrapErr(errorCount, func() ([]byte, error) {
-			return srvEventLog(events)
+		"/publish/EventLog": wrap(WrapParams(func(params url.Values, path string) ([]byte, int) {
+			return srvEventLog(params, errorCount, path, events)
 		}, ContentTypeJSON)),
 		"/publish/PeerStates": wrap(WrapParams(func(params url.Values, path string) ([]byte, int) {
 			return srvPeerStates(params, errorCount, path, toData, peerStates)
@@ -128,6 +131,9 @@ func MakeDispatchMap(
 		"/api/crconfig-history": wrap(WrapErr(errorCount, func() ([]byte, error) {
 			return srvAPICRConfigHist(toSession)
 		}, ContentTypeJSON)),
+		"/metrics": wrap(WrapBytes(func() []byte {
+			return srvMetrics(opsConfig, toData, localStates, combinedStates, peerStates, statInfoHistory, statMaxKbpses, lastStats, dsStats, lastHealthDurations, fetchCount, healthIteration, errorCount, monitorConfig, staticAppData)
+		}, ContentTypePrometheus)),
 	}
 	return addTrailingSlashEndpoints(dispatchMap)
 }
@@ -270,6 +276,12 @@ func wrapUnpolledCheck(unpolledCaches threadsafe.UnpolledCaches, errorCount thre
 
 const ContentTypeJSON = "application/json"
 
+// ContentTypeEventStream is the content type of Server-Sent Events streams.
+const ContentTypeEventStream = "text/event-stream"
+
+// ContentTypePrometheus is the content type of the Prometheus text exposition format.
+const ContentTypePrometheus = "text/plain; version=0.0.4; charset=utf-8"
+
 func stripAllWhitespace(s string) string {
 	return strings.Map(func(r rune) rune {
 		if unicode.IsSpace(r) {
@@ -20,7 +20,15 @@
 package datareq
 
 import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 
 	"github.com/json-iterator/go"
 )
@@ -30,7 +38,117 @@ type JSONEvents struct {
 	Events []health.Event `json:"events"`
 }
 
-func srvEventLog(events health.ThreadsafeEvents) ([]byte, error) {
+func srvEventLog(params url.Values, errorCount threadsafe.Uint, path string, events health.ThreadsafeEvents) ([]byte, int) {
+	filter, err := NewEventFilter(params)
+	if err != nil {
+		HandleErr(errorCount, path, err)
+		return []byte(err.Error()), http.StatusBadRequest
+	}
+	matches, err := events.Query(filter)
+	if err != nil {
+		return WrapErrCode(errorCount, path, nil, err)
+	}
 	json := jsoniter.ConfigFastest
-	return json.Marshal(JSONEvents{Events: events.Get()})
+	bytes, err := json.Marshal(JSONEvents{Events: matches})
+	return WrapErrCode(errorCount, path, bytes, err)
+}
+
+// NewEventFilter takes the HTTP query parameters and creates an event filter.
+// Query parameters used are `cache`, `cachegroup`, `type`, `available`, `override`, `startTime`, `endTime`, `limit`, and `offset`.
+// The `cache`, `cachegroup`, and `type` parameters are comma-separated lists, and `startTime` and `endTime` are milliseconds since the epoch.
+func NewEventFilter(params url.Values) (health.EventFilter, error) {
+	validParams := map[string]struct{}{
+		"cache":      struct{}{},
+		"cachegroup": struct{}{},
+		"type":       struct{}{},
+		"available":  struct{}{},
+		"override":   struct{}{},
+		"startTime":  struct{}{},
+		"endTime":    struct{}{},
+		"limit":      struct{}{},
+		"offset":     struct{}{},
+	}
+	for param := range params {
+		if _, ok := validParams[param]; !ok {
+			return health.EventFilter{}, errors.New("invalid query parameter '" + param + "'")
+		}
+	}
+
+	filter := health.EventFilter{}
+	filter.Names = paramSet(params, "cache", false)
+	filter.CacheGroups = paramSet(params, "cachegroup", false)
+	filter.Types = paramSet(params, "type", true)
+
+	var err error
+	if filter.Available, err = paramBool(params, "available"); err != nil {
+		return health.EventFilter{}, err
+	}
+	if filter.Override, err = paramBool(params, "override"); err != nil {
+		return health.EventFilter{}, err
+	}
+	if filter.Start, err = paramTimeMS(params, "startTime"); err != nil {
+		return health.EventFilter{}, err
+	}
+	if filter.End, err = paramTimeMS(params, "endTime"); err != nil {
+		return health.EventFilter{}, err
+	}
+	if filter.Limit, err = paramUint(params, "limit"); err != nil {
+		return health.EventFilter{}, err
+	}
+	if filter.Offset, err = paramUint(params, "offset"); err != nil {
+		return health.EventFilter{}, err
+	}
+	return filter, nil
+}
+
+// paramSet returns the set of the comma-separated values of the given parameter, uppercased if upper, or nil if it's empty.
+func paramSet(params url.Values, name string, upper bool) map[string]struct{} {
+	val := params.Get(name)
+	if val == "" {
+		return nil
+	}
+	set := map[string]struct{}{}
+	for _, v := range strings.Split(val, ",") {
+		if upper {
+			v = strings.ToUpper(v)
+		}
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+func paramBool(params url.Values, name string) (*bool, error) {
+	val := params.Get(name)
+	if val == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return nil, errors.New("invalid query parameter " + name + " '" + val + "', must be true or false")
+	}
+	return &b, nil
+}
+
+func paramTimeMS(params url.Values, name string) (time.Time, error) {
+	val := params.Get(name)
+	if val == "" {
+		return time.Time{}, nil
+	}
+	ms, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("invalid query parameter " + name + " '" + val + "', must be milliseconds since the epoch")
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), nil
+}
+
+func paramUint(params url.Values, name string) (uint64, error) {
+	val := params.Get(name)
+	if val == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid query parameter " + name + " '" + val + "', must be a non-negative integer")
+	}
+	return n, nil
 }
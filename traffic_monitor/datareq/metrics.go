@@ -0,0 +1,362 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package datareq
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+	"github.com/apache/trafficcontrol/traffic_monitor/ds"
+	"github.com/apache/trafficcontrol/traffic_monitor/dsdata"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+// MetricsPrefix is prepended to the name of every metric served by /metrics.
+const MetricsPrefix = "traffic_monitor_"
+
+// MetricsData is the data served as metrics by /metrics. It's gotten from the threadsafe objects once per request, so all metrics are consistent with each other.
+type MetricsData struct {
+	CDN                 string
+	StaticAppData       config.StaticAppData
+	Servers             map[string]tc.TrafficServer
+	CombinedStates      map[tc.CacheName]tc.IsAvailable
+	LocalStates         map[tc.CacheName]tc.IsAvailable
+	StatInfoHistory     cache.ResultInfoHistory
+	MaxKbpses           cache.Kbpses
+	LastStats           dsdata.LastStats
+	DSTypes             map[tc.DeliveryServiceName]tc.DSTypeCategory
+	DSStats             dsdata.StatsReadonly
+	LastHealthDurations map[tc.CacheName]time.Duration
+	PeersOnline         map[tc.TrafficMonitorName]bool
+	PeerQueryTimes      map[tc.TrafficMonitorName]time.Time
+	PeerStates          map[tc.TrafficMonitorName]tc.CRStates
+	FetchCount          uint64
+	HealthIteration     uint64
+	ErrorCount          uint64
+	Now                 time.Time
+}
+
+func srvMetrics(
+	opsConfig threadsafe.OpsConfig,
+	toData todata.TODataThreadsafe,
+	localStates peer.CRStatesThreadsafe,
+	combinedStates peer.CRStatesThreadsafe,
+	peerStates peer.CRStatesPeersThreadsafe,
+	statInfoHistory threadsafe.ResultInfoHistory,
+	statMaxKbpses threadsafe.CacheKbpses,
+	lastStats threadsafe.LastStats,
+	dsStats threadsafe.DSStatsReader,
+	lastHealthDurations threadsafe.DurationMap,
+	fetchCount threadsafe.Uint,
+	healthIteration threadsafe.Uint,
+	errorCount threadsafe.Uint,
+	monitorConfig threadsafe.TrafficMonitorConfigMap,
+	staticAppData config.StaticAppData,
+) []byte {
+	return createMetrics(MetricsData{
+		CDN:                 opsConfig.Get().CdnName,
+		StaticAppData:       staticAppData,
+		Servers:             monitorConfig.Get().TrafficServer,
+		CombinedStates:      combinedStates.GetCaches(),
+		LocalStates:         localStates.GetCaches(),
+		StatInfoHistory:     statInfoHistory.Get(),
+		MaxKbpses:           statMaxKbpses.Get(),
+		LastStats:           lastStats.Get(),
+		DSTypes:             toData.Get().DeliveryServiceTypes,
+		DSStats:             dsStats.Get(),
+		LastHealthDurations: lastHealthDurations.Get(),
+		PeersOnline:         peerStates.GetPeersOnline(),
+		PeerQueryTimes:      peerStates.GetQueryTimes(),
+		PeerStates:          peerStates.GetCrstates(),
+		FetchCount:          fetchCount.Get(),
+		HealthIteration:     healthIteration.Get(),
+		ErrorCount:          errorCount.Get(),
+		Now:                 time.Now(),
+	})
+}
+
+// createMetrics returns the given data in the Prometheus text exposition format.
+func createMetrics(d MetricsData) []byte {
+	w := &metricsWriter{}
+
+	w.Family("info", "gauge", "Information about this Traffic Monitor. Always 1.")
+	w.Sample("info", 1, "cdn", d.CDN, "name", d.StaticAppData.Name, "version", d.StaticAppData.Version, "git_revision", d.StaticAppData.GitRevision)
+	w.Family("uptime_seconds", "gauge", "Seconds since this Traffic Monitor started.")
+	w.Sample("uptime_seconds", d.Now.Sub(d.StaticAppData.StartTime).Seconds(), "cdn", d.CDN)
+	w.Family("errors_total", "counter", "Errors serving requests and polling.")
+	w.Sample("errors_total", float64(d.ErrorCount), "cdn", d.CDN)
+	w.Family("fetches_total", "counter", "Cache health polls fetched.")
+	w.Sample("fetches_total", float64(d.FetchCount), "cdn", d.CDN)
+	w.Family("health_iterations_total", "counter", "Cache health poll results processed.")
+	w.Sample("health_iterations_total", float64(d.HealthIteration), "cdn", d.CDN)
+
+	createCacheMetrics(w, d)
+	createDSMetrics(w, d)
+	createPeerMetrics(w, d)
+	return w.Bytes()
+}
+
+func createCacheMetrics(w *metricsWriter, d MetricsData) {
+	cacheNames := make([]string, 0, len(d.Servers))
+	for name := range d.Servers {
+		cacheNames = append(cacheNames, name)
+	}
+	sort.Strings(cacheNames)
+
+	// labels returns the labels of the given cache, which every cache metric has.
+	labels := func(name string, extra ...string) []string {
+		server := d.Servers[name]
+		return append([]string{"cache", name, "cachegroup", server.CacheGroup, "type", server.Type, "profile", server.Profile, "cdn", d.CDN}, extra...)
+	}
+
+	w.Family("cache_available", "gauge", "Whether the cache is available, combined with peers, as served to Traffic Router.")
+	for _, name := range cacheNames {
+		if state, ok := d.CombinedStates[tc.CacheName(name)]; ok {
+			w.Sample("cache_available", boolMetric(state.IsAvailable), labels(name)...)
+		}
+	}
+
+	w.Family("cache_local_available", "gauge", "Whether the cache is available, as polled by this Traffic Monitor alone.")
+	for _, name := range cacheNames {
+		if state, ok := d.LocalStates[tc.CacheName(name)]; ok {
+			w.Sample("cache_local_available", boolMetric(state.IsAvailable), labels(name)...)
+		}
+	}
+
+	w.Family("cache_kbps", "gauge", "The cache's bandwidth, in kilobits per second, from its delivery service stats.")
+	for _, name := range cacheNames {
+		if lastStat, ok := d.LastStats.Caches[tc.CacheName(name)]; ok {
+			w.Sample("cache_kbps", lastStat.Bytes.PerSec/float64(ds.BytesPerKilobit), labels(name)...)
+		}
+	}
+
+	w.Family("cache_max_kbps", "gauge", "The cache's bandwidth capacity, in kilobits per second.")
+	for _, name := range cacheNames {
+		if maxKbps, ok := d.MaxKbpses[tc.CacheName(name)]; ok {
+			w.Sample("cache_max_kbps", float64(maxKbps), labels(name)...)
+		}
+	}
+
+	vitals := map[string]cache.Vitals{}
+	statTimes := map[string]time.Duration{}
+	for _, name := range cacheNames {
+		if history := d.StatInfoHistory[tc.CacheName(name)]; len(history) > 0 {
+			vitals[name] = history[0].Vitals
+			statTimes[name] = history[0].RequestTime
+		}
+	}
+
+	w.Family("cache_load_average", "gauge", "The cache's 1 minute load average.")
+	for _, name := range cacheNames {
+		if v, ok := vitals[name]; ok {
+			w.Sample("cache_load_average", v.LoadAvg, labels(name)...)
+		}
+	}
+
+	w.Family("cache_vitals_kbps_out", "gauge", "The cache's outgoing interface bandwidth, in kilobits per second.")
+	for _, name := range cacheNames {
+		if v, ok := vitals[name]; ok {
+			w.Sample("cache_vitals_kbps_out", float64(v.KbpsOut), labels(name)...)
+		}
+	}
+
+	w.Family("cache_interface_bytes_total", "counter", "Bytes through the cache's interface.")
+	for _, name := range cacheNames {
+		if v, ok := vitals[name]; ok {
+			w.Sample("cache_interface_bytes_total", float64(v.BytesIn), labels(name, "direction", "in")...)
+			w.Sample("cache_interface_bytes_total", float64(v.BytesOut), labels(name, "direction", "out")...)
+		}
+	}
+
+	w.Family("cache_health_poll_duration_seconds", "gauge", "The duration of the latest health poll of the cache, including processing.")
+	for _, name := range cacheNames {
+		if duration, ok := d.LastHealthDurations[tc.CacheName(name)]; ok {
+			w.Sample("cache_health_poll_duration_seconds", duration.Seconds(), labels(name)...)
+		}
+	}
+
+	w.Family("cache_stat_poll_duration_seconds", "gauge", "The duration of the latest stat poll request to the cache.")
+	for _, name := range cacheNames {
+		if duration, ok := statTimes[name]; ok {
+			w.Sample("cache_stat_poll_duration_seconds", duration.Seconds(), labels(name)...)
+		}
+	}
+}
+
+func createDSMetrics(w *metricsWriter, d MetricsData) {
+	dsNames := make([]string, 0, len(d.DSTypes))
+	stats := map[string]*dsdata.Stat{}
+	for dsName := range d.DSTypes {
+		if d.DSStats == nil {
+			break
+		}
+		stat, ok := d.DSStats.Get(dsName)
+		if !ok {
+			continue
+		}
+		dsNames = append(dsNames, string(dsName))
+		stats[string(dsName)] = stat.Copy()
+	}
+	sort.Strings(dsNames)
+
+	labels := func(name string, extra ...string) []string {
+		return append([]string{"deliveryservice", name, "type", string(d.DSTypes[tc.DeliveryServiceName(name)]), "cdn", d.CDN}, extra...)
+	}
+
+	w.Family("ds_available", "gauge", "Whether the delivery service is available.")
+	for _, name := range dsNames {
+		w.Sample("ds_available", boolMetric(stats[name].CommonStats.IsAvailable.Value), labels(name)...)
+	}
+
+	w.Family("ds_caches_configured", "gauge", "The number of caches assigned to the delivery service.")
+	for _, name := range dsNames {
+		w.Sample("ds_caches_configured", float64(stats[name].CommonStats.CachesConfiguredNum.Value), labels(name)...)
+	}
+
+	w.Family("ds_caches_available", "gauge", "The number of available caches assigned to the delivery service.")
+	for _, name := range dsNames {
+		w.Sample("ds_caches_available", float64(stats[name].CommonStats.CachesAvailableNum.Value), labels(name)...)
+	}
+
+	w.Family("ds_kbps", "gauge", "The delivery service's bandwidth, in kilobits per second, summed over all caches.")
+	for _, name := range dsNames {
+		w.Sample("ds_kbps", stats[name].TotalStats.Kbps.Value, labels(name)...)
+	}
+
+	w.Family("ds_tps", "gauge", "The delivery service's transactions per second, summed over all caches, by response status class.")
+	for _, name := range dsNames {
+		total := stats[name].TotalStats
+		w.Sample("ds_tps", total.Tps2xx.Value, labels(name, "status", "2xx")...)
+		w.Sample("ds_tps", total.Tps3xx.Value, labels(name, "status", "3xx")...)
+		w.Sample("ds_tps", total.Tps4xx.Value, labels(name, "status", "4xx")...)
+		w.Sample("ds_tps", total.Tps5xx.Value, labels(name, "status", "5xx")...)
+	}
+
+	w.Family("ds_cachegroup_kbps", "gauge", "The delivery service's bandwidth, in kilobits per second, summed over the caches in each cachegroup.")
+	for _, name := range dsNames {
+		for _, cg := range sortedCacheGroups(stats[name].CacheGroups) {
+			w.Sample("ds_cachegroup_kbps", stats[name].CacheGroups[cg].Kbps.Value, labels(name, "cachegroup", string(cg))...)
+		}
+	}
+
+	w.Family("ds_cachegroup_tps", "gauge", "The delivery service's total transactions per second, summed over the caches in each cachegroup.")
+	for _, name := range dsNames {
+		for _, cg := range sortedCacheGroups(stats[name].CacheGroups) {
+			w.Sample("ds_cachegroup_tps", stats[name].CacheGroups[cg].TpsTotal.Value, labels(name, "cachegroup", string(cg))...)
+		}
+	}
+}
+
+func sortedCacheGroups(cgs map[tc.CacheGroupName]*dsdata.StatCacheStats) []tc.CacheGroupName {
+	names := make([]tc.CacheGroupName, 0, len(cgs))
+	for name := range cgs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+func createPeerMetrics(w *metricsWriter, d MetricsData) {
+	peerNames := make([]string, 0, len(d.PeersOnline))
+	for name := range d.PeersOnline {
+		peerNames = append(peerNames, string(name))
+	}
+	sort.Strings(peerNames)
+
+	w.Family("peer_available", "gauge", "Whether the peer Traffic Monitor was reachable at its latest poll.")
+	for _, name := range peerNames {
+		w.Sample("peer_available", boolMetric(d.PeersOnline[tc.TrafficMonitorName(name)]), "peer", name, "cdn", d.CDN)
+	}
+
+	w.Family("peer_poll_age_seconds", "gauge", "Seconds since the peer Traffic Monitor was last polled.")
+	for _, name := range peerNames {
+		if t, ok := d.PeerQueryTimes[tc.TrafficMonitorName(name)]; ok && !t.IsZero() {
+			w.Sample("peer_poll_age_seconds", d.Now.Sub(t).Seconds(), "peer", name, "cdn", d.CDN)
+		}
+	}
+
+	w.Family("peer_caches_available", "gauge", "The number of caches the peer Traffic Monitor reports available.")
+	for _, name := range peerNames {
+		states, ok := d.PeerStates[tc.TrafficMonitorName(name)]
+		if !ok {
+			continue
+		}
+		available := 0
+		for _, state := range states.Caches {
+			if state.IsAvailable {
+				available++
+			}
+		}
+		w.Sample("peer_caches_available", float64(available), "peer", name, "cdn", d.CDN)
+	}
+}
+
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// metricsWriter writes metrics in the Prometheus text exposition format.
+// Callers must write a Family before its Samples, and all of a family's Samples together.
+type metricsWriter struct {
+	buf bytes.Buffer
+}
+
+// Family writes the HELP and TYPE of the metric family name, which is prefixed with MetricsPrefix.
+func (w *metricsWriter) Family(name string, typ string, help string) {
+	w.buf.WriteString("# HELP " + MetricsPrefix + name + " " + escapeMetricHelp(help) + "\n")
+	w.buf.WriteString("# TYPE " + MetricsPrefix + name + " " + typ + "\n")
+}
+
+// Sample writes a sample of the metric name, which is prefixed with MetricsPrefix. The labels are alternating names and values.
+func (w *metricsWriter) Sample(name string, value float64, labels ...string) {
+	w.buf.WriteString(MetricsPrefix + name)
+	if len(labels) > 1 {
+		w.buf.WriteString("{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.buf.WriteString(",")
+			}
+			w.buf.WriteString(labels[i] + `="` + escapeMetricLabelValue(labels[i+1]) + `"`)
+		}
+		w.buf.WriteString("}")
+	}
+	w.buf.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+func (w *metricsWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+var metricLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var metricHelpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeMetricLabelValue(s string) string { return metricLabelValueReplacer.Replace(s) }
+func escapeMetricHelp(s string) string       { return metricHelpReplacer.Replace(s) }
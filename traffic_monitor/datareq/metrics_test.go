@@ -0,0 +1,105 @@
+package datareq
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	w := &metricsWriter{}
+	w.Family("foo", "gauge", "A foo\nwith a newline and \\ backslash.")
+	w.Sample("foo", 1.5, "cache", `a"b\c`+"\nd", "cdn", "mycdn")
+	w.Sample("foo", 2)
+
+	expected := `# HELP traffic_monitor_foo A foo\nwith a newline and \\ backslash.
+# TYPE traffic_monitor_foo gauge
+traffic_monitor_foo{cache="a\"b\\c\nd",cdn="mycdn"} 1.5
+traffic_monitor_foo 2
+`
+	if actual := string(w.Bytes()); actual != expected {
+		t.Errorf("metricsWriter expected:\n%v\nactual:\n%v", expected, actual)
+	}
+}
+
+func TestCreateMetrics(t *testing.T) {
+	now := time.Now()
+	appData := getMockStaticAppData()
+	appData.StartTime = now.Add(-10 * time.Second)
+
+	d := MetricsData{
+		CDN:           "mycdn",
+		StaticAppData: appData,
+		Servers: map[string]tc.TrafficServer{
+			"edge1": tc.TrafficServer{HostName: "edge1", CacheGroup: "cg1", Type: "EDGE", Profile: "EDGE_PROFILE"},
+			"edge2": tc.TrafficServer{HostName: "edge2", CacheGroup: "cg2", Type: "EDGE", Profile: "EDGE_PROFILE"},
+		},
+		CombinedStates: map[tc.CacheName]tc.IsAvailable{
+			"edge1": tc.IsAvailable{IsAvailable: true},
+			"edge2": tc.IsAvailable{IsAvailable: false},
+		},
+		StatInfoHistory: cache.ResultInfoHistory{
+			"edge1": []cache.ResultInfo{{RequestTime: 250 * time.Millisecond, Vitals: cache.Vitals{LoadAvg: 0.5, KbpsOut: 1000}}},
+		},
+		MaxKbpses:           cache.Kbpses{"edge1": 10000000},
+		LastHealthDurations: map[tc.CacheName]time.Duration{"edge2": 2 * time.Second},
+		PeersOnline:         map[tc.TrafficMonitorName]bool{"tm1": true, "tm2": false},
+		PeerQueryTimes:      map[tc.TrafficMonitorName]time.Time{"tm1": now.Add(-3 * time.Second)},
+		PeerStates: map[tc.TrafficMonitorName]tc.CRStates{
+			"tm1": tc.CRStates{Caches: map[tc.CacheName]tc.IsAvailable{"edge1": {IsAvailable: true}, "edge2": {IsAvailable: true}}},
+		},
+		ErrorCount: 42,
+		Now:        now,
+	}
+
+	metrics := string(createMetrics(d))
+
+	expecteds := []string{
+		`traffic_monitor_uptime_seconds{cdn="mycdn"} 10` + "\n",
+		`traffic_monitor_errors_total{cdn="mycdn"} 42` + "\n",
+		`traffic_monitor_cache_available{cache="edge1",cachegroup="cg1",type="EDGE",profile="EDGE_PROFILE",cdn="mycdn"} 1` + "\n",
+		`traffic_monitor_cache_available{cache="edge2",cachegroup="cg2",type="EDGE",profile="EDGE_PROFILE",cdn="mycdn"} 0` + "\n",
+		`traffic_monitor_cache_max_kbps{cache="edge1",cachegroup="cg1",type="EDGE",profile="EDGE_PROFILE",cdn="mycdn"} 1e+07` + "\n",
+		`traffic_monitor_cache_load_average{cache="edge1",cachegroup="cg1",type="EDGE",profile="EDGE_PROFILE",cdn="mycdn"} 0.5` + "\n",
+		`traffic_monitor_cache_stat_poll_duration_seconds{cache="edge1",cachegroup="cg1",type="EDGE",profile="EDGE_PROFILE",cdn="mycdn"} 0.25` + "\n",
+		`traffic_monitor_cache_health_poll_duration_seconds{cache="edge2",cachegroup="cg2",type="EDGE",profile="EDGE_PROFILE",cdn="mycdn"} 2` + "\n",
+		`traffic_monitor_peer_available{peer="tm1",cdn="mycdn"} 1` + "\n",
+		`traffic_monitor_peer_available{peer="tm2",cdn="mycdn"} 0` + "\n",
+		`traffic_monitor_peer_poll_age_seconds{peer="tm1",cdn="mycdn"} 3` + "\n",
+		`traffic_monitor_peer_caches_available{peer="tm1",cdn="mycdn"} 2` + "\n",
+	}
+	for _, expected := range expecteds {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("metrics expected to contain '%v', actual:\n%v", strings.TrimSpace(expected), metrics)
+		}
+	}
+
+	if strings.Contains(metrics, `traffic_monitor_cache_local_available{`) {
+		t.Errorf("metrics expected no local availability samples without local states, actual:\n%v", metrics)
+	}
+	if strings.Contains(metrics, `traffic_monitor_peer_poll_age_seconds{peer="tm2"`) {
+		t.Errorf("metrics expected no poll age for a never-polled peer, actual:\n%v", metrics)
+	}
+}
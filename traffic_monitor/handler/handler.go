@@ -42,6 +42,7 @@ type OpsConfig struct {
 	KeyFile       string `json:"keyFile"`
 }
 
+// Handler handles poll results. The interface{} is the context of the format, and the bool is whether the poll was made over IPv6, as opposed to IPv4.
 type Handler interface {
-	Handle(string, io.Reader, string, time.Duration, time.Time, error, uint64, chan<- uint64)
+	Handle(string, io.Reader, string, interface{}, time.Duration, time.Time, error, uint64, bool, chan<- uint64)
 }
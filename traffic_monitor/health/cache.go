@@ -20,7 +20,9 @@ package health
  */
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -55,6 +57,13 @@ func GetVitals(newResult *cache.Result, prevResult *cache.Result, mc *tc.Traffic
 		return
 	}
 
+	if mc != nil {
+		if serverInfo, ok := mc.TrafficServer[string(newResult.ID)]; ok && len(serverInfo.Interfaces) > 0 {
+			getInterfaceVitals(newResult, prevResult, serverInfo.Interfaces)
+			return
+		}
+	}
+
 	// proc.net.dev -- need to compare to prevSample
 	// value looks like
 	// "bond0:8495786321839 31960528603    0    0    0     0          0   2349716 143283576747316 101104535041    0    0    0     0       0          0"
@@ -92,11 +101,107 @@ func GetVitals(newResult *cache.Result, prevResult *cache.Result, mc *tc.Traffic
 	// log.Infoln(newResult.Id, "BytesOut", newResult.Vitals.BytesOut, "BytesIn", newResult.Vitals.BytesIn, "Kbps", newResult.Vitals.KbpsOut, "max", newResult.Vitals.MaxKbpsOut)
 }
 
+// interfaceOperStatesDown are the Linux interface operstates of an interface which can't pass traffic.
+var interfaceOperStatesDown = map[string]struct{}{
+	"down":           struct{}{},
+	"lowerlayerdown": struct{}{},
+	"notpresent":     struct{}{},
+}
+
+// getInterfaceVitals sets the vitals of each of the server's monitored interfaces, and the result's bandwidth vitals to the sums of those of the interfaces which haven't failed.
+// An interface's max kbps is its declared max, or the speed the cache reports if it doesn't declare one. If the cache reports a lower speed than the declared max, for example a bond with a failed member, the reported speed is used.
+func getInterfaceVitals(newResult *cache.Result, prevResult *cache.Result, interfaces []tc.TrafficServerInterface) {
+	newResult.Vitals.Interfaces = make(map[string]cache.InterfaceVitals, len(interfaces))
+	for _, inf := range interfaces {
+		vitals := cache.InterfaceVitals{Required: inf.Required}
+		system, ok := interfaceSystem(newResult.Astats.System, inf.Name)
+		_, down := interfaceOperStatesDown[system.OperState]
+		switch {
+		case !ok:
+			vitals.Error = errors.New("interface " + inf.Name + " not found in stats")
+		case down:
+			vitals.Error = errors.New("interface " + inf.Name + " operstate " + system.OperState)
+		default:
+			vitals.BytesIn, vitals.BytesOut, vitals.Error = parseInterfaceProcNetDev(inf.Name, system.ProcNetDev)
+		}
+		if vitals.Error != nil {
+			newResult.Vitals.Interfaces[inf.Name] = vitals
+			continue
+		}
+
+		speedKbps := int64(system.Speed) * 1000
+		vitals.MaxKbpsOut = inf.MaxKbps
+		if vitals.MaxKbpsOut == 0 || (speedKbps > 0 && speedKbps < vitals.MaxKbpsOut) {
+			vitals.MaxKbpsOut = speedKbps
+		}
+		if prevResult != nil {
+			if prevVitals, ok := prevResult.Vitals.Interfaces[inf.Name]; ok && prevVitals.BytesOut != 0 && prevVitals.BytesOut <= vitals.BytesOut {
+				elapsedTimeInSecs := float64(newResult.Time.UnixNano()-prevResult.Time.UnixNano()) / 1000000000
+				vitals.KbpsOut = int64(float64((vitals.BytesOut-prevVitals.BytesOut)*8/1000) / elapsedTimeInSecs)
+			}
+		}
+		newResult.Vitals.Interfaces[inf.Name] = vitals
+
+		newResult.Vitals.BytesIn += vitals.BytesIn
+		newResult.Vitals.BytesOut += vitals.BytesOut
+		newResult.Vitals.KbpsOut += vitals.KbpsOut
+		newResult.Vitals.MaxKbpsOut += vitals.MaxKbpsOut
+	}
+}
+
+// interfaceSystem returns the stats of the given interface, and whether the cache reported them. The interface reported as inf.name is used if the cache doesn't report the interface separately, for caches which only report one interface.
+func interfaceSystem(system cache.AstatsSystem, name string) (cache.AstatsSystemInterface, bool) {
+	if inf, ok := system.Interfaces[name]; ok {
+		return inf, true
+	}
+	if name == system.InfName && system.ProcNetDev != "" {
+		return cache.AstatsSystemInterface{Speed: system.InfSpeed, ProcNetDev: system.ProcNetDev}, true
+	}
+	return cache.AstatsSystemInterface{}, false
+}
+
+// parseInterfaceProcNetDev parses the given interface's proc.net.dev line, and returns its bytes in and out.
+func parseInterfaceProcNetDev(name string, procNetDev string) (int64, int64, error) {
+	colon := strings.LastIndex(procNetDev, ":")
+	if colon < 0 {
+		return 0, 0, fmt.Errorf("interface %s proc.net.dev '%s' malformed", name, procNetDev)
+	}
+	numbers := strings.Fields(procNetDev[colon+1:])
+	if len(numbers) < 9 {
+		return 0, 0, fmt.Errorf("interface %s proc.net.dev '%s' malformed", name, procNetDev)
+	}
+	bytesIn, err := strconv.ParseInt(numbers[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("interface %s converting BytesIn from procnetdev: %v", name, err)
+	}
+	bytesOut, err := strconv.ParseInt(numbers[8], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("interface %s converting BytesOut from procnetdev: %v", name, err)
+	}
+	return bytesIn, bytesOut, nil
+}
+
+// requiredInterfaceError returns the error of the first required interface which has failed, by name, or nil if none have.
+func requiredInterfaceError(vitals cache.Vitals) error {
+	names := make([]string, 0, len(vitals.Interfaces))
+	for name, inf := range vitals.Interfaces {
+		if inf.Required && inf.Error != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return vitals.Interfaces[names[0]].Error
+}
+
 func EvalCacheWithStatusInfo(result cache.ResultInfo, mc *tc.TrafficMonitorConfigMap, status tc.CacheStatus, serverInfo tc.TrafficServer) (bool, string, string) {
 	availability := AvailableStr
 	if !result.Available {
 		availability = UnavailableStr
 	}
+	interfaceErr := requiredInterfaceError(result.Vitals)
 	switch {
 	case status == tc.CacheStatusInvalid:
 		log.Errorf("Cache %v got invalid status from Traffic Ops '%v' - treating as OFFLINE\n", result.ID, serverInfo.ServerStatus)
@@ -112,6 +217,8 @@ func EvalCacheWithStatusInfo(result cache.ResultInfo, mc *tc.TrafficMonitorConfi
 		return false, eventDesc(status, fmt.Sprintf("%v", result.Error)), ""
 	case result.System.NotAvailable == true:
 		return false, eventDesc(status, fmt.Sprintf("system.notAvailable == %v", result.System.NotAvailable)), ""
+	case interfaceErr != nil:
+		return false, eventDesc(status, interfaceErr.Error()), ""
 	}
 	return result.Available, eventDesc(status, availability), ""
 }
@@ -119,67 +226,132 @@ func EvalCacheWithStatusInfo(result cache.ResultInfo, mc *tc.TrafficMonitorConfi
 const AvailableStr = "available"
 const UnavailableStr = "unavailable"
 
-// EvalCache returns whether the given cache should be marked available, a string describing why, and which stat exceeded a threshold. The `stats` may be nil, for pollers which don't poll stats.
-// The availability of EvalCache MAY NOT be used to directly set the cache's local availability, because the threshold stats may not be part of the poller which produced the result. Rather, if the cache was previously unavailable from a threshold, it must be verified that threshold stat is in the results before setting the cache to available.
-// The resultStats may be nil, and if so, won't be checked for thresholds. For example, the Health poller doesn't have Stats.
-// TODO change to return a `cache.AvailableStatus`
-func EvalCache(result cache.ResultInfo, resultStats *threadsafe.ResultStatValHistory, mc *tc.TrafficMonitorConfigMap) (bool, string, string) {
+// EvalCache returns whether the given cache should be marked available, a string describing why, which stat exceeded a threshold, and the hysteresis state of each threshold, as an AvailableStatus without a Poller. The `stats` may be nil, for pollers which don't poll stats.
+// The prevThresholds are the threshold states of the cache's previous AvailableStatus, and may be nil. A threshold whose stat isn't in the given result keeps its previous state, so a poller which doesn't have that stat (for example, the Health poller doesn't have Stats) won't mark a cache available which was marked unavailable by that threshold.
+// Thresholds on interface stats, prefixed by cache.InterfaceStatPrefix, are evaluated for each of the server's required interfaces, and the threshold states are those of each interface's stat, e.g. `interface.bond0.availableBandwidthInKbps`.
+// Thresholds are only evaluated by IPv4 polls, so each poll interval counts once toward hysteresis; an IPv6 poll keeps the previous threshold states, as a poller without the stats would.
+// It also returns the reason for each threshold change which was damped by hysteresis, for the event log.
+func EvalCache(result cache.ResultInfo, resultStats *threadsafe.ResultStatValHistory, mc *tc.TrafficMonitorConfigMap, prevThresholds map[string]cache.ThresholdStatus) (cache.AvailableStatus, []string) {
 	serverInfo, ok := mc.TrafficServer[string(result.ID)]
 	if !ok {
 		log.Errorf("Cache %v missing from from Traffic Ops Monitor Config - treating as OFFLINE\n", result.ID)
-		return false, "ERROR - server missing in Traffic Ops monitor config", ""
+		return cache.AvailableStatus{Available: false, Why: "ERROR - server missing in Traffic Ops monitor config"}, nil
 	}
 	status := tc.CacheStatusFromString(serverInfo.ServerStatus)
+	availStatus := cache.AvailableStatus{Status: serverInfo.ServerStatus}
 	if status == tc.CacheStatusOnline {
 		// return here first, even though EvalCacheWithStatus checks online, because we later assume that if EvalCacheWithStatus returns true, to return false if thresholds are exceeded; but, if the cache is ONLINE, we don't want to check thresholds.
-		return true, eventDesc(status, AvailableStr), ""
+		availStatus.Available, availStatus.Why = true, eventDesc(status, AvailableStr)
+		return availStatus, nil
 	}
 
 	serverProfile, ok := mc.Profile[serverInfo.Profile]
 	if !ok {
 		log.Errorf("Cache %v profile %v missing from from Traffic Ops Monitor Config - treating as OFFLINE\n", result.ID, serverInfo.Profile)
-		return false, "ERROR - server profile missing in Traffic Ops monitor config", ""
+		availStatus.Available, availStatus.Why = false, "ERROR - server profile missing in Traffic Ops monitor config"
+		return availStatus, nil
 	}
 
 	avail, eventDescVal, eventMsg := EvalCacheWithStatusInfo(result, mc, status, serverInfo)
 	if !avail {
-		return avail, eventDescVal, eventMsg
+		availStatus.Available, availStatus.Why, availStatus.UnavailableStat = avail, eventDescVal, eventMsg
+		if status == tc.CacheStatusReported {
+			// a failed poll of a REPORTED cache doesn't change its thresholds, but an ADMIN_DOWN or OFFLINE cache's thresholds start over when it's REPORTED again.
+			availStatus.Thresholds = prevThresholds
+		}
+		return availStatus, nil
 	}
 
 	computedStats := cache.ComputedStats()
+	interfaceStats := cache.InterfaceComputedStats()
+	if result.UsingIPv6 {
+		computedStats, interfaceStats, resultStats = nil, nil, nil
+	}
 
-	for stat, threshold := range serverProfile.Parameters.Thresholds {
-		resultStat := interface{}(nil)
-		if computedStatF, ok := computedStats[stat]; ok {
-			dummyCombinedstate := tc.IsAvailable{} // the only stats which use combinedState are things like isAvailable, which don't make sense to ever be thresholds.
-			resultStat = computedStatF(result, serverInfo, serverProfile, dummyCombinedstate)
-		} else {
-			if resultStats == nil {
-				continue
-			}
-			resultStatHistory := resultStats.Load(stat)
-			if len(resultStatHistory) == 0 {
-				continue
+	thresholds := map[string]cache.ThresholdStatus{}
+	dampedReasons := []string{}
+	exceededStat := ""
+	exceededWhy := ""
+	evalThreshold := func(stat string, threshold tc.HealthThreshold, resultStat interface{}) {
+		prevThreshold := prevThresholds[stat]
+		thresholds[stat] = prevThreshold
+
+		if resultStat == nil {
+			// this poller doesn't have the stat, so the threshold keeps its previous state.
+			if prevThreshold.Exceeded && (exceededStat == "" || stat < exceededStat) {
+				exceededStat, exceededWhy = stat, stat+" exceeded threshold"
 			}
-			resultStat = resultStatHistory[0].Val
+			return
 		}
 
 		resultStatNum, ok := util.ToNumeric(resultStat)
 		if !ok {
 			log.Errorf("health.EvalCache threshold stat %s was not a number: %v", stat, resultStat)
+			return
+		}
+
+		nextThreshold, dampedReason := evalHysteresis(stat, prevThreshold, !inThreshold(threshold, resultStatNum), threshold.Hysteresis, result.Time)
+		thresholds[stat] = nextThreshold
+		if dampedReason != "" {
+			dampedReasons = append(dampedReasons, eventDesc(status, dampedReason))
+		}
+		if !nextThreshold.Exceeded || (exceededStat != "" && stat > exceededStat) {
+			return
+		}
+		exceededStat = stat
+		if inThreshold(threshold, resultStatNum) {
+			exceededWhy = fmt.Sprintf("%s within threshold for %d of %d polls", stat, nextThreshold.Clean, requiredUpCount(threshold.Hysteresis, flapPenalty(nextThreshold, threshold.Hysteresis, result.Time)))
+		} else {
+			exceededWhy = exceedsThresholdMsg(stat, threshold, resultStatNum)
+		}
+	}
+
+	for stat, threshold := range serverProfile.Parameters.Thresholds {
+		if strings.HasPrefix(stat, cache.InterfaceStatPrefix) {
+			// an interface threshold is evaluated for each required interface, each with its own hysteresis.
+			infStat := stat[len(cache.InterfaceStatPrefix):]
+			for _, inf := range serverInfo.Interfaces {
+				if inf.Required {
+					evalThreshold(cache.InterfaceStatName(inf.Name, infStat), threshold, interfaceStat(result, inf.Name, interfaceStats[infStat]))
+				}
+			}
 			continue
 		}
 
-		if !inThreshold(threshold, resultStatNum) {
-			return false, eventDesc(status, exceedsThresholdMsg(stat, threshold, resultStatNum)), stat
+		resultStat := interface{}(nil)
+		if computedStatF, ok := computedStats[stat]; ok {
+			dummyCombinedstate := tc.IsAvailable{} // the only stats which use combinedState are things like isAvailable, which don't make sense to ever be thresholds.
+			resultStat = computedStatF(result, serverInfo, serverProfile, dummyCombinedstate)
+		} else if resultStats != nil {
+			if resultStatHistory := resultStats.Load(stat); len(resultStatHistory) > 0 {
+				resultStat = resultStatHistory[0].Val
+			}
 		}
+		evalThreshold(stat, threshold, resultStat)
+	}
+	sort.Strings(dampedReasons)
+
+	availStatus.Thresholds = thresholds
+	if exceededStat != "" {
+		availStatus.Available, availStatus.Why, availStatus.UnavailableStat = false, eventDesc(status, exceededWhy), exceededStat
+		return availStatus, dampedReasons
 	}
+	availStatus.Available, availStatus.Why, availStatus.UnavailableStat = avail, eventDescVal, eventMsg
+	return availStatus, dampedReasons
+}
 
-	return avail, eventDescVal, eventMsg
+// interfaceStat returns the stat of the given interface computed by computeStat, or nil if the result doesn't have the interface, the interface has failed, or computeStat is nil.
+func interfaceStat(result cache.ResultInfo, name string, computeStat cache.InterfaceStatComputeFunc) interface{} {
+	vitals, ok := result.Vitals.Interfaces[name]
+	if !ok || vitals.Error != nil || computeStat == nil {
+		return nil
+	}
+	return computeStat(vitals)
 }
 
 // CalcAvailabilityWithStats calculates the availability of each cache in results.
 // statResultHistory may be nil, in which case stats won't be used to calculate availability.
+// Availability is calculated separately for each address family, by the results polled over it, and a cache is available if it's available over either family. The local cache statuses are those of the IPv4 polls.
 func CalcAvailability(results []cache.Result, pollerName string, statResultHistory *threadsafe.ResultStatHistory, mc tc.TrafficMonitorConfigMap, toData todata.TOData, localCacheStatusThreadsafe threadsafe.CacheAvailableStatus, localStates peer.CRStatesThreadsafe, events ThreadsafeEvents) {
 	localCacheStatuses := localCacheStatusThreadsafe.Get().Copy()
 	statResults := (*threadsafe.ResultStatValHistory)(nil)
@@ -188,28 +360,41 @@ func CalcAvailability(results []cache.Result, pollerName string, statResultHisto
 			statResultsVal := statResultHistory.LoadOrStore(result.ID)
 			statResults = &statResultsVal
 		}
-		isAvailable, whyAvailable, unavailableStat := EvalCache(cache.ToInfo(result), statResults, &mc)
+		availStatus, dampedReasons := EvalCache(cache.ToInfo(result), statResults, &mc, localCacheStatuses[result.ID].Thresholds)
+		isAvailable, whyAvailable := availStatus.Available, availStatus.Why
 
-		// if the cache is now Available, and was previously unavailable due to a threshold, make sure this poller contains the stat which exceeded the threshold.
-		if previousStatus, hasPreviousStatus := localCacheStatuses[result.ID]; isAvailable && hasPreviousStatus && !previousStatus.Available && previousStatus.UnavailableStat != "" {
-			if !result.HasStat(previousStatus.UnavailableStat) {
-				return
-			}
+		protocol := "IPv4"
+		if result.UsingIPv6 {
+			protocol = "IPv6"
+		} else {
+			availStatus.Poller = pollerName
+			localCacheStatuses[result.ID] = availStatus // TODO move within localStates?
 		}
-		localCacheStatuses[result.ID] = cache.AvailableStatus{
-			Available:       isAvailable,
-			Status:          mc.TrafficServer[string(result.ID)].ServerStatus,
-			Why:             whyAvailable,
-			UnavailableStat: unavailableStat,
-			Poller:          pollerName,
-		} // TODO move within localStates?
-
-		if available, ok := localStates.GetCache(result.ID); !ok || available.IsAvailable != isAvailable {
-			log.Infof("Changing state for %s was: %t now: %t because %s poller: %v error: %v", result.ID, available.IsAvailable, isAvailable, whyAvailable, pollerName, result.Error)
-			events.Add(Event{Time: Time(time.Now()), Description: whyAvailable + " (" + pollerName + ")", Name: string(result.ID), Hostname: string(result.ID), Type: toData.ServerTypes[result.ID].String(), Available: isAvailable})
+
+		for _, dampedReason := range dampedReasons {
+			log.Infof("Threshold hysteresis for %s: %s poller: %v", result.ID, dampedReason, pollerName)
+			events.Add(Event{Time: Time(time.Now()), Description: "Threshold hysteresis: " + dampedReason + " (" + pollerName + ")", Name: string(result.ID), Hostname: string(result.ID), Type: toData.ServerTypes[result.ID].String(), Available: isAvailable, CacheGroup: string(toData.ServerCachegroups[result.ID])})
 		}
 
-		localStates.SetCache(result.ID, tc.IsAvailable{IsAvailable: isAvailable})
+		available, ok := localStates.GetCache(result.ID)
+		wasAvailable := available.Ipv4Available
+		if result.UsingIPv6 {
+			wasAvailable = available.Ipv6Available
+		}
+		if !ok || wasAvailable != isAvailable {
+			log.Infof("Changing state for %s %s was: %t now: %t because %s poller: %v error: %v", result.ID, protocol, wasAvailable, isAvailable, whyAvailable, pollerName, result.Error)
+			events.Add(Event{Time: Time(time.Now()), Description: whyAvailable + " (" + pollerName + ", " + protocol + ")", Name: string(result.ID), Hostname: string(result.ID), Type: toData.ServerTypes[result.ID].String(), Available: isAvailable, CacheGroup: string(toData.ServerCachegroups[result.ID])})
+		}
+
+		// isAvailable is driven by the IPv4 poll alone, as it always has been; the IPv6 poll only sets ipv6Available.
+		nextAvailable := tc.IsAvailable{IsAvailable: available.IsAvailable, Ipv4Available: available.Ipv4Available, Ipv6Available: available.Ipv6Available}
+		if result.UsingIPv6 {
+			nextAvailable.Ipv6Available = isAvailable
+		} else {
+			nextAvailable.IsAvailable = isAvailable
+			nextAvailable.Ipv4Available = isAvailable
+		}
+		localStates.SetCache(result.ID, nextAvailable)
 	}
 	calculateDeliveryServiceState(toData.DeliveryServiceServers, localStates, toData)
 	localCacheStatusThreadsafe.Set(localCacheStatuses)
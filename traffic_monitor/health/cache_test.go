@@ -20,6 +20,8 @@ package health
  */
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -147,3 +149,247 @@ func TestCalcAvailabilityThresholds(t *testing.T) {
 		t.Fatalf("localCacheStatus.Why expected 'availableBandwidthInKbps too low' actual %v", localCacheStatus.Why)
 	}
 }
+
+func TestCalcAvailabilityHysteresis(t *testing.T) {
+	cacheName := tc.CacheName("myCacheName")
+	mc := tc.TrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.TrafficServer{
+			string(cacheName): {ServerStatus: string(tc.CacheStatusReported), Profile: "myProfileName"},
+		},
+		Profile: map[string]tc.TMProfile{
+			"myProfileName": tc.TMProfile{
+				Name: "myProfileName",
+				Parameters: tc.TMParameters{
+					Thresholds: map[string]tc.HealthThreshold{
+						"loadavg": tc.HealthThreshold{Val: 25, Comparator: "<", Hysteresis: tc.HealthHysteresis{DownCount: 2, UpCount: 3}},
+					},
+				},
+			},
+		},
+	}
+	toData := todata.TOData{
+		ServerTypes:            map[tc.CacheName]tc.CacheType{cacheName: tc.CacheTypeEdge},
+		DeliveryServiceServers: map[tc.DeliveryServiceName][]tc.CacheName{},
+		ServerCachegroups:      map[tc.CacheName]tc.CacheGroupName{cacheName: "myCG"},
+	}
+	localCacheStatusThreadsafe := threadsafe.NewCacheAvailableStatus()
+	localStates := peer.NewCRStatesThreadsafe()
+	events := NewThreadsafeEvents(200)
+
+	loadAvgs := []float64{30, 10, 30, 30, 30, 10, 10, 10}
+	expectedAvailables := []bool{true, true, true, false, false, false, false, true}
+	for i, loadAvg := range loadAvgs {
+		result := cache.Result{ID: cacheName, Time: time.Now(), Available: true, Vitals: cache.Vitals{LoadAvg: loadAvg}}
+		CalcAvailability([]cache.Result{result}, "stat", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+		if available := localCacheStatusThreadsafe.Get()[cacheName].Available; available != expectedAvailables[i] {
+			t.Errorf("poll %v loadavg %v expected available %v, actual %v", i, loadAvg, expectedAvailables[i], available)
+		}
+	}
+
+	hysteresisEvents := 0
+	for _, event := range events.Get() {
+		if strings.HasPrefix(event.Description, "Threshold hysteresis: ") {
+			hysteresisEvents++
+			if !strings.Contains(event.Description, "loadavg") {
+				t.Errorf("hysteresis event expected to contain the stat 'loadavg', actual '%v'", event.Description)
+			}
+		}
+	}
+	if hysteresisEvents != 2 {
+		t.Errorf("hysteresis events expected 2, actual %v", hysteresisEvents)
+	}
+}
+
+func TestCalcAvailabilityIPv6(t *testing.T) {
+	cacheName := tc.CacheName("myCacheName")
+	mc := tc.TrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.TrafficServer{
+			string(cacheName): {ServerStatus: string(tc.CacheStatusReported), Profile: "myProfileName"},
+		},
+		Profile: map[string]tc.TMProfile{
+			"myProfileName": tc.TMProfile{
+				Name: "myProfileName",
+				Parameters: tc.TMParameters{
+					Thresholds: map[string]tc.HealthThreshold{
+						"loadavg": tc.HealthThreshold{Val: 25, Comparator: "<", Hysteresis: tc.HealthHysteresis{DownCount: 2}},
+					},
+				},
+			},
+		},
+	}
+	toData := todata.TOData{
+		ServerTypes:            map[tc.CacheName]tc.CacheType{cacheName: tc.CacheTypeEdge},
+		DeliveryServiceServers: map[tc.DeliveryServiceName][]tc.CacheName{},
+		ServerCachegroups:      map[tc.CacheName]tc.CacheGroupName{cacheName: "myCG"},
+	}
+	localCacheStatusThreadsafe := threadsafe.NewCacheAvailableStatus()
+	localStates := peer.NewCRStatesThreadsafe()
+	localStates.AddCache(cacheName, tc.IsAvailable{}) // caches are seeded unavailable until polled
+	events := NewThreadsafeEvents(200)
+
+	// test that a failed IPv6 poll marks only IPv6 unavailable
+
+	ipv4Result := cache.Result{ID: cacheName, Time: time.Now(), Available: true, Vitals: cache.Vitals{LoadAvg: 10}}
+	ipv6Result := cache.Result{ID: cacheName, Time: time.Now(), Available: true, Vitals: cache.Vitals{LoadAvg: 10}, UsingIPv6: true}
+	CalcAvailability([]cache.Result{ipv4Result, ipv6Result}, "health", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+	ipv6Result = cache.Result{ID: cacheName, Time: time.Now(), Error: errors.New("connection refused"), UsingIPv6: true}
+	CalcAvailability([]cache.Result{ipv4Result, ipv6Result}, "health", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+
+	if available, _ := localStates.GetCache(cacheName); !available.IsAvailable || !available.Ipv4Available || available.Ipv6Available {
+		t.Errorf("failed IPv6 poll expected available true ipv4 true ipv6 false, actual available %v ipv4 %v ipv6 %v", available.IsAvailable, available.Ipv4Available, available.Ipv6Available)
+	}
+	if localCacheStatus := localCacheStatusThreadsafe.Get()[cacheName]; !localCacheStatus.Available {
+		t.Errorf("failed IPv6 poll expected local cache status of the IPv4 poll, available true, actual false")
+	}
+	ipv6Events := 0
+	for _, event := range events.Get() {
+		if strings.HasSuffix(event.Description, "(health, IPv6)") && !event.Available {
+			ipv6Events++
+		}
+	}
+	if ipv6Events != 1 {
+		t.Errorf("IPv6 unavailable events expected 1, actual %v", ipv6Events)
+	}
+
+	// test that a failed IPv4 poll marks the cache unavailable, even if IPv6 is available
+
+	ipv4Failed := cache.Result{ID: cacheName, Time: time.Now(), Error: errors.New("connection refused")}
+	ipv6Result = cache.Result{ID: cacheName, Time: time.Now(), Available: true, Vitals: cache.Vitals{LoadAvg: 10}, UsingIPv6: true}
+	CalcAvailability([]cache.Result{ipv4Failed, ipv6Result}, "health", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+
+	if available, _ := localStates.GetCache(cacheName); available.IsAvailable || available.Ipv4Available || !available.Ipv6Available {
+		t.Errorf("failed IPv4 poll expected available false ipv4 false ipv6 true, actual available %v ipv4 %v ipv6 %v", available.IsAvailable, available.Ipv4Available, available.Ipv6Available)
+	}
+	CalcAvailability([]cache.Result{ipv4Result, ipv6Result}, "health", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+
+	// test that IPv6 polls don't count toward threshold hysteresis
+
+	ipv4Result.Vitals.LoadAvg = 30
+	ipv6Result = cache.Result{ID: cacheName, Time: time.Now(), Available: true, Vitals: cache.Vitals{LoadAvg: 30}, UsingIPv6: true}
+	CalcAvailability([]cache.Result{ipv4Result, ipv6Result}, "health", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+
+	if available, _ := localStates.GetCache(cacheName); !available.IsAvailable || !available.Ipv4Available || !available.Ipv6Available {
+		t.Errorf("one threshold violation expected available true ipv4 true ipv6 true, actual available %v ipv4 %v ipv6 %v", available.IsAvailable, available.Ipv4Available, available.Ipv6Available)
+	}
+	if violations := localCacheStatusThreadsafe.Get()[cacheName].Thresholds["loadavg"].Violations; violations != 1 {
+		t.Errorf("threshold violations expected 1, actual %v", violations)
+	}
+
+	// test that an exceeded threshold marks both address families unavailable
+
+	CalcAvailability([]cache.Result{ipv4Result, ipv6Result}, "health", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+
+	if available, _ := localStates.GetCache(cacheName); available.IsAvailable || available.Ipv4Available || available.Ipv6Available {
+		t.Errorf("exceeded threshold expected available false ipv4 false ipv6 false, actual available %v ipv4 %v ipv6 %v", available.IsAvailable, available.Ipv4Available, available.Ipv6Available)
+	}
+}
+
+func TestCalcAvailabilityInterfaces(t *testing.T) {
+	cacheName := tc.CacheName("myCacheName")
+	mc := tc.TrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.TrafficServer{
+			string(cacheName): {
+				ServerStatus:  string(tc.CacheStatusReported),
+				Profile:       "myProfileName",
+				InterfaceName: "bond0",
+				Interfaces: []tc.TrafficServerInterface{
+					{Name: "bond0", MaxKbps: 20000000, Required: true},
+					{Name: "eth2", MaxKbps: 10000000, Required: true},
+					{Name: "eth3", Required: false},
+				},
+			},
+		},
+		Profile: map[string]tc.TMProfile{
+			"myProfileName": tc.TMProfile{
+				Name: "myProfileName",
+				Parameters: tc.TMParameters{
+					Thresholds: map[string]tc.HealthThreshold{
+						"interface.availableBandwidthInKbps": tc.HealthThreshold{Val: 2000000, Comparator: ">"},
+						"availableBandwidthInKbps":           tc.HealthThreshold{Val: 5000000, Comparator: ">"},
+					},
+				},
+			},
+		},
+	}
+	toData := todata.TOData{
+		ServerTypes:            map[tc.CacheName]tc.CacheType{cacheName: tc.CacheTypeEdge},
+		DeliveryServiceServers: map[tc.DeliveryServiceName][]tc.CacheName{},
+		ServerCachegroups:      map[tc.CacheName]tc.CacheGroupName{cacheName: "myCG"},
+	}
+	localCacheStatusThreadsafe := threadsafe.NewCacheAvailableStatus()
+	localStates := peer.NewCRStatesThreadsafe()
+	events := NewThreadsafeEvents(200)
+
+	newResult := func(t time.Time, bond0Out int64, eth2Out int64, eth2OperState string) cache.Result {
+		return cache.Result{
+			ID:        cacheName,
+			Time:      t,
+			Available: true,
+			Astats: cache.Astats{
+				Ats: map[string]interface{}{},
+				System: cache.AstatsSystem{
+					InfName:     "bond0",
+					InfSpeed:    20000,
+					ProcNetDev:  "bond0: 1000 10 0 0 0 0 0 0 " + strconv.FormatInt(bond0Out, 10) + " 10 0 0 0 0 0 0",
+					ProcLoadavg: "0.30 0.12 0.21 1/863 1421",
+					Interfaces: map[string]cache.AstatsSystemInterface{
+						"bond0": {OperState: "up", Speed: 20000, ProcNetDev: "bond0: 1000 10 0 0 0 0 0 0 " + strconv.FormatInt(bond0Out, 10) + " 10 0 0 0 0 0 0"},
+						"eth2":  {OperState: eth2OperState, Speed: 25000, ProcNetDev: "eth2: 1000 10 0 0 0 0 0 0 " + strconv.FormatInt(eth2Out, 10) + " 10 0 0 0 0 0 0"},
+					},
+				},
+			},
+		}
+	}
+
+	// test per-interface and aggregate vitals, with the missing optional interface not failing the cache
+
+	start := time.Now()
+	prevResult := newResult(start, 1000000000, 1000000000, "up")
+	GetVitals(&prevResult, nil, &mc)
+	result := newResult(start.Add(time.Second), 1000000000+1250000000, 1000000000+125000000, "up") // bond0 10 gigabits, eth2 1 gigabit
+	GetVitals(&result, &prevResult, &mc)
+	if result.Error != nil {
+		t.Fatalf("GetVitals error expected nil, actual %v", result.Error)
+	}
+
+	if bond0 := result.Vitals.Interfaces["bond0"]; bond0.Error != nil || bond0.KbpsOut != 10000000 || bond0.MaxKbpsOut != 20000000 {
+		t.Errorf("bond0 vitals expected kbps 10000000 max 20000000, actual %+v", bond0)
+	}
+	if eth2 := result.Vitals.Interfaces["eth2"]; eth2.Error != nil || eth2.KbpsOut != 1000000 || eth2.MaxKbpsOut != 10000000 {
+		t.Errorf("eth2 vitals expected kbps 1000000 max 10000000, actual %+v", eth2)
+	}
+	if eth3 := result.Vitals.Interfaces["eth3"]; eth3.Error == nil || eth3.Required {
+		t.Errorf("missing optional eth3 vitals expected error, actual %+v", eth3)
+	}
+	if result.Vitals.KbpsOut != 11000000 || result.Vitals.MaxKbpsOut != 30000000 {
+		t.Errorf("aggregate vitals expected kbps 11000000 max 30000000, actual kbps %v max %v", result.Vitals.KbpsOut, result.Vitals.MaxKbpsOut)
+	}
+
+	CalcAvailability([]cache.Result{result}, "stat", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+	if status := localCacheStatusThreadsafe.Get()[cacheName]; !status.Available {
+		t.Errorf("interfaces within thresholds expected available, actual unavailable: %v", status.Why)
+	}
+
+	// test that a required interface exceeding an interface threshold marks the cache unavailable, though the aggregate is within its threshold
+
+	prevResult = result
+	result = newResult(prevResult.Time.Add(time.Second), 1000000000+1250000000+2350000000, 1000000000+125000000, "up") // bond0 18.8 gigabits
+	GetVitals(&result, &prevResult, &mc)
+	CalcAvailability([]cache.Result{result}, "stat", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+	if status := localCacheStatusThreadsafe.Get()[cacheName]; status.Available || status.UnavailableStat != "interface.bond0.availableBandwidthInKbps" {
+		t.Errorf("bond0 over interface threshold expected unavailable by interface.bond0.availableBandwidthInKbps, actual available %v stat '%v'", status.Available, status.UnavailableStat)
+	}
+
+	// test that a failed required interface marks the cache unavailable
+
+	prevResult = result
+	result = newResult(prevResult.Time.Add(time.Second), 1000000000+1250000000+2350000000, 1000000000+125000000, "down")
+	GetVitals(&result, &prevResult, &mc)
+	CalcAvailability([]cache.Result{result}, "stat", nil, mc, toData, localCacheStatusThreadsafe, localStates, events)
+	if status := localCacheStatusThreadsafe.Get()[cacheName]; status.Available || !strings.Contains(status.Why, "eth2") {
+		t.Errorf("eth2 down expected unavailable because of eth2, actual available %v why '%v'", status.Available, status.Why)
+	}
+	if result.Vitals.MaxKbpsOut != 20000000 {
+		t.Errorf("aggregate max kbps with eth2 down expected 20000000, actual %v", result.Vitals.MaxKbpsOut)
+	}
+}
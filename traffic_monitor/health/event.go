@@ -21,6 +21,8 @@ package health
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,7 +35,18 @@ func (t Time) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf("%d", time.Time(t).Unix())), nil
 }
 
+// UnmarshalJSON unmarshals the Unix seconds written by MarshalJSON.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	secs, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed time '%s': %v", string(data), err)
+	}
+	*t = Time(time.Unix(secs, 0))
+	return nil
+}
+
 // Event represents an event change in aggregated data. For example, a cache being marked as unavailable.
+// CacheGroup is the cache group of cache events. Override is whether the event is a Health Protocol decision made from peers' states, such as an optimistic override or a quorum vote.
 type Event struct {
 	Time        Time   `json:"time"`
 	Index       uint64 `json:"index"`
@@ -42,14 +55,68 @@ type Event struct {
 	Hostname    string `json:"hostname"`
 	Type        string `json:"type"`
 	Available   bool   `json:"isAvailable"`
+	CacheGroup  string `json:"cachegroup,omitempty"`
+	Override    bool   `json:"override,omitempty"`
+}
+
+// EventFilter selects events. Empty fields match all events. Names match either the event name or hostname, and types are case-insensitive.
+// Start and End are inclusive, and Offset and Limit paginate the matching events, newest first. A Limit of 0 is unlimited.
+type EventFilter struct {
+	Names       map[string]struct{}
+	CacheGroups map[string]struct{}
+	Types       map[string]struct{}
+	Available   *bool
+	Override    *bool
+	Start       time.Time
+	End         time.Time
+	Offset      uint64
+	Limit       uint64
+}
+
+// IsEmpty returns whether the filter matches all events, without pagination.
+func (f EventFilter) IsEmpty() bool {
+	return len(f.Names) == 0 && len(f.CacheGroups) == 0 && len(f.Types) == 0 && f.Available == nil && f.Override == nil && f.Start.IsZero() && f.End.IsZero() && f.Offset == 0 && f.Limit == 0
+}
+
+// Match returns whether the given event matches the filter. Pagination isn't considered.
+func (f EventFilter) Match(e Event) bool {
+	if len(f.Names) != 0 {
+		_, nameOk := f.Names[e.Name]
+		_, hostnameOk := f.Names[e.Hostname]
+		if !nameOk && !hostnameOk {
+			return false
+		}
+	}
+	if _, ok := f.CacheGroups[e.CacheGroup]; len(f.CacheGroups) != 0 && !ok {
+		return false
+	}
+	if _, ok := f.Types[strings.ToUpper(e.Type)]; len(f.Types) != 0 && !ok {
+		return false
+	}
+	if f.Available != nil && *f.Available != e.Available {
+		return false
+	}
+	if f.Override != nil && *f.Override != e.Override {
+		return false
+	}
+	// event times are whole seconds, so the start is truncated to include events in its second.
+	if !f.Start.IsZero() && time.Time(e.Time).Before(f.Start.Truncate(time.Second)) {
+		return false
+	}
+	if !f.End.IsZero() && time.Time(e.Time).After(f.End) {
+		return false
+	}
+	return true
 }
 
 // Events provides safe access for multiple goroutines readers and a single writer to a stored Events slice.
+// If it has an EventStore, every event is also appended to the store, and filtered queries are made against the store, so they include events no longer in memory, and from before a restart.
 type ThreadsafeEvents struct {
 	events    *[]Event
 	m         *sync.RWMutex
 	nextIndex *uint64
 	max       uint64
+	store     *EventStore
 }
 
 func copyEvents(a []Event) []Event {
@@ -64,6 +131,19 @@ func NewThreadsafeEvents(maxEvents uint64) ThreadsafeEvents {
 	return ThreadsafeEvents{m: &sync.RWMutex{}, events: &[]Event{}, nextIndex: &i, max: maxEvents}
 }
 
+// NewThreadsafeEventsStore creates a new Threadsafe object which persists events to the given store. The newest events in the store are loaded into memory, and indexes continue from the newest.
+func NewThreadsafeEventsStore(maxEvents uint64, store *EventStore) (ThreadsafeEvents, error) {
+	events, err := store.Last(maxEvents)
+	if err != nil {
+		return ThreadsafeEvents{}, fmt.Errorf("loading events: %v", err)
+	}
+	i := uint64(0)
+	if len(events) > 0 {
+		i = events[0].Index + 1
+	}
+	return ThreadsafeEvents{m: &sync.RWMutex{}, events: &events, nextIndex: &i, max: maxEvents, store: store}, nil
+}
+
 // Get returns the internal slice of Events for reading. This MUST NOT be modified. If modification is necessary, copy the slice.
 func (o *ThreadsafeEvents) Get() []Event {
 	o.m.RLock()
@@ -85,5 +165,40 @@ func (o *ThreadsafeEvents) Add(e Event) {
 	// o.m.Lock()
 	*o.events = events
 	*o.nextIndex++
+	if o.store != nil {
+		if err := o.store.Append(e); err != nil {
+			log.Errorf("event store appending event %v: %v\n", e.Index, err)
+		}
+	}
 	o.m.Unlock()
 }
+
+// Query returns the events matching the given filter, newest first. If there's a store, and the filter isn't empty, the store is queried; otherwise, the events in memory are.
+// At most the maximum number of events kept in memory are returned if the filter has no limit, so a filtered query doesn't read every file of the store.
+func (o *ThreadsafeEvents) Query(filter EventFilter) ([]Event, error) {
+	if filter.IsEmpty() {
+		return o.Get(), nil
+	}
+	if filter.Limit == 0 {
+		filter.Limit = o.max
+	}
+	if o.store != nil {
+		return o.store.Query(filter)
+	}
+	matches := []Event{}
+	skipped := uint64(0)
+	for _, e := range o.Get() {
+		if !filter.Match(e) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		matches = append(matches, e)
+		if filter.Limit != 0 && uint64(len(matches)) >= filter.Limit {
+			break
+		}
+	}
+	return matches, nil
+}
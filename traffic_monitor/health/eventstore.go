@@ -0,0 +1,234 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+)
+
+const eventStoreFilePrefix = "events-"
+const eventStoreFileSuffix = ".log"
+
+// EventStore is an append-only on-disk store of events. Events are written as JSON lines to a file, which is rotated when it exceeds a size or age, and rotated files are deleted when all their events are older than the max age.
+// File names contain the time the file was started, so queries over a time range don't read files which ended before it.
+type EventStore struct {
+	dir          string
+	maxFileBytes uint64
+	maxFileAge   time.Duration
+	maxAge       time.Duration
+	file         *os.File
+	fileStart    time.Time
+	fileBytes    uint64
+	m            *sync.Mutex
+}
+
+// NewEventStore creates a store in the given directory, creating it if it doesn't exist. Each file is rotated after maxFileBytes or maxFileAge, and events older than maxAge are deleted. A zero value for any of these disables that limit.
+// A new file is always started, so existing files are never modified.
+func NewEventStore(dir string, maxFileBytes uint64, maxFileAge time.Duration, maxAge time.Duration) (*EventStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.New("creating event store directory: " + err.Error())
+	}
+	s := &EventStore{dir: dir, maxFileBytes: maxFileBytes, maxFileAge: maxFileAge, maxAge: maxAge, m: &sync.Mutex{}}
+	if err := s.rotate(time.Now()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Append writes the given event to the current file, first rotating it if necessary. It is safe for multiple goroutines.
+func (s *EventStore) Append(e Event) error {
+	bts, err := json.Marshal(e)
+	if err != nil {
+		return errors.New("marshalling event: " + err.Error())
+	}
+	bts = append(bts, '\n')
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	now := time.Now()
+	if (s.maxFileBytes != 0 && s.fileBytes+uint64(len(bts)) > s.maxFileBytes && s.fileBytes > 0) || (s.maxFileAge != 0 && now.Sub(s.fileStart) >= s.maxFileAge) {
+		if err := s.rotate(now); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(bts)
+	s.fileBytes += uint64(n)
+	if err != nil {
+		return errors.New("writing event: " + err.Error())
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (s *EventStore) Close() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.file.Close()
+}
+
+// rotate closes the current file, if any, starts a new file, and deletes expired files. It must be called with the lock held.
+func (s *EventStore) rotate(now time.Time) error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			log.Errorf("event store closing '%s': %v\n", s.file.Name(), err)
+		}
+	}
+	name := filepath.Join(s.dir, fmt.Sprintf("%s%020d%s", eventStoreFilePrefix, now.UnixNano(), eventStoreFileSuffix))
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.New("creating event store file: " + err.Error())
+	}
+	s.file = file
+	s.fileStart = now
+	s.fileBytes = 0
+	s.deleteExpired(now)
+	return nil
+}
+
+// deleteExpired deletes files all of whose events are older than the max age, which are those whose next file started before it.
+func (s *EventStore) deleteExpired(now time.Time) {
+	if s.maxAge == 0 {
+		return
+	}
+	files, err := s.files()
+	if err != nil {
+		log.Errorf("event store listing files: %v\n", err)
+		return
+	}
+	for i := 1; i < len(files); i++ {
+		if now.Sub(files[i-1].start) < s.maxAge {
+			continue
+		}
+		if err := os.Remove(files[i].path); err != nil {
+			log.Errorf("event store deleting expired file '%s': %v\n", files[i].path, err)
+		}
+	}
+}
+
+type eventStoreFile struct {
+	path  string
+	start time.Time
+}
+
+// files returns the store's files, newest first.
+func (s *EventStore) files() ([]eventStoreFile, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []eventStoreFile{}
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, eventStoreFilePrefix) || !strings.HasSuffix(name, eventStoreFileSuffix) {
+			continue
+		}
+		nanos, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, eventStoreFilePrefix), eventStoreFileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, eventStoreFile{path: filepath.Join(s.dir, name), start: time.Unix(0, nanos)})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].start.After(files[j].start) })
+	return files, nil
+}
+
+// Query returns the events matching the given filter, newest first, after skipping filter.Offset matches and returning at most filter.Limit.
+// A zero filter.Limit returns every match, reading every file; ThreadsafeEvents.Query defaults it to max_events.
+// Events are timestamped before they're written, so files which ended before the filter's start time aren't read. Newer files are read, because an event may be written some time after its timestamp.
+func (s *EventStore) Query(filter EventFilter) ([]Event, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, errors.New("listing event store files: " + err.Error())
+	}
+	matches := []Event{}
+	skipped := uint64(0)
+	fileEnd := time.Time{} // the start of the next newer file, which is after every event in this file; zero for the current file
+	for _, file := range files {
+		end := fileEnd
+		fileEnd = file.start
+		if !filter.Start.IsZero() && !end.IsZero() && end.Before(filter.Start) {
+			break // this and all older files are before the range
+		}
+		events, err := readEventFile(file.path)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(events) - 1; i >= 0; i-- {
+			if !filter.Match(events[i]) {
+				continue
+			}
+			if skipped < filter.Offset {
+				skipped++
+				continue
+			}
+			matches = append(matches, events[i])
+			if filter.Limit != 0 && uint64(len(matches)) >= filter.Limit {
+				return matches, nil
+			}
+		}
+	}
+	return matches, nil
+}
+
+// Last returns the newest n events, newest first.
+func (s *EventStore) Last(n uint64) ([]Event, error) {
+	return s.Query(EventFilter{Limit: n})
+}
+
+// readEventFile reads the events in the given file, oldest first. Lines which can't be decoded, such as a line being written, are skipped.
+func readEventFile(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // deleted since it was listed
+		}
+		return nil, errors.New("opening event store file: " + err.Error())
+	}
+	defer file.Close()
+
+	events := []Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		e := Event{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			log.Debugf("event store skipping malformed line in '%s': %v\n", path, err)
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("reading event store file '" + path + "': " + err.Error())
+	}
+	return events, nil
+}
@@ -0,0 +1,138 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEventStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-eventstore")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewEventStore(dir, 300, 0, 0) // small enough to rotate every couple of events
+	if err != nil {
+		t.Fatalf("NewEventStore expected nil error, actual %v", err)
+	}
+	events, err := NewThreadsafeEventsStore(3, store)
+	if err != nil {
+		t.Fatalf("NewThreadsafeEventsStore expected nil error, actual %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 10; i++ {
+		cg := "cg0"
+		if i%2 == 1 {
+			cg = "cg1"
+		}
+		events.Add(Event{Time: Time(start.Add(time.Duration(i) * time.Minute)), Description: "test", Name: "edge0", Hostname: "edge0", Type: "EDGE", Available: i%3 == 0, CacheGroup: cg})
+	}
+	store.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, eventStoreFilePrefix+"*"))
+	if err != nil || len(files) < 2 {
+		t.Errorf("event store expected rotation to multiple files, actual %v %v", files, err)
+	}
+
+	if inMemory := events.Get(); len(inMemory) != 2 || inMemory[0].Index != 9 {
+		t.Errorf("events in memory expected newest max-1, actual %+v", inMemory)
+	}
+
+	cg1 := map[string]struct{}{"cg1": struct{}{}}
+	matches, err := events.Query(EventFilter{CacheGroups: cg1, Limit: 10})
+	if err != nil || len(matches) != 5 || matches[0].Index != 9 || matches[4].Index != 1 {
+		t.Errorf("query cachegroup expected 5 events from disk newest first, actual %+v %v", matches, err)
+	}
+
+	matches, err = events.Query(EventFilter{CacheGroups: cg1})
+	if err != nil || len(matches) != 3 || matches[0].Index != 9 || matches[2].Index != 5 {
+		t.Errorf("query without limit expected max events 9 to 5, actual %+v %v", matches, err)
+	}
+
+	matches, err = events.Query(EventFilter{CacheGroups: cg1, Offset: 1, Limit: 2})
+	if err != nil || len(matches) != 2 || matches[0].Index != 7 || matches[1].Index != 5 {
+		t.Errorf("query page expected events 7 and 5, actual %+v %v", matches, err)
+	}
+
+	available := true
+	matches, err = events.Query(EventFilter{Available: &available, Types: map[string]struct{}{"EDGE": struct{}{}}, Limit: 10})
+	if err != nil || len(matches) != 4 {
+		t.Errorf("query available expected 4 events, actual %+v %v", matches, err)
+	}
+
+	matches, err = events.Query(EventFilter{Start: start.Add(2 * time.Minute), End: start.Add(4 * time.Minute)})
+	if err != nil || len(matches) != 3 || matches[0].Index != 4 || matches[2].Index != 2 {
+		t.Errorf("query time range expected events 4 to 2, actual %+v %v", matches, err)
+	}
+
+	store, err = NewEventStore(dir, 300, 0, 0)
+	if err != nil {
+		t.Fatalf("reopening NewEventStore expected nil error, actual %v", err)
+	}
+	defer store.Close()
+	events, err = NewThreadsafeEventsStore(3, store)
+	if err != nil {
+		t.Fatalf("reopening NewThreadsafeEventsStore expected nil error, actual %v", err)
+	}
+	if loaded := events.Get(); len(loaded) != 3 || loaded[0].Index != 9 || loaded[0].CacheGroup != "cg1" {
+		t.Errorf("reopened events expected newest 3 loaded, actual %+v", loaded)
+	}
+	events.Add(Event{Time: Time(time.Now()), Name: "edge0", Hostname: "edge0"})
+	if added := events.Get(); added[0].Index != 10 {
+		t.Errorf("reopened events expected index to continue at 10, actual %v", added[0].Index)
+	}
+}
+
+func TestEventStoreExpiry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tm-eventstore")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewEventStore(dir, 0, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewEventStore expected nil error, actual %v", err)
+	}
+	store.Append(Event{Time: Time(time.Now()), Name: "edge0"})
+	store.m.Lock()
+	store.rotate(time.Now())
+	store.m.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	store.m.Lock()
+	store.rotate(time.Now())
+	store.m.Unlock()
+	defer store.Close()
+
+	files, err := store.files()
+	if err != nil || len(files) != 2 {
+		t.Errorf("event store expected expired file deleted leaving 2 files, actual %+v %v", files, err)
+	}
+	if matches, err := store.Query(EventFilter{}); err != nil || len(matches) != 0 {
+		t.Errorf("event store expected expired events deleted, actual %+v %v", matches, err)
+	}
+}
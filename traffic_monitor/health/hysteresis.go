@@ -0,0 +1,105 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+)
+
+// MaxFlapPenaltyMultiplier is the most a flap penalty may multiply a threshold's hysteresis UpCount by.
+const MaxFlapPenaltyMultiplier = 64
+
+// isDamped returns whether the given hysteresis changes availability differently than a plain threshold, which marks unavailable and available on the first poll.
+func isDamped(h tc.HealthHysteresis) bool {
+	return h.DownCount > 1 || h.UpCount > 1 || h.FlapHalfLife > 0
+}
+
+// flapPenalty returns the flap penalty of the given threshold status at the given time.
+func flapPenalty(s cache.ThresholdStatus, h tc.HealthHysteresis, now time.Time) float64 {
+	if h.FlapHalfLife <= 0 || s.FlapPenalty == 0 {
+		return 0
+	}
+	elapsed := now.Sub(s.FlapPenaltyTime)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return s.FlapPenalty * math.Pow(0.5, float64(elapsed)/float64(h.FlapHalfLife))
+}
+
+// requiredUpCount returns the number of consecutive polls within a threshold required to mark it no longer exceeded, with the given flap penalty.
+func requiredUpCount(h tc.HealthHysteresis, penalty float64) int {
+	upCount := h.UpCount
+	if upCount < 1 {
+		upCount = 1
+	}
+	multiplier := 1.0
+	if penalty > 1 {
+		multiplier = math.Min(math.Pow(2, penalty-1), MaxFlapPenaltyMultiplier)
+	}
+	return int(math.Ceil(float64(upCount) * multiplier))
+}
+
+// evalHysteresis returns the next status of a threshold, from its previous status and whether the latest poll exceeded it.
+// If the threshold's Exceeded changed, and the hysteresis damped the change, it also returns the reason, for the event log. Otherwise, the returned reason is empty.
+func evalHysteresis(stat string, prev cache.ThresholdStatus, exceeded bool, h tc.HealthHysteresis, now time.Time) (cache.ThresholdStatus, string) {
+	next := prev
+	if exceeded {
+		next.Clean = 0
+		next.Violations++
+		downCount := h.DownCount
+		if downCount < 1 {
+			downCount = 1
+		}
+		if next.Exceeded || next.Violations < downCount {
+			return next, ""
+		}
+		next.Exceeded = true
+		if h.FlapHalfLife > 0 {
+			next.FlapPenalty = flapPenalty(prev, h, now) + 1
+			next.FlapPenaltyTime = now
+		}
+		if !isDamped(h) {
+			return next, ""
+		}
+		return next, fmt.Sprintf("%s exceeded threshold for %d consecutive polls (flap penalty %.2f)", stat, next.Violations, next.FlapPenalty)
+	}
+
+	next.Violations = 0
+	if !next.Exceeded {
+		return next, ""
+	}
+	next.Clean++
+	penalty := flapPenalty(next, h, now)
+	if upCount := requiredUpCount(h, penalty); next.Clean < upCount {
+		return next, ""
+	}
+	next.Exceeded = false
+	clean := next.Clean
+	next.Clean = 0
+	if !isDamped(h) {
+		return next, ""
+	}
+	return next, fmt.Sprintf("%s within threshold for %d consecutive polls (flap penalty %.2f)", stat, clean, penalty)
+}
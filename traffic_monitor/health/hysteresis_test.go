@@ -0,0 +1,119 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/cache"
+)
+
+func TestEvalHysteresis(t *testing.T) {
+	h := tc.HealthHysteresis{DownCount: 3, UpCount: 2}
+	now := time.Now()
+	status := cache.ThresholdStatus{}
+
+	// polls exceeding, flapping with a clean poll, exceeding, clean
+	polls := []struct {
+		exceeded         bool
+		expectedExceeded bool
+		expectedReason   bool
+	}{
+		{true, false, false},
+		{true, false, false},
+		{false, false, false},
+		{true, false, false},
+		{true, false, false},
+		{true, true, true},
+		{true, true, false},
+		{false, true, false},
+		{true, true, false},
+		{false, true, false},
+		{false, false, true},
+		{false, false, false},
+	}
+	for i, poll := range polls {
+		reason := ""
+		status, reason = evalHysteresis("loadavg", status, poll.exceeded, h, now.Add(time.Duration(i)*time.Second))
+		if status.Exceeded != poll.expectedExceeded {
+			t.Errorf("poll %v exceeded %v expected threshold exceeded %v, actual %v", i, poll.exceeded, poll.expectedExceeded, status.Exceeded)
+		}
+		if (reason != "") != poll.expectedReason {
+			t.Errorf("poll %v exceeded %v expected reason %v, actual '%v'", i, poll.exceeded, poll.expectedReason, reason)
+		}
+		if reason != "" && !strings.HasPrefix(reason, "loadavg ") {
+			t.Errorf("poll %v expected reason to start with the stat, actual '%v'", i, reason)
+		}
+	}
+}
+
+func TestEvalHysteresisNotDamped(t *testing.T) {
+	status, reason := evalHysteresis("loadavg", cache.ThresholdStatus{}, true, tc.HealthHysteresis{}, time.Now())
+	if !status.Exceeded {
+		t.Errorf("zero hysteresis expected exceeded on first poll, actual not exceeded")
+	}
+	if reason != "" {
+		t.Errorf("zero hysteresis expected no damped reason, actual '%v'", reason)
+	}
+	status, reason = evalHysteresis("loadavg", status, false, tc.HealthHysteresis{}, time.Now())
+	if status.Exceeded {
+		t.Errorf("zero hysteresis expected not exceeded on first clean poll, actual exceeded")
+	}
+	if reason != "" {
+		t.Errorf("zero hysteresis expected no damped reason, actual '%v'", reason)
+	}
+}
+
+func TestEvalHysteresisFlapPenalty(t *testing.T) {
+	h := tc.HealthHysteresis{UpCount: 2, FlapHalfLife: time.Minute}
+	now := time.Now()
+	status := cache.ThresholdStatus{}
+
+	// flap 3 times in quick succession, so the penalty is nearly 3, and the up count nearly 4x.
+	for i := 0; i < 3; i++ {
+		status, _ = evalHysteresis("loadavg", status, true, h, now)
+		for status.Exceeded {
+			status, _ = evalHysteresis("loadavg", status, false, h, now)
+		}
+	}
+	status, _ = evalHysteresis("loadavg", status, true, h, now)
+	if status.FlapPenalty != 4 {
+		t.Fatalf("flap penalty after 4 flaps with no decay expected 4, actual %v", status.FlapPenalty)
+	}
+	if upCount := requiredUpCount(h, flapPenalty(status, h, now)); upCount != 16 {
+		t.Errorf("up count with flap penalty 4 expected %v, actual %v", 16, upCount)
+	}
+
+	// after 2 half-lives, the penalty should be 1, and the up count no longer multiplied.
+	later := now.Add(2 * time.Minute)
+	if penalty := flapPenalty(status, h, later); penalty < 0.999 || penalty > 1.001 {
+		t.Errorf("flap penalty after 2 half-lives expected 1, actual %v", penalty)
+	}
+	if upCount := requiredUpCount(h, flapPenalty(status, h, later)); upCount != 2 {
+		t.Errorf("up count after 2 half-lives expected 2, actual %v", upCount)
+	}
+
+	if upCount := requiredUpCount(h, 100); upCount != 2*MaxFlapPenaltyMultiplier {
+		t.Errorf("up count with huge flap penalty expected %v, actual %v", 2*MaxFlapPenaltyMultiplier, upCount)
+	}
+}
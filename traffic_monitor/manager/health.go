@@ -175,6 +175,10 @@ func processHealthResult(
 			results[i] = healthResult
 		}
 
+		if healthResult.UsingIPv6 {
+			continue // IPv6 polls are only used for availability, so the history and durations are of one poll per interval
+		}
+
 		maxHistory := uint64(monitorConfigCopy.Profile[monitorConfigCopy.TrafficServer[string(healthResult.ID)].Profile].Parameters.HistoryCount)
 		if maxHistory < 1 {
 			log.Infof("processHealthResult got history count %v for %v, setting to 1\n", maxHistory, healthResult.ID)
@@ -193,6 +197,9 @@ func processHealthResult(
 
 	lastHealthDurations := threadsafe.CopyDurationMap(lastHealthDurationsThreadsafe.Get())
 	for _, healthResult := range results {
+		if healthResult.UsingIPv6 {
+			continue
+		}
 		if lastHealthStart, ok := lastHealthEndTimes[healthResult.ID]; ok {
 			d := time.Since(lastHealthStart)
 			lastHealthDurations[healthResult.ID] = d
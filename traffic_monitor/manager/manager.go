@@ -28,6 +28,7 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_monitor/cache"
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/handler"
@@ -66,6 +67,15 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 	go peerPoller.Poll()
 
 	events := health.NewThreadsafeEvents(cfg.MaxEvents)
+	if cfg.EventStoreDir != "" {
+		eventStore, err := health.NewEventStore(cfg.EventStoreDir, cfg.EventStoreMaxFileBytes, cfg.EventStoreMaxFileAge, cfg.EventStoreMaxAge)
+		if err != nil {
+			return fmt.Errorf("creating event store: %v", err)
+		}
+		if events, err = health.NewThreadsafeEventsStore(cfg.MaxEvents, eventStore); err != nil {
+			return fmt.Errorf("creating event store: %v", err)
+		}
+	}
 
 	cachesChanged := make(chan struct{})
 	peerStates := peer.NewCRStatesPeersThreadsafe() // each peer's last state is saved in this map
@@ -85,7 +95,9 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		toData,
 	)
 
-	combinedStates, combineStateFunc := StartStateCombiner(events, peerStates, localStates, toData)
+	localStream := peer.NewCRStatesStream()    // streams changes to the local states, to peers
+	combinedStream := peer.NewCRStatesStream() // streams changes to the combined states, to Traffic Routers
+	combinedStates, combineStateFunc := StartStateCombiner(events, peerStates, localStates, toData, cfg, tc.TrafficMonitorName(appData.Hostname), localStream, combinedStream)
 
 	StartPeerManager(
 		peerHandler.ResultChannel,
@@ -146,6 +158,8 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		unpolledCaches,
 		monitorConfig,
 		cfg,
+		localStream,
+		combinedStream,
 	)
 
 	if err := startMonitorConfigFilePoller(trafficMonitorConfigFileName); err != nil {
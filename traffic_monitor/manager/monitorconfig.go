@@ -21,6 +21,7 @@ package manager
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"strconv"
@@ -182,6 +183,23 @@ func trafficOpsTOPollIntervalToDuration(t int) time.Duration {
 	return time.Duration(t) * time.Millisecond
 }
 
+// getFormatCtx returns the context given to the stats type parser of caches of the given profile, or nil if the format has none.
+// For the prometheus format, this is the config's prometheus_stats mapping, with any fields in the profile's health.polling.prometheus_stats Parameter replacing it.
+func getFormatCtx(format string, profile string, params tc.TMParameters, cfg config.Config) interface{} {
+	if format != cache.StatsTypePrometheus {
+		return nil
+	}
+	if params.HealthPollingPrometheusStats == "" {
+		return cfg.PrometheusStats
+	}
+	mapping, err := cfg.PrometheusStats.WithOverrides(params.HealthPollingPrometheusStats)
+	if err != nil {
+		log.Errorf("profile %v health.polling.prometheus_stats Parameter is malformed, using the config prometheus_stats: %v", profile, err)
+		return cfg.PrometheusStats
+	}
+	return mapping
+}
+
 // PollIntervalRatio is the ratio of the configuration interval to poll. The configured intervals are 'target' times, so we actually poll at some small fraction less, in attempt to make the actual poll marginally less than the target.
 const PollIntervalRatio = float64(0.97) // TODO make config?
 
@@ -225,6 +243,7 @@ func monitorConfigListen(
 		statURLs := map[string]poller.PollConfig{}
 		peerURLs := map[string]poller.PollConfig{}
 		caches := map[string]string{}
+		formatCtxs := map[string]interface{}{} // the format context of each profile, so each profile's Parameters are only parsed once
 
 		intervals, err := getIntervals(monitorConfig, cfg, logMissingIntervalParams)
 		logMissingIntervalParams = false // only log missing parameters once
@@ -240,7 +259,7 @@ func monitorConfigListen(
 
 			srvStatus := tc.CacheStatusFromString(srv.ServerStatus)
 			if srvStatus == tc.CacheStatusOnline {
-				localStates.AddCache(cacheName, tc.IsAvailable{IsAvailable: true})
+				localStates.AddCache(cacheName, tc.IsAvailable{IsAvailable: true, Ipv4Available: true, Ipv6Available: serverIPv6(srv) != ""})
 				continue
 			}
 			if srvStatus == tc.CacheStatusOffline {
@@ -263,12 +282,19 @@ func monitorConfigListen(
 				log.Infof("health.polling.format for '%v' is empty, using default '%v'", srv.HostName, format)
 			}
 
+			formatCtx, ok := formatCtxs[srv.Profile]
+			if !ok {
+				formatCtx = getFormatCtx(format, srv.Profile, monitorConfig.Profile[srv.Profile].Parameters, cfg)
+				formatCtxs[srv.Profile] = formatCtx
+			}
+
 			pollType := monitorConfig.Profile[srv.Profile].Parameters.HealthPollingType
 			if pollType == "" {
 				pollType = poller.DefaultPollerType
 				log.Infof("health.polling.type for '%v' is empty, using default '%v'", srv.HostName, pollType)
 			}
 
+			pollURLv6Str := createServerHealthPollURLv6(pollURLStr, srv)
 			pollURLStr = createServerHealthPollURL(pollURLStr, srv)
 
 			connTimeout := trafficOpsHealthConnectionTimeoutToDuration(monitorConfig.Profile[srv.Profile].Parameters.HealthConnectionTimeout)
@@ -277,10 +303,10 @@ func monitorConfigListen(
 				log.Warnln("profile " + srv.Profile + " health.connection.timeout Parameter is missing or zero, using default " + DefaultHealthConnectionTimeout.String())
 			}
 
-			healthURLs[srv.HostName] = poller.PollConfig{URL: pollURLStr, Host: srv.FQDN, Timeout: connTimeout, Format: format, PollType: pollType}
+			healthURLs[srv.HostName] = poller.PollConfig{URL: pollURLStr, URLv6: pollURLv6Str, Host: srv.FQDN, Timeout: connTimeout, Format: format, FormatCtx: formatCtx, PollType: pollType}
 
 			statURL := createServerStatPollURL(pollURLStr)
-			statURLs[srv.HostName] = poller.PollConfig{URL: statURL, Host: srv.FQDN, Timeout: connTimeout, Format: format, PollType: pollType}
+			statURLs[srv.HostName] = poller.PollConfig{URL: statURL, Host: srv.FQDN, Timeout: connTimeout, Format: format, FormatCtx: formatCtx, PollType: pollType}
 		}
 
 		peerSet := map[tc.TrafficMonitorName]struct{}{}
@@ -292,8 +318,13 @@ func monitorConfigListen(
 				continue
 			}
 			// TODO: the URL should be config driven. -jse
-			url := fmt.Sprintf("http://%s:%d/publish/CrStates?raw", srv.IP, srv.Port)
-			peerURLs[srv.HostName] = poller.PollConfig{URL: url, Host: srv.FQDN} // TODO determine timeout.
+			if cfg.PeerStreaming {
+				url := fmt.Sprintf("http://%s:%d/publish/CrStatesStream?raw", srv.IP, srv.Port)
+				peerURLs[srv.HostName] = poller.PollConfig{URL: url, Host: srv.FQDN, PollType: poller.PollerTypeCRStatesStream}
+			} else {
+				url := fmt.Sprintf("http://%s:%d/publish/CrStates?raw", srv.IP, srv.Port)
+				peerURLs[srv.HostName] = poller.PollConfig{URL: url, Host: srv.FQDN} // TODO determine timeout.
+			}
 			peerSet[tc.TrafficMonitorName(srv.HostName)] = struct{}{}
 		}
 
@@ -332,11 +363,52 @@ func monitorConfigListen(
 	}
 }
 
-// createServerHealthPollURL takes the template pollingURLStr, and replaces variables with data from srv, and returns the polling URL for srv.
+// createServerHealthPollURL takes the template pollingURLStr, and replaces variables with data from srv, and returns the polling URL for srv's IPv4 address.
 func createServerHealthPollURL(pollingURLStr string, srv tc.TrafficServer) string {
+	return createServerHealthPollURLForHost(pollingURLStr, srv, srv.IP)
+}
+
+// createServerHealthPollURLv6 takes the template pollingURLStr, and replaces variables with data from srv, and returns the polling URL for srv's IPv6 address, or the empty string if srv has no IPv6 address.
+func createServerHealthPollURLv6(pollingURLStr string, srv tc.TrafficServer) string {
+	ip6 := serverIPv6(srv)
+	if ip6 == "" {
+		return ""
+	}
+	return createServerHealthPollURLForHost(pollingURLStr, srv, "["+ip6+"]")
+}
+
+// serverIPv6 returns srv's IPv6 address without any prefix length, or the empty string if srv has no valid IPv6 address.
+func serverIPv6(srv tc.TrafficServer) string {
+	if srv.IP6 == "" {
+		return ""
+	}
+	ip6 := strings.SplitN(srv.IP6, "/", 2)[0] // Traffic Ops IPv6 addresses may include the prefix length
+	if ip := net.ParseIP(ip6); ip == nil || ip.To4() != nil {
+		log.Warnln("cache '" + srv.HostName + "' IPv6 address '" + srv.IP6 + "' is not a valid IPv6 address, not polling over IPv6")
+		return ""
+	}
+	return ip6
+}
+
+// serverInterfaceNames returns the interfaces to poll for srv: its InterfaceName, followed by any other monitored interfaces, comma-separated.
+func serverInterfaceNames(srv tc.TrafficServer) string {
+	names := []string{}
+	if srv.InterfaceName != "" {
+		names = append(names, srv.InterfaceName)
+	}
+	for _, inf := range srv.Interfaces {
+		if inf.Name != srv.InterfaceName {
+			names = append(names, inf.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// createServerHealthPollURLForHost takes the template pollingURLStr, and replaces variables with data from srv, using the given host for the hostname, and returns the polling URL.
+func createServerHealthPollURLForHost(pollingURLStr string, srv tc.TrafficServer, host string) string {
 	pollingURLStr = strings.NewReplacer(
-		"${hostname}", srv.IP,
-		"${interface_name}", srv.InterfaceName,
+		"${hostname}", host,
+		"${interface_name}", serverInterfaceNames(srv),
 		"application=plugin.remap", "application=system",
 		"application=", "application=system",
 	).Replace(pollingURLStr)
@@ -100,3 +100,38 @@ func TestCreateServerStatPollURL(t *testing.T) {
 		t.Errorf("expected createServerStatPollURL '" + expected + "' actual: '" + actual + "'")
 	}
 }
+
+func TestCreateServerHealthPollURLInterfaces(t *testing.T) {
+	tmpl := `http://${hostname}/_astats?application=&inf.name=${interface_name}`
+	srv := tc.TrafficServer{IP: "192.0.2.42", InterfaceName: "bond0", Interfaces: []tc.TrafficServerInterface{
+		{Name: "eth2", Required: true},
+		{Name: "bond0", MaxKbps: 20000000, Required: true},
+		{Name: "eth3"},
+	}}
+
+	expected := `http://` + srv.IP + `/_astats?application=system&inf.name=bond0,eth2,eth3`
+	actual := createServerHealthPollURL(tmpl, srv)
+
+	if expected != actual {
+		t.Errorf("expected createServerHealthPollURL '%v' actual: '%v'", expected, actual)
+	}
+}
+
+func TestCreateServerHealthPollURLv6(t *testing.T) {
+	tmpl := `http://${hostname}/_astats?application=&inf.name=${interface_name}`
+	srv := tc.TrafficServer{IP: "192.0.2.42", IP6: "2001:db8::42/64", Port: 5678, InterfaceName: "george"}
+
+	expected := `http://[2001:db8::42]:` + strconv.Itoa(srv.Port) + `/_astats?application=system&inf.name=` + srv.InterfaceName
+	actual := createServerHealthPollURLv6(tmpl, srv)
+
+	if expected != actual {
+		t.Errorf("expected createServerHealthPollURLv6 '%v' actual: '%v'", expected, actual)
+	}
+
+	for _, ip6 := range []string{"", "192.0.2.43", "not an ip"} {
+		srv.IP6 = ip6
+		if actual := createServerHealthPollURLv6(tmpl, srv); actual != "" {
+			t.Errorf("expected createServerHealthPollURLv6 with IPv6 address '%v' to be empty, actual: '%v'", ip6, actual)
+		}
+	}
+}
@@ -74,6 +74,8 @@ func StartOpsConfigManager(
 	unpolledCaches threadsafe.UnpolledCaches,
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
 	cfg config.Config,
+	localStream peer.CRStatesStream,
+	combinedStream peer.CRStatesStream,
 ) (threadsafe.OpsConfig, error) {
 
 	handleErr := func(err error) {
@@ -130,6 +132,8 @@ func StartOpsConfigManager(
 			lastStats,
 			unpolledCaches,
 			monitorConfig,
+			localStream,
+			combinedStream,
 		)
 
 		// If the HTTPS Listener is defined in the traffic_ops.cfg file then it creates the HTTPS endpoint and the corresponding HTTP endpoint as a redirect
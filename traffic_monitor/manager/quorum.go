@@ -0,0 +1,161 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+// quorumConsensus combines cache states by the votes of a quorum of Traffic Monitors. See config.PeerConsensusQuorum.
+// It must only be used by the single state combiner goroutine.
+type quorumConsensus struct {
+	// self is the name of this Traffic Monitor, whose vote is its local state.
+	self tc.TrafficMonitorName
+	// quorum is the number of monitors which must vote a cache available, or 0 for a majority of the voters.
+	quorum int
+	// hasQuorum is whether the last combine had a quorum of monitors reachable, and lastCombined is whether there was a last combine, so events are only added when the quorum is lost or regained.
+	hasQuorum    bool
+	lastCombined bool
+}
+
+func newQuorumConsensus(self tc.TrafficMonitorName, quorum int) *quorumConsensus {
+	return &quorumConsensus{self: self, quorum: quorum}
+}
+
+// voters returns the CRStates of the peers which may vote, which are the ONLINE peers which are available, and whether they and this monitor are a majority of all ONLINE monitors.
+// Requiring a majority of all monitors, rather than of those reachable, prevents both sides of a network partition from reaching a quorum of their own.
+func (q *quorumConsensus) voters(peerStates peer.CRStatesPeersThreadsafe) (map[tc.TrafficMonitorName]tc.CRStates, bool) {
+	peers := peerStates.GetPeers()
+	crStates := peerStates.GetCrstates()
+	voters := map[tc.TrafficMonitorName]tc.CRStates{}
+	for peerName := range peers {
+		if peerName == q.self || !peerStates.GetPeerAvailability(peerName) {
+			continue
+		}
+		if peerCRStates, ok := crStates[peerName]; ok {
+			voters[peerName] = peerCRStates
+		}
+	}
+	monitors := len(peers) + 1
+	return voters, len(voters)+1 > monitors/2
+}
+
+// votes returns whether this monitor and each of the given voting peers consider the given cache available, by the given availability of a cache state, for example whether it's available over IPv4.
+// Peers which don't have a state for the cache, for example because they haven't yet gotten a new config from Traffic Ops, don't vote.
+func (q *quorumConsensus) votes(cacheName tc.CacheName, localCacheState tc.IsAvailable, voters map[tc.TrafficMonitorName]tc.CRStates, isAvailable func(tc.IsAvailable) bool) map[tc.TrafficMonitorName]bool {
+	votes := map[tc.TrafficMonitorName]bool{q.self: isAvailable(localCacheState)}
+	for peerName, peerCRStates := range voters {
+		if peerCacheState, ok := peerCRStates.Caches[cacheName]; ok {
+			votes[peerName] = isAvailable(peerCacheState)
+		}
+	}
+	return votes
+}
+
+func cacheAvailable(a tc.IsAvailable) bool     { return a.IsAvailable }
+func cacheIPv6Available(a tc.IsAvailable) bool { return a.Ipv6Available }
+
+// quorumAvailable returns whether at least quorum of the given votes are available, or a majority if quorum is 0, and whether there were enough votes to reach a quorum at all.
+func quorumAvailable(votes map[tc.TrafficMonitorName]bool, quorum int) (bool, bool) {
+	needed := quorum
+	if needed <= 0 {
+		needed = len(votes)/2 + 1
+	}
+	if len(votes) < needed {
+		return false, false
+	}
+	return availableVotes(votes) >= needed, true
+}
+
+func availableVotes(votes map[tc.TrafficMonitorName]bool) int {
+	available := 0
+	for _, vote := range votes {
+		if vote {
+			available++
+		}
+	}
+	return available
+}
+
+// votesStr returns the given votes as a human-readable string, sorted by monitor name, for events.
+func votesStr(votes map[tc.TrafficMonitorName]bool) string {
+	strs := make([]string, 0, len(votes))
+	for monitor, vote := range votes {
+		strs = append(strs, fmt.Sprintf("%s=%t", monitor, vote))
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ", ")
+}
+
+// combineCaches combines the states of all caches in localStates by quorum, or optimistically for caches without enough votes, or all caches if a majority of monitors isn't reachable.
+func (q *quorumConsensus) combineCaches(events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
+	voters, hasQuorum := q.voters(peerStates)
+	if !q.lastCombined || hasQuorum != q.hasQuorum {
+		description := fmt.Sprintf("Health protocol quorum established; %d of %d Traffic Monitors reachable", len(voters)+1, len(peerStates.GetPeers())+1)
+		if !hasQuorum {
+			description = fmt.Sprintf("Health protocol quorum lost; %d of %d Traffic Monitors reachable, combining cache states optimistically", len(voters)+1, len(peerStates.GetPeers())+1)
+		}
+		events.Add(health.Event{Time: health.Time(time.Now()), Description: description, Name: q.self.String(), Hostname: q.self.String(), Type: tc.MonitorTypeName, Available: hasQuorum, Override: true})
+	}
+	q.hasQuorum = hasQuorum
+	q.lastCombined = true
+
+	for cacheName, localCacheState := range localStates.Caches {
+		if !hasQuorum {
+			combineCacheState(cacheName, localCacheState, events, true, peerStates, localStates, combinedStates, overrideMap, toData)
+			continue
+		}
+		q.combineCacheState(cacheName, localCacheState, voters, events, peerStates, localStates, combinedStates, overrideMap, toData)
+	}
+}
+
+// combineCacheState combines the state of the given cache by the quorum of the votes of this monitor and the given voting peers.
+// If there aren't enough votes for a quorum, the cache state is combined optimistically.
+// The cache, and so IPv4, is available if a quorum votes it available; IPv6 is available if a quorum votes IPv6 available.
+func (q *quorumConsensus) combineCacheState(cacheName tc.CacheName, localCacheState tc.IsAvailable, voters map[tc.TrafficMonitorName]tc.CRStates, events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
+	votes := q.votes(cacheName, localCacheState, voters, cacheAvailable)
+	available, ok := quorumAvailable(votes, q.quorum)
+	if !ok {
+		combineCacheState(cacheName, localCacheState, events, true, peerStates, localStates, combinedStates, overrideMap, toData)
+		return
+	}
+	overrideMap[cacheName] = false // the optimistic override doesn't apply to quorum states
+
+	lastState, hasLastState := combinedStates.GetCache(cacheName)
+	if (hasLastState && lastState.IsAvailable != available) || (!hasLastState && localCacheState.IsAvailable != available) {
+		availableStr := "available"
+		if !available {
+			availableStr = "unavailable"
+		}
+		description := fmt.Sprintf("Health protocol quorum %s; %d of %d votes available: %s", availableStr, availableVotes(votes), len(votes), votesStr(votes))
+		events.Add(health.Event{Time: health.Time(time.Now()), Description: description, Name: cacheName.String(), Hostname: cacheName.String(), Type: toData.ServerTypes[cacheName].String(), Available: available, CacheGroup: string(toData.ServerCachegroups[cacheName]), Override: true})
+	}
+
+	ipv6Available, _ := quorumAvailable(q.votes(cacheName, localCacheState, voters, cacheIPv6Available), q.quorum)
+	combinedStates.AddCache(cacheName, tc.IsAvailable{IsAvailable: available, Ipv4Available: available, Ipv6Available: ipv6Available, Votes: votes})
+}
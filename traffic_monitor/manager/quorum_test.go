@@ -0,0 +1,205 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
+)
+
+func TestQuorumAvailable(t *testing.T) {
+	tests := []struct {
+		votes     map[tc.TrafficMonitorName]bool
+		quorum    int
+		available bool
+		ok        bool
+	}{
+		{map[tc.TrafficMonitorName]bool{"a": true, "b": true, "c": false}, 0, true, true},
+		{map[tc.TrafficMonitorName]bool{"a": true, "b": false, "c": false}, 0, false, true},
+		{map[tc.TrafficMonitorName]bool{"a": true, "b": false}, 0, false, true},
+		{map[tc.TrafficMonitorName]bool{"a": true}, 0, true, true},
+		{map[tc.TrafficMonitorName]bool{"a": true, "b": true, "c": false}, 3, false, true},
+		{map[tc.TrafficMonitorName]bool{"a": true, "b": true}, 3, false, false},
+	}
+	for i, test := range tests {
+		available, ok := quorumAvailable(test.votes, test.quorum)
+		if available != test.available || ok != test.ok {
+			t.Errorf("test %d quorumAvailable(%v, %d) expected %v %v, actual %v %v", i, test.votes, test.quorum, test.available, test.ok, available, ok)
+		}
+	}
+}
+
+// quorumTestPeers returns peer states of the given peers, each of which reports the given cache availability, and is reachable if it's in reachable.
+func quorumTestPeers(cacheName tc.CacheName, peerCaches map[tc.TrafficMonitorName]bool, reachable map[tc.TrafficMonitorName]bool) peer.CRStatesPeersThreadsafe {
+	peerStates := peer.NewCRStatesPeersThreadsafe()
+	peers := map[tc.TrafficMonitorName]struct{}{}
+	for peerName, available := range peerCaches {
+		peers[peerName] = struct{}{}
+		crStates := tc.NewCRStates()
+		crStates.Caches[cacheName] = tc.IsAvailable{IsAvailable: available, Ipv4Available: available}
+		peerStates.Set(peer.Result{ID: peerName, Available: reachable[peerName], PeerStates: crStates, Time: time.Now()})
+	}
+	peerStates.SetPeers(peers)
+	return peerStates
+}
+
+func TestCombineCrStatesQuorum(t *testing.T) {
+	cacheName := tc.CacheName("edge")
+	peerCaches := map[tc.TrafficMonitorName]bool{"tm-b": true, "tm-c": true}
+
+	tests := []struct {
+		name      string
+		local     bool
+		reachable map[tc.TrafficMonitorName]bool
+		quorum    int
+		available bool
+		votes     map[tc.TrafficMonitorName]bool
+	}{
+		{
+			name:      "majority available",
+			local:     false,
+			reachable: map[tc.TrafficMonitorName]bool{"tm-b": true, "tm-c": true},
+			available: true,
+			votes:     map[tc.TrafficMonitorName]bool{"tm-a": false, "tm-b": true, "tm-c": true},
+		},
+		{
+			name:      "configured quorum not reached",
+			local:     false,
+			reachable: map[tc.TrafficMonitorName]bool{"tm-b": true, "tm-c": true},
+			quorum:    3,
+			available: false,
+			votes:     map[tc.TrafficMonitorName]bool{"tm-a": false, "tm-b": true, "tm-c": true},
+		},
+		{
+			name:      "partitioned minority is optimistic",
+			local:     false,
+			reachable: map[tc.TrafficMonitorName]bool{},
+			available: false,
+			votes:     nil,
+		},
+		{
+			name:      "too few votes for configured quorum is optimistic",
+			local:     false,
+			reachable: map[tc.TrafficMonitorName]bool{"tm-b": true},
+			quorum:    3,
+			available: true,
+			votes:     nil,
+		},
+	}
+	for _, test := range tests {
+		events := health.NewThreadsafeEvents(100)
+		peerStates := quorumTestPeers(cacheName, peerCaches, test.reachable)
+		localStates := tc.NewCRStates()
+		localStates.Caches[cacheName] = tc.IsAvailable{IsAvailable: test.local}
+		combinedStates := peer.NewCRStatesThreadsafe()
+		quorum := newQuorumConsensus("tm-a", test.quorum)
+
+		combineCrStates(events, true, quorum, peerStates, localStates, combinedStates, map[tc.CacheName]bool{}, todata.TOData{})
+
+		combined, ok := combinedStates.GetCache(cacheName)
+		if !ok {
+			t.Fatalf("%s: expected combined cache state, actual none", test.name)
+		}
+		if combined.IsAvailable != test.available {
+			t.Errorf("%s: expected available %v, actual %v", test.name, test.available, combined.IsAvailable)
+		}
+		if !reflect.DeepEqual(combined.Votes, test.votes) {
+			t.Errorf("%s: expected votes %v, actual %v", test.name, test.votes, combined.Votes)
+		}
+	}
+}
+
+func TestCombineCrStatesQuorumIPv6(t *testing.T) {
+	cacheName := tc.CacheName("edge")
+	peerCaches := map[tc.TrafficMonitorName]tc.IsAvailable{
+		"tm-b": {IsAvailable: true, Ipv4Available: true, Ipv6Available: true},
+		"tm-c": {IsAvailable: true, Ipv4Available: true},
+	}
+	peerStates := peer.NewCRStatesPeersThreadsafe()
+	peers := map[tc.TrafficMonitorName]struct{}{}
+	for peerName, available := range peerCaches {
+		peers[peerName] = struct{}{}
+		crStates := tc.NewCRStates()
+		crStates.Caches[cacheName] = available
+		peerStates.Set(peer.Result{ID: peerName, Available: true, PeerStates: crStates, Time: time.Now()})
+	}
+	peerStates.SetPeers(peers)
+
+	localStates := tc.NewCRStates()
+	localStates.Caches[cacheName] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+	combinedStates := peer.NewCRStatesThreadsafe()
+
+	combineCrStates(health.NewThreadsafeEvents(100), true, newQuorumConsensus("tm-a", 0), peerStates, localStates, combinedStates, map[tc.CacheName]bool{}, todata.TOData{})
+
+	combined, _ := combinedStates.GetCache(cacheName)
+	if !combined.IsAvailable || !combined.Ipv4Available || combined.Ipv6Available {
+		t.Errorf("expected available true ipv4 true ipv6 false, actual available %v ipv4 %v ipv6 %v", combined.IsAvailable, combined.Ipv4Available, combined.Ipv6Available)
+	}
+
+	// optimistically, IPv6 is available because a peer considers it available
+	combinedStates = peer.NewCRStatesThreadsafe()
+	combineCrStates(health.NewThreadsafeEvents(100), true, nil, peerStates, localStates, combinedStates, map[tc.CacheName]bool{}, todata.TOData{})
+
+	combined, _ = combinedStates.GetCache(cacheName)
+	if !combined.IsAvailable || !combined.Ipv4Available || !combined.Ipv6Available {
+		t.Errorf("optimistic expected available true ipv4 true ipv6 true, actual available %v ipv4 %v ipv6 %v", combined.IsAvailable, combined.Ipv4Available, combined.Ipv6Available)
+	}
+}
+
+func TestCombineCrStatesQuorumEvents(t *testing.T) {
+	cacheName := tc.CacheName("edge")
+	peerCaches := map[tc.TrafficMonitorName]bool{"tm-b": false, "tm-c": false}
+	events := health.NewThreadsafeEvents(100)
+	localStates := tc.NewCRStates()
+	localStates.Caches[cacheName] = tc.IsAvailable{IsAvailable: true}
+	combinedStates := peer.NewCRStatesThreadsafe()
+	quorum := newQuorumConsensus("tm-a", 0)
+
+	peerStates := quorumTestPeers(cacheName, peerCaches, map[tc.TrafficMonitorName]bool{"tm-b": true, "tm-c": true})
+	combineCrStates(events, true, quorum, peerStates, localStates, combinedStates, map[tc.CacheName]bool{}, todata.TOData{})
+
+	peerStates = quorumTestPeers(cacheName, peerCaches, map[tc.TrafficMonitorName]bool{})
+	combineCrStates(events, true, quorum, peerStates, localStates, combinedStates, map[tc.CacheName]bool{}, todata.TOData{})
+
+	descriptions := []string{}
+	for _, event := range events.Get() {
+		descriptions = append([]string{event.Description}, descriptions...) // events are newest first
+	}
+	expected := []string{
+		"Health protocol quorum established; 3 of 3 Traffic Monitors reachable",
+		"Health protocol quorum unavailable; 1 of 3 votes available: tm-a=true, tm-b=false, tm-c=false",
+		"Health protocol quorum lost; 1 of 3 Traffic Monitors reachable, combining cache states optimistically",
+	}
+	if len(descriptions) < len(expected) {
+		t.Fatalf("expected events %v, actual %v", expected, descriptions)
+	}
+	for i, description := range expected {
+		if !strings.HasPrefix(descriptions[i], description) {
+			t.Errorf("expected event %d '%s', actual '%s'", i, description, descriptions[i])
+		}
+	}
+}
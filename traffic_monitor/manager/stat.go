@@ -278,9 +278,17 @@ func processStatResults(
 		}
 
 		// TODO determine if we want to add results with errors, or just print the errors now and don't add them.
-		if lastResult, ok := lastResults[result.ID]; ok && result.Error == nil {
-			health.GetVitals(&result, &lastResult, &mc) // TODO precompute
+		if result.Error == nil {
+			prevResult := (*cache.Result)(nil)
+			if lastResult, ok := lastResults[result.ID]; ok {
+				prevResult = &lastResult
+			}
+			health.GetVitals(&result, prevResult, &mc) // TODO precompute
 			if result.Error == nil {
+				if len(result.Vitals.Interfaces) > 0 {
+					// the cache's bytes and capacity are those of all its monitored interfaces, not only the one reported as inf.name.
+					result.PrecomputedData.OutBytes, result.PrecomputedData.MaxKbps = result.Vitals.BytesOut, result.Vitals.MaxKbpsOut
+				}
 				results[i] = result
 			} else {
 				log.Errorf("stat poll getting vitals for %v: %v\n", result.ID, result.Error)
@@ -27,13 +27,16 @@ import (
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 )
 
 // StartStateCombiner starts the State Combiner goroutine, and returns the threadsafe CombinedStates, and a func to signal to combine states.
-func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates peer.CRStatesThreadsafe, toData todata.TODataThreadsafe) (peer.CRStatesThreadsafe, func()) {
+// Cache states are combined per cfg.PeerConsensus, where self is the name of this Traffic Monitor.
+// Each time states are combined, the local and combined states are published to localStream and combinedStream, which send any changes to streaming clients.
+func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates peer.CRStatesThreadsafe, toData todata.TODataThreadsafe, cfg config.Config, self tc.TrafficMonitorName, localStream peer.CRStatesStream, combinedStream peer.CRStatesStream) (peer.CRStatesThreadsafe, func()) {
 	combinedStates := peer.NewCRStatesThreadsafe()
 
 	// the chan buffer just reduces the number of goroutines on our infinite buffer hack in combineState(), no real writer will block, since combineState() writes in a goroutine.
@@ -56,9 +59,16 @@ func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStates
 
 	go func() {
 		overrideMap := map[tc.CacheName]bool{}
+		quorum := (*quorumConsensus)(nil)
+		if cfg.PeerConsensus == config.PeerConsensusQuorum {
+			quorum = newQuorumConsensus(self, cfg.PeerQuorum)
+		}
 		for range combineStateChan {
 			drain(combineStateChan)
-			combineCrStates(events, true, peerStates, localStates.Get(), combinedStates, overrideMap, toData.Get())
+			local := localStates.Get()
+			combineCrStates(events, true, quorum, peerStates, local, combinedStates, overrideMap, toData.Get())
+			localStream.Publish(local)
+			combinedStream.Publish(combinedStates.Get())
 		}
 	}()
 
@@ -111,10 +121,20 @@ func combineCacheState(cacheName tc.CacheName, localCacheState tc.IsAvailable, e
 	}
 
 	if overrideCondition != "" {
-		events.Add(health.Event{Time: health.Time(time.Now()), Description: fmt.Sprintf("Health protocol override condition %s", overrideCondition), Name: cacheName.String(), Hostname: cacheName.String(), Type: toData.ServerTypes[cacheName].String(), Available: available})
+		events.Add(health.Event{Time: health.Time(time.Now()), Description: fmt.Sprintf("Health protocol override condition %s", overrideCondition), Name: cacheName.String(), Hostname: cacheName.String(), Type: toData.ServerTypes[cacheName].String(), Available: available, CacheGroup: string(toData.ServerCachegroups[cacheName]), Override: true})
+	}
+
+	// isAvailable is the IPv4 availability, so ipv4Available follows it; IPv6 is combined optimistically on its own.
+	ipv6Available := localCacheState.Ipv6Available
+	if peerOptimistic {
+		for peer, peerCrStates := range peerStates.GetCrstates() {
+			if peerStates.GetPeerAvailability(peer) {
+				ipv6Available = ipv6Available || peerCrStates.Caches[cacheName].Ipv6Available
+			}
+		}
 	}
 
-	combinedStates.AddCache(cacheName, tc.IsAvailable{IsAvailable: available})
+	combinedStates.AddCache(cacheName, tc.IsAvailable{IsAvailable: available, Ipv4Available: available, Ipv6Available: ipv6Available})
 }
 
 func combineDSState(
@@ -183,9 +203,14 @@ func pruneCombinedCaches(combinedStates peer.CRStatesThreadsafe, localStates tc.
 	}
 }
 
-func combineCrStates(events health.ThreadsafeEvents, peerOptimistic bool, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
-	for cacheName, localCacheState := range localStates.Caches { // localStates gets pruned when servers are disabled, it's the source of truth
-		combineCacheState(cacheName, localCacheState, events, peerOptimistic, peerStates, localStates, combinedStates, overrideMap, toData)
+// combineCrStates combines the local and peer states into combinedStates. Cache states are combined by the given quorum consensus, or optimistically if it's nil.
+func combineCrStates(events health.ThreadsafeEvents, peerOptimistic bool, quorum *quorumConsensus, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
+	if quorum != nil {
+		quorum.combineCaches(events, peerStates, localStates, combinedStates, overrideMap, toData)
+	} else {
+		for cacheName, localCacheState := range localStates.Caches { // localStates gets pruned when servers are disabled, it's the source of truth
+			combineCacheState(cacheName, localCacheState, events, peerOptimistic, peerStates, localStates, combinedStates, overrideMap, toData)
+		}
 	}
 
 	for deliveryServiceName, localDeliveryService := range localStates.DeliveryService {
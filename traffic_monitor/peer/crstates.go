@@ -121,6 +121,7 @@ type CRStatesPeersThreadsafe struct {
 	peerStates map[tc.TrafficMonitorName]bool
 	peerTimes  map[tc.TrafficMonitorName]time.Time
 	peerOnline map[tc.TrafficMonitorName]bool
+	peers      map[tc.TrafficMonitorName]struct{}
 	timeout    *time.Duration
 	m          *sync.RWMutex
 }
@@ -135,6 +136,7 @@ func NewCRStatesPeersThreadsafe() CRStatesPeersThreadsafe {
 		crStates:   map[tc.TrafficMonitorName]tc.CRStates{},
 		peerStates: map[tc.TrafficMonitorName]bool{},
 		peerTimes:  map[tc.TrafficMonitorName]time.Time{},
+		peers:      map[tc.TrafficMonitorName]struct{}{},
 	}
 }
 
@@ -151,6 +153,23 @@ func (t *CRStatesPeersThreadsafe) SetPeers(newPeers map[tc.TrafficMonitorName]st
 		_, ok := newPeers[peer]
 		t.peerOnline[peer] = ok
 	}
+	for peer := range t.peers {
+		delete(t.peers, peer) // t is copied by value, so the map must be modified rather than replaced
+	}
+	for peer := range newPeers {
+		t.peers[peer] = struct{}{}
+	}
+}
+
+// GetPeers returns the peers last set by SetPeers, which are the ONLINE Traffic Monitors in the latest Traffic Monitor config, whether or not they've been polled.
+func (t *CRStatesPeersThreadsafe) GetPeers() map[tc.TrafficMonitorName]struct{} {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	peers := make(map[tc.TrafficMonitorName]struct{}, len(t.peers))
+	for peer := range t.peers {
+		peers[peer] = struct{}{}
+	}
+	return peers
 }
 
 // GetCrstates returns the internal Traffic Monitor peer Crstates data. This MUST NOT be modified.
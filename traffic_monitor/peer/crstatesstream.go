@@ -0,0 +1,338 @@
+package peer
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// CRStatesStreamHeartbeatInterval is how often a CRStates stream sends a heartbeat event when there are no changes. Clients should consider a stream dead if nothing is received for a few heartbeat intervals.
+const CRStatesStreamHeartbeatInterval = 5 * time.Second
+
+// CRStatesStreamSubscriberBuffer is the number of events buffered for each stream subscriber. A subscriber which falls further behind than this is dropped, and must reconnect to resync.
+const CRStatesStreamSubscriberBuffer = 64
+
+// CRStatesStreamEventType is the type of an event in a CRStates stream, which is the Server-Sent Events `event` field.
+type CRStatesStreamEventType string
+
+const (
+	// CRStatesStreamEventSnapshot is a full CRStates, sent when a client connects.
+	CRStatesStreamEventSnapshot = CRStatesStreamEventType("snapshot")
+	// CRStatesStreamEventDelta is the change from the previous sequence number.
+	CRStatesStreamEventDelta = CRStatesStreamEventType("delta")
+	// CRStatesStreamEventHeartbeat is sent periodically without changes, with the current sequence number, so clients can detect dead streams and missed deltas.
+	CRStatesStreamEventHeartbeat = CRStatesStreamEventType("heartbeat")
+)
+
+// CRStatesDelta is the change between two CRStates. Caches and DeliveryService contain added and changed states; the Deleted fields contain removed states.
+type CRStatesDelta struct {
+	Caches                  map[tc.CacheName]tc.IsAvailable                       `json:"caches,omitempty"`
+	DeliveryService         map[tc.DeliveryServiceName]tc.CRStatesDeliveryService `json:"deliveryServices,omitempty"`
+	DeletedCaches           []tc.CacheName                                        `json:"deletedCaches,omitempty"`
+	DeletedDeliveryServices []tc.DeliveryServiceName                              `json:"deletedDeliveryServices,omitempty"`
+}
+
+// Empty returns whether the delta has no changes.
+func (d CRStatesDelta) Empty() bool {
+	return len(d.Caches) == 0 && len(d.DeliveryService) == 0 && len(d.DeletedCaches) == 0 && len(d.DeletedDeliveryServices) == 0
+}
+
+// DiffCRStates returns the delta which changes a into b. It does not modify a or b.
+func DiffCRStates(a tc.CRStates, b tc.CRStates) CRStatesDelta {
+	d := CRStatesDelta{}
+	for name, bCache := range b.Caches {
+		if aCache, ok := a.Caches[name]; !ok || !isAvailableEqual(aCache, bCache) {
+			if d.Caches == nil {
+				d.Caches = map[tc.CacheName]tc.IsAvailable{}
+			}
+			d.Caches[name] = bCache
+		}
+	}
+	for name := range a.Caches {
+		if _, ok := b.Caches[name]; !ok {
+			d.DeletedCaches = append(d.DeletedCaches, name)
+		}
+	}
+	for name, bDS := range b.DeliveryService {
+		if aDS, ok := a.DeliveryService[name]; !ok || !crStatesDSEqual(aDS, bDS) {
+			if d.DeliveryService == nil {
+				d.DeliveryService = map[tc.DeliveryServiceName]tc.CRStatesDeliveryService{}
+			}
+			d.DeliveryService[name] = bDS
+		}
+	}
+	for name := range a.DeliveryService {
+		if _, ok := b.DeliveryService[name]; !ok {
+			d.DeletedDeliveryServices = append(d.DeletedDeliveryServices, name)
+		}
+	}
+	return d
+}
+
+// ApplyCRStatesDelta applies the given delta to states. This modifies states.
+func ApplyCRStatesDelta(states tc.CRStates, d CRStatesDelta) {
+	for name, cache := range d.Caches {
+		states.Caches[name] = cache
+	}
+	for name, ds := range d.DeliveryService {
+		states.DeliveryService[name] = ds
+	}
+	for _, name := range d.DeletedCaches {
+		delete(states.Caches, name)
+	}
+	for _, name := range d.DeletedDeliveryServices {
+		delete(states.DeliveryService, name)
+	}
+}
+
+func isAvailableEqual(a tc.IsAvailable, b tc.IsAvailable) bool {
+	if a.IsAvailable != b.IsAvailable || a.Ipv4Available != b.Ipv4Available || a.Ipv6Available != b.Ipv6Available || len(a.Votes) != len(b.Votes) {
+		return false
+	}
+	for name, aVote := range a.Votes {
+		if bVote, ok := b.Votes[name]; !ok || aVote != bVote {
+			return false
+		}
+	}
+	return true
+}
+
+// crStatesDSEqual returns whether a and b are equal. The order of DisabledLocations doesn't matter, because the state combiner sorts them.
+func crStatesDSEqual(a tc.CRStatesDeliveryService, b tc.CRStatesDeliveryService) bool {
+	if a.IsAvailable != b.IsAvailable || len(a.DisabledLocations) != len(b.DisabledLocations) {
+		return false
+	}
+	locations := make(map[tc.CacheGroupName]int, len(a.DisabledLocations))
+	for _, loc := range a.DisabledLocations {
+		locations[loc]++
+	}
+	for _, loc := range b.DisabledLocations {
+		if locations[loc] == 0 {
+			return false
+		}
+		locations[loc]--
+	}
+	return true
+}
+
+// CRStatesStreamEvent is a single event in a CRStates stream. States is set for snapshots, and Delta for deltas.
+type CRStatesStreamEvent struct {
+	Seq    uint64
+	Type   CRStatesStreamEventType
+	States *tc.CRStates
+	Delta  *CRStatesDelta
+}
+
+// CRStatesStream publishes CRStates to streaming subscribers, as a snapshot followed by the delta of each change. It is safe for multiple goroutines, but Publish MUST NOT be called by multiple goroutines.
+type CRStatesStream struct {
+	seq         *uint64
+	states      *tc.CRStates
+	subscribers map[chan CRStatesStreamEvent]struct{}
+	m           *sync.Mutex
+}
+
+// NewCRStatesStream creates a new CRStatesStream, with empty states at sequence number 0.
+func NewCRStatesStream() CRStatesStream {
+	seq := uint64(0)
+	states := tc.NewCRStates()
+	return CRStatesStream{
+		seq:         &seq,
+		states:      &states,
+		subscribers: map[chan CRStatesStreamEvent]struct{}{},
+		m:           &sync.Mutex{},
+	}
+}
+
+// Publish sends the delta from the last published states to all subscribers, if anything changed, incrementing the sequence number. Subscribers whose buffer is full are dropped, by closing their channel.
+func (s CRStatesStream) Publish(states tc.CRStates) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delta := DiffCRStates(*s.states, states)
+	if delta.Empty() {
+		return
+	}
+	*s.seq++
+	*s.states = states.Copy()
+	event := CRStatesStreamEvent{Seq: *s.seq, Type: CRStatesStreamEventDelta, Delta: &delta}
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Subscribe returns a snapshot of the current states, and a channel of the deltas after it. The channel is closed if the subscriber falls too far behind. The returned func unsubscribes, and MUST be called when the subscriber is finished.
+func (s CRStatesStream) Subscribe() (CRStatesStreamEvent, <-chan CRStatesStreamEvent, func()) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	states := s.states.Copy()
+	snapshot := CRStatesStreamEvent{Seq: *s.seq, Type: CRStatesStreamEventSnapshot, States: &states}
+	subscriber := make(chan CRStatesStreamEvent, CRStatesStreamSubscriberBuffer)
+	s.subscribers[subscriber] = struct{}{}
+	unsubscribe := func() {
+		s.m.Lock()
+		defer s.m.Unlock()
+		if _, ok := s.subscribers[subscriber]; ok {
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return snapshot, subscriber, unsubscribe
+}
+
+// WriteCRStatesStreamEvent writes the given event to w as a Server-Sent Event. The event ID is the sequence number, and the data is the JSON states, delta, or an empty object for heartbeats.
+func WriteCRStatesStreamEvent(w io.Writer, event CRStatesStreamEvent) error {
+	data := []byte(`{}`)
+	err := error(nil)
+	switch event.Type {
+	case CRStatesStreamEventSnapshot:
+		data, err = json.Marshal(event.States)
+	case CRStatesStreamEventDelta:
+		data, err = json.Marshal(event.Delta)
+	}
+	if err != nil {
+		return fmt.Errorf("marshalling %s event: %v", event.Type, err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
+
+// CRStatesStreamDecoder reads a CRStates Server-Sent Events stream, applying each delta to the last snapshot.
+type CRStatesStreamDecoder struct {
+	r       *bufio.Reader
+	states  tc.CRStates
+	seq     uint64
+	started bool
+}
+
+// NewCRStatesStreamDecoder returns a decoder reading the stream from r.
+func NewCRStatesStreamDecoder(r io.Reader) *CRStatesStreamDecoder {
+	return &CRStatesStreamDecoder{r: bufio.NewReader(r), states: tc.NewCRStates()}
+}
+
+// Next reads the next event, and returns a copy of the resulting states, and whether they changed. Heartbeats return the unchanged states.
+// If a sequence number was missed, an error is returned, and the stream must be reconnected to get a new snapshot.
+func (d *CRStatesStreamDecoder) Next() (tc.CRStates, bool, error) {
+	event, data, err := d.readEvent()
+	if err != nil {
+		return tc.CRStates{}, false, err
+	}
+	switch event.Type {
+	case CRStatesStreamEventSnapshot:
+		states := tc.NewCRStates()
+		if err := json.Unmarshal(data, &states); err != nil {
+			return tc.CRStates{}, false, fmt.Errorf("decoding snapshot: %v", err)
+		}
+		if states.Caches == nil {
+			states.Caches = map[tc.CacheName]tc.IsAvailable{}
+		}
+		if states.DeliveryService == nil {
+			states.DeliveryService = map[tc.DeliveryServiceName]tc.CRStatesDeliveryService{}
+		}
+		d.states = states
+		d.seq = event.Seq
+		d.started = true
+		return d.states.Copy(), true, nil
+	case CRStatesStreamEventDelta:
+		if !d.started {
+			return tc.CRStates{}, false, errors.New("delta received before snapshot")
+		}
+		if event.Seq != d.seq+1 {
+			return tc.CRStates{}, false, fmt.Errorf("sequence gap: expected delta %d, received %d", d.seq+1, event.Seq)
+		}
+		delta := CRStatesDelta{}
+		if err := json.Unmarshal(data, &delta); err != nil {
+			return tc.CRStates{}, false, fmt.Errorf("decoding delta: %v", err)
+		}
+		ApplyCRStatesDelta(d.states, delta)
+		d.seq = event.Seq
+		return d.states.Copy(), true, nil
+	case CRStatesStreamEventHeartbeat:
+		if !d.started {
+			return tc.CRStates{}, false, errors.New("heartbeat received before snapshot")
+		}
+		if event.Seq != d.seq {
+			return tc.CRStates{}, false, fmt.Errorf("sequence gap: at %d, received heartbeat at %d", d.seq, event.Seq)
+		}
+		return d.states.Copy(), false, nil
+	default:
+		return tc.CRStates{}, false, errors.New("unknown event type '" + string(event.Type) + "'")
+	}
+}
+
+// readEvent reads the next Server-Sent Event, skipping comments. It returns the event with its sequence number and type, and the event data.
+func (d *CRStatesStreamDecoder) readEvent() (CRStatesStreamEvent, []byte, error) {
+	event := CRStatesStreamEvent{}
+	data := bytes.Buffer{}
+	hasFields := false
+	for {
+		line, err := d.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return CRStatesStreamEvent{}, nil, io.ErrUnexpectedEOF
+			}
+			return CRStatesStreamEvent{}, nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !hasFields {
+				continue
+			}
+			return event, data.Bytes(), nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment
+		}
+		hasFields = true
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			seq, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return CRStatesStreamEvent{}, nil, errors.New("malformed event id '" + value + "'")
+			}
+			event.Seq = seq
+		case "event":
+			event.Type = CRStatesStreamEventType(value)
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+}
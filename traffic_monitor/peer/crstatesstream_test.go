@@ -0,0 +1,143 @@
+package peer
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func testCRStates() tc.CRStates {
+	states := tc.NewCRStates()
+	states.Caches["edge0"] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+	states.Caches["edge1"] = tc.IsAvailable{IsAvailable: false}
+	states.DeliveryService["ds0"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{"cg0", "cg1"}}
+	return states
+}
+
+func TestDiffCRStates(t *testing.T) {
+	a := testCRStates()
+	b := testCRStates()
+	b.DeliveryService["ds0"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{"cg1", "cg0"}}
+	if delta := DiffCRStates(a, b); !delta.Empty() {
+		t.Errorf("DiffCRStates of equal states expected empty, actual %+v", delta)
+	}
+
+	b.Caches["edge1"] = tc.IsAvailable{IsAvailable: true, Ipv6Available: true}
+	b.Caches["edge2"] = tc.IsAvailable{IsAvailable: true}
+	delete(b.Caches, "edge0")
+	b.DeliveryService["ds0"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{"cg0"}}
+	delete(a.DeliveryService, "ds0")
+	a.DeliveryService["ds1"] = tc.CRStatesDeliveryService{IsAvailable: true, DisabledLocations: []tc.CacheGroupName{}}
+
+	delta := DiffCRStates(a, b)
+	if len(delta.Caches) != 2 || len(delta.DeletedCaches) != 1 || len(delta.DeliveryService) != 1 || len(delta.DeletedDeliveryServices) != 1 {
+		t.Fatalf("DiffCRStates expected 2 changed caches, 1 deleted cache, 1 changed ds, 1 deleted ds, actual %+v", delta)
+	}
+
+	ApplyCRStatesDelta(a, delta)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("ApplyCRStatesDelta expected %+v, actual %+v", b, a)
+	}
+}
+
+func TestCRStatesStream(t *testing.T) {
+	stream := NewCRStatesStream()
+	stream.Publish(testCRStates())
+
+	snapshot, events, unsubscribe := stream.Subscribe()
+	defer unsubscribe()
+	if snapshot.Seq != 1 {
+		t.Errorf("snapshot seq expected 1, actual %v", snapshot.Seq)
+	}
+
+	changed := testCRStates()
+	changed.Caches["edge1"] = tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+	stream.Publish(changed)
+	stream.Publish(changed) // unchanged, so nothing is sent
+
+	buf := &bytes.Buffer{}
+	if err := WriteCRStatesStreamEvent(buf, snapshot); err != nil {
+		t.Fatalf("writing snapshot: %v", err)
+	}
+	delta := <-events
+	if delta.Seq != 2 || delta.Type != CRStatesStreamEventDelta {
+		t.Fatalf("delta expected seq 2, actual %+v", delta)
+	}
+	if err := WriteCRStatesStreamEvent(buf, delta); err != nil {
+		t.Fatalf("writing delta: %v", err)
+	}
+	if err := WriteCRStatesStreamEvent(buf, CRStatesStreamEvent{Seq: 2, Type: CRStatesStreamEventHeartbeat}); err != nil {
+		t.Fatalf("writing heartbeat: %v", err)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("publishing unchanged states expected no event, actual %+v", event)
+	default:
+	}
+
+	decoder := NewCRStatesStreamDecoder(buf)
+	states, updated, err := decoder.Next()
+	if err != nil || !updated || !reflect.DeepEqual(states, testCRStates()) {
+		t.Errorf("decoding snapshot expected %+v true nil, actual %+v %v %v", testCRStates(), states, updated, err)
+	}
+	states, updated, err = decoder.Next()
+	if err != nil || !updated || !reflect.DeepEqual(states, changed) {
+		t.Errorf("decoding delta expected %+v true nil, actual %+v %v %v", changed, states, updated, err)
+	}
+	states, updated, err = decoder.Next()
+	if err != nil || updated || !reflect.DeepEqual(states, changed) {
+		t.Errorf("decoding heartbeat expected %+v false nil, actual %+v %v %v", changed, states, updated, err)
+	}
+}
+
+func TestCRStatesStreamGap(t *testing.T) {
+	stream := "id: 4\nevent: snapshot\ndata: {\"caches\":{},\"deliveryServices\":{}}\n\n" +
+		": comment\n\n" +
+		"id: 6\nevent: delta\ndata: {\"caches\":{\"edge0\":{\"isAvailable\":true}}}\n\n"
+	decoder := NewCRStatesStreamDecoder(strings.NewReader(stream))
+	if _, _, err := decoder.Next(); err != nil {
+		t.Fatalf("decoding snapshot expected nil error, actual %v", err)
+	}
+	if _, _, err := decoder.Next(); err == nil || !strings.Contains(err.Error(), "sequence gap") {
+		t.Errorf("decoding skipped delta expected sequence gap error, actual %v", err)
+	}
+}
+
+func TestCRStatesStreamSlowSubscriber(t *testing.T) {
+	stream := NewCRStatesStream()
+	_, events, unsubscribe := stream.Subscribe()
+	defer unsubscribe()
+	for i := 0; i <= CRStatesStreamSubscriberBuffer; i++ {
+		states := tc.NewCRStates()
+		states.Caches["edge0"] = tc.IsAvailable{IsAvailable: i%2 == 0}
+		stream.Publish(states)
+	}
+	for i := 0; i < CRStatesStreamSubscriberBuffer; i++ {
+		<-events
+	}
+	if _, ok := <-events; ok {
+		t.Errorf("subscriber which fell behind expected closed channel, actual open")
+	}
+}
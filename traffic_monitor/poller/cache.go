@@ -23,7 +23,9 @@ import (
 	"bytes"
 	"io"
 	"math/rand"
+	"reflect"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -40,12 +42,17 @@ type CachePoller struct {
 	Handler        handler.Handler
 }
 
+// PollConfig is the configuration of the polling of a single cache or peer.
+// URLv6 is the URL to poll over IPv6, if any. Each poll interval, URL is polled over IPv4, and URLv6 concurrently over IPv6 if it isn't empty.
+// FormatCtx is the configuration of the stats Format, given to the handler with each result, e.g. the config.PrometheusStatsMapping of the cache's profile for the prometheus format.
 type PollConfig struct {
-	URL      string
-	Host     string
-	Timeout  time.Duration
-	Format   string
-	PollType string
+	URL       string
+	URLv6     string
+	Host      string
+	Timeout   time.Duration
+	Format    string
+	FormatCtx interface{}
+	PollType  string
 }
 
 type CachePollerConfig struct {
@@ -119,7 +126,11 @@ func (p CachePoller) Poll() {
 			if pollerObj.Init != nil {
 				pollerCtx = pollerObj.Init(pollerCfg, p.GlobalContexts[info.PollType])
 			}
-			go poller(info.Interval, info.ID, info.URL, info.Host, info.Format, p.Handler, pollerObj.Poll, pollerCtx, kill)
+			if pollerObj.Stream != nil {
+				go streamPoller(info.Interval, info.ID, info.URL, info.Host, info.Format, info.FormatCtx, p.Handler, pollerObj.Stream, pollerCtx, kill)
+				continue
+			}
+			go poller(info.Interval, info.ID, info.URL, info.URLv6, info.Host, info.Format, info.FormatCtx, p.Handler, pollerObj.Poll, pollerCtx, kill)
 		}
 		p.Config = newConfig
 	}
@@ -139,8 +150,10 @@ func poller(
 	interval time.Duration,
 	id string,
 	url string,
+	urlv6 string,
 	host string,
 	format string,
+	formatCtx interface{},
 	handler handler.Handler,
 	pollFunc PollerFunc,
 	pollCtx interface{},
@@ -159,18 +172,17 @@ func poller(
 			}
 			lastTime = time.Now()
 
-			pollID := atomic.AddUint64(&pollNum, 1)
-			pollFinishedChan := make(chan uint64)
-			log.Debugf("poll %v %v start\n", pollID, time.Now())
-			bts, reqEnd, reqTime, err := pollFunc(pollCtx, url, host, pollID)
-			rdr := io.Reader(nil)
-			if bts != nil {
-				rdr = bytes.NewReader(bts) // TODO change handler to take bytes? Benchmark?
-			} else {
+			// poll the families concurrently, so a slow or timing out IPv6 path doesn't delay the IPv4 poll, or make the interval overrun
+			wg := sync.WaitGroup{}
+			if urlv6 != "" {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					poll(id, urlv6, host, format, formatCtx, true, handler, pollFunc, pollCtx)
+				}()
 			}
-			log.Debugf("poll %v %v poller end\n", pollID, time.Now())
-			go handler.Handle(id, rdr, format, reqTime, reqEnd, err, pollID, pollFinishedChan)
-			<-pollFinishedChan
+			poll(id, url, host, format, formatCtx, false, handler, pollFunc, pollCtx)
+			wg.Wait()
 		case <-die:
 			tick.Stop()
 			return
@@ -178,6 +190,56 @@ func poller(
 	}
 }
 
+// poll polls the given URL once, over IPv6 if usingIPv6, otherwise over IPv4, and passes the result to the handler, returning when the handler has finished with it.
+func poll(
+	id string,
+	url string,
+	host string,
+	format string,
+	formatCtx interface{},
+	usingIPv6 bool,
+	handler handler.Handler,
+	pollFunc PollerFunc,
+	pollCtx interface{},
+) {
+	pollID := atomic.AddUint64(&pollNum, 1)
+	log.Debugf("poll %v %v start\n", pollID, time.Now())
+	bts, reqEnd, reqTime, err := pollFunc(pollCtx, url, host, pollID)
+	log.Debugf("poll %v %v poller end\n", pollID, time.Now())
+	handle(id, bts, format, formatCtx, reqTime, reqEnd, err, pollID, usingIPv6, handler)
+}
+
+// streamPoller streams from the given URL with the given stream func until die is signalled, passing each result to the handler.
+func streamPoller(
+	interval time.Duration,
+	id string,
+	url string,
+	host string,
+	format string,
+	formatCtx interface{},
+	handler handler.Handler,
+	streamFunc PollerStreamFunc,
+	pollCtx interface{},
+	die <-chan struct{},
+) {
+	streamFunc(pollCtx, url, host, interval, die, func(bts []byte, reqEnd time.Time, reqTime time.Duration, err error) {
+		pollID := atomic.AddUint64(&pollNum, 1)
+		log.Debugf("poll %v %v stream result\n", pollID, time.Now())
+		handle(id, bts, format, formatCtx, reqTime, reqEnd, err, pollID, false, handler)
+	})
+}
+
+// handle passes a poll result to the handler, returning when the handler has finished with it.
+func handle(id string, bts []byte, format string, formatCtx interface{}, reqTime time.Duration, reqEnd time.Time, err error, pollID uint64, usingIPv6 bool, handler handler.Handler) {
+	pollFinishedChan := make(chan uint64)
+	rdr := io.Reader(nil)
+	if bts != nil {
+		rdr = bytes.NewReader(bts) // TODO change handler to take bytes? Benchmark?
+	}
+	go handler.Handle(id, rdr, format, formatCtx, reqTime, reqEnd, err, pollID, usingIPv6, pollFinishedChan)
+	<-pollFinishedChan
+}
+
 // diffConfigs takes the old and new configs, and returns a list of deleted IDs, and a list of new polls to do
 func diffConfigs(old CachePollerConfig, new CachePollerConfig) ([]string, []CachePollInfo) {
 	deletions := []string{}
@@ -202,7 +264,7 @@ func diffConfigs(old CachePollerConfig, new CachePollerConfig) ([]string, []Cach
 		newPollCfg, newIdExists := new.Urls[id]
 		if !newIdExists {
 			deletions = append(deletions, id)
-		} else if newPollCfg != oldPollCfg {
+		} else if !reflect.DeepEqual(newPollCfg, oldPollCfg) {
 			deletions = append(deletions, id)
 			additions = append(additions, CachePollInfo{
 				Interval:    new.Interval,
@@ -0,0 +1,173 @@
+package poller
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+)
+
+// PollerTypeCRStatesStream streams CRStates from a peer Traffic Monitor's /publish/CrStatesStream, reporting the full states to the handler each time they change, as if they had been polled.
+const PollerTypeCRStatesStream = "crstates-stream"
+
+// crStatesStreamIdleTimeout is how long a stream may go without any event, including heartbeats, before it's considered dead and reconnected.
+const crStatesStreamIdleTimeout = peer.CRStatesStreamHeartbeatInterval * 3
+
+func init() {
+	AddStreamPollerType(PollerTypeCRStatesStream, httpGlobalInit, crStatesStreamInit, crStatesStream)
+}
+
+// crStatesStreamInit creates a client without an overall timeout, which would end the stream. The timeout is instead used for connecting and the response headers.
+func crStatesStreamInit(cfg PollerConfig, globalCtxI interface{}) interface{} {
+	gctx := (globalCtxI).(*HTTPPollGlobalCtx)
+	timeout := gctx.Client.Timeout
+	if cfg.Timeout != 0 {
+		timeout = cfg.Timeout
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+			DialContext:           (&net.Dialer{Timeout: timeout}).DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+	}
+	return &HTTPPollCtx{
+		Client:    client,
+		UserAgent: gctx.UserAgent,
+		URL:       cfg.URL,
+		Host:      cfg.Host,
+		PollerID:  cfg.PollerID,
+	}
+}
+
+// crStatesStream streams until die is signalled, reconnecting after each interval if the stream fails.
+func crStatesStream(ctxI interface{}, url string, host string, interval time.Duration, die <-chan struct{}, result PollerStreamResultFunc) {
+	ctx := (ctxI).(*HTTPPollCtx)
+	for {
+		start := time.Now()
+		err := streamCRStates(ctx, url, host, interval, die, result)
+		if mustDie(die) {
+			return
+		}
+		reqEnd := time.Now()
+		result(nil, reqEnd, reqEnd.Sub(start), fmt.Errorf("id %v url %v stream error: %v", ctx.PollerID, url, err))
+
+		select {
+		case <-die:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+type crStatesStreamResult struct {
+	states  tc.CRStates
+	changed bool
+	err     error
+}
+
+// streamCRStates streams from the given URL, calling result with the states each time they change, and at least every interval. It returns when die is signalled, or with the error if the stream fails.
+func streamCRStates(ctx *HTTPPollCtx, url string, host string, interval time.Duration, die <-chan struct{}, result PollerStreamResultFunc) error {
+	reqCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return errors.New("creating HTTP request: " + err.Error())
+	}
+	req = req.WithContext(reqCtx)
+	req.Header.Set("User-Agent", ctx.UserAgent)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Host = host
+
+	start := time.Now()
+	resp, err := ctx.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("bad HTTP status: %v", resp.StatusCode)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	results := make(chan crStatesStreamResult)
+	go func() {
+		decoder := peer.NewCRStatesStreamDecoder(resp.Body)
+		for {
+			states, changed, err := decoder.Next()
+			select {
+			case results <- crStatesStreamResult{states: states, changed: changed, err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	idle := time.NewTimer(crStatesStreamIdleTimeout)
+	defer idle.Stop()
+	lastResult := time.Time{}
+	lastEvent := start
+	for {
+		select {
+		case <-die:
+			return nil
+		case <-idle.C:
+			return fmt.Errorf("no events for %v", crStatesStreamIdleTimeout)
+		case r := <-results:
+			if r.err != nil {
+				return r.err
+			}
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(crStatesStreamIdleTimeout)
+
+			now := time.Now()
+			reqTime := now.Sub(lastEvent)
+			lastEvent = now
+			if !r.changed && now.Sub(lastResult) < interval/2 {
+				continue // unchanged heartbeats are only reported about once an interval, to keep the peer from being considered stale
+			}
+			bts, err := tc.CRStatesMarshall(r.states)
+			if err != nil {
+				return errors.New("marshalling states: " + err.Error())
+			}
+			log.Debugf("crstates stream %v received %v states\n", ctx.PollerID, len(r.states.Caches))
+			result(bts, now, reqTime, nil)
+			lastResult = now
+		}
+	}
+}
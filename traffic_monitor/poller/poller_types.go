@@ -28,10 +28,12 @@ import (
 const DefaultPollerType = PollerTypeHTTP
 
 // Poller is a particular type of cache stat poller. Examples are HTTP, TCP, or NFS files. It only polls and returns bytes received, it does not do any parsing. For stat parsing, see traffic_monitor/cache/stats_types.go.
+// A poller type may instead be a streaming poller, which has a Stream func rather than a Poll func, and reports results as they're received rather than each poll interval.
 type PollerType struct {
 	GlobalInit PollerGlobalInitFunc
 	Init       PollerInitFunc
 	Poll       PollerFunc
+	Stream     PollerStreamFunc
 }
 
 // PollerConfig is the data given to cache pollers when they're initialized.
@@ -58,11 +60,23 @@ var pollers = map[string]PollerType{}
 // If the PollerFunc needs the global context object, the Init func should embed it in the context object it returns. If Init is nil, the global context will be given to the poller.
 type PollerFunc func(ctx interface{}, url string, host string, pollID uint64) ([]byte, time.Time, time.Duration, error)
 
+// PollerStreamFunc streams from a cache or peer. It takes the global or poller-specific context, like a PollerFunc, and the poll interval, and calls result with the bytes of each result as it's received, until die is signalled. The result func returns when the result has been handled.
+// Stream funcs are responsible for reconnecting, and should call result with an error when the stream fails, and with a result at least every interval while it's healthy, so the polled object isn't considered stale.
+type PollerStreamFunc func(ctx interface{}, url string, host string, interval time.Duration, die <-chan struct{}, result PollerStreamResultFunc)
+
+// PollerStreamResultFunc handles a single streamed result, with the same values returned by a PollerFunc.
+type PollerStreamResultFunc func(bts []byte, reqEnd time.Time, reqTime time.Duration, err error)
+
 // AddPollerType adds a poller with the given name, and the given init and poll funcs. The globalInit and init funcs may be nil; poller MUST NOT be nil.
 func AddPollerType(name string, globalInit PollerGlobalInitFunc, init PollerInitFunc, poller PollerFunc) {
 	pollers[name] = PollerType{GlobalInit: globalInit, Init: init, Poll: poller}
 }
 
+// AddStreamPollerType adds a streaming poller with the given name, and the given init and stream funcs. The globalInit and init funcs may be nil; stream MUST NOT be nil.
+func AddStreamPollerType(name string, globalInit PollerGlobalInitFunc, init PollerInitFunc, stream PollerStreamFunc) {
+	pollers[name] = PollerType{GlobalInit: globalInit, Init: init, Stream: stream}
+}
+
 // GetGlobalContexts returns the global contexts corresponding to the registered pollers
 func GetGlobalContexts(cfg config.Config, appData config.StaticAppData) map[string]interface{} {
 	ctxs := map[string]interface{}{}
@@ -191,6 +191,7 @@ func StatsMarshall(statResultHistory ResultStatHistory, statInfo cache.ResultInf
 	}
 
 	computedStats := cache.ComputedStats()
+	interfaceStats := cache.InterfaceComputedStats()
 
 	// TODO in 1.0, stats are divided into 'location', 'cache', and 'type'. 'cache' are hidden by default.
 
@@ -245,6 +246,16 @@ func StatsMarshall(statResultHistory ResultStatHistory, statInfo cache.ResultInf
 				}
 				stats.Caches[id][stat] = append(stats.Caches[id][stat], cache.ResultStatVal{Val: statValF(resultInfo, serverInfo, serverProfile, combinedStatesCache), Time: t, Span: 1}) // combinedState will default to unavailable
 			}
+
+			for name, vitals := range resultInfo.Vitals.Interfaces {
+				for infStat, statValF := range interfaceStats {
+					stat := cache.InterfaceStatName(name, infStat)
+					if !filter.UseStat(stat) {
+						continue
+					}
+					stats.Caches[id][stat] = append(stats.Caches[id][stat], cache.ResultStatVal{Val: statValF(vitals), Time: t, Span: 1})
+				}
+			}
 		}
 	}
 
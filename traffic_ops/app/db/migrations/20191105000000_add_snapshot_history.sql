@@ -0,0 +1,35 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS snapshot_history (
+    id bigserial PRIMARY KEY,
+    cdn text NOT NULL,
+    crconfig json NOT NULL,
+    monitoring json NOT NULL,
+    username text NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    CONSTRAINT fk_snapshot_history_cdn FOREIGN KEY (cdn) REFERENCES cdn(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS snapshot_history_cdn_idx ON snapshot_history (cdn, id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS snapshot_history_cdn_idx;
+DROP TABLE IF EXISTS snapshot_history;
@@ -0,0 +1,63 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS topology (
+    name text PRIMARY KEY,
+    description text NOT NULL DEFAULT '',
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    CONSTRAINT topology_name_empty CHECK (length(name) > 0)
+);
+
+CREATE TABLE IF NOT EXISTS topology_cachegroup (
+    id bigserial PRIMARY KEY,
+    topology text NOT NULL,
+    cachegroup text NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    CONSTRAINT unique_topology_cachegroup UNIQUE (topology, cachegroup),
+    CONSTRAINT fk_topology FOREIGN KEY (topology) REFERENCES topology(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_cachegroup FOREIGN KEY (cachegroup) REFERENCES cachegroup(name) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS topology_cachegroup_cachegroup_idx ON topology_cachegroup (cachegroup);
+
+CREATE TABLE IF NOT EXISTS topology_cachegroup_parents (
+    child bigint NOT NULL,
+    parent bigint NOT NULL,
+    rank integer NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (child, parent),
+    CONSTRAINT unique_child_rank UNIQUE (child, rank),
+    CONSTRAINT topology_cachegroup_parents_rank CHECK (rank = 1 OR rank = 2),
+    CONSTRAINT fk_child FOREIGN KEY (child) REFERENCES topology_cachegroup(id) ON DELETE CASCADE,
+    CONSTRAINT fk_parent FOREIGN KEY (parent) REFERENCES topology_cachegroup(id) ON DELETE CASCADE
+);
+
+ALTER TABLE deliveryservice ADD COLUMN IF NOT EXISTS topology text;
+ALTER TABLE deliveryservice ADD CONSTRAINT fk_deliveryservice_topology FOREIGN KEY (topology) REFERENCES topology(name) ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE deliveryservice DROP CONSTRAINT IF EXISTS fk_deliveryservice_topology;
+ALTER TABLE deliveryservice DROP COLUMN IF EXISTS topology;
+DROP TABLE IF EXISTS topology_cachegroup_parents;
+DROP INDEX IF EXISTS topology_cachegroup_cachegroup_idx;
+DROP TABLE IF EXISTS topology_cachegroup;
+DROP TABLE IF EXISTS topology;
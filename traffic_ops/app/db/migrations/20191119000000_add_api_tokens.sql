@@ -0,0 +1,36 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS api_token (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    token_hash text NOT NULL,
+    tm_user_id bigint NOT NULL,
+    capabilities text[],
+    expires timestamp with time zone,
+    last_used timestamp with time zone,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    CONSTRAINT api_token_name_empty CHECK (length(name) > 0),
+    CONSTRAINT unique_api_token_hash UNIQUE (token_hash),
+    CONSTRAINT unique_api_token_user_name UNIQUE (tm_user_id, name),
+    CONSTRAINT fk_api_token_user FOREIGN KEY (tm_user_id) REFERENCES tm_user(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS api_token;
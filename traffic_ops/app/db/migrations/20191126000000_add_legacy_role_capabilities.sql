@@ -0,0 +1,57 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Routes now require both their privilege level and their capabilities, so roles
+-- without any capabilities, such as federation, portal, and steering roles, are
+-- given the capabilities required by the routes of their privilege level.
+
+-- capabilities added since the last seeds were loaded
+INSERT INTO capability (name, description) VALUES ('api-tokens-read', 'Ability to view API tokens') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('api-tokens-write', 'Ability to issue and revoke API tokens') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('servers-queue-updates', 'Ability to queue updates on servers') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('topologies-read', 'Ability to view topologies') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('topologies-write', 'Ability to edit topologies') ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name
+FROM role AS r
+CROSS JOIN capability AS c
+WHERE r.priv_level > 0
+AND NOT EXISTS (SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id = r.id)
+AND (
+  -- admin
+  r.priv_level >= 30
+  -- operations
+  OR (r.priv_level >= 20 AND c.name NOT IN ('db-dump', 'roles-write'))
+  -- federation, portal, and steering
+  OR (r.priv_level >= 15 AND c.name IN (
+    'delivery-service-requests-write',
+    'delivery-services-write',
+    'federations-write',
+    'jobs-write',
+    'steering-targets-write'
+  ))
+  -- read-only and ORT
+  OR (r.priv_level >= 10 AND (c.name LIKE '%-read' OR c.name IN ('auth', 'iso-generate', 'riak')))
+)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+-- The capabilities given to roles can't be distinguished from those given by users, so they're kept.
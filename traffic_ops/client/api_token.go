@@ -0,0 +1,89 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	APIAPITokens = apiBase + "/api_tokens"
+)
+
+// CreateAPIToken issues an API token. The response contains the secret token, which can't be retrieved again.
+// Tokens can't be issued by a Session authenticated with an API token.
+func (to *Session) CreateAPIToken(req tc.APITokenRequest) (*tc.APITokenCreateResponse, ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, reqInf, err
+	}
+	resp, remoteAddr, err := to.request(http.MethodPost, APIAPITokens, reqBody)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return nil, reqInf, err
+	}
+	defer resp.Body.Close()
+	var tokenResp tc.APITokenCreateResponse
+	if err = json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, reqInf, err
+	}
+	return &tokenResp, reqInf, nil
+}
+
+// GetAPITokens returns the API tokens of the current user.
+func (to *Session) GetAPITokens() ([]tc.APIToken, ReqInf, error) {
+	return to.getAPITokens(APIAPITokens)
+}
+
+// GetUserAPITokens returns the API tokens of the given user. Only admins may get the tokens of other users.
+func (to *Session) GetUserAPITokens(userID int) ([]tc.APIToken, ReqInf, error) {
+	return to.getAPITokens(APIAPITokens + "?userId=" + strconv.Itoa(userID))
+}
+
+func (to *Session) getAPITokens(path string) ([]tc.APIToken, ReqInf, error) {
+	resp, remoteAddr, err := to.request(http.MethodGet, path, nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return nil, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	var data tc.APITokensResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, reqInf, err
+	}
+	return data.Response, reqInf, nil
+}
+
+// DeleteAPIToken revokes the API token with the given ID.
+func (to *Session) DeleteAPIToken(id int) (tc.Alerts, ReqInf, error) {
+	resp, remoteAddr, err := to.request(http.MethodDelete, APIAPITokens+"/"+strconv.Itoa(id), nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+	var alerts tc.Alerts
+	if err = json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	return alerts, reqInf, nil
+}
@@ -0,0 +1,164 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// Pager iterates over the pages of a Traffic Ops API collection, using the limit and page query parameters which every generic Traffic Ops read endpoint supports.
+//
+// Example:
+//
+//	pager := to.NewPager(API_v13_Servers, url.Values{"orderby": {"hostName"}}, 100)
+//	for {
+//	  servers := []tc.Server{}
+//	  ok, err := pager.Next(&servers)
+//	  if err != nil {
+//	    return err
+//	  }
+//	  if !ok {
+//	    break
+//	  }
+//	  // use servers
+//	}
+type Pager struct {
+	to       *Session
+	path     string
+	params   url.Values
+	pageSize int
+	page     int
+	count    int
+	done     bool
+	lastPage []byte
+	reqInf   ReqInf
+}
+
+// NewPager returns a Pager over the collection at the given API path, e.g. API_v13_Servers, with pages of pageSize objects.
+// The params are any other query parameters, such as filters, orderby, sortOrder, and fields, and may be nil.
+func (to *Session) NewPager(path string, params url.Values, pageSize int) *Pager {
+	return &Pager{to: to, path: path, params: params, pageSize: pageSize}
+}
+
+// Next requests the next page, and decodes its objects into v, which must be a pointer to a slice of the collection's type.
+// Returns false when there are no more pages, in which case v is unmodified.
+//
+// If the endpoint doesn't paginate, and ignores limit and page, Next still ends: a page with more than the page size of objects is the whole collection, and a page identical to the one before it is the end.
+func (p *Pager) Next(v interface{}) (bool, error) {
+	if p.done {
+		return false, nil
+	}
+	if p.pageSize < 1 {
+		return false, errors.New("page size must be positive")
+	}
+
+	params := url.Values{}
+	for k, vals := range p.params {
+		params[k] = vals
+	}
+	params.Set("limit", strconv.Itoa(p.pageSize))
+	params.Set("page", strconv.Itoa(p.page+1))
+
+	resp, remoteAddr, err := p.to.request(http.MethodGet, p.path+"?"+params.Encode(), nil)
+	p.reqInf = ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	data := struct {
+		Response []json.RawMessage `json:"response"`
+		Summary  *tc.Summary       `json:"summary"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return false, errors.New("decoding response: " + err.Error())
+	}
+	p.page++
+	countKnown := data.Summary != nil // endpoints which don't count their objects omit the summary of paginated responses
+	if countKnown {
+		p.count = data.Summary.Count
+	} else {
+		p.count += len(data.Response)
+	}
+	if len(data.Response) == 0 {
+		p.done = true
+		return false, nil
+	}
+
+	bts, err := json.Marshal(data.Response)
+	if err != nil {
+		return false, errors.New("encoding page: " + err.Error())
+	}
+	if p.lastPage != nil && bytes.Equal(bts, p.lastPage) {
+		p.done = true // the endpoint ignored page, and returned the same objects again
+		if !countKnown {
+			p.count -= len(data.Response)
+		}
+		return false, nil
+	}
+	p.lastPage = bts
+	if len(data.Response) != p.pageSize || (countKnown && p.page*p.pageSize >= p.count) {
+		p.done = true // the last page, or the whole collection if the endpoint ignored limit; don't request an empty page after it
+	}
+	if err := json.Unmarshal(bts, v); err != nil {
+		return false, errors.New("decoding page: " + err.Error())
+	}
+	return true, nil
+}
+
+// Count returns the number of objects in the collection, as of the last page requested.
+// If the endpoint doesn't return the count, it's the number of objects in the pages requested so far.
+func (p *Pager) Count() int {
+	return p.count
+}
+
+// ReqInf returns the request info of the last page requested.
+func (p *Pager) ReqInf() ReqInf {
+	return p.reqInf
+}
+
+// GetAllPages requests every page of the collection at the given API path, and decodes all their objects into v, which must be a pointer to a slice of the collection's type.
+// Requesting pages, rather than the whole collection at once, keeps each response small for large collections, such as servers and parameters.
+func (to *Session) GetAllPages(path string, params url.Values, pageSize int, v interface{}) (ReqInf, error) {
+	pager := to.NewPager(path, params, pageSize)
+	all := []json.RawMessage{}
+	for {
+		page := []json.RawMessage{}
+		ok, err := pager.Next(&page)
+		if err != nil {
+			return pager.ReqInf(), err
+		}
+		if !ok {
+			break
+		}
+		all = append(all, page...)
+	}
+	bts, err := json.Marshal(all)
+	if err != nil {
+		return pager.ReqInf(), errors.New("encoding pages: " + err.Error())
+	}
+	if err := json.Unmarshal(bts, v); err != nil {
+		return pager.ReqInf(), errors.New("decoding pages: " + err.Error())
+	}
+	return pager.ReqInf(), nil
+}
@@ -0,0 +1,165 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// testPages serves a collection of CDNs named 0 through len-1, paginated by the limit and page query parameters.
+type testPages struct {
+	names     []string
+	requests  int
+	noSummary bool // like endpoints which don't count their objects
+	noLimit   bool // like endpoints which don't paginate, and return every object
+	noPage    bool // like endpoints which apply limit, but not page
+}
+
+func (tp *testPages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tp.requests++
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if tp.noLimit {
+		limit = len(tp.names)
+	}
+	if tp.noLimit || tp.noPage {
+		page = 1
+	}
+	start := (page - 1) * limit
+	end := start + limit
+	if start > len(tp.names) {
+		start = len(tp.names)
+	}
+	if end > len(tp.names) {
+		end = len(tp.names)
+	}
+	cdns := []tc.CDN{}
+	for _, name := range tp.names[start:end] {
+		cdns = append(cdns, tc.CDN{Name: name})
+	}
+	summary := &tc.Summary{Count: len(tp.names)}
+	if tp.noSummary {
+		summary = nil
+	}
+	json.NewEncoder(w).Encode(struct {
+		Response []tc.CDN    `json:"response"`
+		Summary  *tc.Summary `json:"summary,omitempty"`
+	}{cdns, summary})
+}
+
+func TestPager(t *testing.T) {
+	tp := &testPages{names: []string{"0", "1", "2", "3", "4"}}
+	srv := httptest.NewServer(tp)
+	defer srv.Close()
+	to := NewSession("user", "pass", srv.URL, "test", &http.Client{}, false)
+
+	pager := to.NewPager(API_v13_CDNs, url.Values{"orderby": {"name"}}, 2)
+	names := []string{}
+	for {
+		cdns := []tc.CDN{}
+		ok, err := pager.Next(&cdns)
+		if err != nil {
+			t.Fatalf("Pager.Next expected: nil error, actual: %v", err)
+		}
+		if !ok {
+			break
+		}
+		for _, cdn := range cdns {
+			names = append(names, cdn.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, tp.names) {
+		t.Errorf("Pager expected: %v, actual: %v", tp.names, names)
+	}
+	if pager.Count() != len(tp.names) {
+		t.Errorf("Pager.Count expected: %v, actual: %v", len(tp.names), pager.Count())
+	}
+	if tp.requests != 3 {
+		t.Errorf("Pager expected: 3 page requests, actual: %v", tp.requests)
+	}
+
+	cdns := []tc.CDN{}
+	if _, err := to.GetAllPages(API_v13_CDNs, nil, 4, &cdns); err != nil {
+		t.Fatalf("GetAllPages expected: nil error, actual: %v", err)
+	}
+	if len(cdns) != len(tp.names) || cdns[4].Name != "4" {
+		t.Errorf("GetAllPages expected: %v CDNs, actual: %+v", len(tp.names), cdns)
+	}
+}
+
+func TestPagerNoSummary(t *testing.T) {
+	tp := &testPages{names: []string{"0", "1", "2", "3"}, noSummary: true}
+	srv := httptest.NewServer(tp)
+	defer srv.Close()
+	to := NewSession("user", "pass", srv.URL, "test", &http.Client{}, false)
+
+	cdns := []tc.CDN{}
+	if _, err := to.GetAllPages(API_v13_CDNs, nil, 2, &cdns); err != nil {
+		t.Fatalf("GetAllPages expected: nil error, actual: %v", err)
+	}
+	if len(cdns) != len(tp.names) {
+		t.Errorf("GetAllPages expected: %v CDNs, actual: %+v", len(tp.names), cdns)
+	}
+	// without a count, the pager can't know the last full page is the last
+	if tp.requests != 3 {
+		t.Errorf("Pager expected: 3 page requests, actual: %v", tp.requests)
+	}
+}
+
+func TestPagerUnpaginated(t *testing.T) {
+	tp := &testPages{names: []string{"0", "1", "2", "3", "4"}, noSummary: true, noLimit: true}
+	srv := httptest.NewServer(tp)
+	defer srv.Close()
+	to := NewSession("user", "pass", srv.URL, "test", &http.Client{}, false)
+
+	cdns := []tc.CDN{}
+	if _, err := to.GetAllPages(API_v13_CDNs, nil, 2, &cdns); err != nil {
+		t.Fatalf("GetAllPages ignoring limit expected: nil error, actual: %v", err)
+	}
+	if len(cdns) != len(tp.names) || tp.requests != 1 {
+		t.Errorf("GetAllPages ignoring limit expected: %v CDNs in 1 request, actual: %v CDNs in %v requests", len(tp.names), len(cdns), tp.requests)
+	}
+
+	tp = &testPages{names: []string{"0", "1", "2", "3", "4"}, noSummary: true, noPage: true}
+	srv2 := httptest.NewServer(tp)
+	defer srv2.Close()
+	to = NewSession("user", "pass", srv2.URL, "test", &http.Client{}, false)
+
+	pager := to.NewPager(API_v13_CDNs, nil, 2)
+	cdns = []tc.CDN{}
+	if _, err := to.GetAllPages(API_v13_CDNs, nil, 2, &cdns); err != nil {
+		t.Fatalf("GetAllPages ignoring page expected: nil error, actual: %v", err)
+	}
+	if len(cdns) != 2 || tp.requests != 2 {
+		t.Errorf("GetAllPages ignoring page expected: 2 CDNs in 2 requests, actual: %v CDNs in %v requests", len(cdns), tp.requests)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := pager.Next(&[]tc.CDN{}); err != nil {
+			t.Fatalf("Pager.Next ignoring page expected: nil error, actual: %v", err)
+		}
+	}
+	if pager.Count() != 2 {
+		t.Errorf("Pager.Count ignoring page expected: 2, actual: %v", pager.Count())
+	}
+}
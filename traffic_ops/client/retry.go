@@ -0,0 +1,240 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-util"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const DefaultRetryMaxAttempts = 3
+const DefaultRetryBackoffMin = time.Millisecond * 250
+const DefaultRetryBackoffMax = time.Second * 10
+
+// RetryOptions is how a Session retries requests. Only idempotent requests and logins are retried, and only if they fail with a network error, a 429 Too Many Requests, or a 502, 503, or 504. Each retry fails over to the next Traffic Ops URL, if the Session has multiple.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of times to try a request, including the first. If this is less than 2, requests aren't retried.
+	MaxAttempts int
+	// BackoffMin is the duration to wait before the first retry. Each subsequent retry waits exponentially longer, with jitter, up to BackoffMax.
+	BackoffMin time.Duration
+	// BackoffMax is the maximum duration to wait between retries.
+	BackoffMax time.Duration
+	// BackoffFactor is the factor to increase the wait by, between retries. If this is 0, util.DefaultFactor is used.
+	BackoffFactor float64
+}
+
+// DefaultRetryOptions returns the default RetryOptions of LoginWithOptions.
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxAttempts:   DefaultRetryMaxAttempts,
+		BackoffMin:    DefaultRetryBackoffMin,
+		BackoffMax:    DefaultRetryBackoffMax,
+		BackoffFactor: util.DefaultFactor,
+	}
+}
+
+// ClientOpts is the options of a Session created with LoginWithOptions.
+type ClientOpts struct {
+	// URLs is the Traffic Ops URLs to use. Requests use the first URL until one fails and is retried, which fails over to the next URL. At least one URL is required.
+	URLs           []string
+	User           string
+	Password       string
+	Insecure       bool
+	UserAgent      string
+	UseCache       bool
+	RequestTimeout time.Duration
+	Retry          RetryOptions
+	// APIToken is a Traffic Ops API token. If set, requests are authenticated with the token, instead of logging in with User and Password.
+	APIToken string
+}
+
+// LoginWithOptions creates a Session which fails over between multiple Traffic Ops URLs, and retries requests. Login is retried, failing over between URLs, like other requests. Requests which are Unauthorized or Forbidden, because the login cookie expired or the request failed over to a Traffic Ops which hasn't been logged in to, log in again, and are retried once.
+//
+// If opts.APIToken is set, the Session doesn't log in, and requests are authenticated with the token instead. Unauthorized and Forbidden requests aren't retried, because a token doesn't expire by logging in again.
+//
+// The ctx is only used for logging in. To use a context for other requests, use WithContext.
+//
+// Returns the logged in client, the remote address of the Traffic Ops which was used to log in, and any error. If the error is not nil, the remote address may or may not be nil, depending whether the error occurred before the login request.
+func LoginWithOptions(ctx context.Context, opts ClientOpts) (*Session, net.Addr, error) {
+	if len(opts.URLs) == 0 {
+		return nil, nil, errors.New("no Traffic Ops URLs")
+	}
+	if opts.Retry.MaxAttempts > 1 {
+		if _, err := opts.Retry.newBackoff(); err != nil {
+			return nil, nil, errors.New("invalid retry options: " + err.Error())
+		}
+	}
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to := NewSession(opts.User, opts.Password, opts.URLs[0], opts.UserAgent, &http.Client{
+		Timeout: opts.RequestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: opts.Insecure},
+		},
+		Jar: jar,
+	}, opts.UseCache)
+	to.urls = newFailoverURLs(opts.URLs)
+	to.retry = opts.Retry
+
+	if opts.APIToken != "" {
+		to.apiToken = opts.APIToken
+		return to, nil, nil // requests are authenticated with the token, so there's nothing to log in to
+	}
+
+	remoteAddr, err := to.WithContext(ctx).loginWithRetry()
+	if err != nil {
+		return nil, remoteAddr, errors.New("logging in: " + err.Error())
+	}
+	return to, remoteAddr, nil
+}
+
+// WithContext returns a shallow copy of the Session, whose requests use the given context. Requests are canceled, and retries stop, when the context is done.
+// The returned Session shares its login cookie, cache, and Traffic Ops URL failover with the original, and is safe to use concurrently with it.
+func (to *Session) WithContext(ctx context.Context) *Session {
+	if ctx == nil {
+		panic("nil context")
+	}
+	toCopy := *to
+	toCopy.ctx = ctx
+	return &toCopy
+}
+
+// Context returns the Session's context. If the Session has no context, context.Background is returned.
+func (to *Session) Context() context.Context {
+	if to.ctx == nil {
+		return context.Background()
+	}
+	return to.ctx
+}
+
+// loginWithRetry logs in, retrying per the Session's RetryOptions. Login is always retried, because it doesn't modify anything.
+func (to *Session) loginWithRetry() (net.Addr, error) {
+	remoteAddr := net.Addr(nil)
+	_, _, err := to.withRetry(true, func() (*http.Response, net.Addr, error) {
+		addr, err := to.login()
+		remoteAddr = addr
+		return nil, addr, err
+	})
+	return remoteAddr, err
+}
+
+// withRetry calls do, which performs a request, retrying and failing over per the Session's RetryOptions. Idempotent is whether the request can safely be retried if it may have been received by Traffic Ops.
+func (to *Session) withRetry(idempotent bool, do func() (*http.Response, net.Addr, error)) (*http.Response, net.Addr, error) {
+	if to.retry.MaxAttempts < 2 {
+		return do()
+	}
+	backoff, err := to.retry.newBackoff()
+	if err != nil {
+		return do() // should never happen, LoginWithOptions verifies the options
+	}
+
+	for attempt := 1; ; attempt++ {
+		url := to.getURL("")
+		resp, remoteAddr, err := do()
+		if err == nil || attempt >= to.retry.MaxAttempts || !to.isRetryable(idempotent, err) {
+			return resp, remoteAddr, err
+		}
+		if to.urls != nil {
+			to.urls.failover(url)
+		}
+		select {
+		case <-to.Context().Done():
+			return nil, remoteAddr, err
+		case <-time.After(backoff.BackoffDuration()):
+		}
+	}
+}
+
+// isRetryable returns whether a request which failed with the given error should be retried. Idempotent is whether the request can safely be retried if it may have been received by Traffic Ops.
+func (to *Session) isRetryable(idempotent bool, err error) bool {
+	if to.Context().Err() != nil {
+		return false
+	}
+	if !idempotent {
+		return false
+	}
+	switch err := err.(type) {
+	case *ClientError:
+		return err.HTTPStatusCode == http.StatusTooManyRequests
+	case *ServerError:
+		switch err.HTTPStatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	case net.Error:
+		return true // network failures, including timeouts
+	}
+	return err == io.ErrUnexpectedEOF // the connection was closed while reading the response
+}
+
+// isIdempotent returns whether a request with the given method can safely be retried.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (r RetryOptions) newBackoff() (util.Backoff, error) {
+	factor := r.BackoffFactor
+	if factor == 0 {
+		factor = util.DefaultFactor
+	}
+	return util.NewBackoff(r.BackoffMin, r.BackoffMax, factor)
+}
+
+// failoverURLs is a list of Traffic Ops URLs, and the one currently in use. It is safe for use by multiple goroutines.
+type failoverURLs struct {
+	urls []string
+	i    int
+	m    sync.RWMutex
+}
+
+func newFailoverURLs(urls []string) *failoverURLs {
+	return &failoverURLs{urls: append([]string{}, urls...)}
+}
+
+func (f *failoverURLs) current() string {
+	f.m.RLock()
+	defer f.m.RUnlock()
+	return f.urls[f.i]
+}
+
+// failover fails over to the next URL, if the current URL is the given failed URL. This prevents concurrent failures of the same URL from failing over multiple times.
+func (f *failoverURLs) failover(failed string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if f.urls[f.i] == failed {
+		f.i = (f.i + 1) % len(f.urls)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// testTO is a fake Traffic Ops, which serves logins and CDNs.
+type testTO struct {
+	m sync.Mutex
+	// status is the status code to return for all requests, or 0 to serve them.
+	status int
+	// session is the login cookie value; requests with any other cookie are Unauthorized.
+	session  int
+	logins   int
+	requests int
+	// apiToken is the API token to authorize requests with in an Authorization header, in addition to the login cookie.
+	apiToken string
+	// password is the password logins must have, or empty to accept any.
+	password string
+}
+
+func (to *testTO) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	to.m.Lock()
+	defer to.m.Unlock()
+	to.requests++
+	if to.status != 0 {
+		w.WriteHeader(to.status)
+		return
+	}
+	if r.URL.Path == apiBase+"/user/login" {
+		creds := tc.UserCredentials{}
+		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil || (to.password != "" && creds.Password != to.password) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"alerts":[{"level":"error","text":"Invalid username or password."}]}`))
+			return
+		}
+		to.logins++
+		to.session++
+		http.SetCookie(w, &http.Cookie{Name: "mojolicious", Value: strconv.Itoa(to.session), Path: "/"})
+		w.Write([]byte(`{"alerts":[{"level":"success","text":"Successfully logged in."}]}`))
+		return
+	}
+	tokenAuthorized := to.apiToken != "" && r.Header.Get("Authorization") == "Bearer "+to.apiToken
+	if cookie, err := r.Cookie("mojolicious"); !tokenAuthorized && (err != nil || cookie.Value != strconv.Itoa(to.session)) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path != API_v13_CDNs {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Write([]byte(`{"response":[{"name":"mycdn"}]}`))
+}
+
+func (to *testTO) set(f func(to *testTO)) {
+	to.m.Lock()
+	defer to.m.Unlock()
+	f(to)
+}
+
+func testOpts(urls ...string) ClientOpts {
+	return ClientOpts{
+		URLs:           urls,
+		User:           "user",
+		Password:       "pass",
+		UserAgent:      "test",
+		RequestTimeout: time.Second,
+		Retry:          RetryOptions{MaxAttempts: 3, BackoffMin: time.Millisecond, BackoffMax: time.Millisecond * 2},
+	}
+}
+
+func TestLoginWithOptionsFailover(t *testing.T) {
+	down := &testTO{status: http.StatusServiceUnavailable}
+	downSrv := httptest.NewServer(down)
+	defer downSrv.Close()
+	up := &testTO{}
+	upSrv := httptest.NewServer(up)
+	defer upSrv.Close()
+
+	to, _, err := LoginWithOptions(context.Background(), testOpts(downSrv.URL, upSrv.URL))
+	if err != nil {
+		t.Fatalf("LoginWithOptions with one Traffic Ops down expected: nil error, actual: %v", err)
+	}
+	if down.requests != 1 || up.logins != 1 {
+		t.Errorf("LoginWithOptions expected: 1 failed login and 1 login on the next URL, actual: %v and %v", down.requests, up.logins)
+	}
+
+	cdns, _, err := to.GetCDNs()
+	if err != nil {
+		t.Fatalf("GetCDNs after failover expected: nil error, actual: %v", err)
+	}
+	if len(cdns) != 1 || cdns[0].Name != "mycdn" {
+		t.Errorf("GetCDNs after failover expected: [mycdn], actual: %+v", cdns)
+	}
+	if down.requests != 1 {
+		t.Errorf("GetCDNs after failover expected: the failed-over URL, actual: %v requests to the failed URL", down.requests-1)
+	}
+}
+
+func TestLoginWithOptionsBadCredentials(t *testing.T) {
+	first := &testTO{password: "secret"}
+	firstSrv := httptest.NewServer(first)
+	defer firstSrv.Close()
+	second := &testTO{password: "secret"}
+	secondSrv := httptest.NewServer(second)
+	defer secondSrv.Close()
+
+	if _, _, err := LoginWithOptions(context.Background(), testOpts(firstSrv.URL, secondSrv.URL)); err == nil {
+		t.Fatalf("LoginWithOptions with bad credentials expected: error, actual: nil")
+	}
+	if first.requests != 1 || second.requests != 0 {
+		t.Errorf("LoginWithOptions with bad credentials expected: 1 login attempt and no failover, actual: %v and %v", first.requests, second.requests)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	to := &Session{}
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"network failure", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"connection closed reading response", io.ErrUnexpectedEOF, true},
+		{"unavailable", &ServerError{HTTPError: HTTPError{HTTPStatusCode: http.StatusServiceUnavailable}}, true},
+		{"internal server error", &ServerError{HTTPError: HTTPError{HTTPStatusCode: http.StatusInternalServerError}}, false},
+		{"too many requests", &ClientError{HTTPError: HTTPError{HTTPStatusCode: http.StatusTooManyRequests}}, true},
+		{"unauthorized", &ClientError{HTTPError: HTTPError{HTTPStatusCode: http.StatusUnauthorized}}, false},
+		{"login failed", &LoginError{Msg: "Login failed"}, false},
+		{"other", errors.New("decoding response JSON"), false},
+	}
+	for _, test := range tests {
+		if actual := to.isRetryable(true, test.err); actual != test.expected {
+			t.Errorf("isRetryable %v expected: %v, actual: %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestRequestReLogin(t *testing.T) {
+	up := &testTO{}
+	srv := httptest.NewServer(up)
+	defer srv.Close()
+
+	to, _, err := LoginWithOptions(context.Background(), testOpts(srv.URL))
+	if err != nil {
+		t.Fatalf("LoginWithOptions expected: nil error, actual: %v", err)
+	}
+
+	up.set(func(to *testTO) { to.session++ }) // expire the cookie
+	if _, _, err := to.GetCDNs(); err != nil {
+		t.Fatalf("GetCDNs with expired cookie expected: nil error, actual: %v", err)
+	}
+	if up.logins != 2 {
+		t.Errorf("GetCDNs with expired cookie expected: 2 logins, actual: %v", up.logins)
+	}
+}
+
+func TestLoginWithOptionsAPIToken(t *testing.T) {
+	up := &testTO{apiToken: "mytoken"}
+	srv := httptest.NewServer(up)
+	defer srv.Close()
+
+	opts := testOpts(srv.URL)
+	opts.APIToken = "mytoken"
+	to, _, err := LoginWithOptions(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("LoginWithOptions with API token expected: nil error, actual: %v", err)
+	}
+	if _, _, err := to.GetCDNs(); err != nil {
+		t.Fatalf("GetCDNs with API token expected: nil error, actual: %v", err)
+	}
+
+	up.set(func(to *testTO) { to.apiToken = "revoked" })
+	if _, _, err := to.GetCDNs(); err == nil {
+		t.Errorf("GetCDNs with revoked API token expected: error, actual: nil")
+	}
+	if up.logins != 0 {
+		t.Errorf("API token expected: no logins, actual: %v", up.logins)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	up := &testTO{}
+	srv := httptest.NewServer(up)
+	defer srv.Close()
+
+	to, _, err := LoginWithOptions(context.Background(), testOpts(srv.URL))
+	if err != nil {
+		t.Fatalf("LoginWithOptions expected: nil error, actual: %v", err)
+	}
+
+	if _, _, err := to.GetCDNByID(42); err == nil {
+		t.Errorf("GetCDNByID nonexistent path expected: error, actual: nil")
+	} else if clientErr, ok := err.(*ClientError); !ok || clientErr.HTTPStatusCode != http.StatusNotFound {
+		t.Errorf("GetCDNByID nonexistent path expected: *ClientError 404, actual: %T %v", err, err)
+	}
+
+	up.set(func(to *testTO) { to.status = http.StatusServiceUnavailable; to.requests = 0 })
+	if _, _, err := to.GetCDNs(); err == nil {
+		t.Errorf("GetCDNs from unavailable Traffic Ops expected: error, actual: nil")
+	} else if _, ok := err.(*ServerError); !ok {
+		t.Errorf("GetCDNs from unavailable Traffic Ops expected: *ServerError, actual: %T %v", err, err)
+	}
+	if up.requests != 3 {
+		t.Errorf("GetCDNs from unavailable Traffic Ops expected: 3 attempts, actual: %v", up.requests)
+	}
+
+	up.set(func(to *testTO) { to.requests = 0 })
+	if _, _, err := to.CreateCDN(tc.CDN{Name: "foo"}); err == nil {
+		t.Errorf("CreateCDN on unavailable Traffic Ops expected: error, actual: nil")
+	}
+	if up.requests != 1 {
+		t.Errorf("CreateCDN on unavailable Traffic Ops expected: 1 attempt, actual: %v", up.requests)
+	}
+
+	up.set(func(to *testTO) { to.requests = 0 })
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := to.WithContext(ctx).GetCDNs(); err == nil {
+		t.Errorf("GetCDNs with canceled context expected: error, actual: nil")
+	}
+	if up.requests != 0 {
+		t.Errorf("GetCDNs with canceled context expected: no requests, actual: %v", up.requests)
+	}
+}
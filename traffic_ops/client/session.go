@@ -17,6 +17,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -26,11 +27,11 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptrace"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/apache/trafficcontrol/lib/go-rfc"
 	tc "github.com/apache/trafficcontrol/lib/go-tc"
 
 	"golang.org/x/net/publicsuffix"
@@ -46,6 +47,15 @@ type Session struct {
 	cacheMutex   *sync.RWMutex
 	useCache     bool
 	UserAgentStr string
+
+	// ctx is the context of requests, or nil for none. See WithContext.
+	ctx context.Context
+	// urls is the Traffic Ops URLs to fail over between, or nil to only use URL. See LoginWithOptions.
+	urls *failoverURLs
+	// retry is how requests are retried. The zero value doesn't retry.
+	retry RetryOptions
+	// apiToken is the Traffic Ops API token requests are authenticated with, or empty to use the login cookie. See ClientOpts.APIToken.
+	apiToken string
 }
 
 func NewSession(user, password, url, userAgent string, client *http.Client, useCache bool) *Session {
@@ -63,7 +73,7 @@ func NewSession(user, password, url, userAgent string, client *http.Client, useC
 
 const DefaultTimeout = time.Second * time.Duration(30)
 
-// HTTPError is returned on Update Session failure.
+// HTTPError is returned when Traffic Ops responds with a status code which isn't a success. Responses with 4xx and 5xx codes are returned as a *ClientError or *ServerError, which embed HTTPError.
 type HTTPError struct {
 	HTTPStatusCode int
 	HTTPStatus     string
@@ -76,11 +86,48 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("%s[%d] - Error requesting Traffic Ops %s %s", e.HTTPStatus, e.HTTPStatusCode, e.URL, e.Body)
 }
 
+// ClientError is returned when Traffic Ops responds with a 4xx status code, meaning the request was invalid, unauthorized, or for something which doesn't exist.
+type ClientError struct {
+	HTTPError
+}
+
+// ServerError is returned when Traffic Ops responds with a 5xx status code, meaning Traffic Ops failed to handle a valid request.
+type ServerError struct {
+	HTTPError
+}
+
+// LoginError is returned when Traffic Ops responds successfully to a login request without logging in, or the login request can't be created. Logins which fail with a LoginError aren't retried.
+type LoginError struct {
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *LoginError) Error() string {
+	return e.Msg
+}
+
+// newHTTPError returns the typed error for the given unsuccessful status code: a *ClientError for a 4xx code, a *ServerError for a 5xx code, or else a *HTTPError.
+func newHTTPError(code int, status string, url string, body string) error {
+	err := HTTPError{HTTPStatusCode: code, HTTPStatus: status, URL: url, Body: body}
+	switch {
+	case code >= 400 && code < 500:
+		return &ClientError{HTTPError: err}
+	case code >= 500 && code < 600:
+		return &ServerError{HTTPError: err}
+	default:
+		return &err
+	}
+}
+
 // CacheEntry ...
 type CacheEntry struct {
 	Entered    int64
 	Bytes      []byte
 	RemoteAddr net.Addr
+	// ETag is the entity tag Traffic Ops sent with Bytes, used to revalidate the entry once it expires, or empty if none was sent.
+	ETag string
+	// LastModified is the Last-Modified time Traffic Ops sent with Bytes, used to revalidate the entry once it expires, or empty if none was sent.
+	LastModified string
 }
 
 // TODO JvD
@@ -122,23 +169,24 @@ func Login(toURL string, toUser string, toPasswd string, insecure bool) (*Sessio
 }
 
 // login tries to log in to Traffic Ops, and set the auth cookie in the Session. Returns the IP address of the remote Traffic Ops.
+// If the request fails, the request's error is returned unwrapped, so retries can tell network failures from rejected credentials, which are a *ClientError. Otherwise, a failed login returns a *LoginError.
 func (to *Session) login() (net.Addr, error) {
 	credentials, err := loginCreds(to.UserName, to.Password)
 	if err != nil {
-		return nil, errors.New("creating login credentials: " + err.Error())
+		return nil, &LoginError{Msg: "creating login credentials: " + err.Error()}
 	}
 
 	path := apiBase + "/user/login"
 	resp, remoteAddr, err := to.RawRequest("POST", path, credentials)
 	resp, remoteAddr, err = to.ErrUnlessOK(resp, remoteAddr, err, path)
 	if err != nil {
-		return remoteAddr, errors.New("requesting: " + err.Error())
+		return remoteAddr, err
 	}
 	defer resp.Body.Close()
 
 	var alerts tc.Alerts
 	if err := json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
-		return remoteAddr, errors.New("decoding response JSON: " + err.Error())
+		return remoteAddr, &LoginError{Msg: "decoding response JSON: " + err.Error()}
 	}
 
 	success := false
@@ -150,7 +198,7 @@ func (to *Session) login() (net.Addr, error) {
 	}
 
 	if !success {
-		return remoteAddr, fmt.Errorf("Login failed, alerts string: %+v", alerts)
+		return remoteAddr, &LoginError{Msg: fmt.Sprintf("Login failed, alerts string: %+v", alerts)}
 	}
 
 	return remoteAddr, nil
@@ -312,54 +360,91 @@ func NewNoAuthSession(toURL string, insecure bool, userAgent string, useCache bo
 	}, useCache)
 }
 
-// ErrUnlessOk returns nil and an error if the given Response's status code is anything but 200 OK. This includes reading the Response.Body and Closing it. Otherwise, the given response and error are returned unchanged.
+// ErrUnlessOk returns nil and an error if the given Response's status code is anything but a 2xx success. This includes reading the Response.Body and Closing it. Otherwise, the given response and error are returned unchanged.
+// The returned error for an unsuccessful status code is a *ClientError, *ServerError, or *HTTPError.
 func (to *Session) ErrUnlessOK(resp *http.Response, remoteAddr net.Addr, err error, path string) (*http.Response, net.Addr, error) {
+	return to.errUnlessOK(resp, remoteAddr, err, path, false)
+}
+
+// errUnlessOK is ErrUnlessOK, but if notModifiedOK, a 304 Not Modified is also returned unchanged, for conditional requests, which expect one.
+func (to *Session) errUnlessOK(resp *http.Response, remoteAddr net.Addr, err error, path string, notModifiedOK bool) (*http.Response, net.Addr, error) {
 	if err != nil {
 		return resp, remoteAddr, err
 	}
-	if resp.StatusCode < 300 {
+	if resp.StatusCode < 300 || (notModifiedOK && resp.StatusCode == http.StatusNotModified) {
 		return resp, remoteAddr, err
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotImplemented {
-		return nil, remoteAddr, errors.New("Traffic Ops Server returned 'Not Implemented', this client is probably newer than Traffic Ops, and you probably need to either upgrade Traffic Ops, or use a client whose version matches your Traffic Ops version.")
+		return nil, remoteAddr, &ServerError{HTTPError: HTTPError{
+			HTTPStatusCode: resp.StatusCode,
+			HTTPStatus:     resp.Status,
+			URL:            to.getURL(path),
+			Body:           "Traffic Ops Server returned 'Not Implemented', this client is probably newer than Traffic Ops, and you probably need to either upgrade Traffic Ops, or use a client whose version matches your Traffic Ops version.",
+		}}
 	}
 
 	body, readErr := ioutil.ReadAll(resp.Body)
 	if readErr != nil {
 		return nil, remoteAddr, readErr
 	}
-	return nil, remoteAddr, errors.New(resp.Status + "[" + strconv.Itoa(resp.StatusCode) + "] - Error requesting Traffic Ops " + to.getURL(path) + " " + string(body))
+	return nil, remoteAddr, newHTTPError(resp.StatusCode, resp.Status, to.getURL(path), string(body))
 }
 
-func (to *Session) getURL(path string) string { return to.URL + path }
+func (to *Session) getURL(path string) string {
+	if to.urls != nil {
+		return to.urls.current() + path
+	}
+	return to.URL + path
+}
 
-// request performs the HTTP request to Traffic Ops, trying to refresh the cookie if an Unauthorized or Forbidden code is received. It only tries once. If the login fails, the original Unauthorized/Forbidden response is returned. If the login succeeds and the subsequent re-request fails, the re-request's response is returned even if it's another Unauthorized/Forbidden.
+// request performs the HTTP request to Traffic Ops, trying to refresh the cookie if an Unauthorized or Forbidden code is received, and retrying per the Session's RetryOptions. See requestOnce.
 // Returns the response, the remote address of the Traffic Ops instance used, and any error.
 // The returned net.Addr is guaranteed to be either nil or valid, even if the returned error is not nil. Callers are encouraged to check and use the net.Addr if an error is returned, and use the remote address in their own error messages. This violates the Go idiom that a non-nil error implies all other values are undefined, but it's more straightforward than alternatives like typecasting.
 func (to *Session) request(method, path string, body []byte) (*http.Response, net.Addr, error) {
-	r, remoteAddr, err := to.RawRequest(method, path, body)
+	return to.requestWithHeaders(method, path, body, nil)
+}
+
+// requestWithHeaders is like request, but also sends the given headers, which may be nil.
+func (to *Session) requestWithHeaders(method, path string, body []byte, header http.Header) (*http.Response, net.Addr, error) {
+	return to.withRetry(isIdempotent(method), func() (*http.Response, net.Addr, error) {
+		return to.requestOnce(method, path, body, header)
+	})
+}
+
+// requestOnce performs the HTTP request to Traffic Ops, trying to refresh the cookie if an Unauthorized or Forbidden code is received. It only tries once. If the login fails, the original Unauthorized/Forbidden response is returned. If the login succeeds and the subsequent re-request fails, the re-request's response is returned even if it's another Unauthorized/Forbidden.
+// Returns the response, the remote address of the Traffic Ops instance used, and any error.
+// The returned net.Addr is guaranteed to be either nil or valid, even if the returned error is not nil. Callers are encouraged to check and use the net.Addr if an error is returned, and use the remote address in their own error messages. This violates the Go idiom that a non-nil error implies all other values are undefined, but it's more straightforward than alternatives like typecasting.
+// A 304 Not Modified response is only returned, rather than an error, if the request was conditional, with an If-None-Match or If-Modified-Since header.
+func (to *Session) requestOnce(method, path string, body []byte, header http.Header) (*http.Response, net.Addr, error) {
+	conditional := header.Get(rfc.IfNoneMatch) != "" || header.Get(rfc.IfModifiedSince) != ""
+	r, remoteAddr, err := to.rawRequest(method, path, body, header)
 	if err != nil {
 		return r, remoteAddr, err
 	}
-	if r.StatusCode != http.StatusUnauthorized && r.StatusCode != http.StatusForbidden {
-		return to.ErrUnlessOK(r, remoteAddr, err, path)
+	if (r.StatusCode != http.StatusUnauthorized && r.StatusCode != http.StatusForbidden) || to.apiToken != "" {
+		return to.errUnlessOK(r, remoteAddr, err, path, conditional)
 	}
 	if _, lerr := to.login(); lerr != nil {
-		return to.ErrUnlessOK(r, remoteAddr, err, path) // if re-logging-in fails, return the original request's response
+		return to.errUnlessOK(r, remoteAddr, err, path, conditional) // if re-logging-in fails, return the original request's response
 	}
 
 	// return second request, even if it's another Unauthorized or Forbidden.
-	r, remoteAddr, err = to.RawRequest(method, path, body)
-	return to.ErrUnlessOK(r, remoteAddr, err, path)
+	r, remoteAddr, err = to.rawRequest(method, path, body, header)
+	return to.errUnlessOK(r, remoteAddr, err, path, conditional)
 }
 
 // RawRequest performs the actual HTTP request to Traffic Ops, simply, without trying to refresh the cookie if an Unauthorized code is returned.
 // Returns the response, the remote address of the Traffic Ops instance used, and any error.
 // The returned net.Addr is guaranteed to be either nil or valid, even if the returned error is not nil. Callers are encouraged to check and use the net.Addr if an error is returned, and use the remote address in their own error messages. This violates the Go idiom that a non-nil error implies all other values are undefined, but it's more straightforward than alternatives like typecasting.
 func (to *Session) RawRequest(method, path string, body []byte) (*http.Response, net.Addr, error) {
+	return to.rawRequest(method, path, body, nil)
+}
+
+// rawRequest is like RawRequest, but also sends the given headers, which may be nil.
+func (to *Session) rawRequest(method, path string, body []byte, header http.Header) (*http.Response, net.Addr, error) {
 	url := to.getURL(path)
 
 	var req *http.Request
@@ -379,6 +464,10 @@ func (to *Session) RawRequest(method, path string, body []byte) (*http.Response,
 		}
 	}
 
+	if to.ctx != nil {
+		req = req.WithContext(to.ctx)
+	}
+
 	trace := &httptrace.ClientTrace{
 		GotConn: func(connInfo httptrace.GotConnInfo) {
 			remoteAddr = connInfo.Conn.RemoteAddr()
@@ -386,7 +475,13 @@ func (to *Session) RawRequest(method, path string, body []byte) (*http.Response,
 	}
 	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
+	for name, vals := range header {
+		req.Header[name] = vals
+	}
 	req.Header.Set("User-Agent", to.UserAgentStr)
+	if to.apiToken != "" {
+		req.Header.Set("Authorization", "Bearer "+to.apiToken)
+	}
 
 	resp, err := to.Client.Do(req)
 	if err != nil {
@@ -406,6 +501,9 @@ type CacheHitStatus string
 const CacheHitStatusHit = CacheHitStatus("hit")
 const CacheHitStatusExpired = CacheHitStatus("expired")
 const CacheHitStatusMiss = CacheHitStatus("miss")
+
+// CacheHitStatusRevalidated is an expired cache entry which Traffic Ops responded was unchanged, and which was returned and refreshed.
+const CacheHitStatusRevalidated = CacheHitStatus("revalidated")
 const CacheHitStatusInvalid = CacheHitStatus("")
 
 func (s CacheHitStatus) String() string {
@@ -421,6 +519,8 @@ func StringToCacheHitStatus(s string) CacheHitStatus {
 		return CacheHitStatusExpired
 	case "miss":
 		return CacheHitStatusMiss
+	case "revalidated":
+		return CacheHitStatusRevalidated
 	default:
 		return CacheHitStatusInvalid
 	}
@@ -447,14 +547,15 @@ func (to *Session) getCache(path string) (CacheEntry, bool) {
 //if cacheEntry, ok := to.Cache[path]; ok {
 
 // getBytesWithTTL gets the path, and caches in the session. Returns bytes from the cache, if found and the TTL isn't expired. Otherwise, gets it and store it in cache
+// An expired entry is revalidated with the ETag and Last-Modified Traffic Ops sent with it, so unchanged bytes aren't downloaded again.
 func (to *Session) getBytesWithTTL(path string, ttl int64) ([]byte, ReqInf, error) {
 	var body []byte
-	var err error
 	var cacheHitStatus CacheHitStatus
 	var remoteAddr net.Addr
 
 	getFresh := false
-	if cacheEntry, ok := to.getCache(path); ok {
+	cacheEntry, ok := to.getCache(path)
+	if ok {
 		if cacheEntry.Entered > time.Now().Unix()-ttl {
 			cacheHitStatus = CacheHitStatusHit
 			body = cacheEntry.Bytes
@@ -469,35 +570,36 @@ func (to *Session) getBytesWithTTL(path string, ttl int64) ([]byte, ReqInf, erro
 	}
 
 	if getFresh {
-		body, remoteAddr, err = to.getBytes(path)
+		header := http.Header{}
+		if cacheEntry.ETag != "" {
+			header.Set(rfc.IfNoneMatch, cacheEntry.ETag)
+		}
+		if cacheEntry.LastModified != "" {
+			header.Set(rfc.IfModifiedSince, cacheEntry.LastModified)
+		}
+		resp, respRemoteAddr, err := to.requestWithHeaders(http.MethodGet, path, nil, header)
+		remoteAddr = respRemoteAddr
 		if err != nil {
 			return nil, ReqInf{CacheHitStatus: CacheHitStatusInvalid, RemoteAddr: remoteAddr}, err
 		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotModified && ok {
+			cacheHitStatus = CacheHitStatusRevalidated
+			body = cacheEntry.Bytes
+		} else if body, err = ioutil.ReadAll(resp.Body); err != nil {
+			return nil, ReqInf{CacheHitStatus: CacheHitStatusInvalid, RemoteAddr: remoteAddr}, err
+		}
 
 		newEntry := CacheEntry{
-			Entered:    time.Now().Unix(),
-			Bytes:      body,
-			RemoteAddr: remoteAddr,
+			Entered:      time.Now().Unix(),
+			Bytes:        body,
+			RemoteAddr:   remoteAddr,
+			ETag:         resp.Header.Get(rfc.ETag),
+			LastModified: resp.Header.Get(rfc.LastModified),
 		}
 		to.setCache(path, newEntry)
 	}
 
 	return body, ReqInf{CacheHitStatus: cacheHitStatus, RemoteAddr: remoteAddr}, nil
 }
-
-// GetBytes - get []bytes array for a certain path on the to session.
-// returns the raw body, the remote address the Traffic Ops URL resolved to, or any error. If the error is not nil, the RemoteAddr may or may not be nil, depending whether the error occurred before the request was executed.
-func (to *Session) getBytes(path string) ([]byte, net.Addr, error) {
-	resp, remoteAddr, err := to.request("GET", path, nil)
-	if err != nil {
-		return nil, remoteAddr, err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, remoteAddr, err
-	}
-
-	return body, remoteAddr, nil
-}
@@ -0,0 +1,99 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+)
+
+// testConditional serves a body with an ETag, and responds Not Modified to requests which already have it.
+type testConditional struct {
+	body      string
+	etag      string
+	unchanged int
+}
+
+func (cond *testConditional) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(rfc.ETag, cond.etag)
+	if r.Header.Get(rfc.IfNoneMatch) == cond.etag {
+		cond.unchanged++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write([]byte(cond.body))
+}
+
+func TestGetBytesWithTTLRevalidates(t *testing.T) {
+	cond := &testConditional{body: `{"response":{}}`, etag: `"1"`}
+	srv := httptest.NewServer(cond)
+	defer srv.Close()
+
+	to := NewSession("user", "pass", srv.URL, "test", srv.Client(), true)
+	path := apiBase + "/cdns/mycdn/snapshot"
+
+	expected := []struct {
+		ttl    int64
+		status CacheHitStatus
+	}{
+		{0, CacheHitStatusMiss},
+		{60, CacheHitStatusHit},
+		{0, CacheHitStatusRevalidated},
+	}
+	for i, e := range expected {
+		body, reqInf, err := to.getBytesWithTTL(path, e.ttl)
+		if err != nil {
+			t.Fatalf("request %d expected err nil, actual %v", i, err)
+		}
+		if reqInf.CacheHitStatus != e.status {
+			t.Errorf("request %d expected cache hit status %s, actual %s", i, e.status, reqInf.CacheHitStatus)
+		}
+		if string(body) != cond.body {
+			t.Errorf("request %d expected body %s, actual %s", i, cond.body, body)
+		}
+	}
+	if cond.unchanged != 1 {
+		t.Errorf("expected 1 Not Modified response, actual %d", cond.unchanged)
+	}
+
+	cond.body = `{"response":{"changed":true}}`
+	cond.etag = `"2"`
+	body, reqInf, err := to.getBytesWithTTL(path, 0)
+	if err != nil {
+		t.Fatalf("changed request expected err nil, actual %v", err)
+	}
+	if reqInf.CacheHitStatus != CacheHitStatusExpired {
+		t.Errorf("changed request expected cache hit status %s, actual %s", CacheHitStatusExpired, reqInf.CacheHitStatus)
+	}
+	if string(body) != cond.body {
+		t.Errorf("changed request expected body %s, actual %s", cond.body, body)
+	}
+}
+
+func TestNotModifiedUnconditional(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	to := NewSession("user", "pass", srv.URL, "test", srv.Client(), false)
+	if _, _, err := to.request(http.MethodGet, apiBase+"/cdns", nil); err == nil {
+		t.Errorf("unconditional request answered Not Modified expected error, actual nil")
+	}
+}
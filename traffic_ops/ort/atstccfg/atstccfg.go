@@ -72,6 +72,18 @@ func main() {
 		return
 	}
 
+	if cfg.Diff {
+		code := DiffAllConfigFiles(tccfg)
+		log.Infof("DiffAllConfigFiles exiting with code %v\n", code)
+		os.Exit(code)
+	}
+
+	if cfg.All {
+		code := WriteAllConfigFiles(tccfg)
+		log.Infof("WriteAllConfigFiles exiting with code %v\n", code)
+		os.Exit(code)
+	}
+
 	cfgFile, code, err := GetConfigFile(tccfg)
 	log.Infof("GetConfigFile returned %v %v\n", code, err)
 	if err != nil {
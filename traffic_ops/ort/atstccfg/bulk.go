@@ -0,0 +1,294 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/ort/atstccfg/cfgfile"
+	"github.com/apache/trafficcontrol/traffic_ops/ort/atstccfg/config"
+)
+
+// ManifestFileName is the name of the manifest written to the output directory in --all mode.
+const ManifestFileName = "manifest.json"
+
+// Manifest describes the files generated by a single --all run.
+type Manifest struct {
+	Server    string         `json:"server"`
+	Generated time.Time      `json:"generated"`
+	Files     []ManifestFile `json:"files"`
+	// Removed is the paths of files in the previous run's manifest which are no longer in the server's meta config.
+	Removed []string `json:"removed,omitempty"`
+}
+
+type ManifestFile struct {
+	Name     string `json:"name"`
+	Scope    string `json:"scope"`
+	Location string `json:"location"`
+	// Path is the file's path, relative to the output directory, or its key in the bundle.
+	Path string `json:"path"`
+	// Checksum is the hex SHA-256 of the file's text. It is empty if the file failed to generate.
+	Checksum string `json:"checksum"`
+	// Changed is whether the checksum differs from the previous run's manifest, or the file wasn't in it.
+	Changed bool   `json:"changed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Bundle is the JSON written to stdout in --all mode when no output directory is given.
+type Bundle struct {
+	Manifest Manifest `json:"manifest"`
+	// Files is the text of each file, keyed by its manifest path.
+	Files map[string]string `json:"files"`
+}
+
+// GeneratedFile is a single config file generated in --all mode.
+type GeneratedFile struct {
+	Name     string
+	Scope    string
+	Location string
+	Text     string
+	Err      error
+}
+
+// GetAllConfigFiles generates every config file in the meta config of the server in cfg.TOURL, which must be the meta config route.
+// Files are generated in order, so the Traffic Ops data they share is fetched once and served from the toreq cache thereafter.
+// A file which fails to generate is returned with its Err set; the returned error is only for failing to get the meta config itself.
+func GetAllConfigFiles(cfg config.TCCfg) (string, []GeneratedFile, error) {
+	server, ok := GetMetaConfigServer(cfg.TOURL.Path)
+	if !ok {
+		return "", nil, errors.New("--all requires the Traffic Ops URL to be a server meta config path /api/1.x/servers/name/configfiles/ats, got '" + cfg.TOURL.Path + "'")
+	}
+
+	metaTxt, err := cfgfile.GetConfigFileMeta(cfg, server)
+	if err != nil {
+		return "", nil, errors.New("getting meta config for server '" + server + "': " + err.Error())
+	}
+
+	meta := tc.ATSConfigMetaData{}
+	if err := json.Unmarshal([]byte(metaTxt), &meta); err != nil {
+		return "", nil, errors.New("unmarshalling meta config for server '" + server + "': " + err.Error())
+	}
+
+	sort.Slice(meta.ConfigFiles, func(i, j int) bool { return meta.ConfigFiles[i].FileNameOnDisk < meta.ConfigFiles[j].FileNameOnDisk })
+
+	files := []GeneratedFile{}
+	for _, metaFile := range meta.ConfigFiles {
+		if metaFile.URL != "" {
+			log.Infoln("GetAllConfigFiles skipping '" + metaFile.FileNameOnDisk + "', which ORT gets from its URL '" + metaFile.URL + "'")
+			continue
+		}
+
+		file := GeneratedFile{Name: metaFile.FileNameOnDisk, Scope: metaFile.Scope, Location: metaFile.Location}
+
+		scopeID := ""
+		switch tc.ATSConfigMetaDataConfigFileScope(metaFile.Scope) {
+		case tc.ATSConfigMetaDataConfigFileScopeCDNs:
+			scopeID = meta.Info.CDNName
+		case tc.ATSConfigMetaDataConfigFileScopeProfiles:
+			scopeID = meta.Info.ProfileName
+		default:
+			scopeID = meta.Info.ServerName
+		}
+
+		scopeConfigFileFunc, ok := scopeConfigFileFuncs[metaFile.Scope]
+		if !ok {
+			file.Err = errors.New("unknown scope '" + metaFile.Scope + "'")
+			files = append(files, file)
+			continue
+		}
+
+		txt, _, err := scopeConfigFileFunc(cfg, scopeID, metaFile.FileNameOnDisk)
+		if err != nil {
+			file.Err = err
+		}
+		file.Text = txt
+		files = append(files, file)
+	}
+	return meta.Info.ServerName, files, nil
+}
+
+// MakeManifest creates the manifest of files, marking which changed since prev.
+// The prev manifest may be nil, in which case all files are changed.
+func MakeManifest(server string, files []GeneratedFile, prev *Manifest, now time.Time) Manifest {
+	prevChecksums := map[string]string{}
+	if prev != nil {
+		for _, prevFile := range prev.Files {
+			prevChecksums[prevFile.Path] = prevFile.Checksum
+		}
+	}
+
+	manifest := Manifest{Server: server, Generated: now, Files: []ManifestFile{}}
+	paths := map[string]struct{}{}
+	for _, file := range files {
+		mf := ManifestFile{Name: file.Name, Scope: file.Scope, Location: file.Location, Path: file.Name}
+		paths[mf.Path] = struct{}{}
+		if err := validateFileName(file.Name); err != nil {
+			mf.Error = err.Error()
+		} else if file.Err != nil {
+			mf.Error = file.Err.Error()
+		} else {
+			mf.Checksum = Checksum(file.Text)
+			prevChecksum, ok := prevChecksums[mf.Path]
+			mf.Changed = !ok || prevChecksum != mf.Checksum
+		}
+		manifest.Files = append(manifest.Files, mf)
+	}
+
+	if prev != nil {
+		for _, prevFile := range prev.Files {
+			if _, ok := paths[prevFile.Path]; !ok {
+				manifest.Removed = append(manifest.Removed, prevFile.Path)
+			}
+		}
+	}
+	return manifest
+}
+
+// Checksum returns the hex SHA-256 of txt, as used in the Manifest.
+func Checksum(txt string) string {
+	sum := sha256.Sum256([]byte(txt))
+	return hex.EncodeToString(sum[:])
+}
+
+// validateFileName returns an error if name can't safely be written as a file directly inside the output directory.
+func validateFileName(name string) error {
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name || name == ManifestFileName {
+		return errors.New("invalid file name '" + name + "'")
+	}
+	return nil
+}
+
+// ReadManifest reads the manifest at path. It returns a nil manifest and no error if the file doesn't exist.
+func ReadManifest(path string) (*Manifest, error) {
+	bts, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.New("reading manifest '" + path + "': " + err.Error())
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(bts, manifest); err != nil {
+		return nil, errors.New("unmarshalling manifest '" + path + "': " + err.Error())
+	}
+	return manifest, nil
+}
+
+// WriteFileAtomic writes bts to path via a temp file and rename, so readers never see a partial file.
+func WriteFileAtomic(path string, bts []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, bts, perm); err != nil {
+		return errors.New("writing temp file '" + tmpPath + "': " + err.Error())
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return errors.New("renaming temp file '" + tmpPath + "' to '" + path + "': " + err.Error())
+	}
+	return nil
+}
+
+// WriteAllConfigFiles generates every config file for the server in cfg.TOURL, and writes them to cfg.OutputDir, or a JSON bundle to stdout.
+// Returns the exit code, which is ExitCodeErrGeneric if any file failed to generate, though all other files are still written.
+func WriteAllConfigFiles(cfg config.TCCfg) int {
+	server, files, err := GetAllConfigFiles(cfg)
+	if err != nil {
+		log.Errorln("Getting all config files: " + err.Error())
+		return config.ExitCodeErrGeneric
+	}
+
+	manifestPath := filepath.Join(cfg.TempDir, "manifest_"+server+".json")
+	if cfg.OutputDir != "" {
+		manifestPath = filepath.Join(cfg.OutputDir, ManifestFileName)
+	}
+
+	prev, err := ReadManifest(manifestPath)
+	if err != nil {
+		log.Errorln("Getting previous manifest, marking all files changed: " + err.Error())
+		prev = nil
+	}
+
+	manifest := MakeManifest(server, files, prev, time.Now())
+
+	code := config.ExitCodeSuccess
+	texts := map[string]string{}
+	for i, mf := range manifest.Files {
+		if mf.Error != "" {
+			log.Errorln("Generating config file '" + mf.Name + "' scope '" + mf.Scope + "': " + mf.Error)
+			code = config.ExitCodeErrGeneric
+			continue
+		}
+		texts[mf.Path] = files[i].Text
+	}
+
+	if cfg.OutputDir == "" {
+		bts, err := json.Marshal(Bundle{Manifest: manifest, Files: texts})
+		if err != nil {
+			log.Errorln("Marshalling bundle: " + err.Error())
+			return config.ExitCodeErrGeneric
+		}
+		fmt.Println(string(bts))
+	} else {
+		for _, mf := range manifest.Files {
+			txt, ok := texts[mf.Path]
+			if !ok {
+				continue
+			}
+			path := filepath.Join(cfg.OutputDir, mf.Path)
+			if !mf.Changed {
+				if _, err := os.Stat(path); err == nil {
+					continue
+				}
+			}
+			if err := WriteFileAtomic(path, []byte(txt), 0644); err != nil {
+				log.Errorln("Writing config file: " + err.Error())
+				code = config.ExitCodeErrGeneric
+			}
+		}
+		for _, removedPath := range manifest.Removed {
+			if validateFileName(removedPath) != nil {
+				continue
+			}
+			if err := os.Remove(filepath.Join(cfg.OutputDir, removedPath)); err != nil && !os.IsNotExist(err) {
+				log.Errorln("Removing config file no longer in meta config '" + removedPath + "': " + err.Error())
+			}
+		}
+	}
+
+	manifestBts, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Errorln("Marshalling manifest: " + err.Error())
+		return config.ExitCodeErrGeneric
+	}
+	if err := WriteFileAtomic(manifestPath, manifestBts, 0644); err != nil {
+		log.Errorln("Writing manifest: " + err.Error())
+		return config.ExitCodeErrGeneric
+	}
+	return code
+}
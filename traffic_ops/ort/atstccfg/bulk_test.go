@@ -0,0 +1,101 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetMetaConfigServer(t *testing.T) {
+	if server, ok := GetMetaConfigServer("/api/1.4/servers/edge0/configfiles/ats"); !ok || server != "edge0" {
+		t.Errorf("expected meta config server 'edge0', actual '%v' %v", server, ok)
+	}
+	if _, ok := GetMetaConfigServer("/api/1.4/servers/edge0/configfiles/ats/remap.config"); ok {
+		t.Errorf("expected file path not to be meta config, actual meta config")
+	}
+}
+
+func TestMakeManifest(t *testing.T) {
+	files := []GeneratedFile{
+		{Name: "remap.config", Scope: "servers", Location: "/opt/trafficserver/etc/trafficserver", Text: "remap"},
+		{Name: "records.config", Scope: "profiles", Location: "/opt/trafficserver/etc/trafficserver", Text: "records"},
+		{Name: "bg_fetch.config", Scope: "cdns", Location: "/opt/trafficserver/etc/trafficserver", Err: errors.New("fail")},
+		{Name: "../passwd", Scope: "profiles", Location: "/etc", Text: "evil"},
+	}
+	now := time.Now()
+
+	first := MakeManifest("edge0", files, nil, now)
+	if len(first.Files) != len(files) {
+		t.Fatalf("expected %v manifest files, actual %v", len(files), len(first.Files))
+	}
+	for i, mf := range first.Files {
+		switch i {
+		case 0, 1:
+			if !mf.Changed || mf.Checksum != Checksum(files[i].Text) || mf.Error != "" {
+				t.Errorf("expected first run file '%v' changed with checksum, actual %+v", mf.Name, mf)
+			}
+		default:
+			if mf.Changed || mf.Checksum != "" || mf.Error == "" {
+				t.Errorf("expected file '%v' error, actual %+v", mf.Name, mf)
+			}
+		}
+	}
+
+	files[1].Text = "records changed"
+	second := MakeManifest("edge0", files[:2], &first, now)
+	if second.Files[0].Changed {
+		t.Errorf("expected unchanged remap.config not changed, actual changed")
+	}
+	if !second.Files[1].Changed {
+		t.Errorf("expected modified records.config changed, actual not changed")
+	}
+	if len(second.Removed) != 2 || second.Removed[0] != "bg_fetch.config" || second.Removed[1] != "../passwd" {
+		t.Errorf("expected removed bg_fetch.config and ../passwd, actual %+v", second.Removed)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atstccfg_manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ManifestFileName)
+	if manifest, err := ReadManifest(path); err != nil || manifest != nil {
+		t.Errorf("expected nonexistent manifest nil with no error, actual %+v %v", manifest, err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(`{"server":"edge0","files":[{"name":"remap.config","path":"remap.config","checksum":"abc"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Server != "edge0" || len(manifest.Files) != 1 || manifest.Files[0].Checksum != "abc" {
+		t.Errorf("expected manifest for edge0 with remap.config, actual %+v", manifest)
+	}
+}
@@ -0,0 +1,346 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package cfgdiff compares two versions of an ATS config file.
+// For formats it understands, such as remap.config, parent.config, and records.config, changes are reported per rule or setting, keyed by what identifies it, e.g. a remap rule's from-URL. Other files are compared line by line.
+package cfgdiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Op string
+
+const OpAdded = Op("+")
+const OpRemoved = Op("-")
+const OpChanged = Op("~")
+
+// Change is a single difference between two versions of a config file.
+type Change struct {
+	Op Op
+	// Key identifies the rule or setting which changed, e.g. "map http://from.example.net/". It is empty for files compared line by line.
+	Key string
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	txt := ""
+	switch c.Op {
+	case OpAdded:
+		txt = c.New
+	case OpRemoved:
+		txt = c.Old
+	default:
+		txt = c.Old + " => " + c.New
+	}
+	if c.Key == "" {
+		return string(c.Op) + " " + txt
+	} else if txt == "" {
+		return string(c.Op) + " " + c.Key
+	}
+	return string(c.Op) + " " + c.Key + ": " + txt
+}
+
+// MaxLineDiffCells is the largest product of old and new line counts which Diff will compute a minimal line diff for, which takes memory proportional to it.
+// Larger files which changed are diffed whole, as every old line removed and every new line added.
+const MaxLineDiffCells = 1024 * 1024
+
+// Diff returns the changes from oldTxt to newTxt, which are versions of the config file fileName.
+// Comments and blank lines are ignored, so the generated header with its timestamp is never a change.
+func Diff(fileName string, oldTxt string, newTxt string) []Change {
+	switch fileName {
+	case "remap.config":
+		return diffEntries(remapEntries(oldTxt), remapEntries(newTxt), false)
+	case "parent.config":
+		return diffEntries(parentEntries(oldTxt), parentEntries(newTxt), true)
+	case "records.config":
+		return diffEntries(recordsEntries(oldTxt), recordsEntries(newTxt), false)
+	}
+	return diffLines(configLines(oldTxt, false), configLines(newTxt, false))
+}
+
+// entry is a single rule or setting in a config file. Text is everything but the key, with whitespace normalized.
+type entry struct {
+	Key  string
+	Text string
+}
+
+// configLines returns the lines of txt, without blank lines or the generated header comment.
+// If semantic, backslash-continued lines are joined and all comments are removed. Otherwise, comments are kept, since they may be significant to a reader of a file compared line by line.
+func configLines(txt string, semantic bool) []string {
+	lines := []string{}
+	continued := ""
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if semantic && strings.HasSuffix(line, `\`) {
+			continued += strings.TrimSuffix(line, `\`) + " "
+			continue
+		}
+		line = continued + line
+		continued = ""
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "# DO NOT EDIT") {
+			continue
+		}
+		if semantic && strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if continued != "" {
+		lines = append(lines, continued)
+	}
+	return lines
+}
+
+// remapEntries returns the remap rules in txt, keyed by their type and from-URL.
+func remapEntries(txt string) []entry {
+	entries := []entry{}
+	for _, line := range configLines(txt, true) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			entries = append(entries, entry{Key: strings.Join(fields, " ")})
+			continue
+		}
+		entries = append(entries, entry{Key: fields[0] + " " + fields[1], Text: strings.Join(fields[2:], " ")})
+	}
+	return entries
+}
+
+// parentDestKeys are the parent.config fields which identify the requests a rule applies to.
+var parentDestKeys = []string{"dest_domain", "dest_host", "dest_ip", "url_regex", "port"}
+
+// parentEntries returns the parent rules in txt, keyed by their destination fields.
+func parentEntries(txt string) []entry {
+	entries := []entry{}
+	for _, line := range configLines(txt, true) {
+		fields := splitQuoted(line)
+		keys := []string{}
+		rest := []string{}
+		for _, field := range fields {
+			isKey := false
+			for _, destKey := range parentDestKeys {
+				if strings.HasPrefix(field, destKey+"=") {
+					isKey = true
+					break
+				}
+			}
+			if isKey {
+				keys = append(keys, field)
+			} else {
+				rest = append(rest, field)
+			}
+		}
+		entries = append(entries, entry{Key: strings.Join(keys, " "), Text: strings.Join(rest, " ")})
+	}
+	return entries
+}
+
+// recordsEntries returns the settings in txt, keyed by their name.
+func recordsEntries(txt string) []entry {
+	entries := []entry{}
+	for _, line := range configLines(txt, true) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			entries = append(entries, entry{Key: strings.Join(fields, " ")})
+			continue
+		}
+		entries = append(entries, entry{Key: fields[1], Text: fields[0] + " " + strings.Join(fields[2:], " ")})
+	}
+	return entries
+}
+
+// splitQuoted splits line on whitespace, except whitespace inside double quotes.
+func splitQuoted(line string) []string {
+	fields := []string{}
+	field := strings.Builder{}
+	inQuote := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			field.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t'):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// uniqueKeys makes the keys of entries unique, by appending the occurrence number to repeated keys.
+func uniqueKeys(entries []entry) []entry {
+	counts := map[string]int{}
+	for i, e := range entries {
+		counts[e.Key]++
+		if n := counts[e.Key]; n > 1 {
+			entries[i].Key = e.Key + " (" + strconv.Itoa(n) + ")"
+		}
+	}
+	return entries
+}
+
+// diffEntries returns the entries added, removed, and changed from oldEntries to newEntries, matched by key.
+// If fieldDetail, changed entries only include the fields which differ, rather than the whole entry.
+func diffEntries(oldEntries []entry, newEntries []entry, fieldDetail bool) []Change {
+	oldEntries = uniqueKeys(oldEntries)
+	newEntries = uniqueKeys(newEntries)
+
+	oldTexts := map[string]string{}
+	for _, e := range oldEntries {
+		oldTexts[e.Key] = e.Text
+	}
+	newKeys := map[string]struct{}{}
+
+	changes := []Change{}
+	for _, e := range newEntries {
+		newKeys[e.Key] = struct{}{}
+		oldText, ok := oldTexts[e.Key]
+		if !ok {
+			changes = append(changes, Change{Op: OpAdded, Key: e.Key, New: e.Text})
+			continue
+		}
+		if oldText == e.Text {
+			continue
+		}
+		if fieldDetail {
+			oldText, e.Text = diffFields(oldText, e.Text)
+		}
+		changes = append(changes, Change{Op: OpChanged, Key: e.Key, Old: oldText, New: e.Text})
+	}
+	for _, e := range oldEntries {
+		if _, ok := newKeys[e.Key]; !ok {
+			changes = append(changes, Change{Op: OpRemoved, Key: e.Key, Old: e.Text})
+		}
+	}
+	return changes
+}
+
+// diffFields returns the fields of the key=value lists oldText and newText which differ.
+func diffFields(oldText string, newText string) (string, string) {
+	oldFields := splitQuoted(oldText)
+	newFields := splitQuoted(newText)
+	oldSet := map[string]struct{}{}
+	for _, field := range oldFields {
+		oldSet[field] = struct{}{}
+	}
+	newSet := map[string]struct{}{}
+	for _, field := range newFields {
+		newSet[field] = struct{}{}
+	}
+	oldDiff := []string{}
+	for _, field := range oldFields {
+		if _, ok := newSet[field]; !ok {
+			oldDiff = append(oldDiff, field)
+		}
+	}
+	newDiff := []string{}
+	for _, field := range newFields {
+		if _, ok := oldSet[field]; !ok {
+			newDiff = append(newDiff, field)
+		}
+	}
+	if len(oldDiff) == 0 && len(newDiff) == 0 {
+		return oldText, newText // only the field order changed
+	}
+	return strings.Join(oldDiff, " "), strings.Join(newDiff, " ")
+}
+
+// diffLines returns the lines added and removed from oldLines to newLines, in order, by longest common subsequence.
+func diffLines(oldLines []string, newLines []string) []Change {
+	if len(oldLines)*len(newLines) > MaxLineDiffCells {
+		return diffWholeLines(oldLines, newLines)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:].
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	changes := []Change{}
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			changes = append(changes, Change{Op: OpRemoved, Old: oldLines[i]})
+			i++
+		default:
+			changes = append(changes, Change{Op: OpAdded, New: newLines[j]})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		changes = append(changes, Change{Op: OpRemoved, Old: oldLines[i]})
+	}
+	for ; j < len(newLines); j++ {
+		changes = append(changes, Change{Op: OpAdded, New: newLines[j]})
+	}
+	return changes
+}
+
+// diffWholeLines returns every line of oldLines removed and every line of newLines added, or no changes if they're the same.
+func diffWholeLines(oldLines []string, newLines []string) []Change {
+	changes := []Change{}
+	if stringsEqual(oldLines, newLines) {
+		return changes
+	}
+	for _, line := range oldLines {
+		changes = append(changes, Change{Op: OpRemoved, Old: line})
+	}
+	for _, line := range newLines {
+		changes = append(changes, Change{Op: OpAdded, New: line})
+	}
+	return changes
+}
+
+func stringsEqual(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package cfgdiff
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiffRemap(t *testing.T) {
+	oldTxt := `# DO NOT EDIT - Generated for edge0 by atstccfg on Mon Jan 1 00:00:00 UTC 2019
+map	http://a.example.net/     http://origin-a.example.net/ @plugin=header_rewrite.so @pparam=dscp/set_dscp_0.config
+map	http://b.example.net/     http://origin-b.example.net/
+map	http://c.example.net/     http://origin-c.example.net/
+`
+	newTxt := `# DO NOT EDIT - Generated for edge0 by atstccfg on Tue Jan 2 00:00:00 UTC 2019
+map http://a.example.net/ http://origin-a.example.net/ @plugin=header_rewrite.so @pparam=dscp/set_dscp_0.config
+map	http://b.example.net/     http://origin-b2.example.net/
+map	http://d.example.net/     \
+	http://origin-d.example.net/
+`
+	expected := []Change{
+		{Op: OpChanged, Key: "map http://b.example.net/", Old: "http://origin-b.example.net/", New: "http://origin-b2.example.net/"},
+		{Op: OpAdded, Key: "map http://d.example.net/", New: "http://origin-d.example.net/"},
+		{Op: OpRemoved, Key: "map http://c.example.net/", Old: "http://origin-c.example.net/"},
+	}
+	if actual := Diff("remap.config", oldTxt, newTxt); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v, actual %+v", expected, actual)
+	}
+}
+
+func TestDiffParent(t *testing.T) {
+	oldTxt := `dest_domain=origin.example.net port=80 parent="mid0:80|0.999;mid1:80|0.999" round_robin=consistent_hash go_direct=false qstring=ignore
+dest_domain=. parent="mid0:80|0.999" round_robin=consistent_hash go_direct=false
+`
+	newTxt := `dest_domain=origin.example.net port=80 parent="mid0:80|0.999;mid2:80|0.999" round_robin=consistent_hash go_direct=false qstring=consider
+dest_domain=. parent="mid0:80|0.999" round_robin=consistent_hash go_direct=false
+`
+	expected := []Change{
+		{Op: OpChanged, Key: "dest_domain=origin.example.net port=80", Old: `parent="mid0:80|0.999;mid1:80|0.999" qstring=ignore`, New: `parent="mid0:80|0.999;mid2:80|0.999" qstring=consider`},
+	}
+	if actual := Diff("parent.config", oldTxt, newTxt); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v, actual %+v", expected, actual)
+	}
+}
+
+func TestDiffRecords(t *testing.T) {
+	oldTxt := "CONFIG proxy.config.http.server_ports STRING 80\nCONFIG proxy.config.log.logging_enabled INT 3\n"
+	newTxt := "CONFIG proxy.config.http.server_ports STRING 80 80:ipv6\nCONFIG proxy.config.log.logging_enabled INT 3\n"
+	expected := []Change{
+		{Op: OpChanged, Key: "proxy.config.http.server_ports", Old: "CONFIG STRING 80", New: "CONFIG STRING 80 80:ipv6"},
+	}
+	actual := Diff("records.config", oldTxt, newTxt)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v, actual %+v", expected, actual)
+	}
+	if str := actual[0].String(); str != "~ proxy.config.http.server_ports: CONFIG STRING 80 => CONFIG STRING 80 80:ipv6" {
+		t.Errorf("expected change string, actual '%v'", str)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	oldTxt := "# DO NOT EDIT - Generated on Mon\nsrc_ip=127.0.0.1 action=ip_allow method=ALL\nsrc_ip=::1 action=ip_allow method=ALL\n"
+	newTxt := "# DO NOT EDIT - Generated on Tue\nsrc_ip=127.0.0.1 action=ip_allow method=ALL\nsrc_ip=10.0.0.0/8 action=ip_allow method=ALL\n"
+	expected := []Change{
+		{Op: OpRemoved, Old: "src_ip=::1 action=ip_allow method=ALL"},
+		{Op: OpAdded, New: "src_ip=10.0.0.0/8 action=ip_allow method=ALL"},
+	}
+	if actual := Diff("ip_allow.config", oldTxt, newTxt); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v, actual %+v", expected, actual)
+	}
+	if actual := Diff("ip_allow.config", oldTxt, oldTxt); len(actual) != 0 {
+		t.Errorf("expected no changes for identical files, actual %+v", actual)
+	}
+}
+
+func TestDiffLinesWhole(t *testing.T) {
+	// 1000 old lines, and enough new lines that the line diff would exceed MaxLineDiffCells
+	oldLines := []string{}
+	for i := 0; i < 1000; i++ {
+		oldLines = append(oldLines, "line"+strconv.Itoa(i))
+	}
+	newLines := []string{}
+	for i := 1; i <= MaxLineDiffCells/len(oldLines); i++ {
+		newLines = append(newLines, "line"+strconv.Itoa(i))
+	}
+	newLines = append(newLines, "added")
+	oldTxt := strings.Join(oldLines, "\n")
+	newTxt := strings.Join(newLines, "\n")
+
+	actual := Diff("ip_allow.config", oldTxt, newTxt)
+	if len(actual) != len(oldLines)+len(newLines) {
+		t.Fatalf("file above MaxLineDiffCells expected %v changes, the whole file, actual %v", len(oldLines)+len(newLines), len(actual))
+	}
+	if actual[0] != (Change{Op: OpRemoved, Old: "line0"}) || actual[len(actual)-1] != (Change{Op: OpAdded, New: "added"}) {
+		t.Errorf("file above MaxLineDiffCells expected old lines removed then new lines added, actual first %+v last %+v", actual[0], actual[len(actual)-1])
+	}
+	if actual := Diff("ip_allow.config", newTxt, newTxt); len(actual) != 0 {
+		t.Errorf("identical files above MaxLineDiffCells expected no changes, actual %v", len(actual))
+	}
+}
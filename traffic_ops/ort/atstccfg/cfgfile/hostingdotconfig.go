@@ -132,13 +132,33 @@ func GetConfigFileServerHostingDotConfig(cfg config.TCCfg, serverNameOrID string
 		dsServerMap[*dss.DeliveryService][*dss.Server] = struct{}{}
 	}
 
+	topologies, err := toreq.GetTopologies(cfg)
+	if err != nil {
+		return "", errors.New("getting topologies: " + err.Error())
+	}
+	serverTopologies := getCacheGroupTopologies(tc.CacheGroupName(server.Cachegroup), topologies)
+
 	hostingDSes := map[tc.DeliveryServiceName]tc.DeliveryServiceNullable{}
+	topologyDSes := []atscfg.HostingConfigTopologyDS{}
 	for _, ds := range dses {
 		if ds.Active == nil || ds.Type == nil || ds.XMLID == nil || ds.CDNID == nil || ds.ID == nil || ds.OrgServerFQDN == nil {
 			// some DSes have nil origins. I think MSO? TODO: verify
 			continue
 		}
 
+		if ds.Topology != nil && *ds.Topology != "" {
+			if *ds.CDNID == server.CDNID {
+				topologyDSes = append(topologyDSes, atscfg.HostingConfigTopologyDS{
+					Name:       *ds.XMLID,
+					OriginFQDN: *ds.OrgServerFQDN,
+					Type:       *ds.Type,
+					Active:     *ds.Active,
+					Topology:   *ds.Topology,
+				})
+			}
+			continue
+		}
+
 		if !ServerHostingDotConfigMidIncludeInactive && !*ds.Active {
 			continue
 		}
@@ -177,6 +197,9 @@ func GetConfigFileServerHostingDotConfig(cfg config.TCCfg, serverNameOrID string
 	for _, ds := range hostingDSes {
 		originSet[*ds.OrgServerFQDN] = struct{}{}
 	}
+	for _, origin := range atscfg.GetTopologyHostingOrigins(tc.CacheGroupName(server.Cachegroup), topologyDSes, serverTopologies) {
+		originSet[origin] = struct{}{}
+	}
 	origins := []string{}
 	for origin, _ := range originSet {
 		origins = append(origins, origin)
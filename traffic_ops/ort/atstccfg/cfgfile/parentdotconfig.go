@@ -75,6 +75,12 @@ func GetConfigFileServerParentDotConfig(cfg config.TCCfg, serverNameOrID string)
 		cgMap[*cg.Name] = cg
 	}
 
+	topologies, err := toreq.GetTopologies(cfg)
+	if err != nil {
+		return "", errors.New("getting topologies: " + err.Error())
+	}
+	serverTopologies := getCacheGroupTopologies(tc.CacheGroupName(server.Cachegroup), topologies)
+
 	serverCG, ok := cgMap[server.Cachegroup]
 	if !ok {
 		return "", errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' not found in CacheGroups")
@@ -119,6 +125,7 @@ func GetConfigFileServerParentDotConfig(cfg config.TCCfg, serverNameOrID string)
 
 	serverInfo := atscfg.ServerInfo{
 		CacheGroupID:                  server.CachegroupID,
+		CacheGroupName:                tc.CacheGroupName(server.Cachegroup),
 		CDN:                           tc.CDNName(server.CDNName),
 		CDNID:                         server.CDNID,
 		DomainName:                    server.DomainName,
@@ -307,47 +314,10 @@ func GetConfigFileServerParentDotConfig(cfg config.TCCfg, serverNameOrID string)
 
 	parentConfigServerCacheProfileParams := map[string]atscfg.ProfileCache{} // map[profileName]ProfileCache
 	for _, cgServer := range cgServers {
-		profileCache, ok := parentConfigServerCacheProfileParams[cgServer.Profile]
-		if !ok {
-			profileCache = atscfg.DefaultProfileCache()
-		}
-		params, ok := profileParentConfigParams[cgServer.Profile]
-		if !ok {
-			parentConfigServerCacheProfileParams[cgServer.Profile] = profileCache
+		if _, ok := parentConfigServerCacheProfileParams[cgServer.Profile]; ok {
 			continue
 		}
-		for name, val := range params {
-			switch name {
-			case atscfg.ParentConfigCacheParamWeight:
-				// f, err := strconv.ParseFloat(param.Val, 64)
-				// if err != nil {
-				// 	log.Errorln("parent.config generation: weight param is not a float, skipping! : " + err.Error())
-				// } else {
-				// 	profileCache.Weight = f
-				// }
-				// TODO validate float?
-				profileCache.Weight = val
-			case atscfg.ParentConfigCacheParamPort:
-				i, err := strconv.ParseInt(val, 10, 64)
-				if err != nil {
-					log.Errorln("parent.config generation: port param is not an integer, skipping! : " + err.Error())
-				} else {
-					profileCache.Port = int(i)
-				}
-			case atscfg.ParentConfigCacheParamUseIP:
-				profileCache.UseIP = val == "1"
-			case atscfg.ParentConfigCacheParamRank:
-				i, err := strconv.ParseInt(val, 10, 64)
-				if err != nil {
-					log.Errorln("parent.config generation: rank param is not an integer, skipping! : " + err.Error())
-				} else {
-					profileCache.Rank = int(i)
-				}
-			case atscfg.ParentConfigCacheParamNotAParent:
-				profileCache.NotAParent = val != "false"
-			}
-		}
-		parentConfigServerCacheProfileParams[cgServer.Profile] = profileCache
+		parentConfigServerCacheProfileParams[cgServer.Profile] = makeParentConfigProfileCache(profileParentConfigParams[cgServer.Profile])
 	}
 
 	dsIDMap := map[int]tc.DeliveryServiceNullable{}
@@ -396,7 +366,13 @@ func GetConfigFileServerParentDotConfig(cfg config.TCCfg, serverNameOrID string)
 			continue // TODO warn?
 		}
 
-		if !serverInfo.IsTopLevelCache() {
+		topology := ""
+		if tcDS.Topology != nil && *tcDS.Topology != "" {
+			if _, ok := serverTopologies[*tcDS.Topology]; !ok {
+				continue // skip DSes whose topologies don't contain this server's cachegroup.
+			}
+			topology = *tcDS.Topology
+		} else if !serverInfo.IsTopLevelCache() {
 			if _, ok := parentServerDSes[server.ID][*tcDS.ID]; !ok {
 				continue // skip DSes not assigned to this server.
 			}
@@ -441,6 +417,7 @@ func GetConfigFileServerParentDotConfig(cfg config.TCCfg, serverNameOrID string)
 				MultiSiteOrigin: multiSiteOrigin,
 				OriginShield:    originShield,
 				Type:            dsType,
+				Topology:        topology,
 			},
 		}
 
@@ -539,7 +516,115 @@ func GetConfigFileServerParentDotConfig(cfg config.TCCfg, serverNameOrID string)
 
 	parentInfos := atscfg.MakeParentInfo(&serverInfo, serverCDNDomain, profileCaches, originServers)
 
-	return atscfg.MakeParentDotConfig(&serverInfo, atsMajorVer, toToolName, toURL, parentConfigDSes, serverParams, parentInfos), nil
+	topologyCGServers := map[tc.CacheGroupName][]atscfg.CGServer{}
+	topologyProfileCaches := map[atscfg.ProfileID]atscfg.ProfileCache{}
+	if len(serverTopologies) > 0 {
+		topologyCacheGroups := map[string]struct{}{}
+		for _, topology := range serverTopologies {
+			for _, node := range topology.Nodes {
+				topologyCacheGroups[node.Cachegroup] = struct{}{}
+			}
+		}
+
+		topologyServers := []tc.Server{}
+		topologyServerIDs := []int{}
+		for _, sv := range servers {
+			if sv.CDNName != server.CDNName {
+				continue
+			}
+			if _, ok := topologyCacheGroups[sv.Cachegroup]; !ok {
+				continue
+			}
+			if !strings.HasPrefix(sv.Type, tc.EdgeTypePrefix) && !strings.HasPrefix(sv.Type, tc.MidTypePrefix) {
+				continue
+			}
+			if sv.Status != string(tc.CacheStatusReported) && sv.Status != string(tc.CacheStatusOnline) {
+				continue
+			}
+			topologyServers = append(topologyServers, sv)
+			topologyServerIDs = append(topologyServerIDs, sv.ID)
+		}
+
+		topologyServerCapabilities, err := toreq.GetServerCapabilitiesByID(cfg, topologyServerIDs)
+		if err != nil {
+			return "", errors.New("getting topology server capabilities: " + err.Error())
+		}
+
+		for _, sv := range topologyServers {
+			topologyCGServers[tc.CacheGroupName(sv.Cachegroup)] = append(topologyCGServers[tc.CacheGroupName(sv.Cachegroup)], atscfg.CGServer{
+				ServerID:     atscfg.ServerID(sv.ID),
+				ServerHost:   sv.HostName,
+				ServerIP:     sv.IPAddress,
+				ServerPort:   sv.TCPPort,
+				CacheGroupID: sv.CachegroupID,
+				Status:       sv.StatusID,
+				Type:         sv.TypeID,
+				ProfileID:    atscfg.ProfileID(sv.ProfileID),
+				CDN:          sv.CDNID,
+				TypeName:     sv.Type,
+				Domain:       sv.DomainName,
+				Capabilities: topologyServerCapabilities[sv.ID],
+			})
+			if _, ok := topologyProfileCaches[atscfg.ProfileID(sv.ProfileID)]; !ok {
+				topologyProfileCaches[atscfg.ProfileID(sv.ProfileID)] = makeParentConfigProfileCache(profileParentConfigParams[sv.Profile])
+			}
+		}
+	}
+
+	topologyParentInfos := atscfg.MakeTopologyParentInfo(topologyProfileCaches, topologyCGServers)
+
+	return atscfg.MakeParentDotConfig(&serverInfo, atsMajorVer, toToolName, toURL, parentConfigDSes, serverParams, parentInfos, serverTopologies, topologyParentInfos), nil
+}
+
+// makeParentConfigProfileCache returns the ProfileCache for the given parent.config profile parameters, with defaults for any parameters which don't exist.
+func makeParentConfigProfileCache(params map[string]string) atscfg.ProfileCache {
+	profileCache := atscfg.DefaultProfileCache()
+	for name, val := range params {
+		switch name {
+		case atscfg.ParentConfigCacheParamWeight:
+			// f, err := strconv.ParseFloat(param.Val, 64)
+			// if err != nil {
+			// 	log.Errorln("parent.config generation: weight param is not a float, skipping! : " + err.Error())
+			// } else {
+			// 	profileCache.Weight = f
+			// }
+			// TODO validate float?
+			profileCache.Weight = val
+		case atscfg.ParentConfigCacheParamPort:
+			i, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				log.Errorln("parent.config generation: port param is not an integer, skipping! : " + err.Error())
+			} else {
+				profileCache.Port = int(i)
+			}
+		case atscfg.ParentConfigCacheParamUseIP:
+			profileCache.UseIP = val == "1"
+		case atscfg.ParentConfigCacheParamRank:
+			i, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				log.Errorln("parent.config generation: rank param is not an integer, skipping! : " + err.Error())
+			} else {
+				profileCache.Rank = int(i)
+			}
+		case atscfg.ParentConfigCacheParamNotAParent:
+			profileCache.NotAParent = val != "false"
+		}
+	}
+	return profileCache
+}
+
+// getCacheGroupTopologies returns the topologies which contain the given cachegroup, keyed by name.
+func getCacheGroupTopologies(cacheGroup tc.CacheGroupName, topologies []tc.Topology) map[string]tc.Topology {
+	cgTopologies := map[string]tc.Topology{}
+	for _, topology := range topologies {
+		for _, node := range topology.Nodes {
+			if tc.CacheGroupName(node.Cachegroup) == cacheGroup {
+				cgTopologies[topology.Name] = topology
+				break
+			}
+		}
+	}
+	return cgTopologies
 }
 
 // GetDSOrigins takes a map[deliveryServiceID]DeliveryService, and returns a map[DeliveryServiceID]OriginURI.
@@ -135,6 +135,12 @@ func GetConfigFileServerRemapDotConfig(cfg config.TCCfg, serverNameOrID string)
 		dssMap[*dss.DeliveryService][*dss.Server] = struct{}{}
 	}
 
+	topologies, err := toreq.GetTopologies(cfg)
+	if err != nil {
+		return "", errors.New("getting topologies: " + err.Error())
+	}
+	serverTopologies := getCacheGroupTopologies(tc.CacheGroupName(server.Cachegroup), topologies)
+
 	useInactive := false
 	if !isMid {
 		// mids get inactive DSes, edges don't. This is how it's always behaved, not necessarily how it should.
@@ -149,7 +155,11 @@ func GetConfigFileServerRemapDotConfig(cfg config.TCCfg, serverNameOrID string)
 		if ds.Active == nil {
 			continue // TODO log?
 		}
-		if _, ok := dssMap[*ds.ID]; !ok {
+		if ds.Topology != nil && *ds.Topology != "" {
+			if _, ok := serverTopologies[*ds.Topology]; !ok {
+				continue
+			}
+		} else if _, ok := dssMap[*ds.ID]; !ok {
 			continue
 		}
 		if !useInactive && !*ds.Active {
@@ -201,6 +211,7 @@ func GetConfigFileServerRemapDotConfig(cfg config.TCCfg, serverNameOrID string)
 				Protocol:                 ds.Protocol,
 				AnonymousBlockingEnabled: ds.AnonymousBlockingEnabled,
 				Active:                   *ds.Active,
+				Topology:                 ds.Topology,
 			})
 		}
 	}
@@ -341,6 +352,7 @@ func GetConfigFileServerRemapDotConfig(cfg config.TCCfg, serverNameOrID string)
 
 	serverInfo := &atscfg.ServerInfo{
 		CacheGroupID:                  server.CachegroupID,
+		CacheGroupName:                tc.CacheGroupName(server.Cachegroup),
 		CDN:                           tc.CDNName(server.CDNName),
 		CDNID:                         server.CDNID,
 		DomainName:                    serverCDNDomain, // note this is intentionally the CDN domain, not the server domain. It's what's remapped to.
@@ -358,7 +370,7 @@ func GetConfigFileServerRemapDotConfig(cfg config.TCCfg, serverNameOrID string)
 		Type:                          server.Type,
 	}
 
-	txt := atscfg.MakeRemapDotConfig(tc.CacheName(serverName), toToolName, toURL, atsMajorVer, cacheURLParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapConfigDSData)
+	txt := atscfg.MakeRemapDotConfig(tc.CacheName(serverName), toToolName, toURL, atsMajorVer, cacheURLParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapConfigDSData, serverTopologies)
 	return txt, nil
 }
 
@@ -50,6 +50,9 @@ const ExitCodeErrGeneric = 1
 const ExitCodeNotFound = 104
 const ExitCodeBadRequest = 100
 
+// ExitCodeChanged is returned by --diff when a generated config file differs from the one it's compared against.
+const ExitCodeChanged = 2
+
 var ErrNotFound = errors.New("not found")
 var ErrBadRequest = errors.New("bad request")
 
@@ -67,6 +70,15 @@ type Cfg struct {
 	TOUser              string
 	ListPlugins         bool
 	PrintGeneratedFiles bool
+	// All is whether to generate every config file for the server in TOURL, rather than the single file TOURL names.
+	All bool
+	// OutputDir is the directory to write files to when All is set. If empty, a JSON bundle is written to stdout.
+	// When Diff is set, it's the directory to compare against instead.
+	OutputDir string
+	// Diff is whether to generate every config file for the server like All, but write nothing, and print how they differ from the files on disk or DiffBundle.
+	Diff bool
+	// DiffBundle is the path of a bundle written by All to compare against, rather than the files on disk.
+	DiffBundle string
 }
 
 type TCCfg struct {
@@ -97,6 +109,10 @@ func GetCfg() (Cfg, error) {
 	cacheFileMaxAgeSecondsPtr := flag.IntP("cache-file-max-age-seconds", "a", 60, "Maximum age to use cached files.")
 
 	listPluginsPtr := flag.BoolP("list-plugins", "l", false, "Print the list of plugins.")
+	allPtr := flag.BoolP("all", "A", false, "Generate every config file for the server, fetching Traffic Ops data once. The Traffic Ops URL must be the server's meta config path, /api/1.x/servers/name/configfiles/ats.")
+	outputDirPtr := flag.StringP("output-dir", "o", "", "With --all, the directory to write config files and their manifest to. If omitted, a JSON bundle of all files and the manifest is written to stdout. With --diff, the directory of files to compare against.")
+	diffPtr := flag.BoolP("diff", "d", false, "Dry run. Generate every config file for the server like --all, but write nothing, and print the changes from the files on disk. Files are compared in the --output-dir if given, else in their meta config location. Exits 0 if nothing changed, or 2 if something did.")
+	diffBundlePtr := flag.StringP("diff-bundle", "b", "", "With --diff, the path of a JSON bundle written by --all to compare against, rather than the files on disk.")
 
 	flag.Parse()
 
@@ -118,6 +134,10 @@ func GetCfg() (Cfg, error) {
 	toTimeout := time.Millisecond * time.Duration(*toTimeoutMSPtr)
 	cacheFileMaxAge := time.Second * time.Duration(*cacheFileMaxAgeSecondsPtr)
 	listPlugins := *listPluginsPtr
+	all := *allPtr
+	outputDir := *outputDirPtr
+	diff := *diffPtr
+	diffBundle := *diffBundlePtr
 
 	urlSourceStr := "argument" // for error messages
 	if toURL == "" {
@@ -148,6 +168,16 @@ func GetCfg() (Cfg, error) {
 		return Cfg{}, errors.New("invalid Traffic Ops URL from " + urlSourceStr + " '" + toURL + "': " + err.Error())
 	}
 
+	if outputDir != "" && !all && !diff {
+		return Cfg{}, errors.New("--output-dir requires --all or --diff")
+	}
+	if diffBundle != "" && !diff {
+		return Cfg{}, errors.New("--diff-bundle requires --diff")
+	}
+	if all && diff {
+		return Cfg{}, errors.New("--all and --diff are mutually exclusive")
+	}
+
 	tmpDir := os.TempDir()
 	tmpDir = filepath.Join(tmpDir, TempSubdir)
 
@@ -164,6 +194,10 @@ func GetCfg() (Cfg, error) {
 		TOURL:           toURLParsed,
 		TOUser:          toUser,
 		ListPlugins:     listPlugins,
+		All:             all,
+		OutputDir:       outputDir,
+		Diff:            diff,
+		DiffBundle:      diffBundle,
 	}
 
 	if err := log.InitCfg(cfg); err != nil {
@@ -183,6 +217,12 @@ func GetCfg() (Cfg, error) {
 		return Cfg{}, errors.New("validating temp directory is writeable '" + tmpDir + "': " + err.Error())
 	}
 
+	if outputDir != "" && all {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return Cfg{}, errors.New("creating output directory '" + outputDir + "': " + err.Error())
+		}
+	}
+
 	return cfg, nil
 }
 
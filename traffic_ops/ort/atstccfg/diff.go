@@ -0,0 +1,173 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_ops/ort/atstccfg/cfgdiff"
+	"github.com/apache/trafficcontrol/traffic_ops/ort/atstccfg/config"
+)
+
+// OldFileGetter gets the existing version of a generated file to compare it to.
+// Returns false if there is no existing version.
+type OldFileGetter func(file GeneratedFile) (string, bool, error)
+
+// DiffAllConfigFiles generates every config file for the server in cfg.TOURL, and prints how each differs from the file on disk, or in cfg.DiffBundle. Nothing is written.
+// Returns ExitCodeErrGeneric if anything failed, else ExitCodeChanged if any file changed, else ExitCodeSuccess.
+func DiffAllConfigFiles(cfg config.TCCfg) int {
+	_, files, err := GetAllConfigFiles(cfg)
+	if err != nil {
+		log.Errorln("Getting all config files: " + err.Error())
+		return config.ExitCodeErrGeneric
+	}
+
+	getOld := OldFileGetter(nil)
+	removed := []string{}
+	if cfg.DiffBundle != "" {
+		bundle, err := ReadBundle(cfg.DiffBundle)
+		if err != nil {
+			log.Errorln("Getting bundle to compare: " + err.Error())
+			return config.ExitCodeErrGeneric
+		}
+		getOld = BundleFileGetter(bundle)
+		removed = BundleRemovedFiles(bundle, files)
+	} else {
+		getOld = DiskFileGetter(cfg.OutputDir)
+	}
+
+	changed, failed := DiffConfigFiles(os.Stdout, files, getOld)
+	for _, name := range removed {
+		fmt.Println(name + ": removed")
+		changed = true
+	}
+
+	if failed {
+		return config.ExitCodeErrGeneric
+	} else if changed {
+		return config.ExitCodeChanged
+	}
+	return config.ExitCodeSuccess
+}
+
+// DiffConfigFiles writes to w the changes in each of files from the version getOld returns.
+// Returns whether any file changed, and whether any file failed to generate or get.
+func DiffConfigFiles(w io.Writer, files []GeneratedFile, getOld OldFileGetter) (bool, bool) {
+	changed := false
+	failed := false
+	for _, file := range files {
+		if err := validateFileName(file.Name); err != nil {
+			log.Errorln("Generating config file scope '" + file.Scope + "': " + err.Error())
+			failed = true
+			continue
+		}
+		if file.Err != nil {
+			log.Errorln("Generating config file '" + file.Name + "' scope '" + file.Scope + "': " + file.Err.Error())
+			failed = true
+			continue
+		}
+
+		oldTxt, exists, err := getOld(file)
+		if err != nil {
+			log.Errorln("Getting existing config file '" + file.Name + "': " + err.Error())
+			failed = true
+			continue
+		}
+		if !exists {
+			fmt.Fprintln(w, file.Name+": added")
+			changed = true
+			continue
+		}
+
+		changes := cfgdiff.Diff(file.Name, oldTxt, file.Text)
+		if len(changes) == 0 {
+			continue
+		}
+		changed = true
+		fmt.Fprintln(w, file.Name+":")
+		for _, change := range changes {
+			fmt.Fprintln(w, "  "+change.String())
+		}
+	}
+	return changed, failed
+}
+
+// DiskFileGetter returns an OldFileGetter which reads files from dir, or from each file's meta config location if dir is empty.
+func DiskFileGetter(dir string) OldFileGetter {
+	return func(file GeneratedFile) (string, bool, error) {
+		fileDir := dir
+		if fileDir == "" {
+			fileDir = file.Location
+		}
+		path := filepath.Join(fileDir, file.Name)
+		bts, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			return "", false, nil
+		} else if err != nil {
+			return "", false, errors.New("reading '" + path + "': " + err.Error())
+		}
+		return string(bts), true, nil
+	}
+}
+
+// BundleFileGetter returns an OldFileGetter which gets files from bundle.
+func BundleFileGetter(bundle Bundle) OldFileGetter {
+	return func(file GeneratedFile) (string, bool, error) {
+		txt, ok := bundle.Files[file.Name]
+		return txt, ok, nil
+	}
+}
+
+// BundleRemovedFiles returns the names of the files in bundle which aren't in files, sorted.
+func BundleRemovedFiles(bundle Bundle, files []GeneratedFile) []string {
+	names := map[string]struct{}{}
+	for _, file := range files {
+		names[file.Name] = struct{}{}
+	}
+	removed := []string{}
+	for name := range bundle.Files {
+		if _, ok := names[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// ReadBundle reads the JSON bundle written by --all at path.
+func ReadBundle(path string) (Bundle, error) {
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Bundle{}, errors.New("reading bundle '" + path + "': " + err.Error())
+	}
+	bundle := Bundle{}
+	if err := json.Unmarshal(bts, &bundle); err != nil {
+		return Bundle{}, errors.New("unmarshalling bundle '" + path + "': " + err.Error())
+	}
+	return bundle, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffConfigFiles(t *testing.T) {
+	bundle := Bundle{Files: map[string]string{
+		"remap.config":   "map http://a.example.net/ http://origin-a.example.net/\n",
+		"records.config": "CONFIG proxy.config.log.logging_enabled INT 3\n",
+		"hosting.config": "hostname=* volume=1\n",
+	}}
+	files := []GeneratedFile{
+		{Name: "records.config", Text: "CONFIG proxy.config.log.logging_enabled INT 3\n"},
+		{Name: "remap.config", Text: "map http://a.example.net/ http://origin-a2.example.net/\n"},
+		{Name: "storage.config", Text: "/dev/sdb volume=1\n"},
+	}
+
+	buf := &bytes.Buffer{}
+	changed, failed := DiffConfigFiles(buf, files, BundleFileGetter(bundle))
+	if !changed || failed {
+		t.Errorf("expected changed and not failed, actual changed %v failed %v", changed, failed)
+	}
+	expected := "remap.config:\n  ~ map http://a.example.net/: http://origin-a.example.net/ => http://origin-a2.example.net/\nstorage.config: added\n"
+	if buf.String() != expected {
+		t.Errorf("expected diff '%v', actual '%v'", expected, buf.String())
+	}
+	if removed := BundleRemovedFiles(bundle, files); !reflect.DeepEqual(removed, []string{"hosting.config"}) {
+		t.Errorf("expected removed hosting.config, actual %+v", removed)
+	}
+
+	buf.Reset()
+	changed, failed = DiffConfigFiles(buf, files[:1], BundleFileGetter(bundle))
+	if changed || failed || buf.Len() != 0 {
+		t.Errorf("expected unchanged file to not change, actual changed %v failed %v diff '%v'", changed, failed, buf.String())
+	}
+
+	buf.Reset()
+	files = append(files, GeneratedFile{Name: "bg_fetch.config", Err: errors.New("fail")})
+	if _, failed = DiffConfigFiles(buf, files, BundleFileGetter(bundle)); !failed {
+		t.Errorf("expected failed file to fail, actual not failed")
+	}
+	if strings.Contains(buf.String(), "bg_fetch.config") {
+		t.Errorf("expected failed file not in diff, actual '%v'", buf.String())
+	}
+}
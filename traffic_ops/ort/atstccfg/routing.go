@@ -39,9 +39,7 @@ var scopeConfigFileFuncs = map[string]func(cfg config.TCCfg, resource string, fi
 func GetConfigFile(cfg config.TCCfg) (string, int, error) {
 	pathParts := strings.Split(cfg.TOURL.Path, "/")
 
-	if len(pathParts) == 7 && pathParts[1] == `api` && pathParts[3] == `servers` && pathParts[5] == `configfiles` && pathParts[6] == `ats` {
-		// "/api/1.x/servers/name/configfiles/ats" is the "meta" config route, which lists all the other configs for this server.
-		server := pathParts[4]
+	if server, ok := GetMetaConfigServer(cfg.TOURL.Path); ok {
 		log.Infoln("GetConfigFile is meta config request for server '" + server + "'; generating")
 		txt, err := cfgfile.GetConfigFileMeta(cfg, server)
 		if err != nil {
@@ -74,6 +72,16 @@ func GetConfigFile(cfg config.TCCfg) (string, int, error) {
 	return GetConfigFileFromTrafficOps(cfg)
 }
 
+// GetMetaConfigServer returns the server name or ID in path, and whether path is the "meta" config route.
+// The meta config route, "/api/1.x/servers/name/configfiles/ats", lists all the other configs for the server.
+func GetMetaConfigServer(path string) (string, bool) {
+	pathParts := strings.Split(path, "/")
+	if len(pathParts) == 7 && pathParts[1] == `api` && pathParts[3] == `servers` && pathParts[5] == `configfiles` && pathParts[6] == `ats` {
+		return pathParts[4], true
+	}
+	return "", false
+}
+
 type ConfigFilePrefixSuffixFunc struct {
 	Prefix string
 	Suffix string
@@ -28,18 +28,51 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/traffic_ops/ort/atstccfg/config"
 )
 
-// GetCachedJSON attempts to get the given object from tempDir/cacheFileName.
+// memCache holds the JSON of every object gotten by GetCachedJSON during this run, by cache file name.
+// This makes generating many config files in one run fetch each Traffic Ops object once, even if the run outlasts CacheFileMaxAge.
+var memCache = map[string][]byte{}
+var memCacheM = sync.Mutex{}
+
+func getMemCache(cacheFileName string) ([]byte, bool) {
+	memCacheM.Lock()
+	defer memCacheM.Unlock()
+	bts, ok := memCache[cacheFileName]
+	return bts, ok
+}
+
+func setMemCache(cacheFileName string, obj interface{}) {
+	bts, err := json.Marshal(obj)
+	if err != nil {
+		log.Errorln("serializing object '" + cacheFileName + "' to JSON for memory cache: " + err.Error())
+		return
+	}
+	memCacheM.Lock()
+	defer memCacheM.Unlock()
+	memCache[cacheFileName] = bts
+}
+
+// GetCachedJSON attempts to get the given object from memory, or else tempDir/cacheFileName.
 // If the cache file doesn't exist, is too old, or is malformed, it uses getter to get the object, and stores it in cacheFileName.
 // The object is placed in obj (which must be a pointer to the type of object to decode from JSON), and the error from getter is returned.
 func GetCachedJSON(cfg config.TCCfg, cacheFileName string, obj interface{}, getter func(obj interface{}) error) error {
+	if bts, ok := getMemCache(cacheFileName); ok {
+		if err := json.Unmarshal(bts, obj); err == nil {
+			return nil
+		} else {
+			log.Errorln("unmarshalling object '" + cacheFileName + "' from memory cache, getting from file: " + err.Error())
+		}
+	}
+
 	err := GetJSONObjFromFile(cfg.TempDir, cacheFileName, cfg.CacheFileMaxAge, obj)
 	if err == nil {
+		setMemCache(cacheFileName, obj)
 		return nil
 	}
 
@@ -63,6 +96,7 @@ func GetCachedJSON(cfg config.TCCfg, cacheFileName string, obj interface{}, gett
 	}
 
 	WriteCacheJSON(cfg.TempDir, cacheFileName, obj)
+	setMemCache(cacheFileName, obj)
 	return nil
 }
 
@@ -212,6 +212,23 @@ func GetCacheGroups(cfg config.TCCfg) ([]tc.CacheGroupNullable, error) {
 	return cacheGroups, nil
 }
 
+func GetTopologies(cfg config.TCCfg) ([]tc.Topology, error) {
+	topologies := []tc.Topology{}
+	err := GetCachedJSON(cfg, "topologies.json", &topologies, func(obj interface{}) error {
+		toTopologies, reqInf, err := (*cfg.TOClient).GetTopologies()
+		if err != nil {
+			return errors.New("getting topologies from Traffic Ops '" + MaybeIPStr(reqInf) + "': " + err.Error())
+		}
+		topologies := obj.(*[]tc.Topology)
+		*topologies = toTopologies
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("getting topologies: " + err.Error())
+	}
+	return topologies, nil
+}
+
 func GetDeliveryServiceServers(cfg config.TCCfg, dsIDs []int, serverIDs []int) ([]tc.DeliveryServiceServer, error) {
 	sortIDsInHash := true
 	serverIDsStr := ""
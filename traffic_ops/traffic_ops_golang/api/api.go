@@ -72,6 +72,15 @@ func WriteResp(w http.ResponseWriter, r *http.Request, v interface{}) {
 	WriteRespRaw(w, r, resp)
 }
 
+// WriteRespWithSummary acts like WriteResp, but also writes the given summary of the response, such as the count of objects a page was taken from.
+func WriteRespWithSummary(w http.ResponseWriter, r *http.Request, v interface{}, summary tc.Summary) {
+	resp := struct {
+		Response interface{} `json:"response"`
+		Summary  tc.Summary  `json:"summary"`
+	}{v, summary}
+	WriteRespRaw(w, r, resp)
+}
+
 // WriteRespRaw acts like WriteResp, but doesn't wrap the object in a `{"response":` object. This should be used to respond with endpoints which don't wrap their response in a "response" object.
 func WriteRespRaw(w http.ResponseWriter, r *http.Request, v interface{}) {
 	if respWritten(r) {
@@ -346,6 +355,7 @@ type APIInfo struct {
 	Version   *Version
 	Tx        *sqlx.Tx
 	Config    *config.Config
+	count     *int // the number of objects a paginated Read's results were taken from, if known
 }
 
 // Creates a deprecation warning for an endpoint, with a proposed alternative.
@@ -433,6 +443,11 @@ func (inf *APIInfo) Close() {
 	}
 }
 
+// SetCount sets the number of objects the page returned by a Read was taken from, before limit, offset, and page were applied, for the summary of the response.
+func (inf *APIInfo) SetCount(count int) {
+	inf.count = &count
+}
+
 // SendMail is a convenience method used to call SendMail using an APIInfo structure's configuration.
 func (inf *APIInfo) SendMail(to rfc.EmailAddress, msg []byte) (int, error, error) {
 	return SendMail(to, msg, inf.Config)
@@ -747,8 +762,13 @@ func ParseDBError(ierr error) (error, error, int) {
 }
 
 // GetUserFromReq returns the current user, any user error, any system error, and an error code to be returned if either error was not nil.
+// The user is authenticated by the API token in the request's Authorization header, if it has one, and otherwise by the login cookie.
 // This also uses the given ResponseWriter to refresh the cookie, if it was valid.
 func GetUserFromReq(w http.ResponseWriter, r *http.Request, secret string) (auth.CurrentUser, error, error, int) {
+	if token, ok := auth.GetAPIToken(r); ok {
+		return getUserFromAPIToken(r, token)
+	}
+
 	cookie, err := r.Cookie(tocookie.Name)
 	if err != nil {
 		return auth.CurrentUser{}, errors.New("Unauthorized, please log in."), errors.New("error getting cookie: " + err.Error()), http.StatusUnauthorized
@@ -767,32 +787,50 @@ func GetUserFromReq(w http.ResponseWriter, r *http.Request, secret string) (auth
 	if username == "" {
 		return auth.CurrentUser{}, errors.New("Unauthorized, please log in."), nil, http.StatusUnauthorized
 	}
+	db, timeout, sysErr := getReqDB(r)
+	if sysErr != nil {
+		return auth.CurrentUser{}, nil, sysErr, http.StatusInternalServerError
+	}
+
+	user, userErr, sysErr, code := auth.GetCurrentUserFromDB(db, username, timeout)
+	if userErr != nil || sysErr != nil {
+		return auth.CurrentUser{}, userErr, sysErr, code
+	}
+
+	duration := tocookie.DefaultDuration
+	newCookie := tocookie.GetCookie(oldCookie.AuthData, duration, secret)
+	http.SetCookie(w, newCookie)
+	return user, nil, nil, http.StatusOK
+}
+
+// getUserFromAPIToken returns the user authenticated by the given API token. Unlike cookies, tokens aren't refreshed.
+func getUserFromAPIToken(r *http.Request, token string) (auth.CurrentUser, error, error, int) {
+	db, timeout, sysErr := getReqDB(r)
+	if sysErr != nil {
+		return auth.CurrentUser{}, nil, sysErr, http.StatusInternalServerError
+	}
+	return auth.GetCurrentUserFromAPIToken(db, token, timeout)
+}
+
+// getReqDB returns the database and database query timeout from the request context.
+func getReqDB(r *http.Request) (*sqlx.DB, time.Duration, error) {
 	db := (*sqlx.DB)(nil)
 	val := r.Context().Value(DBContextKey)
 	if val == nil {
-		return auth.CurrentUser{}, nil, errors.New("request context db missing"), http.StatusInternalServerError
+		return nil, 0, errors.New("request context db missing")
 	}
 	switch v := val.(type) {
 	case *sqlx.DB:
 		db = v
 	default:
-		return auth.CurrentUser{}, nil, fmt.Errorf("request context db unknown type %T", val), http.StatusInternalServerError
+		return nil, 0, fmt.Errorf("request context db unknown type %T", val)
 	}
 
 	cfg, err := GetConfig(r.Context())
 	if err != nil {
-		return auth.CurrentUser{}, nil, errors.New("request context config missing"), http.StatusInternalServerError
+		return nil, 0, errors.New("request context config missing")
 	}
-
-	user, userErr, sysErr, code := auth.GetCurrentUserFromDB(db, username, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
-	if userErr != nil || sysErr != nil {
-		return auth.CurrentUser{}, userErr, sysErr, code
-	}
-
-	duration := tocookie.DefaultDuration
-	newCookie := tocookie.GetCookie(oldCookie.AuthData, duration, secret)
-	http.SetCookie(w, newCookie)
-	return user, nil, nil, http.StatusOK
+	return db, time.Duration(cfg.DBQueryTimeoutSeconds) * time.Second, nil
 }
 
 func AddUserToReq(r *http.Request, u auth.CurrentUser) {
@@ -0,0 +1,83 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// WriteRespWithSummaryConditional acts like WriteRespWithSummary, but writes an ETag header, and responds 304 Not Modified if the client already has the response. See WriteConditional.
+// The summary is omitted if it's nil.
+//
+// No Last-Modified header is written, because v is a collection, and the latest last updated time of its objects doesn't change when one of them is deleted, so If-Modified-Since would wrongly be satisfied.
+func WriteRespWithSummaryConditional(w http.ResponseWriter, r *http.Request, v interface{}, summary *tc.Summary) {
+	if respWritten(r) {
+		log.Errorf("WriteRespWithSummaryConditional called after a write already occurred! Not double-writing! Path %s", r.URL.Path)
+		return
+	}
+	setRespWritten(r)
+
+	resp := struct {
+		Response interface{} `json:"response"`
+		Summary  *tc.Summary `json:"summary,omitempty"`
+	}{v, summary}
+	bts, err := json.Marshal(resp)
+	if err != nil {
+		log.Errorf("marshalling JSON for %T: %v", v, err)
+		tc.GetHandleErrorsFunc(w, r)(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+	w.Header().Set(rfc.ContentType, rfc.ApplicationJSON)
+	WriteConditional(w, r, append(bts, '\n'), time.Time{})
+}
+
+// WriteConditional writes the given response body, with an ETag header of its hash, and a Last-Modified header of lastModified if it isn't zero.
+// The lastModified should be zero unless it changes whenever the body does, including when an object is removed from it.
+// If the request's If-None-Match or If-Modified-Since headers show the client already has the body, a 304 Not Modified is written instead, without a body.
+// The Content-Type should be set by the caller.
+func WriteConditional(w http.ResponseWriter, r *http.Request, body []byte, lastModified time.Time) {
+	etag := ETag(body)
+	w.Header().Set(rfc.ETag, etag)
+	if !lastModified.IsZero() {
+		w.Header().Set(rfc.LastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if rfc.NotModified(r, etag, lastModified) {
+		w.Header().Del(rfc.ContentType)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(body)
+}
+
+// ETag returns the weak entity tag of the given response body, for the ETag header.
+// It's weak because the body may be gzipped after it's hashed, and a strong tag must differ for each Content-Encoding.
+func ETag(body []byte) string {
+	sum := sha512.Sum512(body)
+	return `W/"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
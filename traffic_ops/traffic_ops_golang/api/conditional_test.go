@@ -0,0 +1,77 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+)
+
+func TestWriteConditional(t *testing.T) {
+	body := []byte(`{"response":[]}`)
+	lastModified := time.Date(2019, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	WriteConditional(w, r, body, lastModified)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected code %d, actual %d", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != string(body) {
+		t.Errorf("expected body %s, actual %s", body, w.Body.String())
+	}
+	etag := w.Header().Get(rfc.ETag)
+	if etag != ETag(body) {
+		t.Errorf("expected ETag %s, actual %s", ETag(body), etag)
+	}
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Errorf("expected weak ETag, because the body may be gzipped, actual %s", etag)
+	}
+	if actual := w.Header().Get(rfc.LastModified); actual != "Wed, 20 Nov 2019 12:00:00 GMT" {
+		t.Errorf("expected Last-Modified 'Wed, 20 Nov 2019 12:00:00 GMT', actual '%s'", actual)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfModifiedSince, lastModified.Format(http.TimeFormat))
+	WriteConditional(w, r, body, lastModified)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since expected code %d, actual %d", http.StatusNotModified, w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("If-Modified-Since expected no body, actual %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfNoneMatch, ETag([]byte(`{"response":[{}]}`)))
+	WriteConditional(w, r, body, lastModified)
+	if w.Code != http.StatusOK {
+		t.Errorf("changed If-None-Match expected code %d, actual %d", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != string(body) {
+		t.Errorf("changed If-None-Match expected body %s, actual %s", body, w.Body.String())
+	}
+}
@@ -131,6 +131,14 @@ func GenericRead(val GenericReader) ([]interface{}, error, error, int) {
 		}
 		vals = append(vals, v)
 	}
+
+	if pagination != "" {
+		count, err := dbhelpers.GetCount(val.APIInfo().Tx, val.SelectQuery()+where, queryValues)
+		if err != nil {
+			return nil, nil, errors.New("counting " + val.GetType() + ": " + err.Error()), http.StatusInternalServerError
+		}
+		val.APIInfo().SetCount(count)
+	}
 	return vals, nil, nil, http.StatusOK
 }
 
@@ -0,0 +1,87 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type genericReadTester struct {
+	APIInfoImpl
+}
+
+type genericReadObj struct {
+	Name string `db:"name"`
+}
+
+func (v *genericReadTester) GetType() string         { return "tester" }
+func (v *genericReadTester) NewReadObj() interface{} { return &genericReadObj{} }
+func (v *genericReadTester) SelectQuery() string     { return "SELECT t.name FROM tester t" }
+func (v *genericReadTester) ParamColumns() map[string]dbhelpers.WhereColumnInfo {
+	return map[string]dbhelpers.WhereColumnInfo{"name": dbhelpers.WhereColumnInfo{Column: "t.name", Checker: nil}}
+}
+
+func TestGenericReadPaginated(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT t.name FROM tester t\nORDER BY t.name DESC\nLIMIT 2\nOFFSET 2")).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("b").AddRow("a"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM (SELECT t.name FROM tester t) AS results")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	inf := APIInfo{Tx: db.MustBegin(), Params: map[string]string{"orderby": "name", "sortOrder": "desc", "limit": "2", "page": "2"}}
+	vals, userErr, sysErr, _ := GenericRead(&genericReadTester{APIInfoImpl{ReqInfo: &inf}})
+	if userErr != nil || sysErr != nil {
+		t.Fatalf("expected GenericRead errors nil, actual: %v %v", userErr, sysErr)
+	}
+	if len(vals) != 2 {
+		t.Errorf("expected GenericRead 2 results, actual: %v", len(vals))
+	}
+	if inf.count == nil || *inf.count != 4 {
+		t.Errorf("expected GenericRead to set count 4, actual: %v", inf.count)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected queries: %v", err)
+	}
+
+	inf = APIInfo{Tx: inf.Tx, Params: map[string]string{}}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT t.name FROM tester t")).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a"))
+	if _, userErr, sysErr, _ := GenericRead(&genericReadTester{APIInfoImpl{ReqInfo: &inf}}); userErr != nil || sysErr != nil {
+		t.Fatalf("expected GenericRead errors nil, actual: %v %v", userErr, sysErr)
+	}
+	if inf.count != nil {
+		t.Errorf("expected GenericRead without a limit not to count, actual: %v", *inf.count)
+	}
+}
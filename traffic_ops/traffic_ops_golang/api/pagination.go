@@ -0,0 +1,117 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+)
+
+// ReadParams are the field selection query parameters, which every ReadHandler resource supports:
+//   - fields is a comma-delimited list of the fields of each object to return.
+//
+// Fields are JSON object keys of the response objects, not database columns.
+//
+// The orderby, sortOrder, limit, offset, and page query parameters are applied by each resource in its query, with dbhelpers.BuildWhereAndOrderByAndPagination.
+type ReadParams struct {
+	Fields []string
+}
+
+// readParamNames are the query parameters of ReadParams, which ParseReadParams removes.
+var readParamNames = []string{"fields"}
+
+// ParseReadParams returns the ReadParams in the given query parameters, and removes them, so the resource doesn't treat them as filters.
+func ParseReadParams(params map[string]string) ReadParams {
+	rp := ReadParams{Fields: dbhelpers.SplitList(params["fields"])}
+	for _, name := range readParamNames {
+		delete(params, name)
+	}
+	return rp
+}
+
+// Apply returns the given results with only the selected fields, any user error, and any system error.
+func (rp ReadParams) Apply(results []interface{}) ([]interface{}, error, error) {
+	if len(rp.Fields) == 0 {
+		return results, nil, nil
+	}
+
+	objs, err := toJSONObjects(results)
+	if err != nil {
+		return nil, nil, errors.New("converting results to JSON objects: " + err.Error())
+	}
+	if objs, err = selectFields(objs, rp.Fields); err != nil {
+		return nil, err, nil
+	}
+
+	projected := make([]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		projected = append(projected, obj)
+	}
+	return projected, nil, nil
+}
+
+// Paginated returns whether the given query parameters limit the objects returned, in which case the number of objects returned isn't the number of objects in the collection.
+func Paginated(params map[string]string) bool {
+	_, ok := params["limit"]
+	return ok
+}
+
+// toJSONObjects returns the given results as the JSON objects they're serialized as.
+func toJSONObjects(results []interface{}) ([]map[string]interface{}, error) {
+	bts, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	objs := []map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(bts))
+	dec.UseNumber() // don't lose the precision of integers
+	if err := dec.Decode(&objs); err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// selectFields returns the objects with only the given fields. Returns an error if no object has one of the fields.
+func selectFields(objs []map[string]interface{}, fields []string) ([]map[string]interface{}, error) {
+	if len(objs) == 0 {
+		return objs, nil
+	}
+	found := map[string]bool{}
+	selected := make([]map[string]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		sel := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if val, ok := obj[field]; ok {
+				sel[field] = val
+				found[field] = true
+			}
+		}
+		selected = append(selected, sel)
+	}
+	for _, field := range fields {
+		if !found[field] {
+			return nil, errors.New("fields parameter has unknown field '" + field + "'")
+		}
+	}
+	return selected, nil
+}
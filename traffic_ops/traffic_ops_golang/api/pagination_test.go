@@ -0,0 +1,90 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type paginationTester struct {
+	ID   int     `json:"id"`
+	Name *string `json:"name"`
+	CDN  string  `json:"cdn"`
+}
+
+func paginationTesters() []interface{} {
+	str := func(s string) *string { return &s }
+	return []interface{}{
+		paginationTester{ID: 1, Name: str("b"), CDN: "cdn1"},
+		paginationTester{ID: 2, Name: nil, CDN: "cdn0"},
+		paginationTester{ID: 10, Name: str("a"), CDN: "cdn1"},
+		paginationTester{ID: 3, Name: str("c"), CDN: "cdn0"},
+	}
+}
+
+func TestParseReadParams(t *testing.T) {
+	params := map[string]string{"id": "1", "orderby": "cdn", "limit": "2", "fields": "id, name"}
+	rp := ParseReadParams(params)
+	if expected := (ReadParams{Fields: []string{"id", "name"}}); !reflect.DeepEqual(rp, expected) {
+		t.Errorf("expected ParseReadParams %+v, actual: %+v", expected, rp)
+	}
+	// orderby and limit are left for the resource to apply in its query
+	if expected := map[string]string{"id": "1", "orderby": "cdn", "limit": "2"}; !reflect.DeepEqual(params, expected) {
+		t.Errorf("expected ParseReadParams to remove only the fields param, actual: %+v", params)
+	}
+	if !Paginated(params) {
+		t.Errorf("expected Paginated true with a limit, actual: false")
+	}
+	if Paginated(map[string]string{"orderby": "cdn"}) {
+		t.Errorf("expected Paginated false without a limit, actual: true")
+	}
+}
+
+func TestReadParamsApply(t *testing.T) {
+	results, userErr, sysErr := ReadParams{}.Apply(paginationTesters())
+	if userErr != nil || sysErr != nil {
+		t.Fatalf("expected Apply errors nil, actual: %v %v", userErr, sysErr)
+	}
+	if expected := paginationTesters(); !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected Apply without fields to return the results unchanged %+v, actual: %+v", expected, results)
+	}
+
+	results, userErr, sysErr = ReadParams{Fields: []string{"id", "name"}}.Apply(paginationTesters())
+	if userErr != nil || sysErr != nil {
+		t.Fatalf("expected Apply errors nil, actual: %v %v", userErr, sysErr)
+	}
+	if actual, expected := marshalString(t, results), `[{"id":1,"name":"b"},{"id":2,"name":null},{"id":10,"name":"a"},{"id":3,"name":"c"}]`; actual != expected {
+		t.Errorf("expected Apply %v, actual: %v", expected, actual)
+	}
+
+	if _, userErr, _ := (ReadParams{Fields: []string{"nonexistent"}}).Apply(paginationTesters()); userErr == nil {
+		t.Errorf("expected Apply unknown field error, actual: nil")
+	}
+}
+
+func marshalString(t *testing.T, v interface{}) string {
+	bts, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshalling: %v", err)
+	}
+	return string(bts)
+}
@@ -116,7 +116,9 @@ func decodeAndValidateRequestBody(r *http.Request, v Validator) error {
 //      this handler retrieves the user from the context
 //      combines the path and query parameters
 //      produces the proper status code based on the error code returned
-//      marshals the structs returned into the proper response json
+//      selects the fields of the structs returned, per the ReadParams
+//      marshals the structs returned into the proper response json, with a summary of their count, if it's known
+//      responds 304 Not Modified if the client already has the response, per its If-None-Match header
 func ReadHandler(reader Reader) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		inf, userErr, sysErr, errCode := NewInfo(r, nil, nil)
@@ -135,12 +137,32 @@ func ReadHandler(reader Reader) http.HandlerFunc {
 		obj := reflect.New(objectType).Interface().(Reader)
 		obj.SetInfo(inf)
 
+		readParams := ParseReadParams(inf.Params)
+
 		results, userErr, sysErr, errCode := obj.Read()
 		if userErr != nil || sysErr != nil {
 			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 			return
 		}
-		WriteResp(w, r, results)
+
+		// a paginated Read which didn't set its count has an unknown count, so the summary is omitted
+		var summary *tc.Summary
+		if inf.count != nil {
+			summary = &tc.Summary{Count: *inf.count}
+		} else if !Paginated(inf.Params) {
+			summary = &tc.Summary{Count: len(results)}
+		}
+
+		results, userErr, sysErr = readParams.Apply(results)
+		if userErr != nil || sysErr != nil {
+			errCode = http.StatusBadRequest
+			if userErr == nil {
+				errCode = http.StatusInternalServerError
+			}
+			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+		WriteRespWithSummaryConditional(w, r, results, summary)
 	}
 }
 
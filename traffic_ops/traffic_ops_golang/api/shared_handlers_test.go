@@ -27,7 +27,10 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 	"github.com/jmoiron/sqlx"
@@ -182,7 +185,99 @@ func TestReadHandler(t *testing.T) {
 	readFunc(w, r)
 
 	//verifies the body is in the expected format
-	body := `{"response":[{"ID":1}]}` + "\n"
+	body := `{"response":[{"ID":1}],"summary":{"count":1}}` + "\n"
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+
+	//verifies an unchanged response isn't sent again
+	etag := w.Header().Get(rfc.ETag)
+	if etag == "" {
+		t.Fatal("Expected ETag header, got none")
+	}
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("", "", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Set(rfc.IfNoneMatch, etag)
+	r = r.WithContext(ctx)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	readFunc(w, r)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected code %d, got %d", http.StatusNotModified, w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Error("Expected no body, got", w.Body.String())
+	}
+}
+
+// lastUpdatedTesterObjs are the objects a lastUpdatedTester reads, which tests change between requests.
+var lastUpdatedTesterObjs = []interface{}{}
+
+type lastUpdatedTester struct {
+	APIInfoImpl `json:"-"`
+}
+
+func (v *lastUpdatedTester) Read() ([]interface{}, error, error, int) {
+	return lastUpdatedTesterObjs, nil, nil, http.StatusOK
+}
+
+func TestReadHandlerDeleted(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{})
+	ctx = context.WithValue(ctx, DBContextKey, db)
+	ctx = context.WithValue(ctx, ConfigContextKey, &cfg)
+	ctx = context.WithValue(ctx, ReqIDContextKey, uint64(0))
+
+	type obj struct {
+		ID          int
+		LastUpdated tc.TimeNoMod
+	}
+	lastUpdated := time.Date(2019, 11, 20, 12, 0, 0, 0, time.UTC)
+	lastUpdatedTesterObjs = []interface{}{
+		obj{ID: 1, LastUpdated: tc.TimeNoMod{Time: lastUpdated}},
+		obj{ID: 2, LastUpdated: tc.TimeNoMod{Time: lastUpdated}},
+	}
+	readFunc := ReadHandler(&lastUpdatedTester{})
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	readFunc(w, r)
+	if lastModified := w.Header().Get(rfc.LastModified); lastModified != "" {
+		t.Errorf("Expected no Last-Modified header for a collection, got %s", lastModified)
+	}
+
+	// deleting an object doesn't change the last updated time of the others, so only the ETag can tell the collection changed
+	lastUpdatedTesterObjs = lastUpdatedTesterObjs[:1]
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	r.Header.Set(rfc.IfModifiedSince, lastUpdated.Add(time.Hour).Format(http.TimeFormat))
+	readFunc(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected If-Modified-Since after a delete code %d, got %d", http.StatusOK, w.Code)
+	}
+	body := `{"response":[{"ID":1,"LastUpdated":"2019-11-20 12:00:00+00"}],"summary":{"count":1}}` + "\n"
 	if w.Body.String() != body {
 		t.Error("Expected body", body, "got", w.Body.String())
 	}
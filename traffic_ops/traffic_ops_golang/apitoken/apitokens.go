@@ -0,0 +1,257 @@
+package apitoken
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/lib/pq"
+)
+
+const selectQuery = `
+SELECT
+  t.id,
+  t.name,
+  t.tm_user_id,
+  u.username,
+  t.capabilities,
+  t.expires,
+  t.last_used,
+  t.last_updated
+FROM
+  api_token AS t
+JOIN
+  tm_user AS u ON u.id = t.tm_user_id
+WHERE
+  t.tm_user_id = $1
+ORDER BY
+  t.name
+`
+
+const insertQuery = `
+INSERT INTO api_token (name, token_hash, tm_user_id, capabilities, expires)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, last_updated
+`
+
+// Get is the handler for GET requests to /api_tokens. It lists the requesting user's tokens, or those of the user in the userId query parameter, which only admins may request.
+func Get(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, []string{"userId"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	userID := inf.User.ID
+	if paramUserID, ok := inf.IntParams["userId"]; ok {
+		userID = paramUserID
+	}
+	if userID != inf.User.ID && inf.User.PrivLevel < auth.PrivLevelAdmin {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, errors.New("only admins may view the API tokens of other users"), nil)
+		return
+	}
+
+	tokens, err := getTokens(inf.Tx.Tx, userID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting API tokens: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, tokens)
+}
+
+// Create is the handler for POST requests to /api_tokens. It issues a token to the requesting user, or to the request's userId, which only admins may issue to.
+// Tokens can't be issued by requests authenticated with a token, so a token limited to some capabilities can't be used to get one without those limits.
+func Create(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	if _, ok := auth.GetAPIToken(r); ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, errors.New("API tokens can't be issued with an API token, please log in"), nil)
+		return
+	}
+
+	req := tc.APITokenRequest{}
+	if err := api.Parse(r.Body, inf.Tx.Tx, &req); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	userID := inf.User.ID
+	if req.UserID != nil {
+		userID = *req.UserID
+	}
+	if userID != inf.User.ID && inf.User.PrivLevel < auth.PrivLevelAdmin {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, errors.New("only admins may issue API tokens to other users"), nil)
+		return
+	}
+
+	userName, ok, err := getUserName(inf.Tx.Tx, userID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting API token user: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("no user with id "+strconv.Itoa(userID)), nil)
+		return
+	}
+
+	caps := []string(nil)
+	if req.Capabilities != nil {
+		caps = *req.Capabilities
+		if missing, err := getMissingCapabilities(inf.Tx.Tx, caps); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking API token capabilities: "+err.Error()))
+			return
+		} else if len(missing) > 0 {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("capabilities do not exist: "+strings.Join(missing, ", ")), nil)
+			return
+		}
+	}
+
+	token, err := auth.GenerateAPIToken()
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("generating API token: "+err.Error()))
+		return
+	}
+
+	created := tc.APITokenCreated{
+		APIToken: tc.APIToken{
+			Name:         req.Name,
+			UserID:       &userID,
+			Username:     &userName,
+			Capabilities: req.Capabilities,
+			Expires:      req.Expires,
+		},
+		Token: token,
+	}
+	capsArr := interface{}(nil) // a nil capabilities array is NULL, an empty one is a token with no capabilities
+	if caps != nil {
+		capsArr = pq.Array(caps)
+	}
+	if err := inf.Tx.Tx.QueryRow(insertQuery, *req.Name, auth.HashAPIToken(token), userID, capsArr, req.Expires).Scan(&created.ID, &created.LastUpdated); err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "API TOKEN: "+*req.Name+", USER: "+userName+", ID: "+strconv.Itoa(*created.ID)+", ACTION: Created", inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "API token issued. Save the token, it can't be retrieved again.", created)
+}
+
+// Delete is the handler for DELETE requests to /api_tokens/{id}. It revokes the token. Users may revoke their own tokens, and admins may revoke any token.
+func Delete(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	id := inf.IntParams["id"]
+	name := ""
+	userID := 0
+	if err := inf.Tx.Tx.QueryRow(`SELECT name, tm_user_id FROM api_token WHERE id = $1`, id).Scan(&name, &userID); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no API token with id "+strconv.Itoa(id)), nil)
+			return
+		}
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting API token: "+err.Error()))
+		return
+	}
+	if userID != inf.User.ID && inf.User.PrivLevel < auth.PrivLevelAdmin {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, errors.New("only admins may revoke the API tokens of other users"), nil)
+		return
+	}
+
+	if _, err := inf.Tx.Tx.Exec(`DELETE FROM api_token WHERE id = $1`, id); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting API token: "+err.Error()))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "API TOKEN: "+name+", ID: "+strconv.Itoa(id)+", ACTION: Revoked", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "API token revoked.")
+}
+
+func getTokens(tx *sql.Tx, userID int) ([]tc.APIToken, error) {
+	rows, err := tx.Query(selectQuery, userID)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+
+	tokens := []tc.APIToken{}
+	for rows.Next() {
+		token := tc.APIToken{}
+		caps := []string(nil)
+		if err := rows.Scan(&token.ID, &token.Name, &token.UserID, &token.Username, pq.Array(&caps), &token.Expires, &token.LastUsed, &token.LastUpdated); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		if caps != nil {
+			token.Capabilities = &caps
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// getUserName returns the name of the user with the given ID, and whether the user exists.
+func getUserName(tx *sql.Tx, userID int) (string, bool, error) {
+	name := ""
+	if err := tx.QueryRow(`SELECT username FROM tm_user WHERE id = $1`, userID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, errors.New("querying: " + err.Error())
+	}
+	return name, true, nil
+}
+
+// getMissingCapabilities returns the given capabilities which don't exist.
+func getMissingCapabilities(tx *sql.Tx, caps []string) ([]string, error) {
+	if len(caps) == 0 {
+		return nil, nil
+	}
+	existing := []string{}
+	if err := tx.QueryRow(`SELECT ARRAY(SELECT name FROM capability WHERE name = ANY($1))`, pq.Array(caps)).Scan(pq.Array(&existing)); err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	existingSet := map[string]struct{}{}
+	for _, cap := range existing {
+		existingSet[cap] = struct{}{}
+	}
+	missing := []string{}
+	for _, cap := range caps {
+		if _, ok := existingSet[cap]; !ok {
+			missing = append(missing, cap)
+		}
+	}
+	return missing, nil
+}
@@ -30,6 +30,9 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ats"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/topology"
+
+	"github.com/lib/pq"
 )
 
 func GetHostingDotConfig(w http.ResponseWriter, r *http.Request) {
@@ -79,6 +82,13 @@ func GetHostingDotConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	topologyOrigins, err := getServerTopologyHostingOrigins(inf.Tx.Tx, serverName)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting server '"+string(serverName)+"' topology hosting origins: "+err.Error()))
+		return
+	}
+	origins = append(origins, topologyOrigins...)
+
 	log.Errorf("hosting config DEBUG params %+v\n", params)
 	log.Errorf("hosting config DEBUG multiParams %+v\n", multiParams)
 
@@ -108,6 +118,7 @@ WHERE
   AND ds.type IN (SELECT id FROM type WHERE name like '%` + tc.DSTypeLiveNationalSuffix + `')
   AND ds.active = true
   AND ds.cdn_id = s.cdn_id
+  AND ds.topology IS NULL
 `
 	} else {
 		qry += `
@@ -115,6 +126,7 @@ WHERE
   s.host_name = $1
   AND ds.cdn_id = s.cdn_id
   AND ds.type IN (SELECT id FROM type WHERE (name LIKE '%` + tc.DSTypeLiveSuffix + `' OR name LIKE '%` + tc.DSTypeLiveNationalSuffix + `'))
+  AND ds.topology IS NULL
 `
 	}
 	// Note the 'ds.cdn_id = s.cdn_id' in the query shouldn't be necessary, but it is, because there's no DB constraint.
@@ -137,3 +149,63 @@ WHERE
 	}
 	return origins, nil
 }
+
+// getServerTopologyHostingOrigins returns the list of origins on delivery services with topologies containing the given server's cachegroup, to be used in the ATS config file.
+// Delivery services without topologies are returned by GetServerHostingOrigins.
+func getServerTopologyHostingOrigins(tx *sql.Tx, serverName tc.CacheName) ([]string, error) {
+	serverInfo, ok, err := ats.GetServerInfoByHost(tx, serverName)
+	if err != nil {
+		return nil, errors.New("getting server info: " + err.Error())
+	} else if !ok {
+		return nil, errors.New("server not found")
+	}
+
+	topologies, err := topology.GetCacheGroupTopologies(tx, serverInfo.CacheGroupName)
+	if err != nil {
+		return nil, errors.New("getting server cachegroup topologies: " + err.Error())
+	}
+	if len(topologies) == 0 {
+		return []string{}, nil
+	}
+	topologyNames := []string{}
+	for name, _ := range topologies {
+		topologyNames = append(topologyNames, name)
+	}
+
+	qry := `
+SELECT
+  ds.xml_id,
+  COALESCE((SELECT o.protocol::text || '://' || o.fqdn || rtrim(concat(':', o.port::text), ':')
+    FROM origin o
+    WHERE o.deliveryservice = ds.id
+    AND o.is_primary), '') as org_server_fqdn,
+  dt.name,
+  ds.active,
+  ds.topology
+FROM
+  deliveryservice ds
+  JOIN type dt ON dt.id = ds.type
+WHERE
+  ds.topology = ANY($1)
+  AND ds.cdn_id = $2
+`
+	rows, err := tx.Query(qry, pq.Array(topologyNames), serverInfo.CDNID)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+
+	dses := []atscfg.HostingConfigTopologyDS{}
+	for rows.Next() {
+		ds := atscfg.HostingConfigTopologyDS{}
+		if err := rows.Scan(&ds.Name, &ds.OriginFQDN, &ds.Type, &ds.Active, &ds.Topology); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		if ds.OriginFQDN == "" {
+			continue
+		}
+		ds.Type = tc.DSTypeFromString(string(ds.Type))
+		dses = append(dses, ds)
+	}
+	return atscfg.GetTopologyHostingOrigins(serverInfo.CacheGroupName, dses, topologies), nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ats"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/topology"
 
 	"github.com/lib/pq"
 )
@@ -102,7 +103,19 @@ func GetParentDotConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	text := atscfg.MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos)
+	topologies, err := topology.GetCacheGroupTopologies(inf.Tx.Tx, serverInfo.CacheGroupName)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting server cachegroup topologies: "+err.Error()))
+		return
+	}
+
+	topologyParentInfos, err := getTopologyParentInfo(inf.Tx.Tx, serverInfo, topologies)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting server topology parent info: "+err.Error()))
+		return
+	}
+
+	text := atscfg.MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos, topologies, topologyParentInfos)
 
 	w.Header().Set("Content-Type", "text/plain")
 	w.Write([]byte(text))
@@ -130,7 +143,7 @@ func getServerInfoByHost(tx *sql.Tx, host string) (*atscfg.ServerInfo, bool, err
 // getServerInfo returns the necessary info about the server, whether the server exists, and any error.
 func getServerInfo(tx *sql.Tx, qry string, qryParams []interface{}) (*atscfg.ServerInfo, bool, error) {
 	s := atscfg.ServerInfo{}
-	if err := tx.QueryRow(qry, qryParams...).Scan(&s.CDN, &s.CDNID, &s.ID, &s.HostName, &s.DomainName, &s.IP, &s.ProfileID, &s.ProfileName, &s.Port, &s.Type, &s.CacheGroupID, &s.ParentCacheGroupID, &s.SecondaryParentCacheGroupID, &s.ParentCacheGroupType, &s.SecondaryParentCacheGroupType); err != nil {
+	if err := tx.QueryRow(qry, qryParams...).Scan(&s.CDN, &s.CDNID, &s.ID, &s.HostName, &s.DomainName, &s.IP, &s.ProfileID, &s.ProfileName, &s.Port, &s.Type, &s.CacheGroupID, &s.CacheGroupName, &s.ParentCacheGroupID, &s.SecondaryParentCacheGroupID, &s.ParentCacheGroupType, &s.SecondaryParentCacheGroupType); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, false, nil
 		}
@@ -153,6 +166,7 @@ SELECT
   s.tcp_port,
   t.name as type,
   s.cachegroup,
+  cg.name as cachegroup_name,
   COALESCE(cg.parent_cachegroup_id, ` + strconv.Itoa(atscfg.InvalidID) + `) as parent_cachegroup_id,
   COALESCE(cg.secondary_parent_cachegroup_id, ` + strconv.Itoa(atscfg.InvalidID) + `) as secondary_parent_cachegroup_id,
   COALESCE(parentt.name, '') as parent_cachegroup_type,
@@ -214,7 +228,8 @@ SELECT
   COALESCE(ds.multi_site_origin, false),
   COALESCE(ds.origin_shield, ''),
   ARRAY(SELECT required_capability FROM deliveryservices_required_capability dsrc WHERE dsrc.deliveryservice_id = ds.id),
-  dt.name AS ds_type
+  dt.name AS ds_type,
+  COALESCE(ds.topology, '')
 `
 }
 
@@ -233,13 +248,21 @@ ORDER BY ds.id
 ` // TODO: perl does 'ORDER BY ds.id, rt.name, dsr.set_number' - but doesn't actually use regexes - ensure it isn't necessary
 
 const ParentConfigDSQueryWhere = `
-WHERE ds.id in (SELECT DISTINCT(dss.deliveryservice) FROM deliveryservice_server dss where dss.server = $1)
+WHERE
+  ds.id in (SELECT DISTINCT(dss.deliveryservice) FROM deliveryservice_server dss where dss.server = $1)
+  OR ds.topology IN (
+    SELECT tcg.topology
+    FROM topology_cachegroup tcg
+    JOIN cachegroup cg ON cg.name = tcg.cachegroup
+    JOIN server s ON s.cachegroup = cg.id
+    WHERE s.id = $1
+  )
 `
 
 const ParentConfigDSQueryWhereTopLevel = `
 WHERE
   cdn.name = $1
-  AND ds.id in (SELECT deliveryservice_server.deliveryservice FROM deliveryservice_server)
+  AND (ds.id in (SELECT deliveryservice_server.deliveryservice FROM deliveryservice_server) OR ds.topology IS NOT NULL)
   AND ds.active = true
 `
 
@@ -335,7 +358,7 @@ func getParentConfigDSRaw(tx *sql.Tx, qry string, qryParams []interface{}) ([]at
 	for rows.Next() {
 		d := atscfg.ParentConfigDS{RequiredCapabilities: map[atscfg.ServerCapability]struct{}{}}
 		requiredCaps := []string{}
-		if err := rows.Scan(&d.Name, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.OriginShield, pq.Array(&requiredCaps), &d.Type); err != nil {
+		if err := rows.Scan(&d.Name, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.OriginShield, pq.Array(&requiredCaps), &d.Type, &d.Topology); err != nil {
 			return nil, errors.New("scanning: " + err.Error())
 		}
 		if d.OriginFQDN == "" {
@@ -509,6 +532,93 @@ func getParentInfo(tx *sql.Tx, server *atscfg.ServerInfo) (map[atscfg.OriginHost
 	return atscfg.MakeParentInfo(server, serverDomain, profileCaches, originServers), nil
 }
 
+// getTopologyParentInfo returns the parents in each cachegroup of the given topologies, which must be the topologies containing the server's cachegroup.
+func getTopologyParentInfo(tx *sql.Tx, server *atscfg.ServerInfo, topologies map[string]tc.Topology) (map[tc.CacheGroupName][]atscfg.ParentInfo, error) {
+	cacheGroups := []string{}
+	seenCacheGroups := map[string]struct{}{}
+	for _, top := range topologies {
+		for _, node := range top.Nodes {
+			if _, ok := seenCacheGroups[node.Cachegroup]; ok {
+				continue
+			}
+			seenCacheGroups[node.Cachegroup] = struct{}{}
+			cacheGroups = append(cacheGroups, node.Cachegroup)
+		}
+	}
+	if len(cacheGroups) == 0 {
+		return map[tc.CacheGroupName][]atscfg.ParentInfo{}, nil
+	}
+
+	qry := `
+SELECT
+  s.id,
+  s.host_name,
+  s.ip_address,
+  s.tcp_port,
+  s.cachegroup,
+  s.status,
+  s.type,
+  s.profile,
+  s.cdn_id,
+  stype.name as type_name,
+  ARRAY(SELECT server_capability FROM server_server_capability ssc WHERE ssc.server = s.id),
+  s.domain_name,
+  cg.name
+FROM
+  server s
+  JOIN type stype ON s.type = stype.id
+  JOIN cachegroup cg ON cg.id = s.cachegroup
+  JOIN cdn on s.cdn_id = cdn.id
+  JOIN status st ON st.id = s.status
+WHERE
+  cg.name = ANY($2)
+  AND (stype.name LIKE '` + tc.EdgeTypePrefix + `%' OR stype.name LIKE '` + tc.MidTypePrefix + `%')
+  AND (st.name = '` + string(tc.CacheStatusReported) + `' OR st.name = '` + string(tc.CacheStatusOnline) + `')
+  AND cdn.name = $1
+`
+	rows, err := tx.Query(qry, server.CDN, pq.Array(cacheGroups))
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+
+	cgServerIDs := []int{}
+	cgServers := map[tc.CacheGroupName][]atscfg.CGServer{}
+	for rows.Next() {
+		s := atscfg.CGServer{Capabilities: map[atscfg.ServerCapability]struct{}{}}
+		caps := []string{}
+		cgName := tc.CacheGroupName("")
+		if err := rows.Scan(&s.ServerID, &s.ServerHost, &s.ServerIP, &s.ServerPort, &s.CacheGroupID, &s.Status, &s.Type, &s.ProfileID, &s.CDN, &s.TypeName, pq.Array(&caps), &s.Domain, &cgName); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		for _, cap := range caps {
+			s.Capabilities[atscfg.ServerCapability(cap)] = struct{}{}
+		}
+		cgServers[cgName] = append(cgServers[cgName], s)
+		cgServerIDs = append(cgServerIDs, int(s.ServerID))
+	}
+
+	profileParams, err := getParentConfigServerCacheProfileParams(tx, cgServerIDs)
+	if err != nil {
+		return nil, errors.New("getting cachegroup server profile params: " + err.Error())
+	}
+
+	profileCaches := map[atscfg.ProfileID]atscfg.ProfileCache{}
+	for _, servers := range cgServers {
+		for _, cgServer := range servers {
+			if _, ok := profileCaches[cgServer.ProfileID]; ok {
+				continue
+			}
+			if profileCache, ok := profileParams[cgServer.ProfileID]; ok {
+				profileCaches[cgServer.ProfileID] = profileCache
+			} else {
+				profileCaches[cgServer.ProfileID] = atscfg.DefaultProfileCache()
+			}
+		}
+	}
+	return atscfg.MakeTopologyParentInfo(profileCaches, cgServers), nil
+}
+
 // getServerParentCacheGroupProfiles gets the profile information for servers belonging to the parent cachegroup, and secondary parent cachegroup, of the cachegroup of each server.
 func getServerParentCacheGroupProfiles(tx *sql.Tx, server *atscfg.ServerInfo) (map[atscfg.ProfileID]atscfg.ProfileCache, map[atscfg.OriginHost][]atscfg.CGServer, error) {
 	// TODO make this more efficient - should be a single query - this was transliterated from Perl - it's extremely inefficient.
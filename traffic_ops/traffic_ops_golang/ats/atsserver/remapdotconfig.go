@@ -28,6 +28,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-rfc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ats"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/topology"
 )
 
 func GetServerConfigRemap(w http.ResponseWriter, r *http.Request) {
@@ -86,7 +87,13 @@ func GetServerConfigRemap(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	txt := atscfg.MakeRemapDotConfig(serverName, toToolName, toURL, atsMajorVersion, cacheURLConfigParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapDSData)
+	topologies, err := topology.GetCacheGroupTopologies(inf.Tx.Tx, serverInfo.CacheGroupName)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("Getting server cachegroup topologies: "+err.Error()))
+		return
+	}
+
+	txt := atscfg.MakeRemapDotConfig(serverName, toToolName, toURL, atsMajorVersion, cacheURLConfigParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapDSData, topologies)
 
 	w.Header().Set(rfc.ContentType, rfc.ContentTypeTextPlain)
 	io.WriteString(w, txt)
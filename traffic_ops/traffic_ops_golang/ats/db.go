@@ -471,7 +471,8 @@ SELECT
   ds.protocol,
   ds.profile,
   ds.anonymous_blocking_enabled,
-  ds.active
+  ds.active,
+  ds.topology
 FROM
   deliveryservice ds
   JOIN deliveryservice_regex dsr ON dsr.deliveryservice = ds.id
@@ -481,16 +482,29 @@ FROM
   JOIN cdn ON cdn.id = ds.cdn_id
 `
 
+// RemapDSDataQueryServerTopologies selects the topologies containing the cachegroup of the server with the ID $2.
+const RemapDSDataQueryServerTopologies = `
+SELECT tcg.topology
+FROM topology_cachegroup tcg
+JOIN cachegroup cg ON cg.name = tcg.cachegroup
+JOIN server s ON s.cachegroup = cg.id
+WHERE s.id = $2
+`
+
 const RemapDSDataQueryWhereForMid = `
 WHERE
   cdn.name = $1
-  AND ds.id in (SELECT dss.deliveryservice FROM deliveryservice_server dss)
+  AND (
+    ds.id in (SELECT dss.deliveryservice FROM deliveryservice_server dss)
+    OR ds.topology IN (` + RemapDSDataQueryServerTopologies + `)
+  )
   AND ds.active = true
 `
 
 const RemapDSDataQueryWhereForEdge = `
-JOIN deliveryservice_server dss ON dss.deliveryservice = ds.id
-WHERE dss.server = $1
+WHERE
+  ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server dss WHERE dss.server = $1)
+  OR ds.topology IN (` + RemapDSDataQueryServerTopologies + `)
 `
 
 const RemapDSDataQueryOrderBy = `
@@ -502,7 +516,7 @@ ORDER BY
 
 func GetRemapDSDataForMid(tx *sql.Tx, serverInfo *atscfg.ServerInfo) ([]atscfg.RemapConfigDSData, error) {
 	qry := RemapDSDataQuerySelectFrom + RemapDSDataQueryWhereForMid + RemapDSDataQueryOrderBy
-	rows, err := tx.Query(qry, serverInfo.CDN)
+	rows, err := tx.Query(qry, serverInfo.CDN, serverInfo.ID)
 	if err != nil {
 		return nil, errors.New("querying: " + err.Error())
 	}
@@ -511,7 +525,7 @@ func GetRemapDSDataForMid(tx *sql.Tx, serverInfo *atscfg.ServerInfo) ([]atscfg.R
 	dses := []atscfg.RemapConfigDSData{}
 	for rows.Next() {
 		d := atscfg.RemapConfigDSData{}
-		if err := rows.Scan(&d.Name, &d.ID, &d.DSCP, &d.RoutingName, &d.SigningAlgorithm, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.RangeRequestHandling, &d.FQPacingRate, &d.OriginShield, &d.Pattern, &d.RegexType, &d.Type, &d.Domain, &d.RegexSetNumber, &d.EdgeHeaderRewrite, &d.MidHeaderRewrite, &d.RegexRemap, &d.CacheURL, &d.RemapText, &d.Protocol, &d.ProfileID, &d.AnonymousBlockingEnabled, &d.Active); err != nil {
+		if err := rows.Scan(&d.Name, &d.ID, &d.DSCP, &d.RoutingName, &d.SigningAlgorithm, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.RangeRequestHandling, &d.FQPacingRate, &d.OriginShield, &d.Pattern, &d.RegexType, &d.Type, &d.Domain, &d.RegexSetNumber, &d.EdgeHeaderRewrite, &d.MidHeaderRewrite, &d.RegexRemap, &d.CacheURL, &d.RemapText, &d.Protocol, &d.ProfileID, &d.AnonymousBlockingEnabled, &d.Active, &d.Topology); err != nil {
 			return nil, errors.New("scanning: " + err.Error())
 		}
 		if !RemapDotConfigIncludeInactiveDeliveryServices && !d.Active {
@@ -525,7 +539,7 @@ func GetRemapDSDataForMid(tx *sql.Tx, serverInfo *atscfg.ServerInfo) ([]atscfg.R
 
 func GetRemapDSDataForEdge(tx *sql.Tx, server *atscfg.ServerInfo) ([]atscfg.RemapConfigDSData, error) {
 	qry := RemapDSDataQuerySelectFrom + RemapDSDataQueryWhereForEdge + RemapDSDataQueryOrderBy
-	rows, err := tx.Query(qry, server.ID)
+	rows, err := tx.Query(qry, server.ID, server.ID)
 	if err != nil {
 		return nil, errors.New("querying: " + err.Error())
 	}
@@ -534,7 +548,7 @@ func GetRemapDSDataForEdge(tx *sql.Tx, server *atscfg.ServerInfo) ([]atscfg.Rema
 	dses := []atscfg.RemapConfigDSData{}
 	for rows.Next() {
 		d := atscfg.RemapConfigDSData{}
-		if err := rows.Scan(&d.Name, &d.ID, &d.DSCP, &d.RoutingName, &d.SigningAlgorithm, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.RangeRequestHandling, &d.FQPacingRate, &d.OriginShield, &d.Pattern, &d.RegexType, &d.Type, &d.Domain, &d.RegexSetNumber, &d.EdgeHeaderRewrite, &d.MidHeaderRewrite, &d.RegexRemap, &d.CacheURL, &d.RemapText, &d.Protocol, &d.ProfileID, &d.AnonymousBlockingEnabled, &d.Active); err != nil {
+		if err := rows.Scan(&d.Name, &d.ID, &d.DSCP, &d.RoutingName, &d.SigningAlgorithm, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.RangeRequestHandling, &d.FQPacingRate, &d.OriginShield, &d.Pattern, &d.RegexType, &d.Type, &d.Domain, &d.RegexSetNumber, &d.EdgeHeaderRewrite, &d.MidHeaderRewrite, &d.RegexRemap, &d.CacheURL, &d.RemapText, &d.Protocol, &d.ProfileID, &d.AnonymousBlockingEnabled, &d.Active, &d.Topology); err != nil {
 			return nil, errors.New("scanning: " + err.Error())
 		}
 		if !RemapDotConfigIncludeInactiveDeliveryServices && !d.Active {
@@ -594,7 +608,7 @@ func GetServerInfoByHost(tx *sql.Tx, host tc.CacheName) (*atscfg.ServerInfo, boo
 // getServerInfo returns the necessary info about the server, whether the server exists, and any error.
 func getServerInfo(tx *sql.Tx, qry string, qryParams []interface{}) (*atscfg.ServerInfo, bool, error) {
 	s := atscfg.ServerInfo{}
-	if err := tx.QueryRow(qry, qryParams...).Scan(&s.CDN, &s.CDNID, &s.ID, &s.HostName, &s.DomainName, &s.IP, &s.ProfileID, &s.ProfileName, &s.Port, &s.HTTPSPort, &s.Type, &s.CacheGroupID, &s.ParentCacheGroupID, &s.SecondaryParentCacheGroupID, &s.ParentCacheGroupType, &s.SecondaryParentCacheGroupType); err != nil {
+	if err := tx.QueryRow(qry, qryParams...).Scan(&s.CDN, &s.CDNID, &s.ID, &s.HostName, &s.DomainName, &s.IP, &s.ProfileID, &s.ProfileName, &s.Port, &s.HTTPSPort, &s.Type, &s.CacheGroupID, &s.CacheGroupName, &s.ParentCacheGroupID, &s.SecondaryParentCacheGroupID, &s.ParentCacheGroupType, &s.SecondaryParentCacheGroupType); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, false, nil
 		}
@@ -618,6 +632,7 @@ SELECT
   s.https_port,
   t.name as type,
   s.cachegroup,
+  cg.name as cachegroup_name,
   COALESCE(cg.parent_cachegroup_id, ` + strconv.Itoa(atscfg.InvalidID) + `) as parent_cachegroup_id,
   COALESCE(cg.secondary_parent_cachegroup_id, ` + strconv.Itoa(atscfg.InvalidID) + `) as secondary_parent_cachegroup_id,
   COALESCE(parentt.name, '') as parent_cachegroup_type,
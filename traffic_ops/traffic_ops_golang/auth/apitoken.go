@@ -0,0 +1,144 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// APITokenAuthScheme is the Authorization header scheme of API tokens.
+const APITokenAuthScheme = "Bearer"
+
+// apiTokenBytes is the number of random bytes in a generated API token.
+const apiTokenBytes = 32
+
+// APITokenLastUsedInterval is how often an API token's last used time is updated. Requests within this interval of the last update don't update it, so authenticating with a token doesn't write to the database on every request.
+const APITokenLastUsedInterval = time.Minute
+
+// GenerateAPIToken returns a new random API token.
+func GenerateAPIToken() (string, error) {
+	bts := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(bts); err != nil {
+		return "", errors.New("reading random bytes: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(bts), nil
+}
+
+// HashAPIToken returns the hash of the given API token. Only the hash of a token is stored, so the database can't be used to authenticate as the token.
+func HashAPIToken(token string) string {
+	hash := sha512.Sum512([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// GetAPIToken returns the API token in the request's Authorization header, and whether the request has one.
+func GetAPIToken(r *http.Request) (string, bool) {
+	authHdr := r.Header.Get("Authorization")
+	if len(authHdr) <= len(APITokenAuthScheme)+1 || !strings.EqualFold(authHdr[:len(APITokenAuthScheme)], APITokenAuthScheme) || authHdr[len(APITokenAuthScheme)] != ' ' {
+		return "", false
+	}
+	token := strings.TrimSpace(authHdr[len(APITokenAuthScheme)+1:])
+	return token, token != ""
+}
+
+// GetCurrentUserFromAPIToken returns the user the given API token was issued to, limited to the token's capabilities if it has any, and updates the token's last used time, at most once every APITokenLastUsedInterval. Expired and revoked tokens are Unauthorized, and tokens of users with the disallowed role are Forbidden, as with logins.
+// Returns the user, any user error, any system error, and the HTTP status code.
+func GetCurrentUserFromAPIToken(db *sqlx.DB, token string, timeout time.Duration) (CurrentUser, error, error, int) {
+	qry := `
+SELECT
+  u.username,
+  t.capabilities,
+  r.name AS role_name,
+  t.last_used
+FROM
+  api_token AS t
+  JOIN tm_user AS u ON u.id = t.tm_user_id
+  JOIN role AS r ON r.id = u.role
+WHERE
+  t.token_hash = $1
+  AND (t.expires IS NULL OR t.expires > now())
+`
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+
+	tokenHash := HashAPIToken(token)
+	userName := ""
+	tokenCaps := []string(nil)
+	roleName := ""
+	lastUsed := (*time.Time)(nil)
+	if err := db.QueryRowContext(dbCtx, qry, tokenHash).Scan(&userName, pq.Array(&tokenCaps), &roleName, &lastUsed); err != nil {
+		if err == sql.ErrNoRows {
+			return CurrentUser{}, errors.New("Unauthorized, invalid or expired API token."), nil, http.StatusUnauthorized
+		}
+		return CurrentUser{}, nil, errors.New("getting API token: " + err.Error()), http.StatusInternalServerError
+	}
+	if roleName == disallowed { //relies on unchanging role name assumption.
+		return CurrentUser{}, errors.New("Forbidden."), nil, http.StatusForbidden
+	}
+
+	if lastUsed == nil || time.Since(*lastUsed) >= APITokenLastUsedInterval {
+		if _, err := db.ExecContext(dbCtx, `UPDATE api_token SET last_used = now() WHERE token_hash = $1`, tokenHash); err != nil {
+			log.Errorln("updating API token last used time: " + err.Error()) // the request is still authorized
+		}
+	}
+
+	user, userErr, sysErr, errCode := GetCurrentUserFromDB(db, userName, timeout)
+	if userErr != nil || sysErr != nil {
+		return user, userErr, sysErr, errCode
+	}
+	if tokenCaps != nil {
+		user.RestrictCapabilities(tokenCaps)
+	}
+	return user, nil, nil, http.StatusOK
+}
+
+// RestrictCapabilities limits the user to the given capabilities. The user keeps only those of their role's capabilities which are also in caps, so they're only authorized for the routes of their privilege level which require none of the others.
+// A LegacyRole is authorized for every capability of its privilege level, so its user is limited to caps.
+func (u *CurrentUser) RestrictCapabilities(caps []string) {
+	if u.LegacyRole {
+		u.Capabilities = append(pq.StringArray{}, caps...)
+		u.LegacyRole = false
+		return
+	}
+	allowed := make(map[string]struct{}, len(caps))
+	for _, cap := range caps {
+		allowed[cap] = struct{}{}
+	}
+	restricted := pq.StringArray{}
+	for _, cap := range u.Capabilities {
+		if _, ok := allowed[cap]; ok {
+			restricted = append(restricted, cap)
+		}
+	}
+	u.Capabilities = restricted
+}
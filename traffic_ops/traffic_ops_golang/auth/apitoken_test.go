@@ -0,0 +1,150 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("expected GenerateAPIToken error nil, actual: %v", err)
+	}
+	other, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("expected GenerateAPIToken error nil, actual: %v", err)
+	}
+	if token == "" || token == other {
+		t.Errorf("expected unique nonempty tokens, actual: '%v' and '%v'", token, other)
+	}
+	if HashAPIToken(token) != HashAPIToken(token) || HashAPIToken(token) == HashAPIToken(other) {
+		t.Errorf("expected token hashes to be deterministic and unique")
+	}
+}
+
+func TestGetAPIToken(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+		ok       bool
+	}{
+		{"Bearer abc123", "abc123", true},
+		{"bearer abc123", "abc123", true},
+		{"Bearer  abc123 ", "abc123", true},
+		{"Bearer ", "", false},
+		{"Bearerabc123", "", false},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		r, err := http.NewRequest(http.MethodGet, "/", nil)
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+		if token, ok := GetAPIToken(r); token != test.expected || ok != test.ok {
+			t.Errorf("header '%v': expected token '%v' %v, actual '%v' %v", test.header, test.expected, test.ok, token, ok)
+		}
+	}
+}
+
+func TestRestrictCapabilities(t *testing.T) {
+	user := CurrentUser{PrivLevel: PrivLevelAdmin, Capabilities: []string{"servers-read", "servers-write", "users-read"}}
+	user.RestrictCapabilities([]string{"servers-read", "users-read", "users-write"})
+	if expected := []string{"servers-read", "users-read"}; !reflect.DeepEqual([]string(user.Capabilities), expected) {
+		t.Errorf("expected restricted capabilities %v, actual %v", expected, user.Capabilities)
+	}
+	if user.IsAuthorized(PrivLevelAdmin, []string{"servers-write"}) {
+		t.Errorf("expected restricted user not to be authorized by a capability of their role outside the restriction")
+	}
+	if !user.IsAuthorized(PrivLevelAdmin, []string{"users-read"}) {
+		t.Errorf("expected restricted user to be authorized by capability")
+	}
+}
+
+func TestRestrictCapabilitiesLegacyRole(t *testing.T) {
+	user := CurrentUser{PrivLevel: PrivLevelOperations, LegacyRole: true}
+	user.RestrictCapabilities([]string{"servers-read"})
+	if !user.IsAuthorized(PrivLevelOperations, []string{"servers-read"}) {
+		t.Errorf("expected restricted legacy role user to be authorized by a restricted capability")
+	}
+	if user.IsAuthorized(PrivLevelOperations, []string{"servers-write"}) {
+		t.Errorf("expected restricted legacy role user not to be authorized by a capability outside the restriction")
+	}
+}
+
+func TestGetCurrentUserFromAPIToken(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	token := "token1"
+	expectToken := func(roleName string, lastUsed interface{}) {
+		rows := sqlmock.NewRows([]string{"username", "capabilities", "role_name", "last_used"})
+		rows.AddRow("user1", nil, roleName, lastUsed)
+		mock.ExpectQuery("FROM api_token").WithArgs(HashAPIToken(token)).WillReturnRows(rows)
+	}
+	expectUser := func() {
+		rows := sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id", "capabilities"})
+		rows.AddRow(PrivLevelOperations, "user1", 1, 1, []byte("{servers-read}"))
+		mock.ExpectQuery("SELECT").WithArgs("user1").WillReturnRows(rows)
+	}
+
+	// a token used recently doesn't update its last used time
+	expectToken("operations", time.Now())
+	expectUser()
+	if user, userErr, sysErr, _ := GetCurrentUserFromAPIToken(db, token, time.Second); userErr != nil || sysErr != nil || user.UserName != "user1" {
+		t.Errorf("recently used token expected user1 and no errors, actual: '%v' %v %v", user.UserName, userErr, sysErr)
+	}
+
+	// a token never used, or not used recently, does
+	for _, lastUsed := range []interface{}{nil, time.Now().Add(-2 * APITokenLastUsedInterval)} {
+		expectToken("operations", lastUsed)
+		mock.ExpectExec("UPDATE api_token SET last_used").WithArgs(HashAPIToken(token)).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectUser()
+		if _, userErr, sysErr, _ := GetCurrentUserFromAPIToken(db, token, time.Second); userErr != nil || sysErr != nil {
+			t.Errorf("token last used %v expected no errors, actual: %v %v", lastUsed, userErr, sysErr)
+		}
+	}
+
+	// the tokens of disallowed users are forbidden, like their logins
+	expectToken(disallowed, time.Now())
+	if _, userErr, _, code := GetCurrentUserFromAPIToken(db, token, time.Second); userErr == nil || code != http.StatusForbidden {
+		t.Errorf("disallowed user's token expected user error and %v, actual: %v and %v", http.StatusForbidden, userErr, code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the last used time to be updated only when not recent, actual: %v", err)
+	}
+}
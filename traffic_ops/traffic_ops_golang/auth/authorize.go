@@ -42,6 +42,8 @@ type CurrentUser struct {
 	TenantID     int            `json:"tenantId" db:"tenant_id"`
 	Role         int            `json:"role" db:"role"`
 	Capabilities pq.StringArray `json:"capabilities" db:"capabilities"`
+	// LegacyRole is whether the user's role has no capabilities, like roles created before capabilities were required, in which case the user is authorized by their privilege level alone.
+	LegacyRole bool `json:"-" db:"legacy_role"`
 }
 
 type PasswordForm struct {
@@ -84,7 +86,8 @@ SELECT
   u.id,
   u.username,
   COALESCE(u.tenant_id, -1) AS tenant_id,
-  ARRAY(SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id=r.id) AS capabilities
+  ARRAY(SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id=r.id) AS capabilities,
+  NOT EXISTS(SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id=r.id) AS legacy_role
 FROM
   tm_user AS u
 JOIN
@@ -95,7 +98,7 @@ WHERE
 
 	var currentUserInfo CurrentUser
 	if DB == nil {
-		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}}, nil, errors.New("no db provided to GetCurrentUserFromDB"), http.StatusInternalServerError
+		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}, false}, nil, errors.New("no db provided to GetCurrentUserFromDB"), http.StatusInternalServerError
 	}
 	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
 	defer dbClose()
@@ -103,11 +106,11 @@ WHERE
 	err := DB.GetContext(dbCtx, &currentUserInfo, qry, user)
 	switch {
 	case err == sql.ErrNoRows:
-		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}}, errors.New("user not found"), fmt.Errorf("checking user %v info: user not in database", user), http.StatusUnauthorized
+		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}, false}, errors.New("user not found"), fmt.Errorf("checking user %v info: user not in database", user), http.StatusUnauthorized
 	case err == context.DeadlineExceeded || err == context.Canceled:
-		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}}, nil, fmt.Errorf("db access timed out: %s number of open connections: %d\n", err, DB.Stats().OpenConnections), http.StatusServiceUnavailable
+		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}, false}, nil, fmt.Errorf("db access timed out: %s number of open connections: %d\n", err, DB.Stats().OpenConnections), http.StatusServiceUnavailable
 	case err != nil:
-		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}}, nil, fmt.Errorf("Error checking user %v info: %v", user, err.Error()), http.StatusInternalServerError
+		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}, false}, nil, fmt.Errorf("Error checking user %v info: %v", user, err.Error()), http.StatusInternalServerError
 	default:
 		return currentUserInfo, nil, nil, http.StatusOK
 	}
@@ -123,7 +126,31 @@ func GetCurrentUser(ctx context.Context) (*CurrentUser, error) {
 			return nil, fmt.Errorf("CurrentUser found with bad type: %T", v)
 		}
 	}
-	return &CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}}, errors.New("No user found in Context")
+	return &CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, -1, []string{}, false}, errors.New("No user found in Context")
+}
+
+// HasCapabilities returns whether the user's role has every one of the given capabilities.
+func (u CurrentUser) HasCapabilities(caps []string) bool {
+	userCaps := make(map[string]struct{}, len(u.Capabilities))
+	for _, cap := range u.Capabilities {
+		userCaps[cap] = struct{}{}
+	}
+	for _, cap := range caps {
+		if _, ok := userCaps[cap]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAuthorized returns whether the user may access a route requiring the given privilege level and capabilities.
+// The user must have at least privLevelRequired, and their role must have every capability in capabilitiesRequired. Capabilities can only narrow what a privilege level allows, so a role with a privilege level may be limited to a subset of its routes.
+// A LegacyRole, which has no capabilities, is authorized by its privilege level alone.
+func (u CurrentUser) IsAuthorized(privLevelRequired int, capabilitiesRequired []string) bool {
+	if u.PrivLevel < privLevelRequired {
+		return false
+	}
+	return u.LegacyRole || u.HasCapabilities(capabilitiesRequired)
 }
 
 func CheckLocalUserIsAllowed(form PasswordForm, db *sqlx.DB, timeout time.Duration) (bool, error, error) {
@@ -0,0 +1,70 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+)
+
+func TestIsAuthorized(t *testing.T) {
+	user := CurrentUser{PrivLevel: PrivLevelReadOnly, Capabilities: []string{"servers-read", "servers-write"}}
+
+	tests := []struct {
+		name     string
+		privLev  int
+		caps     []string
+		expected bool
+	}{
+		{"priv level", PrivLevelReadOnly, nil, true},
+		{"priv level with capabilities", PrivLevelReadOnly, []string{"servers-read", "servers-write"}, true},
+		{"priv level with missing capabilities", PrivLevelReadOnly, []string{"users-write"}, false},
+		{"priv level with some capabilities", PrivLevelReadOnly, []string{"servers-write", "users-write"}, false},
+		{"low priv level without capabilities", PrivLevelOperations, nil, false},
+		{"low priv level with capabilities", PrivLevelOperations, []string{"servers-write"}, false},
+		{"low priv level with all capabilities", PrivLevelOperations, []string{"servers-read", "servers-write"}, false},
+	}
+	for _, test := range tests {
+		if actual := user.IsAuthorized(test.privLev, test.caps); actual != test.expected {
+			t.Errorf("%v: expected IsAuthorized %v, actual %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestIsAuthorizedLegacyRole(t *testing.T) {
+	user := CurrentUser{PrivLevel: PrivLevelFederation, LegacyRole: true}
+
+	tests := []struct {
+		name     string
+		privLev  int
+		caps     []string
+		expected bool
+	}{
+		{"priv level", PrivLevelFederation, nil, true},
+		{"priv level with capabilities", PrivLevelFederation, []string{"auth", "federations-write"}, true},
+		{"lower priv level with capabilities", PrivLevelReadOnly, []string{"federations-read"}, true},
+		{"low priv level", PrivLevelOperations, nil, false},
+		{"low priv level with capabilities", PrivLevelOperations, []string{"servers-write"}, false},
+	}
+	for _, test := range tests {
+		if actual := user.IsAuthorized(test.privLev, test.caps); actual != test.expected {
+			t.Errorf("%v: expected IsAuthorized %v, actual %v", test.name, test.expected, actual)
+		}
+	}
+}
@@ -107,6 +107,9 @@ type ConfigTrafficOpsGolang struct {
 	// CRConfigEmulateOldPath is whether to emulate the legacy CRConfig request path when generating a new CRConfig. This primarily exists in the event a tool relies on the legacy path '/tools/write_crconfig'.
 	// Deprecated: will be removed in the next major version.
 	CRConfigEmulateOldPath bool `json:"crconfig_emulate_old_path"`
+	// CRConfigSnapshotHistoryMax is the maximum number of Snapshots kept in the Snapshot history of each CDN. Older Snapshots are deleted when a new Snapshot is taken.
+	// This defaults to DefaultCRConfigSnapshotHistoryMax.
+	CRConfigSnapshotHistoryMax int `json:"crconfig_snapshot_history_max"`
 }
 
 // RoutingBlacklist contains the list of route IDs that will be handled by TO-Perl, a list of route IDs that are disabled,
@@ -184,6 +187,7 @@ func NewFakeConfig() Config {
 
 const DefaultLDAPTimeoutSecs = 60
 const DefaultDBQueryTimeoutSecs = 20
+const DefaultCRConfigSnapshotHistoryMax = 20
 
 // ErrorLog - critical messages
 func (c Config) ErrorLog() log.LogLocation {
@@ -361,6 +365,9 @@ func ParseConfig(cfg Config) (Config, error) {
 	if cfg.DBQueryTimeoutSeconds == 0 {
 		cfg.DBQueryTimeoutSeconds = DefaultDBQueryTimeoutSecs
 	}
+	if cfg.CRConfigSnapshotHistoryMax <= 0 {
+		cfg.CRConfigSnapshotHistoryMax = DefaultCRConfigSnapshotHistoryMax
+	}
 
 	invalidTOURLStr := ""
 	var err error
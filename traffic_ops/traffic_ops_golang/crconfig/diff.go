@@ -0,0 +1,257 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/monitoring"
+)
+
+// Diff returns the config keys, servers, routers, monitors, delivery services, and locations which were added, removed, or changed from the old CRConfig to the new one.
+func Diff(old *tc.CRConfig, new *tc.CRConfig) (tc.CRConfigDiff, error) {
+	diff := tc.CRConfigDiff{}
+	sections := []struct {
+		name string
+		old  interface{}
+		new  interface{}
+		diff *tc.CRConfigDiffKeys
+	}{
+		{"config", old.Config, new.Config, &diff.Config},
+		{"content servers", old.ContentServers, new.ContentServers, &diff.ContentServers},
+		{"content routers", old.ContentRouters, new.ContentRouters, &diff.ContentRouters},
+		{"delivery services", old.DeliveryServices, new.DeliveryServices, &diff.DeliveryServices},
+		{"edge locations", old.EdgeLocations, new.EdgeLocations, &diff.EdgeLocations},
+		{"router locations", old.RouterLocations, new.RouterLocations, &diff.RouterLocations},
+		{"monitors", old.Monitors, new.Monitors, &diff.Monitors},
+	}
+	for _, section := range sections {
+		keys, err := diffKeys(section.old, section.new)
+		if err != nil {
+			return tc.CRConfigDiff{}, errors.New("diffing " + section.name + ": " + err.Error())
+		}
+		*section.diff = keys
+	}
+	return diff, nil
+}
+
+// DiffMonitoring returns the config keys, servers, monitors, cachegroups, profiles, and delivery services which were added, removed, or changed from the old monitoring config to the new one.
+func DiffMonitoring(old *monitoring.Monitoring, new *monitoring.Monitoring) (tc.MonitoringDiff, error) {
+	diff := tc.MonitoringDiff{}
+	sections := []struct {
+		name string
+		old  interface{}
+		new  interface{}
+		diff *tc.CRConfigDiffKeys
+	}{
+		{"config", old.Config, new.Config, &diff.Config},
+		{"traffic servers", monitoringCachesByName(old.TrafficServers), monitoringCachesByName(new.TrafficServers), &diff.TrafficServers},
+		{"traffic monitors", monitoringMonitorsByName(old.TrafficMonitors), monitoringMonitorsByName(new.TrafficMonitors), &diff.TrafficMonitors},
+		{"cachegroups", monitoringCachegroupsByName(old.Cachegroups), monitoringCachegroupsByName(new.Cachegroups), &diff.CacheGroups},
+		{"profiles", monitoringProfilesByName(old.Profiles), monitoringProfilesByName(new.Profiles), &diff.Profiles},
+		{"delivery services", monitoringDSesByName(old.DeliveryServices), monitoringDSesByName(new.DeliveryServices), &diff.DeliveryServices},
+	}
+	for _, section := range sections {
+		keys, err := diffKeys(section.old, section.new)
+		if err != nil {
+			return tc.MonitoringDiff{}, errors.New("diffing " + section.name + ": " + err.Error())
+		}
+		*section.diff = keys
+	}
+	return diff, nil
+}
+
+// Dangers returns the changes from the old CRConfig to the new one which are likely to cause an outage, such as a delivery service losing all of its available servers.
+func Dangers(old *tc.CRConfig, new *tc.CRConfig) []tc.SnapshotDiffDanger {
+	dangers := []tc.SnapshotDiffDanger{}
+
+	oldDSServers := availableServerCounts(old, serverDSes)
+	newDSServers := availableServerCounts(new, serverDSes)
+	for _, ds := range sortedKeys(old.DeliveryServices) {
+		if _, ok := new.DeliveryServices[ds]; !ok {
+			dangers = append(dangers, tc.SnapshotDiffDanger{Type: tc.SnapshotDiffDangerDSRemoved, Name: ds, Message: "delivery service '" + ds + "' would be removed"})
+			continue
+		}
+		if oldDSServers[ds] > 0 && newDSServers[ds] == 0 {
+			dangers = append(dangers, tc.SnapshotDiffDanger{Type: tc.SnapshotDiffDangerDSNoServers, Name: ds, Message: "delivery service '" + ds + "' would lose all of its " + strconv.Itoa(oldDSServers[ds]) + " available servers"})
+		}
+	}
+
+	oldLocServers := availableServerCounts(old, serverCacheGroup)
+	newLocServers := availableServerCounts(new, serverCacheGroup)
+	for _, loc := range sortedKeys(old.EdgeLocations) {
+		if oldLocServers[loc] > 0 && newLocServers[loc] == 0 {
+			dangers = append(dangers, tc.SnapshotDiffDanger{Type: tc.SnapshotDiffDangerEdgeLocationNoServers, Name: loc, Message: "edge location '" + loc + "' would lose all of its " + strconv.Itoa(oldLocServers[loc]) + " available servers"})
+		}
+	}
+
+	if len(old.ContentRouters) > 0 && len(new.ContentRouters) == 0 {
+		dangers = append(dangers, tc.SnapshotDiffDanger{Type: tc.SnapshotDiffDangerNoRouters, Name: "", Message: "all " + strconv.Itoa(len(old.ContentRouters)) + " traffic routers would be removed"})
+	}
+	return dangers
+}
+
+// availableServerCounts returns the number of servers in the CRConfig which may be routed to, by each of the keys returned by getKeys for the server.
+func availableServerCounts(crc *tc.CRConfig, getKeys func(sv tc.CRConfigTrafficOpsServer) []string) map[string]int {
+	counts := map[string]int{}
+	for _, sv := range crc.ContentServers {
+		if sv.ServerStatus == nil {
+			continue
+		}
+		if status := tc.CacheStatus(*sv.ServerStatus); status != tc.CacheStatusReported && status != tc.CacheStatusOnline {
+			continue
+		}
+		for _, key := range getKeys(sv) {
+			counts[key]++
+		}
+	}
+	return counts
+}
+
+func serverDSes(sv tc.CRConfigTrafficOpsServer) []string {
+	dses := make([]string, 0, len(sv.DeliveryServices))
+	for ds := range sv.DeliveryServices {
+		dses = append(dses, ds)
+	}
+	return dses
+}
+
+func serverCacheGroup(sv tc.CRConfigTrafficOpsServer) []string {
+	if sv.CacheGroup == nil {
+		return nil
+	}
+	return []string{*sv.CacheGroup}
+}
+
+// sortedKeys returns the sorted keys of m, which must be a map with string keys.
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func monitoringCachesByName(caches []monitoring.Cache) map[string]monitoring.Cache {
+	m := make(map[string]monitoring.Cache, len(caches))
+	for _, c := range caches {
+		m[c.HostName] = c
+	}
+	return m
+}
+
+func monitoringMonitorsByName(monitors []monitoring.Monitor) map[string]monitoring.Monitor {
+	m := make(map[string]monitoring.Monitor, len(monitors))
+	for _, mon := range monitors {
+		m[mon.HostName] = mon
+	}
+	return m
+}
+
+func monitoringCachegroupsByName(cgs []monitoring.Cachegroup) map[string]monitoring.Cachegroup {
+	m := make(map[string]monitoring.Cachegroup, len(cgs))
+	for _, cg := range cgs {
+		m[cg.Name] = cg
+	}
+	return m
+}
+
+func monitoringProfilesByName(profiles []monitoring.Profile) map[string]monitoring.Profile {
+	m := make(map[string]monitoring.Profile, len(profiles))
+	for _, p := range profiles {
+		m[p.Name] = p
+	}
+	return m
+}
+
+func monitoringDSesByName(dses []monitoring.DeliveryService) map[string]monitoring.DeliveryService {
+	m := make(map[string]monitoring.DeliveryService, len(dses))
+	for _, ds := range dses {
+		m[ds.XMLID] = ds
+	}
+	return m
+}
+
+// DiffJSON returns the Diff of two serialized CRConfigs, such as those stored in the snapshot table.
+func DiffJSON(old []byte, new []byte) (tc.CRConfigDiff, error) {
+	oldCRC := tc.CRConfig{}
+	if err := json.Unmarshal(old, &oldCRC); err != nil {
+		return tc.CRConfigDiff{}, errors.New("unmarshalling old CRConfig: " + err.Error())
+	}
+	newCRC := tc.CRConfig{}
+	if err := json.Unmarshal(new, &newCRC); err != nil {
+		return tc.CRConfigDiff{}, errors.New("unmarshalling new CRConfig: " + err.Error())
+	}
+	return Diff(&oldCRC, &newCRC)
+}
+
+// diffKeys returns the keys added, removed, and changed between old and new, which must be JSON objects, such as maps.
+// Values are compared by their JSON serialization. Keys are returned sorted.
+func diffKeys(old interface{}, new interface{}) (tc.CRConfigDiffKeys, error) {
+	oldVals, err := toRawMap(old)
+	if err != nil {
+		return tc.CRConfigDiffKeys{}, err
+	}
+	newVals, err := toRawMap(new)
+	if err != nil {
+		return tc.CRConfigDiffKeys{}, err
+	}
+	diff := tc.CRConfigDiffKeys{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for key, newVal := range newVals {
+		oldVal, ok := oldVals[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+			continue
+		}
+		if !bytes.Equal(oldVal, newVal) {
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for key := range oldVals {
+		if _, ok := newVals[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff, nil
+}
+
+// toRawMap serializes v, which must serialize to a JSON object or null, and returns its members as raw JSON.
+// The raw values are compacted, and object keys are sorted by encoding/json, so equal values have equal bytes.
+func toRawMap(v interface{}) (map[string]json.RawMessage, error) {
+	bts, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.New("marshalling: " + err.Error())
+	}
+	m := map[string]json.RawMessage{}
+	if err := json.Unmarshal(bts, &m); err != nil {
+		return nil, errors.New("unmarshalling: " + err.Error())
+	}
+	return m, nil
+}
@@ -0,0 +1,177 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/monitoring"
+)
+
+func TestDiff(t *testing.T) {
+	hostA, hostB, hostC := "a.example.net", "b.example.net", "c.example.net"
+	port, otherPort := 80, 8080
+	old := &tc.CRConfig{
+		Config: map[string]interface{}{
+			"domain_name": "example.net",
+			"ttls":        map[string]interface{}{"A": "60"},
+			"removed.key": "x",
+		},
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"a": tc.CRConfigTrafficOpsServer{Fqdn: &hostA, Port: &port},
+			"b": tc.CRConfigTrafficOpsServer{Fqdn: &hostB, Port: &port},
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"ds0": tc.CRConfigDeliveryService{},
+		},
+	}
+	new := &tc.CRConfig{
+		Config: map[string]interface{}{
+			"domain_name": "example.net",
+			"ttls":        map[string]interface{}{"A": "30"},
+			"added.key":   "y",
+		},
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"a": tc.CRConfigTrafficOpsServer{Fqdn: &hostA, Port: &port},
+			"b": tc.CRConfigTrafficOpsServer{Fqdn: &hostB, Port: &otherPort},
+			"c": tc.CRConfigTrafficOpsServer{Fqdn: &hostC, Port: &port},
+		},
+	}
+
+	none := tc.CRConfigDiffKeys{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	expected := tc.CRConfigDiff{
+		Config:           tc.CRConfigDiffKeys{Added: []string{"added.key"}, Removed: []string{"removed.key"}, Changed: []string{"ttls"}},
+		ContentServers:   tc.CRConfigDiffKeys{Added: []string{"c"}, Removed: []string{}, Changed: []string{"b"}},
+		ContentRouters:   none,
+		DeliveryServices: tc.CRConfigDiffKeys{Added: []string{}, Removed: []string{"ds0"}, Changed: []string{}},
+		EdgeLocations:    none,
+		RouterLocations:  none,
+		Monitors:         none,
+	}
+
+	actual, err := Diff(old, new)
+	if err != nil {
+		t.Fatalf("Diff err expected: nil, actual: %v", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Diff expected: %+v, actual: %+v", expected, actual)
+	}
+
+	oldBts, err := json.Marshal(old)
+	if err != nil {
+		t.Fatalf("marshalling old: %v", err)
+	}
+	newBts, err := json.Marshal(new)
+	if err != nil {
+		t.Fatalf("marshalling new: %v", err)
+	}
+	actual, err = DiffJSON(oldBts, newBts)
+	if err != nil {
+		t.Fatalf("DiffJSON err expected: nil, actual: %v", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("DiffJSON expected: %+v, actual: %+v", expected, actual)
+	}
+
+	actual, err = Diff(old, old)
+	if err != nil {
+		t.Fatalf("Diff err expected: nil, actual: %v", err)
+	}
+	if !actual.Config.Empty() || !actual.ContentServers.Empty() || !actual.DeliveryServices.Empty() {
+		t.Errorf("Diff of identical CRConfigs expected: empty, actual: %+v", actual)
+	}
+}
+
+func TestDiffMonitoring(t *testing.T) {
+	old := &monitoring.Monitoring{
+		TrafficServers: []monitoring.Cache{
+			{BasicServer: monitoring.BasicServer{HostName: "edge0", Status: "REPORTED"}},
+			{BasicServer: monitoring.BasicServer{HostName: "edge1", Status: "REPORTED"}},
+		},
+		Cachegroups: []monitoring.Cachegroup{{Name: "cg0"}},
+	}
+	new := &monitoring.Monitoring{
+		TrafficServers: []monitoring.Cache{
+			{BasicServer: monitoring.BasicServer{HostName: "edge0", Status: "ADMIN_DOWN"}},
+		},
+		Cachegroups: []monitoring.Cachegroup{{Name: "cg0"}, {Name: "cg1"}},
+	}
+
+	actual, err := DiffMonitoring(old, new)
+	if err != nil {
+		t.Fatalf("DiffMonitoring err expected: nil, actual: %v", err)
+	}
+	if expected := (tc.CRConfigDiffKeys{Added: []string{}, Removed: []string{"edge1"}, Changed: []string{"edge0"}}); !reflect.DeepEqual(expected, actual.TrafficServers) {
+		t.Errorf("DiffMonitoring traffic servers expected: %+v, actual: %+v", expected, actual.TrafficServers)
+	}
+	if expected := (tc.CRConfigDiffKeys{Added: []string{"cg1"}, Removed: []string{}, Changed: []string{}}); !reflect.DeepEqual(expected, actual.CacheGroups) {
+		t.Errorf("DiffMonitoring cachegroups expected: %+v, actual: %+v", expected, actual.CacheGroups)
+	}
+	if !actual.Profiles.Empty() || !actual.DeliveryServices.Empty() || !actual.TrafficMonitors.Empty() || !actual.Config.Empty() {
+		t.Errorf("DiffMonitoring expected unchanged sections to be empty, actual: %+v", actual)
+	}
+}
+
+func TestDangers(t *testing.T) {
+	reported := tc.CRConfigServerStatus(tc.CacheStatusReported)
+	adminDown := tc.CRConfigServerStatus(tc.CacheStatusAdminDown)
+	cgA, cgB := "cgA", "cgB"
+	routerFQDN := "tr.example.net"
+
+	old := &tc.CRConfig{
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edgeA": {CacheGroup: &cgA, ServerStatus: &reported, DeliveryServices: map[string][]string{"ds0": nil, "ds1": nil}},
+			"edgeB": {CacheGroup: &cgB, ServerStatus: &reported, DeliveryServices: map[string][]string{"ds1": nil}},
+		},
+		ContentRouters:   map[string]tc.CRConfigRouter{"tr": {FQDN: &routerFQDN}},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{"ds0": {}, "ds1": {}, "ds2": {}},
+		EdgeLocations:    map[string]tc.CRConfigLatitudeLongitude{cgA: {}, cgB: {}},
+	}
+	new := &tc.CRConfig{
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edgeA": {CacheGroup: &cgA, ServerStatus: &adminDown, DeliveryServices: map[string][]string{"ds0": nil, "ds1": nil}},
+			"edgeB": {CacheGroup: &cgB, ServerStatus: &reported, DeliveryServices: map[string][]string{"ds1": nil}},
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{"ds0": {}, "ds1": {}},
+		EdgeLocations:    map[string]tc.CRConfigLatitudeLongitude{cgA: {}, cgB: {}},
+	}
+
+	dangers := Dangers(old, new)
+	actual := map[tc.SnapshotDiffDangerType][]string{}
+	for _, danger := range dangers {
+		actual[danger.Type] = append(actual[danger.Type], danger.Name)
+	}
+	expected := map[tc.SnapshotDiffDangerType][]string{
+		tc.SnapshotDiffDangerDSNoServers:           {"ds0"},
+		tc.SnapshotDiffDangerDSRemoved:             {"ds2"},
+		tc.SnapshotDiffDangerEdgeLocationNoServers: {cgA},
+		tc.SnapshotDiffDangerNoRouters:             {""},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Dangers expected: %+v, actual: %+v", expected, actual)
+	}
+
+	if dangers := Dangers(old, old); len(dangers) != 0 {
+		t.Errorf("Dangers of identical CRConfigs expected: none, actual: %+v", dangers)
+	}
+}
@@ -21,6 +21,7 @@ package crconfig
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/url"
@@ -31,6 +32,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-rfc"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/monitoring"
 )
@@ -55,7 +57,69 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	api.WriteResp(w, r, crConfig)
 }
 
+// SnapshotDiffHandler serves the difference between the current snapshot of a CDN and the snapshot which would be taken now, including changes likely to cause an outage.
+// This generates, but does not write, the CRConfig and monitoring config.
+func SnapshotDiffHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["cdn"]
+	currentCRConfigStr, cdnExists, err := GetSnapshot(inf.Tx.Tx, cdn)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot: "+err.Error()))
+		return
+	}
+	if !cdnExists {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("CDN not found"), nil)
+		return
+	}
+	currentMonitoringStr, _, err := GetSnapshotMonitoring(inf.Tx.Tx, cdn)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting monitoring snapshot: "+err.Error()))
+		return
+	}
+
+	currentCRConfig := tc.CRConfig{}
+	if err := json.Unmarshal([]byte(currentCRConfigStr), &currentCRConfig); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("unmarshalling snapshot: "+err.Error()))
+		return
+	}
+	currentMonitoring := monitoring.Monitoring{}
+	if err := json.Unmarshal([]byte(currentMonitoringStr), &currentMonitoring); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("unmarshalling monitoring snapshot: "+err.Error()))
+		return
+	}
+
+	newCRConfig, err := Make(inf.Tx.Tx, cdn, inf.User.UserName, r.Host, r.URL.Path, inf.Config.Version, inf.Config.CRConfigUseRequestHost, inf.Config.CRConfigEmulateOldPath)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	newMonitoring, err := monitoring.GetMonitoringJSON(inf.Tx.Tx, cdn)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting monitoring.json data: "+err.Error()))
+		return
+	}
+
+	diff := tc.SnapshotPendingDiff{}
+	if diff.CRConfig, err = Diff(&currentCRConfig, newCRConfig); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("diffing CRConfig: "+err.Error()))
+		return
+	}
+	if diff.Monitoring, err = DiffMonitoring(&currentMonitoring, newMonitoring); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("diffing monitoring: "+err.Error()))
+		return
+	}
+	diff.Dangers = Dangers(&currentCRConfig, newCRConfig)
+	api.WriteResp(w, r, diff)
+}
+
 // SnapshotGetHandler gets and serves the CRConfig from the snapshot table.
+// It responds 304 Not Modified if the client already has the snapshot, per its If-None-Match or If-Modified-Since headers.
 func SnapshotGetHandler(w http.ResponseWriter, r *http.Request) {
 	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn"}, nil)
 	if userErr != nil || sysErr != nil {
@@ -73,11 +137,17 @@ func SnapshotGetHandler(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("CDN not found"), nil)
 		return
 	}
+	lastUpdated, err := GetSnapshotLastUpdated(inf.Tx.Tx, inf.Params["cdn"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot: "+err.Error()))
+		return
+	}
 	w.Header().Set(rfc.ContentType, rfc.ApplicationJSON)
-	w.Write([]byte(`{"response":` + snapshot + `}`))
+	api.WriteConditional(w, r, []byte(`{"response":`+snapshot+`}`), lastUpdated)
 }
 
 // SnapshotGetMonitoringHandler gets and serves the CRConfig from the snapshot table.
+// It responds 304 Not Modified if the client already has the snapshot, per its If-None-Match or If-Modified-Since headers.
 func SnapshotGetMonitoringHandler(w http.ResponseWriter, r *http.Request) {
 	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn"}, nil)
 	if userErr != nil || sysErr != nil {
@@ -95,8 +165,13 @@ func SnapshotGetMonitoringHandler(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("CDN not found"), nil)
 		return
 	}
+	lastUpdated, err := GetSnapshotLastUpdated(inf.Tx.Tx, inf.Params["cdn"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot: "+err.Error()))
+		return
+	}
 	w.Header().Set(rfc.ContentType, rfc.ApplicationJSON)
-	w.Write([]byte(`{"response":` + snapshot + `}`))
+	api.WriteConditional(w, r, []byte(`{"response":`+snapshot+`}`), lastUpdated)
 }
 
 // SnapshotOldGetHandler gets and serves the CRConfig from the snapshot table, not wrapped in response to match the old non-API CRConfig-Snapshots endpoint
@@ -117,8 +192,13 @@ func SnapshotOldGetHandler(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("CDN not found"), nil)
 		return
 	}
+	lastUpdated, err := GetSnapshotLastUpdated(inf.Tx.Tx, inf.Params["cdn"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot: "+err.Error()))
+		return
+	}
 	w.Header().Set(rfc.ContentType, rfc.ApplicationJSON)
-	w.Write([]byte(snapshot))
+	api.WriteConditional(w, r, []byte(snapshot), lastUpdated)
 }
 
 // SnapshotHandler creates the CRConfig JSON and writes it to the snapshot table in the database.
@@ -167,7 +247,7 @@ func SnapshotHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := Snapshot(inf.Tx.Tx, crConfig, monitoringJSON); err != nil {
+	if err := Snapshot(inf.Tx.Tx, crConfig, monitoringJSON, inf.Config.CRConfigSnapshotHistoryMax); err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New(r.RemoteAddr+" snaphsotting CRConfig and Monitoring: "+err.Error()))
 		return
 	}
@@ -181,6 +261,120 @@ func SnapshotHandler(w http.ResponseWriter, r *http.Request) {
 	api.WriteResp(w, r, "SUCCESS")
 }
 
+// SnapshotHistoryHandler serves the snapshot history of a CDN, without the content of each snapshot.
+func SnapshotHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["cdn"]
+	if ok, err := dbhelpers.CDNExists(cdn, inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking CDN existence: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("CDN not found"), nil)
+		return
+	}
+
+	entries, err := GetSnapshotHistory(inf.Tx.Tx, cdn)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot history: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, entries)
+}
+
+// SnapshotHistoryGetHandler serves a single snapshot from the snapshot history of a CDN, including its CRConfig and monitoring content.
+func SnapshotHistoryGetHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn", "id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	entry, ok, err := GetSnapshotHistoryDetail(inf.Tx.Tx, inf.Params["cdn"], inf.IntParams["id"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot history: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot not found"), nil)
+		return
+	}
+	api.WriteResp(w, r, entry)
+}
+
+// SnapshotHistoryDiffHandler serves the difference between two snapshots in the snapshot history of a CDN, given by the 'from' and 'to' query parameters.
+func SnapshotHistoryDiffHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn", "from", "to"}, []string{"from", "to"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["cdn"]
+	from, ok, err := GetSnapshotHistoryDetail(inf.Tx.Tx, cdn, inf.IntParams["from"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot history: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot 'from' not found"), nil)
+		return
+	}
+	to, ok, err := GetSnapshotHistoryDetail(inf.Tx.Tx, cdn, inf.IntParams["to"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot history: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot 'to' not found"), nil)
+		return
+	}
+
+	diff, err := DiffJSON(from.CRConfig, to.CRConfig)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("diffing snapshots: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, tc.SnapshotHistoryDiff{From: from.ID, To: to.ID, Diff: diff})
+}
+
+// SnapshotHistoryRollbackHandler writes a snapshot from the snapshot history of a CDN back to the snapshot table, making it the current snapshot.
+func SnapshotHistoryRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn", "id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["cdn"]
+	id := inf.IntParams["id"]
+	entry, ok, err := GetSnapshotHistoryDetail(inf.Tx.Tx, cdn, id)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot history: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot not found"), nil)
+		return
+	}
+
+	if err := Rollback(inf.Tx.Tx, entry, inf.User.UserName, inf.Config.CRConfigSnapshotHistoryMax); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New(r.RemoteAddr+" rolling back CRConfig and Monitoring: "+err.Error()))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "CDN: "+cdn+", ID: "+strconv.Itoa(id)+", ACTION: Rollback of CRConfig and Monitor to Snapshot from "+entry.LastUpdated.Time.Format(time.RFC3339), inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "CDN "+cdn+" rolled back to snapshot "+strconv.Itoa(id))
+}
+
 // SnapshotGUIHandler creates the CRConfig JSON and writes it to the snapshot table in the database. The response emulates the old Perl UI function. This should go away when the old Perl UI ceases to exist.
 func SnapshotOldGUIHandler(w http.ResponseWriter, r *http.Request) {
 	inf, userErr, sysErr, _ := api.NewInfo(r, []string{"cdn"}, nil)
@@ -210,7 +404,7 @@ func SnapshotOldGUIHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := Snapshot(inf.Tx.Tx, crConfig, tm); err != nil {
+	if err := Snapshot(inf.Tx.Tx, crConfig, tm, inf.Config.CRConfigSnapshotHistoryMax); err != nil {
 		writePerlHTMLErr(w, r, inf.Tx.Tx, errors.New(r.RemoteAddr+" making CRConfig: "+err.Error()), err)
 		return
 	}
@@ -0,0 +1,148 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// addSnapshotHistory adds the given CRConfig and monitoring JSON to the snapshot history of the CDN, and deletes the oldest history entries beyond historyMax.
+func addSnapshotHistory(tx *sql.Tx, cdn string, crconfig []byte, monitoring []byte, user string, date time.Time, historyMax int) error {
+	qry := `
+INSERT INTO snapshot_history (cdn, crconfig, monitoring, username, last_updated)
+VALUES ($1, $2, $3, $4, $5)
+`
+	if _, err := tx.Exec(qry, cdn, crconfig, monitoring, user, date); err != nil {
+		return errors.New("inserting snapshot history: " + err.Error())
+	}
+	if historyMax <= 0 {
+		return nil
+	}
+	delQry := `
+DELETE FROM snapshot_history
+WHERE cdn = $1
+AND id NOT IN (
+  SELECT id FROM snapshot_history WHERE cdn = $1 ORDER BY id DESC LIMIT $2
+)
+`
+	if _, err := tx.Exec(delQry, cdn, historyMax); err != nil {
+		return errors.New("deleting old snapshot history: " + err.Error())
+	}
+	return nil
+}
+
+// GetSnapshotHistory returns the snapshot history entries of the given CDN, newest first, without their content.
+func GetSnapshotHistory(tx *sql.Tx, cdn string) ([]tc.SnapshotHistoryEntry, error) {
+	qry := `
+SELECT id, cdn, username, last_updated
+FROM snapshot_history
+WHERE cdn = $1
+ORDER BY id DESC
+`
+	rows, err := tx.Query(qry, cdn)
+	if err != nil {
+		return nil, errors.New("querying snapshot history: " + err.Error())
+	}
+	defer rows.Close()
+	entries := []tc.SnapshotHistoryEntry{}
+	for rows.Next() {
+		e := tc.SnapshotHistoryEntry{}
+		if err := rows.Scan(&e.ID, &e.CDN, &e.User, &e.LastUpdated); err != nil {
+			return nil, errors.New("scanning snapshot history: " + err.Error())
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// GetSnapshotHistoryDetail returns the snapshot history entry with the given ID of the given CDN, including its content.
+// If no such entry exists, false is returned.
+func GetSnapshotHistoryDetail(tx *sql.Tx, cdn string, id int) (tc.SnapshotHistoryDetail, bool, error) {
+	qry := `
+SELECT id, cdn, username, last_updated, crconfig, monitoring
+FROM snapshot_history
+WHERE cdn = $1 AND id = $2
+`
+	d := tc.SnapshotHistoryDetail{}
+	crconfig := []byte{}
+	monitoring := []byte{}
+	if err := tx.QueryRow(qry, cdn, id).Scan(&d.ID, &d.CDN, &d.User, &d.LastUpdated, &crconfig, &monitoring); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.SnapshotHistoryDetail{}, false, nil
+		}
+		return tc.SnapshotHistoryDetail{}, false, errors.New("querying snapshot history: " + err.Error())
+	}
+	d.CRConfig = crconfig
+	d.Monitoring = monitoring
+	return d, true, nil
+}
+
+// Rollback writes the given snapshot history entry back to the snapshot table as the current snapshot of its CDN.
+// The CRConfig's stats date and user are set to the time of the rollback and the given user, because Traffic Routers ignore a CRConfig which isn't newer than the one they have.
+// The rollback itself is added to the snapshot history, as the given user, so it may be rolled back in turn.
+func Rollback(tx *sql.Tx, entry tc.SnapshotHistoryDetail, user string, historyMax int) error {
+	date := time.Unix(time.Now().Unix(), 0)
+	crconfig, err := restampCRConfig(entry.CRConfig, user, date)
+	if err != nil {
+		return errors.New("restamping CRConfig: " + err.Error())
+	}
+	if err := writeSnapshot(tx, entry.CDN, crconfig, entry.Monitoring, date); err != nil {
+		return err
+	}
+	if err := addSnapshotHistory(tx, entry.CDN, crconfig, entry.Monitoring, user, date, historyMax); err != nil {
+		return errors.New("adding snapshot to history: " + err.Error())
+	}
+	return nil
+}
+
+// restampCRConfig returns the given CRConfig JSON with its stats date and user set to the given values. All other fields are kept as they are, including any this version of Traffic Ops doesn't know.
+func restampCRConfig(crconfig []byte, user string, date time.Time) ([]byte, error) {
+	crc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(crconfig, &crc); err != nil {
+		return nil, errors.New("unmarshalling CRConfig: " + err.Error())
+	}
+	stats := map[string]json.RawMessage{}
+	if statsBts, ok := crc["stats"]; ok && string(statsBts) != "null" {
+		if err := json.Unmarshal(statsBts, &stats); err != nil {
+			return nil, errors.New("unmarshalling CRConfig stats: " + err.Error())
+		}
+	}
+	dateBts, err := json.Marshal(date.Unix())
+	if err != nil {
+		return nil, errors.New("marshalling date: " + err.Error())
+	}
+	userBts, err := json.Marshal(user)
+	if err != nil {
+		return nil, errors.New("marshalling user: " + err.Error())
+	}
+	stats["date"] = dateBts
+	stats["tm_user"] = userBts
+	statsBts, err := json.Marshal(stats)
+	if err != nil {
+		return nil, errors.New("marshalling CRConfig stats: " + err.Error())
+	}
+	crc["stats"] = statsBts
+	return json.Marshal(crc)
+}
@@ -0,0 +1,322 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// RestampedCRConfig matches CRConfig JSON with the given stats user, a stats date at or after the given time, and the given config.
+type RestampedCRConfig struct {
+	User   string
+	After  time.Time
+	Config map[string]interface{}
+}
+
+// Match satisfies sqlmock.Argument interface
+func (a RestampedCRConfig) Match(v driver.Value) bool {
+	bts, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	crc := struct {
+		Config map[string]interface{} `json:"config"`
+		Stats  tc.CRConfigStats       `json:"stats"`
+	}{}
+	if err := json.Unmarshal(bts, &crc); err != nil {
+		return false
+	}
+	if crc.Stats.TMUser == nil || *crc.Stats.TMUser != a.User {
+		return false
+	}
+	if crc.Stats.DateUnixSeconds == nil || *crc.Stats.DateUnixSeconds < a.After.Unix() {
+		return false
+	}
+	if crc.Stats.CDNName == nil || *crc.Stats.CDNName != "mycdn" {
+		return false // other stats must be kept
+	}
+	for k, v := range a.Config {
+		if crc.Config[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRestampCRConfig(t *testing.T) {
+	date := time.Unix(1574251200, 0)
+	actual, err := restampCRConfig([]byte(`{"config":{"a":"b"},"stats":{"CDN_name":"mycdn","date":1500000000,"tm_user":"olduser","unknown":1},"unknownKey":[1,2]}`), "newuser", date)
+	if err != nil {
+		t.Fatalf("restampCRConfig err expected: nil, actual: %v", err)
+	}
+	expected := `{"config":{"a":"b"},"stats":{"CDN_name":"mycdn","date":1574251200,"tm_user":"newuser","unknown":1},"unknownKey":[1,2]}`
+	if string(actual) != expected {
+		t.Errorf("restampCRConfig expected: %s, actual: %s", expected, actual)
+	}
+
+	actual, err = restampCRConfig([]byte(`{"config":{}}`), "newuser", date)
+	if err != nil {
+		t.Fatalf("restampCRConfig without stats err expected: nil, actual: %v", err)
+	}
+	expected = `{"config":{},"stats":{"date":1574251200,"tm_user":"newuser"}}`
+	if string(actual) != expected {
+		t.Errorf("restampCRConfig without stats expected: %s, actual: %s", expected, actual)
+	}
+
+	if _, err := restampCRConfig([]byte(`not json`), "newuser", date); err == nil {
+		t.Errorf("restampCRConfig invalid JSON err expected: not nil, actual: nil")
+	}
+}
+
+func historyEntry() tc.SnapshotHistoryDetail {
+	e := tc.SnapshotHistoryDetail{
+		CRConfig:   []byte(`{"config":{"a":"b"},"stats":{"CDN_name":"mycdn","date":1500000000,"tm_user":"olduser"}}`),
+		Monitoring: []byte(`{"trafficServers":[]}`),
+	}
+	e.ID = 3
+	e.CDN = "mycdn"
+	e.User = "olduser"
+	e.LastUpdated = tc.TimeNoMod{Time: time.Unix(1500000000, 0)}
+	return e
+}
+
+func TestRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	entry := historyEntry()
+	user := "newuser"
+	historyMax := 5
+	start := time.Now().Add(-time.Second)
+	expectedCRConfig := RestampedCRConfig{User: user, After: start, Config: map[string]interface{}{"a": "b"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("insert").WithArgs(entry.CDN, expectedCRConfig, AnyTime{}, []byte(entry.Monitoring)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO snapshot_history").WithArgs(entry.CDN, expectedCRConfig, []byte(entry.Monitoring), user, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM snapshot_history").WithArgs(entry.CDN, historyMax).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("creating transaction: %v", err)
+	}
+	if err := Rollback(tx, entry, user, historyMax); err != nil {
+		t.Fatalf("Rollback err expected: nil, actual: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("committing transaction: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Rollback expected queries: %v", err)
+	}
+}
+
+var historyTestUser = auth.CurrentUser{UserName: "newuser", ID: 1, PrivLevel: auth.PrivLevelAdmin, TenantID: 1, Role: 1}
+
+// historyTestRequest returns a request to the given handler, with the given path params and the context the API handler wrappers would add.
+// Error status codes are written by the wrappers, so tests check error responses by their alerts.
+func historyTestRequest(t *testing.T, db *sqlx.DB, method string, path string, pathParams map[string]string) *http.Request {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+	ctx := req.Context()
+	ctx = context.WithValue(ctx, api.DBContextKey, db)
+	conf := config.Config{}
+	conf.ConfigTrafficOpsGolang.DBQueryTimeoutSeconds = 100
+	conf.CRConfigSnapshotHistoryMax = 5
+	ctx = context.WithValue(ctx, api.ConfigContextKey, &conf)
+	ctx = context.WithValue(ctx, api.ReqIDContextKey, uint64(1))
+	ctx = context.WithValue(ctx, auth.CurrentUserKey, historyTestUser)
+	ctx = context.WithValue(ctx, api.PathParamsKey, pathParams)
+	return req.WithContext(ctx)
+}
+
+func historyEntryRows(entries ...tc.SnapshotHistoryDetail) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "cdn", "username", "last_updated", "crconfig", "monitoring"})
+	for _, e := range entries {
+		rows = rows.AddRow(e.ID, e.CDN, e.User, e.LastUpdated.Time, []byte(e.CRConfig), []byte(e.Monitoring))
+	}
+	return rows
+}
+
+func TestSnapshotHistoryHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+
+	entry := historyEntry()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM cdn").WithArgs("mycdn").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("FROM snapshot_history").WithArgs("mycdn").WillReturnRows(sqlmock.NewRows([]string{"id", "cdn", "username", "last_updated"}).AddRow(entry.ID, entry.CDN, entry.User, entry.LastUpdated.Time))
+	mock.ExpectCommit()
+
+	w := httptest.NewRecorder()
+	SnapshotHistoryHandler(w, historyTestRequest(t, db, http.MethodGet, "/api/1.4/cdns/mycdn/snapshot/history", map[string]string{"cdn": "mycdn"}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("SnapshotHistoryHandler code expected: %v, actual: %v body %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	resp := tc.SnapshotHistoryResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("SnapshotHistoryHandler decoding response: %v", err)
+	}
+	if len(resp.Response) != 1 || resp.Response[0].ID != entry.ID || resp.Response[0].User != entry.User {
+		t.Errorf("SnapshotHistoryHandler expected: [%+v], actual: %+v", entry.SnapshotHistoryEntry, resp.Response)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM cdn").WithArgs("nocdn").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	w = httptest.NewRecorder()
+	SnapshotHistoryHandler(w, historyTestRequest(t, db, http.MethodGet, "/api/1.4/cdns/nocdn/snapshot/history", map[string]string{"cdn": "nocdn"}))
+	if !strings.Contains(w.Body.String(), "CDN not found") {
+		t.Errorf("SnapshotHistoryHandler nonexistent CDN expected: not found error, actual: %s", w.Body.String())
+	}
+}
+
+func TestSnapshotHistoryGetHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+
+	entry := historyEntry()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM snapshot_history").WithArgs("mycdn", entry.ID).WillReturnRows(historyEntryRows(entry))
+	mock.ExpectCommit()
+
+	w := httptest.NewRecorder()
+	SnapshotHistoryGetHandler(w, historyTestRequest(t, db, http.MethodGet, "/api/1.4/cdns/mycdn/snapshot/history/3", map[string]string{"cdn": "mycdn", "id": "3"}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("SnapshotHistoryGetHandler code expected: %v, actual: %v body %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	resp := tc.SnapshotHistoryDetailResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("SnapshotHistoryGetHandler decoding response: %v", err)
+	}
+	if resp.Response.ID != entry.ID || string(resp.Response.CRConfig) != string(entry.CRConfig) || string(resp.Response.Monitoring) != string(entry.Monitoring) {
+		t.Errorf("SnapshotHistoryGetHandler expected: %+v, actual: %+v", entry, resp.Response)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM snapshot_history").WithArgs("mycdn", 4).WillReturnRows(historyEntryRows())
+	mock.ExpectRollback()
+
+	w = httptest.NewRecorder()
+	SnapshotHistoryGetHandler(w, historyTestRequest(t, db, http.MethodGet, "/api/1.4/cdns/mycdn/snapshot/history/4", map[string]string{"cdn": "mycdn", "id": "4"}))
+	if !strings.Contains(w.Body.String(), "snapshot not found") {
+		t.Errorf("SnapshotHistoryGetHandler nonexistent snapshot expected: not found error, actual: %s", w.Body.String())
+	}
+}
+
+func TestSnapshotHistoryDiffHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+
+	from := historyEntry()
+	to := historyEntry()
+	to.ID = 4
+	to.CRConfig = []byte(`{"config":{"a":"c"},"stats":{"CDN_name":"mycdn","date":1500000001,"tm_user":"olduser"}}`)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM snapshot_history").WithArgs("mycdn", from.ID).WillReturnRows(historyEntryRows(from))
+	mock.ExpectQuery("FROM snapshot_history").WithArgs("mycdn", to.ID).WillReturnRows(historyEntryRows(to))
+	mock.ExpectCommit()
+
+	w := httptest.NewRecorder()
+	SnapshotHistoryDiffHandler(w, historyTestRequest(t, db, http.MethodGet, "/api/1.4/cdns/mycdn/snapshot/history/diff?from=3&to=4", map[string]string{"cdn": "mycdn"}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("SnapshotHistoryDiffHandler code expected: %v, actual: %v body %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	resp := tc.SnapshotHistoryDiffResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("SnapshotHistoryDiffHandler decoding response: %v", err)
+	}
+	if resp.Response.From != from.ID || resp.Response.To != to.ID {
+		t.Errorf("SnapshotHistoryDiffHandler expected from %v to %v, actual: from %v to %v", from.ID, to.ID, resp.Response.From, resp.Response.To)
+	}
+}
+
+func TestSnapshotHistoryRollbackHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+
+	entry := historyEntry()
+	expectedCRConfig := RestampedCRConfig{User: historyTestUser.UserName, After: time.Now().Add(-time.Second), Config: map[string]interface{}{"a": "b"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM snapshot_history").WithArgs("mycdn", entry.ID).WillReturnRows(historyEntryRows(entry))
+	mock.ExpectExec("insert").WithArgs(entry.CDN, expectedCRConfig, AnyTime{}, []byte(entry.Monitoring)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO snapshot_history").WithArgs(entry.CDN, expectedCRConfig, []byte(entry.Monitoring), historyTestUser.UserName, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM snapshot_history").WithArgs(entry.CDN, 5).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO log").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	w := httptest.NewRecorder()
+	SnapshotHistoryRollbackHandler(w, historyTestRequest(t, db, http.MethodPost, "/api/1.4/cdns/mycdn/snapshot/history/3/rollback", map[string]string{"cdn": "mycdn", "id": "3"}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("SnapshotHistoryRollbackHandler code expected: %v, actual: %v body %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("SnapshotHistoryRollbackHandler expected queries: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM snapshot_history").WithArgs("mycdn", 4).WillReturnRows(historyEntryRows())
+	mock.ExpectRollback()
+
+	w = httptest.NewRecorder()
+	SnapshotHistoryRollbackHandler(w, historyTestRequest(t, db, http.MethodPost, "/api/1.4/cdns/mycdn/snapshot/history/4/rollback", map[string]string{"cdn": "mycdn", "id": "4"}))
+	if !strings.Contains(w.Body.String(), "snapshot not found") {
+		t.Errorf("SnapshotHistoryRollbackHandler nonexistent snapshot expected: not found error, actual: %s", w.Body.String())
+	}
+}
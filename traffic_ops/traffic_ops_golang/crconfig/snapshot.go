@@ -32,7 +32,8 @@ import (
 
 // Snapshot takes the CRConfig JSON-serializable object (which may be generated via crconfig.Make), and writes it to the snapshot table.
 // It also takes the monitoring config JSON and writes it to the snapshot table.
-func Snapshot(tx *sql.Tx, crc *tc.CRConfig, monitoringJSON *monitoring.Monitoring) error {
+// The snapshot is also added to the CDN's snapshot history, keeping at most historyMax snapshots.
+func Snapshot(tx *sql.Tx, crc *tc.CRConfig, monitoringJSON *monitoring.Monitoring, historyMax int) error {
 	log.Debugln("calling Snapshot")
 	bts, err := json.Marshal(crc)
 	if err != nil {
@@ -48,9 +49,29 @@ func Snapshot(tx *sql.Tx, crc *tc.CRConfig, monitoringJSON *monitoring.Monitorin
 		return errors.New("marshalling JSON: " + err.Error())
 	}
 
+	cdn := ""
+	if crc.Stats.CDNName != nil {
+		cdn = *crc.Stats.CDNName
+	}
+	user := ""
+	if crc.Stats.TMUser != nil {
+		user = *crc.Stats.TMUser
+	}
+
 	log.Debugf("calling Snapshot, writing %+v\n", date)
+	if err := writeSnapshot(tx, cdn, bts, btstm, date); err != nil {
+		return err
+	}
+	if err := addSnapshotHistory(tx, cdn, bts, btstm, user, date, historyMax); err != nil {
+		return errors.New("adding snapshot to history: " + err.Error())
+	}
+	return nil
+}
+
+// writeSnapshot writes the given CRConfig and monitoring JSON to the snapshot table, replacing the CDN's existing snapshot.
+func writeSnapshot(tx *sql.Tx, cdn string, crconfig []byte, monitoring []byte, date time.Time) error {
 	q := `insert into snapshot (cdn, crconfig, last_updated, monitoring) values ($1, $2, $3, $4) on conflict(cdn) do update set crconfig=$2, last_updated=$3, monitoring=$4`
-	if _, err := tx.Exec(q, crc.Stats.CDNName, bts, date, btstm); err != nil {
+	if _, err := tx.Exec(q, cdn, crconfig, date, monitoring); err != nil {
 		return errors.New("Error inserting the crconfig and monitoring snapshot into database: " + err.Error())
 	}
 	return nil
@@ -115,3 +136,17 @@ WHERE c.name = $1
 	}
 	return monitorSnapshot.String, true, nil
 }
+
+// GetSnapshotLastUpdated gets the time the snapshot of the given CDN was last written, shared by its CRConfig and monitoring snapshots.
+// If the CDN or its snapshot does not exist, the zero time is returned.
+// An error is only returned on database error, never if the CDN or snapshot does not exist.
+func GetSnapshotLastUpdated(tx *sql.Tx, cdn string) (time.Time, error) {
+	lastUpdated := time.Time{}
+	if err := tx.QueryRow(`SELECT last_updated FROM snapshot WHERE cdn = $1`, cdn).Scan(&lastUpdated); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, errors.New("querying snapshot last updated: " + err.Error())
+	}
+	return lastUpdated, nil
+}
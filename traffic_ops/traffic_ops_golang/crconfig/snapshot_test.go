@@ -89,6 +89,44 @@ func TestGetSnapshot(t *testing.T) {
 	}
 }
 
+func TestGetSnapshotLastUpdated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cdn := "mycdn"
+	expected := time.Date(2019, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs(cdn).WillReturnRows(sqlmock.NewRows([]string{"last_updated"}).AddRow(expected))
+	mock.ExpectQuery("SELECT").WithArgs(cdn).WillReturnRows(sqlmock.NewRows([]string{"last_updated"}))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("creating transaction: %v", err)
+	}
+	defer tx.Commit()
+
+	actual, err := GetSnapshotLastUpdated(tx, cdn)
+	if err != nil {
+		t.Fatalf("GetSnapshotLastUpdated err expected: nil, actual: %v", err)
+	}
+	if !actual.Equal(expected) {
+		t.Errorf("GetSnapshotLastUpdated expected: %v, actual: %v", expected, actual)
+	}
+
+	actual, err = GetSnapshotLastUpdated(tx, cdn)
+	if err != nil {
+		t.Fatalf("GetSnapshotLastUpdated without a snapshot err expected: nil, actual: %v", err)
+	}
+	if !actual.IsZero() {
+		t.Errorf("GetSnapshotLastUpdated without a snapshot expected: zero time, actual: %v", actual)
+	}
+}
+
 type AnyTime struct{}
 
 // Match satisfies sqlmock.Argument interface
@@ -104,8 +142,10 @@ func (a Any) Match(v driver.Value) bool {
 	return true
 }
 
-func MockSnapshot(mock sqlmock.Sqlmock, expected []byte, expectedtm []byte, cdn string) {
+func MockSnapshot(mock sqlmock.Sqlmock, expected []byte, expectedtm []byte, cdn string, user string, historyMax int) {
 	mock.ExpectExec("insert").WithArgs(cdn, expected, AnyTime{}, expectedtm).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO snapshot_history").WithArgs(cdn, expected, expectedtm, user, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM snapshot_history").WithArgs(cdn, historyMax).WillReturnResult(sqlmock.NewResult(0, 0))
 }
 
 func TestSnapshot(t *testing.T) {
@@ -116,9 +156,12 @@ func TestSnapshot(t *testing.T) {
 	defer db.Close()
 
 	cdn := "mycdn"
+	user := "myuser"
+	historyMax := 5
 
 	crc := &tc.CRConfig{}
 	crc.Stats.CDNName = &cdn
+	crc.Stats.TMUser = &user
 	mock.ExpectBegin()
 
 	dbCtx, _ := context.WithTimeout(context.TODO(), time.Duration(10)*time.Second)
@@ -138,12 +181,12 @@ func TestSnapshot(t *testing.T) {
 	}
 
 	tm, _ := monitoring.GetMonitoringJSON(tx, *crc.Stats.CDNName)
-	MockSnapshot(mock, expected, expectedtm, cdn)
+	MockSnapshot(mock, expected, expectedtm, cdn, user, historyMax)
 	mock.ExpectCommit()
 
 	defer tx.Commit()
 
-	if err := Snapshot(tx, crc, tm); err != nil {
+	if err := Snapshot(tx, crc, tm, historyMax); err != nil {
 		t.Fatalf("GetSnapshot err expected: nil, actual: %v", err)
 	}
 }
@@ -108,21 +108,40 @@ func BuildWhereAndOrderByAndPagination(parameters map[string]string, queryParams
 
 	if orderby, ok := parameters["orderby"]; ok {
 		log.Debugln("orderby: ", orderby)
-		if colInfo, ok := queryParamsToSQLCols[orderby]; ok {
+		// orderby may be a comma-delimited list of columns, and sortOrder a single order for all of them, or a list with one for each
+		orderbyCols := SplitList(orderby)
+		sortOrders := SplitList(parameters["sortOrder"])
+		if len(sortOrders) > 1 && len(sortOrders) != len(orderbyCols) {
+			errs = append(errs, errors.New("sortOrder must have one value, or one for each orderby column"))
+			return "", "", "", queryValues, errs
+		}
+		orderByCols := []string{}
+		for i, col := range orderbyCols {
+			colInfo, ok := queryParamsToSQLCols[col]
+			if !ok {
+				log.Debugln("This column is not configured to support orderby: ", col)
+				continue
+			}
 			log.Debugln("orderby column ", colInfo)
-			orderBy += " " + colInfo.Column
+			orderByCol := colInfo.Column
 
 			// if orderby is specified and valid, also check for sortOrder
-			if sortOrder, exists := parameters["sortOrder"]; exists {
-				log.Debugln("sortOrder: ", sortOrder)
-				if sortOrder == "desc" {
-					orderBy += " DESC"
-				} else if sortOrder != "asc" {
-					log.Debugln("sortOrder value must be desc or asc. Invalid value provided: ", sortOrder)
-				}
+			sortOrder := ""
+			if len(sortOrders) == 1 {
+				sortOrder = sortOrders[0]
+			} else if len(sortOrders) > 1 {
+				sortOrder = sortOrders[i]
+			}
+			log.Debugln("sortOrder: ", sortOrder)
+			if sortOrder == "desc" {
+				orderByCol += " DESC"
+			} else if sortOrder != "asc" && sortOrder != "" {
+				log.Debugln("sortOrder value must be desc or asc. Invalid value provided: ", sortOrder)
 			}
-		} else {
-			log.Debugln("This column is not configured to support orderby: ", orderby)
+			orderByCols = append(orderByCols, orderByCol)
+		}
+		if len(orderByCols) > 0 {
+			orderBy += " " + strings.Join(orderByCols, ", ")
 		}
 	}
 
@@ -167,6 +186,17 @@ func BuildWhereAndOrderByAndPagination(parameters map[string]string, queryParams
 	return whereClause, orderBy, paginationClause, queryValues, errs
 }
 
+// SplitList returns the nonempty values of the comma-delimited list, such as a query parameter.
+func SplitList(list string) []string {
+	vals := []string{}
+	for _, val := range strings.Split(list, ",") {
+		if val = strings.TrimSpace(val); val != "" {
+			vals = append(vals, val)
+		}
+	}
+	return vals
+}
+
 func parseCriteriaAndQueryValues(queryParamsToSQLCols map[string]WhereColumnInfo, parameters map[string]string) (string, map[string]interface{}, []error) {
 	var criteria string
 
@@ -208,6 +238,23 @@ func AddTenancyCheck(where string, queryValues map[string]interface{}, tenantCol
 	return where, queryValues
 }
 
+// GetCount returns the number of rows the given query, with its WHERE clause but without any ORDER BY or pagination, would return.
+// This is the count of the summary of a paginated read.
+func GetCount(tx *sqlx.Tx, query string, queryValues map[string]interface{}) (int, error) {
+	count := 0
+	rows, err := tx.NamedQuery(`SELECT COUNT(*) FROM (`+query+`) AS results`, queryValues)
+	if err != nil {
+		return 0, errors.New("querying count: " + err.Error())
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, errors.New("scanning count: " + err.Error())
+		}
+	}
+	return count, nil
+}
+
 // CommitIf commits if doCommit is true at the time of execution.
 // This is designed as a defer helper.
 //
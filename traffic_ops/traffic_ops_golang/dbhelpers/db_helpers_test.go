@@ -81,6 +81,37 @@ FROM table t
 
 }
 
+func TestBuildOrderBy(t *testing.T) {
+	queryParamsToSQLCols := map[string]WhereColumnInfo{
+		"param1": WhereColumnInfo{"t.col1", nil},
+		"param2": WhereColumnInfo{"t.col2", nil},
+	}
+	tests := []struct {
+		params  map[string]string
+		orderBy string
+	}{
+		{map[string]string{"orderby": "param1"}, "\nORDER BY t.col1"},
+		{map[string]string{"orderby": "param1", "sortOrder": "desc"}, "\nORDER BY t.col1 DESC"},
+		{map[string]string{"orderby": "param2,param1", "sortOrder": "desc"}, "\nORDER BY t.col2 DESC, t.col1 DESC"},
+		{map[string]string{"orderby": "param2, param1", "sortOrder": "desc,asc"}, "\nORDER BY t.col2 DESC, t.col1"},
+		{map[string]string{"orderby": "nonexistent,param1"}, "\nORDER BY t.col1"},
+		{map[string]string{"orderby": "nonexistent"}, ""},
+	}
+	for _, test := range tests {
+		_, orderBy, _, _, errs := BuildWhereAndOrderByAndPagination(test.params, queryParamsToSQLCols)
+		if len(errs) > 0 {
+			t.Errorf("expected %+v errors nil, actual: %v", test.params, errs)
+		} else if orderBy != test.orderBy {
+			t.Errorf("expected %+v orderBy '%s', actual: '%s'", test.params, test.orderBy, orderBy)
+		}
+	}
+
+	params := map[string]string{"orderby": "param1,param2", "sortOrder": "asc,desc,asc"}
+	if _, _, _, _, errs := BuildWhereAndOrderByAndPagination(params, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected %+v error, actual: nil", params)
+	}
+}
+
 func TestGetCacheGroupByName(t *testing.T) {
 	var testCases = []struct {
 		description  string
@@ -255,7 +255,8 @@ func createV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 		&ds.TRRequestHeaders,
 		&ds.TRResponseHeaders,
 		&ds.TypeID,
-		&ds.XMLID)
+		&ds.XMLID,
+		&ds.Topology)
 
 	if err != nil {
 		usrErr, sysErr, code := api.ParseDBError(err)
@@ -380,7 +381,7 @@ func (ds *TODeliveryService) Read() ([]interface{}, error, error, int) {
 	}
 
 	returnable := []interface{}{}
-	dses, userErr, sysErr, errCode := readGetDeliveryServices(ds.APIInfo().Params, ds.APIInfo().Tx, ds.APIInfo().User)
+	dses, count, userErr, sysErr, errCode := readGetDeliveryServices(ds.APIInfo().Params, ds.APIInfo().Tx, ds.APIInfo().User)
 
 	if sysErr != nil {
 		sysErr = errors.New("reading dses: " + sysErr.Error())
@@ -389,6 +390,9 @@ func (ds *TODeliveryService) Read() ([]interface{}, error, error, int) {
 	if userErr != nil || sysErr != nil {
 		return nil, userErr, sysErr, errCode
 	}
+	if count != nil {
+		ds.APIInfo().SetCount(*count)
+	}
 
 	for _, ds := range dses {
 		switch {
@@ -527,7 +531,8 @@ SELECT
   ds.max_origin_connections,
   (SELECT ARRAY_AGG(name ORDER BY name)
     FROM deliveryservice_consistent_hash_query_param
-    WHERE deliveryservice_id = ds.id) AS query_keys
+    WHERE deliveryservice_id = ds.id) AS query_keys,
+  ds.topology
 FROM
   deliveryservice ds
 WHERE
@@ -536,6 +541,7 @@ WHERE
 		&dsV14.ConsistentHashRegex,
 		&dsV14.MaxOriginConnections,
 		pq.Array(&dsV14.ConsistentHashQueryParams),
+		&dsV14.Topology,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, http.StatusNotFound, fmt.Errorf("delivery service ID %d not found", *dsV14.ID), nil
@@ -649,6 +655,7 @@ func updateV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 		&ds.AnonymousBlockingEnabled,
 		&ds.ConsistentHashRegex,
 		&ds.MaxOriginConnections,
+		&ds.Topology,
 		&ds.ID)
 
 	if err != nil {
@@ -797,7 +804,8 @@ func (v *TODeliveryService) DeleteQuery() string {
 	return `DELETE FROM deliveryservice WHERE id = :id`
 }
 
-func readGetDeliveryServices(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) ([]tc.DeliveryServiceNullable, error, error, int) {
+// readGetDeliveryServices returns the delivery services matching the given query parameters and, if they were paginated, the number of delivery services on all pages.
+func readGetDeliveryServices(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) ([]tc.DeliveryServiceNullable, *int, error, error, int) {
 	if strings.HasSuffix(params["id"], ".json") {
 		params["id"] = params["id"][:len(params["id"])-len(".json")]
 	}
@@ -821,14 +829,14 @@ func readGetDeliveryServices(params map[string]string, tx *sqlx.Tx, user *auth.C
 
 	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(params, queryParamsToSQLCols)
 	if len(errs) > 0 {
-		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
+		return nil, nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
 
 	tenantIDs, err := tenant.GetUserTenantIDListTx(tx.Tx, user.TenantID)
 
 	if err != nil {
 		log.Errorln("received error querying for user's tenants: " + err.Error())
-		return nil, nil, tc.DBError, http.StatusInternalServerError
+		return nil, nil, nil, tc.DBError, http.StatusInternalServerError
 	}
 
 	where, queryValues = dbhelpers.AddTenancyCheck(where, queryValues, "ds.tenant_id", tenantIDs)
@@ -838,7 +846,15 @@ func readGetDeliveryServices(params map[string]string, tx *sqlx.Tx, user *auth.C
 	log.Debugln("generated deliveryServices query: " + query)
 	log.Debugf("executing with values: %++v\n", queryValues)
 
-	return GetDeliveryServices(query, queryValues, tx)
+	dses, userErr, sysErr, errCode := GetDeliveryServices(query, queryValues, tx)
+	if userErr != nil || sysErr != nil || pagination == "" {
+		return dses, nil, userErr, sysErr, errCode
+	}
+	count, err := dbhelpers.GetCount(tx, selectQuery()+where, queryValues)
+	if err != nil {
+		return nil, nil, nil, errors.New("counting delivery services: " + err.Error()), http.StatusInternalServerError
+	}
+	return dses, &count, nil, nil, http.StatusOK
 }
 
 func getOldHostName(id int, tx *sql.Tx) (string, error) {
@@ -1034,6 +1050,7 @@ func GetDeliveryServices(query string, queryValues map[string]interface{}, tx *s
 			&ds.Type,
 			&ds.TypeID,
 			&ds.XMLID,
+			&ds.Topology,
 			&cdnDomain)
 
 		if err != nil {
@@ -1564,6 +1581,7 @@ ds.tr_response_headers,
 type.name,
 ds.type as type_id,
 ds.xml_id,
+ds.topology,
 cdn.domain_name as cdn_domain
 from deliveryservice as ds
 JOIN type ON ds.type = type.id
@@ -1628,8 +1646,9 @@ type=$48,
 xml_id=$49,
 anonymous_blocking_enabled=$50,
 consistent_hash_regex=$51,
-max_origin_connections=$52
-WHERE id=$53
+max_origin_connections=$52,
+topology=$53
+WHERE id=$54
 RETURNING last_updated
 `
 }
@@ -1688,9 +1707,10 @@ tenant_id,
 tr_request_headers,
 tr_response_headers,
 type,
-xml_id
+xml_id,
+topology
 )
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46,$47,$48,$49,$50,$51,$52)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46,$47,$48,$49,$50,$51,$52,$53)
 RETURNING id, last_updated
 `
 }
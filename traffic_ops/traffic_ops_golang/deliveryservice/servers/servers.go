@@ -671,6 +671,14 @@ func (dss *TODSSDeliveryService) Read() ([]interface{}, error, error, int) {
 	for _, ds := range dses {
 		returnable = append(returnable, ds)
 	}
+
+	if pagination != "" {
+		count, err := dbhelpers.GetCount(dss.APIInfo().Tx, deliveryservice.GetDSSelectQuery()+where, queryValues)
+		if err != nil {
+			return nil, nil, errors.New("counting server dses: " + err.Error()), http.StatusInternalServerError
+		}
+		dss.APIInfo().SetCount(count)
+	}
 	return returnable, nil, nil, http.StatusOK
 }
 
@@ -26,6 +26,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 
@@ -58,9 +59,10 @@ type Monitor struct {
 
 type Cache struct {
 	BasicServer
-	InterfaceName string `json:"interfacename"`
-	Type          string `json:"type"`
-	HashID        string `json:"hashid"`
+	InterfaceName string                      `json:"interfacename"`
+	Type          string                      `json:"type"`
+	HashID        string                      `json:"hashid"`
+	Interfaces    []tc.TrafficServerInterface `json:"interfaces,omitempty"`
 }
 
 type Cachegroup struct {
@@ -129,6 +131,7 @@ func GetMonitoringJSON(tx *sql.Tx, cdnName string) (*Monitoring, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error getting profiles: %v", err)
 	}
+	setCacheInterfaces(caches, profiles)
 
 	deliveryServices, err := getDeliveryServices(tx, routers)
 	if err != nil {
@@ -337,6 +340,29 @@ WHERE pr.config_file = $2;
 	return profilesArr, nil
 }
 
+// setCacheInterfaces sets the monitored interfaces of each cache from its profile's health.interfaces Parameter, if it has one. Caches whose Parameter is malformed are logged, and only their InterfaceName is monitored.
+// Interfaces can only be declared per profile, so all caches with a profile are monitored on the same interfaces.
+func setCacheInterfaces(caches []Cache, profiles []Profile) {
+	profileInterfaces := map[string]string{}
+	for _, profile := range profiles {
+		if val, ok := profile.Parameters[tc.HealthInterfacesParam]; ok {
+			profileInterfaces[profile.Name] = fmt.Sprintf("%v", val)
+		}
+	}
+	for i, cache := range caches {
+		val, ok := profileInterfaces[cache.Profile]
+		if !ok {
+			continue
+		}
+		interfaces, err := tc.ParseTrafficServerInterfaces(val)
+		if err != nil {
+			log.Warnln("monitoring: profile " + cache.Profile + " parameter " + tc.HealthInterfacesParam + " invalid, monitoring only the interface name of cache " + cache.HostName + ": " + err.Error())
+			continue
+		}
+		caches[i].Interfaces = interfaces
+	}
+}
+
 func getDeliveryServices(tx *sql.Tx, routers []Router) ([]DeliveryService, error) {
 	profileNames := []string{}
 	for _, router := range routers {
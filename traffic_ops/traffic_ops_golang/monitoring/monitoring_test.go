@@ -26,6 +26,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
@@ -108,7 +110,7 @@ func TestGetMonitoringServers(t *testing.T) {
 		t.Errorf("getMonitoringServers expected: len(caches) == 1, actual: %v", len(caches))
 	}
 	sqlCache := caches[0]
-	if sqlCache != cache {
+	if !reflect.DeepEqual(sqlCache, cache) {
 		t.Errorf("getMonitoringServers expected: cache == %+v, actual: %+v", cache, sqlCache)
 	}
 
@@ -585,8 +587,9 @@ func TestGetMonitoringJSON(t *testing.T) {
 				Name: cache.Profile,
 				Type: "EDGE",
 				Parameters: map[string]interface{}{
-					"2param0": "2param0Val",
-					"2param1": "2param1Val",
+					"2param0":                "2param0Val",
+					"2param1":                "2param1Val",
+					tc.HealthInterfacesParam: "cacheInterface:1000,eth1::optional",
 				},
 			},
 		}
@@ -598,6 +601,11 @@ func TestGetMonitoringJSON(t *testing.T) {
 			}
 		}
 
+		resp.Response.TrafficServers[0].Interfaces = []tc.TrafficServerInterface{
+			{Name: "cacheInterface", MaxKbps: 1000, Required: true},
+			{Name: "eth1", Required: false},
+		}
+
 		// caches := []Cache{cache}
 		// routers := []Router{router}
 
@@ -695,3 +703,31 @@ func TestGetMonitoringJSON(t *testing.T) {
 	}
 
 }
+
+func TestSetCacheInterfaces(t *testing.T) {
+	caches := []Cache{
+		{BasicServer: BasicServer{HostName: "cache0", Profile: "interfacesProfile"}, InterfaceName: "bond0"},
+		{BasicServer: BasicServer{HostName: "cache1", Profile: "invalidProfile"}, InterfaceName: "bond0"},
+		{BasicServer: BasicServer{HostName: "cache2", Profile: "otherProfile"}, InterfaceName: "bond0"},
+	}
+	profiles := []Profile{
+		{Name: "interfacesProfile", Parameters: map[string]interface{}{tc.HealthInterfacesParam: "bond0:20000000, eth2::optional"}},
+		{Name: "invalidProfile", Parameters: map[string]interface{}{tc.HealthInterfacesParam: "bond0:a"}},
+		{Name: "otherProfile", Parameters: map[string]interface{}{"param0": "param0Val"}},
+	}
+	setCacheInterfaces(caches, profiles)
+
+	expected := []tc.TrafficServerInterface{
+		{Name: "bond0", MaxKbps: 20000000, Required: true},
+		{Name: "eth2", Required: false},
+	}
+	if !reflect.DeepEqual(caches[0].Interfaces, expected) {
+		t.Errorf("setCacheInterfaces expected: %+v, actual: %+v", expected, caches[0].Interfaces)
+	}
+	if caches[1].Interfaces != nil {
+		t.Errorf("setCacheInterfaces with an invalid parameter expected: no interfaces, actual: %+v", caches[1].Interfaces)
+	}
+	if caches[2].Interfaces != nil {
+		t.Errorf("setCacheInterfaces without the parameter expected: no interfaces, actual: %+v", caches[2].Interfaces)
+	}
+}
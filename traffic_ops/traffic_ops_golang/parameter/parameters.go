@@ -145,6 +145,13 @@ func (param *TOParameter) Read() ([]interface{}, error, error, int) {
 		params = append(params, p)
 	}
 
+	if pagination != "" {
+		count, err := dbhelpers.GetCount(param.ReqInfo.Tx, selectQuery()+where+ParametersGroupBy(), queryValues)
+		if err != nil {
+			return nil, nil, errors.New("counting " + param.GetType() + ": " + err.Error()), http.StatusInternalServerError
+		}
+		param.ReqInfo.SetCount(count)
+	}
 	return params, nil, nil, http.StatusOK
 }
 
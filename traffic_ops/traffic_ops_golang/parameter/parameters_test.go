@@ -114,6 +114,42 @@ func TestGetParameters(t *testing.T) {
 
 }
 
+func TestGetParametersPaginated(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	cols := test.ColsFromStructByTag("db", tc.ParameterNullable{})
+	rows := sqlmock.NewRows(cols)
+	for _, ts := range getTestParameters() {
+		rows = rows.AddRow(ts.ConfigFile, ts.ID, ts.LastUpdated, ts.Name, ts.Profiles, ts.Secure, ts.Value)
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	reqInfo := api.APIInfo{
+		Tx:     db.MustBegin(),
+		User:   &auth.CurrentUser{PrivLevel: 30},
+		Params: map[string]string{"limit": "2"},
+	}
+	obj := TOParameter{
+		api.APIInfoImpl{&reqInfo},
+		tc.ParameterNullable{},
+	}
+	if _, userErr, sysErr, _ := obj.Read(); userErr != nil || sysErr != nil {
+		t.Fatalf("Read expected: no errors, actual: %v %v", userErr, sysErr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("paginated Read expected: count query, actual: %v", err)
+	}
+}
+
 func TestInterfaces(t *testing.T) {
 	var i interface{}
 	i = &TOParameter{}
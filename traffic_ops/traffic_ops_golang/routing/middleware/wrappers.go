@@ -74,8 +74,9 @@ type AuthBase struct {
 }
 
 // GetWrapper returns a Middleware which performs authentication of the current user at the given privilege level.
+// The user's role must also have all of capabilitiesRequired; see auth.CurrentUser.IsAuthorized.
 // The returned Middleware also adds the auth.CurrentUser object to the request context, which may be retrieved by a handler via api.NewInfo or auth.GetCurrentUser.
-func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
+func (a AuthBase) GetWrapper(privLevelRequired int, capabilitiesRequired []string) Middleware {
 	if a.Override != nil {
 		return a.Override
 	}
@@ -86,7 +87,7 @@ func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
 				api.HandleErr(w, r, nil, errCode, userErr, sysErr)
 				return
 			}
-			if user.PrivLevel < privLevelRequired {
+			if !user.IsAuthorized(privLevelRequired, capabilitiesRequired) {
 				api.HandleErr(w, r, nil, http.StatusForbidden, errors.New("Forbidden."), nil)
 				return
 			}
@@ -186,7 +186,7 @@ func TestWrapAuth(t *testing.T) {
 		fmt.Fprintf(w, "%s", respBts)
 	}
 
-	authWrapper := authBase.GetWrapper(15)
+	authWrapper := authBase.GetWrapper(15, nil)
 
 	f := authWrapper(handler)
 
@@ -234,3 +234,68 @@ func TestWrapAuth(t *testing.T) {
 }
 
 // TODO: TestWrapAccessLog
+
+func TestWrapAuthAPIToken(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	userName := "user1"
+	token := "token1"
+	secret := "secret"
+
+	expectTokenUser := func() {
+		tokenRows := sqlmock.NewRows([]string{"username", "capabilities", "role_name", "last_used"})
+		tokenRows.AddRow(userName, []byte("{servers-read}"), "admin", time.Now())
+		mock.ExpectQuery("FROM api_token").WithArgs(auth.HashAPIToken(token)).WillReturnRows(tokenRows)
+
+		userRows := sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id", "capabilities"})
+		userRows.AddRow(30, userName, 1, 1, []byte("{servers-read,servers-write}"))
+		mock.ExpectQuery("SELECT").WithArgs(userName).WillReturnRows(userRows)
+	}
+
+	authBase := AuthBase{secret, nil}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			t.Fatalf("unable to get user: %v", err)
+		}
+		fmt.Fprintf(w, "%s %d %v", user.UserName, user.PrivLevel, []string(user.Capabilities))
+	}
+
+	newReq := func() *http.Request {
+		r, err := http.NewRequest("", "/", nil)
+		if err != nil {
+			t.Fatalf("creating new request: %v", err)
+		}
+		r.Header.Set("Authorization", auth.APITokenAuthScheme+" "+token)
+		r = r.WithContext(context.WithValue(context.Background(), api.DBContextKey, db))
+		return r.WithContext(context.WithValue(r.Context(), api.ConfigContextKey, &config.Config{ConfigTrafficOpsGolang: config.ConfigTrafficOpsGolang{DBQueryTimeoutSeconds: 20}}))
+	}
+
+	expectTokenUser()
+	w := httptest.NewRecorder()
+	authBase.GetWrapper(auth.PrivLevelOperations, []string{"servers-read"})(handler)(w, newReq())
+	if expected := fmt.Sprintf("%s %d [servers-read]", userName, auth.PrivLevelAdmin); w.Body.String() != expected {
+		t.Errorf("expected token with capability to be authorized as '%s', actual: code %d body '%s'", expected, w.Code, w.Body.String())
+	}
+	if len(w.Header()["Set-Cookie"]) > 0 {
+		t.Errorf("expected no cookie for an API token, actual: %v", w.Header()["Set-Cookie"])
+	}
+
+	expectTokenUser()
+	w = httptest.NewRecorder()
+	authBase.GetWrapper(auth.PrivLevelOperations, []string{"servers-write"})(handler)(w, newReq())
+	if expected := `{"alerts":[{"text":"Forbidden.","level":"error"}]}` + "\n"; w.Body.String() != expected {
+		t.Errorf("expected token without capability to be forbidden, even though the user has it, actual: '%s'", w.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all queries to be made, actual: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+)
+
+// routeCapabilitiesHandler returns a handler listing the privilege level and capabilities required by each of the given routes.
+// It takes a pointer, so it may be served by one of the routes it lists.
+func routeCapabilitiesHandler(routes *[]Route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		api.WriteResp(w, r, makeRouteCapabilities(*routes))
+	}
+}
+
+// makeRouteCapabilities returns the authorization required by each of the given routes, in the same order.
+func makeRouteCapabilities(routes []Route) []tc.RouteCapability {
+	caps := make([]tc.RouteCapability, 0, len(routes))
+	for _, rt := range routes {
+		required := []string{}
+		if rt.Authenticated {
+			required = append(required, rt.RequiredCapabilities...)
+		}
+		caps = append(caps, tc.RouteCapability{
+			ID:                   rt.ID,
+			Method:               rt.Method,
+			Path:                 rt.Path,
+			Version:              rt.Version,
+			Authenticated:        rt.Authenticated,
+			RequiredPrivLevel:    rt.RequiredPrivLevel,
+			RequiredCapabilities: required,
+		})
+	}
+	return caps
+}
@@ -50,10 +50,12 @@ type Route struct {
 	Path              string
 	Handler           http.HandlerFunc
 	RequiredPrivLevel int
-	Authenticated     bool
-	Middlewares       []middleware.Middleware
-	ID                int  // unique ID for referencing this Route
-	CanBypassToPerl   bool // if true, this Route can be passed through to Perl
+	// RequiredCapabilities are the role capabilities required to use this Route, in addition to RequiredPrivLevel. A user's role must have all of them.
+	RequiredCapabilities []string
+	Authenticated        bool
+	Middlewares          []middleware.Middleware
+	ID                   int  // unique ID for referencing this Route
+	CanBypassToPerl      bool // if true, this Route can be passed through to Perl
 }
 
 func (r Route) String() string {
@@ -63,12 +65,13 @@ func (r Route) String() string {
 // RawRoute is an HTTP route to be served at the root, rather than under /api/version. Raw Routes should be rare, and almost exclusively converted old Perl routes which have yet to be moved to an API path.
 type RawRoute struct {
 	// Order matters! Do not reorder this! Routes() uses positional construction for readability.
-	Method            string
-	Path              string
-	Handler           http.HandlerFunc
-	RequiredPrivLevel int
-	Authenticated     bool
-	Middlewares       []middleware.Middleware
+	Method               string
+	Path                 string
+	Handler              http.HandlerFunc
+	RequiredPrivLevel    int
+	RequiredCapabilities []string
+	Authenticated        bool
+	Middlewares          []middleware.Middleware
 }
 
 // ServerData ...
@@ -128,7 +131,7 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 			}
 			vstr := strconv.FormatFloat(version, 'f', -1, 64)
 			path := RoutePrefix + "/" + vstr + "/" + r.Path
-			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
+			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, r.RequiredCapabilities, requestTimeout)
 
 			if isPerlRoute {
 				m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: perlHandler})
@@ -141,7 +144,7 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 		}
 	}
 	for _, r := range rawRoutes {
-		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
+		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, r.RequiredCapabilities, requestTimeout)
 		m[r.Method] = append(m[r.Method], PathHandler{Path: r.Path, Handler: middleware.Use(r.Handler, middlewares)})
 		log.Infof("adding raw route %v %v\n", r.Method, r.Path)
 	}
@@ -154,12 +157,12 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 	return m, versionSet
 }
 
-func getRouteMiddleware(middlewares []middleware.Middleware, authBase middleware.AuthBase, authenticated bool, privLevel int, requestTimeout time.Duration) []middleware.Middleware {
+func getRouteMiddleware(middlewares []middleware.Middleware, authBase middleware.AuthBase, authenticated bool, privLevel int, capabilities []string, requestTimeout time.Duration) []middleware.Middleware {
 	if middlewares == nil {
 		middlewares = middleware.GetDefault(authBase.Secret, requestTimeout)
 	}
 	if authenticated { // a privLevel of zero is an unauthenticated endpoint.
-		authWrapper := authBase.GetWrapper(privLevel)
+		authWrapper := authBase.GetWrapper(privLevel, capabilities)
 		middlewares = append(middlewares, authWrapper)
 	}
 	return middlewares
@@ -189,6 +189,33 @@ func TestRoutes(t *testing.T) {
 	}
 }
 
+func TestRouteCapabilities(t *testing.T) {
+	fake := ServerData{Config: config.NewFakeConfig()}
+	routes, _, _, err := Routes(fake)
+	if err != nil {
+		t.Fatalf("expected: no error getting Routes, actual: %v", err)
+	}
+	// verify that every route requiring a privilege level may also be granted by capabilities
+	for _, rt := range routes {
+		if rt.Authenticated && rt.RequiredPrivLevel > 0 && len(rt.RequiredCapabilities) == 0 {
+			t.Errorf("expected: route with a privilege level to require capabilities, actual: none for route %s", rt.String())
+		}
+	}
+
+	caps := makeRouteCapabilities(routes)
+	if len(caps) != len(routes) {
+		t.Fatalf("expected: %v route capabilities, actual: %v", len(routes), len(caps))
+	}
+	for i, rt := range routes {
+		if caps[i].ID != rt.ID || caps[i].Path != rt.Path || caps[i].Method != rt.Method {
+			t.Errorf("expected: route capabilities %v for route %s, actual: %+v", i, rt.String(), caps[i])
+		}
+		if caps[i].RequiredCapabilities == nil {
+			t.Errorf("expected: non-nil required capabilities for route %s, actual: nil", rt.String())
+		}
+	}
+}
+
 func TestCreateRouteMap(t *testing.T) {
 	authBase := middleware.AuthBase{"secret", func(handlerFunc http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
@@ -227,11 +254,11 @@ func TestCreateRouteMap(t *testing.T) {
 	}
 
 	routes := []Route{
-		{1.2, http.MethodGet, `path1`, PathOneHandler, auth.PrivLevelReadOnly, true, nil, 0, false},
-		{1.2, http.MethodGet, `path2`, PathTwoHandler, 0, false, nil, 1, false},
-		{1.2, http.MethodGet, `path3`, PathThreeHandler, 0, false, []middleware.Middleware{}, 2, false},
-		{1.2, http.MethodGet, `path4`, PathFourHandler, 0, false, []middleware.Middleware{}, 3, true},
-		{1.2, http.MethodGet, `path5`, PathFiveHandler, 0, false, []middleware.Middleware{}, 4, false},
+		{1.2, http.MethodGet, `path1`, PathOneHandler, auth.PrivLevelReadOnly, nil, true, nil, 0, false},
+		{1.2, http.MethodGet, `path2`, PathTwoHandler, 0, nil, false, nil, 1, false},
+		{1.2, http.MethodGet, `path3`, PathThreeHandler, 0, nil, false, []middleware.Middleware{}, 2, false},
+		{1.2, http.MethodGet, `path4`, PathFourHandler, 0, nil, false, []middleware.Middleware{}, 3, true},
+		{1.2, http.MethodGet, `path5`, PathFiveHandler, 0, nil, false, []middleware.Middleware{}, 4, false},
 	}
 
 	perlRoutesIDs := []int{3}
@@ -177,11 +177,14 @@ func (server TOServer) ChangeLogMessage(action string) (string, error) {
 func (server *TOServer) Read() ([]interface{}, error, error, int) {
 	returnable := []interface{}{}
 
-	servers, userErr, sysErr, errCode := getServers(server.ReqInfo.Params, server.ReqInfo.Tx, server.ReqInfo.User)
+	servers, count, userErr, sysErr, errCode := getServers(server.ReqInfo.Params, server.ReqInfo.Tx, server.ReqInfo.User)
 
 	if userErr != nil || sysErr != nil {
 		return nil, userErr, sysErr, errCode
 	}
+	if count != nil {
+		server.ReqInfo.SetCount(*count)
+	}
 
 	for _, server := range servers {
 		returnable = append(returnable, server)
@@ -190,7 +193,9 @@ func (server *TOServer) Read() ([]interface{}, error, error, int) {
 	return returnable, nil, nil, http.StatusOK
 }
 
-func getServers(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) ([]tc.ServerNullable, error, error, int) {
+// getServers returns the servers matching the given query parameters and, if they were paginated, the number of servers on all pages.
+// The count is nil if the servers weren't paginated, or if they include the mid-tier caches of a delivery service, which are added to every page.
+func getServers(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) ([]tc.ServerNullable, *int, error, error, int) {
 	// Query Parameters to Database Query column mappings
 	// see the fields mapped in the SQL query
 	queryParamsToSQLCols := map[string]dbhelpers.WhereColumnInfo{
@@ -212,11 +217,11 @@ func getServers(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) (
 		// don't allow query on ds outside user's tenant
 		dsID, err := strconv.Atoi(dsIDStr)
 		if err != nil {
-			return nil, errors.New("dsId must be an integer"), nil, http.StatusNotFound
+			return nil, nil, errors.New("dsId must be an integer"), nil, http.StatusNotFound
 		}
 		userErr, sysErr, _ := tenant.CheckID(tx.Tx, user, dsID)
 		if userErr != nil || sysErr != nil {
-			return nil, errors.New("Forbidden"), sysErr, http.StatusForbidden
+			return nil, nil, errors.New("Forbidden"), sysErr, http.StatusForbidden
 		}
 		// only if dsId is part of params: add join on deliveryservice_server table
 		queryAddition = `
@@ -225,7 +230,7 @@ FULL OUTER JOIN deliveryservice_server dss ON dss.server = s.id
 		// depending on ds type, also need to add mids
 		dsType, err := deliveryservice.GetDeliveryServiceType(dsID, tx.Tx)
 		if err != nil {
-			return nil, err, nil, http.StatusBadRequest
+			return nil, nil, err, nil, http.StatusBadRequest
 		}
 		usesMids = dsType.UsesMidCache()
 		log.Debugf("Servers for ds %d; uses mids? %v\n", dsID, usesMids)
@@ -233,7 +238,7 @@ FULL OUTER JOIN deliveryservice_server dss ON dss.server = s.id
 
 	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(params, queryParamsToSQLCols)
 	if len(errs) > 0 {
-		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
+		return nil, nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
 
 	query := selectQuery() + queryAddition + where + orderBy + pagination
@@ -241,7 +246,7 @@ FULL OUTER JOIN deliveryservice_server dss ON dss.server = s.id
 
 	rows, err := tx.NamedQuery(query, queryValues)
 	if err != nil {
-		return nil, nil, errors.New("querying: " + err.Error()), http.StatusInternalServerError
+		return nil, nil, nil, errors.New("querying: " + err.Error()), http.StatusInternalServerError
 	}
 	defer rows.Close()
 
@@ -252,7 +257,7 @@ FULL OUTER JOIN deliveryservice_server dss ON dss.server = s.id
 	for rows.Next() {
 		var s tc.ServerNullable
 		if err = rows.StructScan(&s); err != nil {
-			return nil, nil, errors.New("getting servers: " + err.Error()), http.StatusInternalServerError
+			return nil, nil, nil, errors.New("getting servers: " + err.Error()), http.StatusInternalServerError
 		}
 		if user.PrivLevel < auth.PrivLevelOperations {
 			s.ILOPassword = &HiddenField
@@ -268,14 +273,21 @@ FULL OUTER JOIN deliveryservice_server dss ON dss.server = s.id
 		log.Debugf("getting mids: %v, %v, %s\n", userErr, sysErr, http.StatusText(errCode))
 
 		if userErr != nil || sysErr != nil {
-			return nil, userErr, sysErr, errCode
+			return nil, nil, userErr, sysErr, errCode
 		}
 		for _, server := range mids {
 			servers = append(servers, server)
 		}
 	}
 
-	return servers, nil, nil, http.StatusOK
+	if pagination == "" || usesMids {
+		return servers, nil, nil, nil, http.StatusOK
+	}
+	count, err := dbhelpers.GetCount(tx, selectQuery()+queryAddition+where, queryValues)
+	if err != nil {
+		return nil, nil, nil, errors.New("counting servers: " + err.Error()), http.StatusInternalServerError
+	}
+	return servers, &count, nil, nil, http.StatusOK
 }
 
 // getMidServers gets mids used by the servers in this ds
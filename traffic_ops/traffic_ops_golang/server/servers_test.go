@@ -163,7 +163,7 @@ func TestGetServersByCachegroup(t *testing.T) {
 
 	user := auth.CurrentUser{}
 
-	servers, userErr, sysErr, errCode := getServers(v, db.MustBegin(), &user)
+	servers, _, userErr, sysErr, errCode := getServers(v, db.MustBegin(), &user)
 	if userErr != nil || sysErr != nil {
 		t.Errorf("getServers expected: no errors, actual: %v %v with status: %s", userErr, sysErr, http.StatusText(errCode))
 	}
@@ -0,0 +1,466 @@
+package topology
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/lib/pq"
+)
+
+// MaxParents is the maximum number of parents of a node in a Topology: a primary and a secondary parent.
+const MaxParents = 2
+
+type TOTopology struct {
+	api.APIInfoImpl `json:"-"`
+	tc.Topology
+}
+
+func (v *TOTopology) SetLastUpdated(t tc.TimeNoMod) { v.LastUpdated = &t }
+
+func (v TOTopology) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{Field: "name", Func: api.GetStringKey}}
+}
+
+// Implementation of the Identifier, Validator interface functions
+func (v TOTopology) GetKeys() (map[string]interface{}, bool) {
+	return map[string]interface{}{"name": v.Name}, v.Name != ""
+}
+
+func (v *TOTopology) SetKeys(keys map[string]interface{}) {
+	v.Name, _ = keys["name"].(string)
+}
+
+func (v *TOTopology) GetAuditName() string {
+	return v.Name
+}
+
+func (v *TOTopology) GetType() string {
+	return "topology"
+}
+
+func (v *TOTopology) Validate() error {
+	rule := validation.NewStringRule(tovalidate.IsAlphanumericUnderscoreDash, "must consist of only alphanumeric, dash, or underscore characters")
+	errs := tovalidate.ToErrors(validation.Errors{
+		"name": validation.Validate(v.Name, validation.Required, rule),
+	})
+	errs = append(errs, ValidateNodes(v.Nodes)...)
+	if len(errs) > 0 {
+		return util.JoinErrs(errs)
+	}
+	return validateCacheGroups(v.ReqInfo.Tx.Tx, v.Nodes)
+}
+
+// ValidateNodes returns any errors in the structure of the given Topology nodes: a Topology must have at least one node, each cachegroup may only be in the Topology once, each node may have at most a primary and secondary parent, which must be other nodes, and the Topology may not have cycles.
+func ValidateNodes(nodes []tc.TopologyNode) []error {
+	if len(nodes) == 0 {
+		return []error{errors.New("nodes: a topology must have at least one cachegroup")}
+	}
+
+	errs := []error{}
+	cacheGroups := map[string]struct{}{}
+	for i, node := range nodes {
+		if node.Cachegroup == "" {
+			errs = append(errs, errors.New("nodes: node "+strconv.Itoa(i)+" has no cachegroup"))
+			continue
+		}
+		if _, ok := cacheGroups[node.Cachegroup]; ok {
+			errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' is in the topology more than once"))
+		}
+		cacheGroups[node.Cachegroup] = struct{}{}
+
+		if len(node.Parents) > MaxParents {
+			errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' has more than "+strconv.Itoa(MaxParents)+" parents"))
+		}
+		for j, parent := range node.Parents {
+			if parent < 0 || parent >= len(nodes) {
+				errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' has parent "+strconv.Itoa(parent)+", which is not a node in the topology"))
+			} else if parent == i {
+				errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' is its own parent"))
+			} else if j > 0 && parent == node.Parents[0] {
+				errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' has the same primary and secondary parent"))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs // don't check for cycles with invalid parents
+	}
+
+	if cycle := findCycle(nodes); len(cycle) > 0 {
+		cycleNames := []string{}
+		for _, i := range cycle {
+			cycleNames = append(cycleNames, nodes[i].Cachegroup)
+		}
+		errs = append(errs, errors.New("nodes: topology has a cycle: "+strings.Join(cycleNames, " -> ")))
+	}
+	return errs
+}
+
+// findCycle returns the node indexes of a cycle in the given nodes, starting and ending with the same node, or nil if the nodes have no cycle. The nodes' parents must be valid indexes.
+func findCycle(nodes []tc.TopologyNode) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(nodes))
+	path := []int{}
+
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		path = append(path, i)
+		for _, parent := range nodes[i].Parents {
+			switch state[parent] {
+			case visiting:
+				for j, p := range path {
+					if p == parent {
+						return append(append([]int{}, path[j:]...), parent)
+					}
+				}
+			case unvisited:
+				if cycle := visit(parent); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range nodes {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// validateCacheGroups returns an error if any node's cachegroup doesn't exist, or isn't an Edge or Mid cachegroup, or if any node without children isn't an Edge cachegroup.
+func validateCacheGroups(tx *sql.Tx, nodes []tc.TopologyNode) error {
+	names := []string{}
+	for _, node := range nodes {
+		names = append(names, node.Cachegroup)
+	}
+	qry := `
+SELECT
+  cg.name,
+  t.name
+FROM
+  cachegroup cg
+  JOIN type t ON t.id = cg.type
+WHERE
+  cg.name = ANY($1)
+`
+	rows, err := tx.Query(qry, pq.Array(names))
+	if err != nil {
+		return errors.New("querying topology cachegroups: " + err.Error())
+	}
+	defer rows.Close()
+
+	cacheGroupTypes := map[string]string{}
+	for rows.Next() {
+		name := ""
+		typeName := ""
+		if err := rows.Scan(&name, &typeName); err != nil {
+			return errors.New("scanning topology cachegroups: " + err.Error())
+		}
+		cacheGroupTypes[name] = typeName
+	}
+
+	hasChildren := map[int]struct{}{}
+	for _, node := range nodes {
+		for _, parent := range node.Parents {
+			hasChildren[parent] = struct{}{}
+		}
+	}
+
+	errs := []error{}
+	for i, node := range nodes {
+		typeName, ok := cacheGroupTypes[node.Cachegroup]
+		if !ok {
+			errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' does not exist"))
+			continue
+		}
+		if typeName != tc.CacheGroupEdgeTypeName && typeName != tc.CacheGroupMidTypeName {
+			errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' has type "+typeName+", but topologies may only contain "+tc.CacheGroupEdgeTypeName+" and "+tc.CacheGroupMidTypeName+" cachegroups"))
+			continue
+		}
+		if _, ok := hasChildren[i]; !ok && typeName != tc.CacheGroupEdgeTypeName {
+			errs = append(errs, errors.New("nodes: cachegroup '"+node.Cachegroup+"' has no children, and must be an "+tc.CacheGroupEdgeTypeName+" cachegroup"))
+		}
+	}
+	return util.JoinErrs(errs)
+}
+
+func (v *TOTopology) Read() ([]interface{}, error, error, int) {
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"name": dbhelpers.WhereColumnInfo{Column: "t.name", Checker: nil},
+	}
+	where, orderByClause, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(v.ReqInfo.Params, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
+	}
+
+	rows, err := v.ReqInfo.Tx.NamedQuery(readQuery(where, orderByClause, pagination), queryValues)
+	if err != nil {
+		return nil, nil, errors.New("topology read: querying: " + err.Error()), http.StatusInternalServerError
+	}
+	defer rows.Close()
+
+	topologies, err := scanTopologies(rows.Rows)
+	if err != nil {
+		return nil, nil, errors.New("topology read: " + err.Error()), http.StatusInternalServerError
+	}
+	iTopologies := []interface{}{}
+	for _, topology := range topologies {
+		iTopologies = append(iTopologies, topology)
+	}
+	return iTopologies, nil, nil, http.StatusOK
+}
+
+// GetCacheGroupTopologies returns the Topologies which contain the given cachegroup.
+func GetCacheGroupTopologies(tx *sql.Tx, cacheGroup tc.CacheGroupName) (map[string]tc.Topology, error) {
+	qry := selectQuery() + `
+WHERE
+  t.name IN (SELECT topology FROM topology_cachegroup WHERE cachegroup = $1)
+` + orderBy
+	rows, err := tx.Query(qry, cacheGroup)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+
+	topologies, err := scanTopologies(rows)
+	if err != nil {
+		return nil, err
+	}
+	topologyMap := map[string]tc.Topology{}
+	for _, topology := range topologies {
+		topologyMap[topology.Name] = topology
+	}
+	return topologyMap, nil
+}
+
+// topologyRow is a row of the topology select query: a topology, and one of its nodes, if it has any.
+type topologyRow struct {
+	Name        string
+	Description string
+	LastUpdated tc.TimeNoMod
+	NodeID      *int64
+	Cachegroup  *string
+	ParentIDs   []int64
+}
+
+func scanTopologies(rows *sql.Rows) ([]tc.Topology, error) {
+	topologyRows := []topologyRow{}
+	for rows.Next() {
+		row := topologyRow{}
+		if err := rows.Scan(&row.Name, &row.Description, &row.LastUpdated, &row.NodeID, &row.Cachegroup, pq.Array(&row.ParentIDs)); err != nil {
+			return nil, errors.New("scanning topologies: " + err.Error())
+		}
+		topologyRows = append(topologyRows, row)
+	}
+	return makeTopologies(topologyRows)
+}
+
+// makeTopologies builds Topologies from the given rows, which must be ordered by topology name, converting the nodes' parent IDs to indexes.
+func makeTopologies(rows []topologyRow) ([]tc.Topology, error) {
+	topologies := []tc.Topology{}
+	nodeIndexes := map[int64]int{}
+	parentIDs := [][]int64{}
+
+	finishTopology := func() error {
+		if len(topologies) == 0 {
+			return nil
+		}
+		topology := &topologies[len(topologies)-1]
+		for i, ids := range parentIDs {
+			for _, id := range ids {
+				parent, ok := nodeIndexes[id]
+				if !ok {
+					return errors.New("topology '" + topology.Name + "' cachegroup '" + topology.Nodes[i].Cachegroup + "' has parent " + strconv.FormatInt(id, 10) + " outside the topology")
+				}
+				topology.Nodes[i].Parents = append(topology.Nodes[i].Parents, parent)
+			}
+		}
+		return nil
+	}
+
+	for _, row := range rows {
+		if len(topologies) == 0 || topologies[len(topologies)-1].Name != row.Name {
+			if err := finishTopology(); err != nil {
+				return nil, err
+			}
+			lastUpdated := row.LastUpdated
+			topologies = append(topologies, tc.Topology{
+				Name:        row.Name,
+				Description: row.Description,
+				Nodes:       []tc.TopologyNode{},
+				LastUpdated: &lastUpdated,
+			})
+			nodeIndexes = map[int64]int{}
+			parentIDs = [][]int64{}
+		}
+		if row.NodeID == nil || row.Cachegroup == nil {
+			continue // topology with no nodes
+		}
+		topology := &topologies[len(topologies)-1]
+		nodeIndexes[*row.NodeID] = len(topology.Nodes)
+		topology.Nodes = append(topology.Nodes, tc.TopologyNode{Cachegroup: *row.Cachegroup, Parents: []int{}})
+		parentIDs = append(parentIDs, row.ParentIDs)
+	}
+	if err := finishTopology(); err != nil {
+		return nil, err
+	}
+	return topologies, nil
+}
+
+func (v *TOTopology) Create() (error, error, int) {
+	if err := v.ReqInfo.Tx.Tx.QueryRow(insertQuery(), v.Name, v.Description).Scan(&v.LastUpdated); err != nil {
+		return api.ParseDBError(err)
+	}
+	if err := insertNodes(v.ReqInfo.Tx.Tx, v.Name, v.Nodes); err != nil {
+		return api.ParseDBError(err)
+	}
+	return nil, nil, http.StatusOK
+}
+
+func (v *TOTopology) Update() (error, error, int) {
+	if err := v.ReqInfo.Tx.Tx.QueryRow(updateQuery(), v.Description, v.Name).Scan(&v.LastUpdated); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("topology '" + v.Name + "' not found"), nil, http.StatusNotFound
+		}
+		return api.ParseDBError(err)
+	}
+	if _, err := v.ReqInfo.Tx.Tx.Exec(`DELETE FROM topology_cachegroup WHERE topology = $1`, v.Name); err != nil {
+		return nil, errors.New("topology update: deleting nodes: " + err.Error()), http.StatusInternalServerError
+	}
+	if err := insertNodes(v.ReqInfo.Tx.Tx, v.Name, v.Nodes); err != nil {
+		return api.ParseDBError(err)
+	}
+	return nil, nil, http.StatusOK
+}
+
+func (v *TOTopology) Delete() (error, error, int) {
+	result, err := v.ReqInfo.Tx.Tx.Exec(deleteQuery(), v.Name)
+	if err != nil {
+		return api.ParseDBError(err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return nil, errors.New("topology delete: getting rows affected: " + err.Error()), http.StatusInternalServerError
+	} else if rowsAffected == 0 {
+		return errors.New("topology '" + v.Name + "' not found"), nil, http.StatusNotFound
+	}
+	return nil, nil, http.StatusOK
+}
+
+// insertNodes inserts the given nodes of the given topology, and their parents. The nodes must be valid.
+func insertNodes(tx *sql.Tx, topology string, nodes []tc.TopologyNode) error {
+	nodeIDs := make([]int64, len(nodes))
+	for i, node := range nodes {
+		if err := tx.QueryRow(`INSERT INTO topology_cachegroup (topology, cachegroup) VALUES ($1, $2) RETURNING id`, topology, node.Cachegroup).Scan(&nodeIDs[i]); err != nil {
+			return err
+		}
+	}
+	for i, node := range nodes {
+		for rank, parent := range node.Parents {
+			if _, err := tx.Exec(`INSERT INTO topology_cachegroup_parents (child, parent, rank) VALUES ($1, $2, $3)`, nodeIDs[i], nodeIDs[parent], rank+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const orderBy = `
+ORDER BY t.name, tc.id
+`
+
+// readQuery returns the query to read the topologies matching the given where clause, in the given order, paginated by the given limit and offset, all of which may be empty.
+// The topologies are ordered and paginated in a subquery, because each topology has a row per node.
+func readQuery(where string, orderByClause string, pagination string) string {
+	if orderByClause == "" {
+		orderByClause = dbhelpers.BaseOrderBy + " t.name"
+	}
+	return selectQuery() + `WHERE t.name IN (
+SELECT t.name FROM topology t` + where + orderByClause + pagination + `
+)` + orderByClause + `, tc.id
+`
+}
+
+func selectQuery() string {
+	return `
+SELECT
+  t.name,
+  t.description,
+  t.last_updated,
+  tc.id,
+  tc.cachegroup,
+  ARRAY(SELECT tcp.parent FROM topology_cachegroup_parents tcp WHERE tcp.child = tc.id ORDER BY tcp.rank) AS parents
+FROM
+  topology t
+  LEFT JOIN topology_cachegroup tc ON tc.topology = t.name
+`
+}
+
+func insertQuery() string {
+	return `
+INSERT INTO topology (
+  name,
+  description
+)
+VALUES (
+  $1,
+  $2
+)
+RETURNING last_updated
+`
+}
+
+func updateQuery() string {
+	return `
+UPDATE topology SET
+  description = $1
+WHERE
+  name = $2
+RETURNING last_updated
+`
+}
+
+func deleteQuery() string {
+	return `
+DELETE FROM topology WHERE name = $1
+`
+}
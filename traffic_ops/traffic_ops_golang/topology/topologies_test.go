@@ -0,0 +1,195 @@
+package topology
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestValidateNodes(t *testing.T) {
+	type testCase struct {
+		name  string
+		nodes []tc.TopologyNode
+		err   string
+	}
+	testCases := []testCase{
+		{
+			name: "valid",
+			nodes: []tc.TopologyNode{
+				{Cachegroup: "edge0", Parents: []int{2, 3}},
+				{Cachegroup: "edge1", Parents: []int{2}},
+				{Cachegroup: "mid0", Parents: []int{4}},
+				{Cachegroup: "mid1", Parents: []int{4}},
+				{Cachegroup: "mid2", Parents: []int{}},
+			},
+		},
+		{
+			name:  "single node",
+			nodes: []tc.TopologyNode{{Cachegroup: "edge0"}},
+		},
+		{
+			name:  "no nodes",
+			nodes: []tc.TopologyNode{},
+			err:   "at least one cachegroup",
+		},
+		{
+			name:  "empty cachegroup",
+			nodes: []tc.TopologyNode{{Cachegroup: "edge0", Parents: []int{1}}, {Cachegroup: ""}},
+			err:   "node 1 has no cachegroup",
+		},
+		{
+			name:  "duplicate cachegroup",
+			nodes: []tc.TopologyNode{{Cachegroup: "edge0", Parents: []int{1}}, {Cachegroup: "edge0"}},
+			err:   "more than once",
+		},
+		{
+			name: "too many parents",
+			nodes: []tc.TopologyNode{
+				{Cachegroup: "edge0", Parents: []int{1, 2, 3}},
+				{Cachegroup: "mid0"},
+				{Cachegroup: "mid1"},
+				{Cachegroup: "mid2"},
+			},
+			err: "more than 2 parents",
+		},
+		{
+			name:  "parent out of range",
+			nodes: []tc.TopologyNode{{Cachegroup: "edge0", Parents: []int{1}}},
+			err:   "not a node in the topology",
+		},
+		{
+			name:  "own parent",
+			nodes: []tc.TopologyNode{{Cachegroup: "edge0", Parents: []int{0}}},
+			err:   "its own parent",
+		},
+		{
+			name:  "same primary and secondary parent",
+			nodes: []tc.TopologyNode{{Cachegroup: "edge0", Parents: []int{1, 1}}, {Cachegroup: "mid0"}},
+			err:   "same primary and secondary parent",
+		},
+		{
+			name: "cycle",
+			nodes: []tc.TopologyNode{
+				{Cachegroup: "edge0", Parents: []int{1}},
+				{Cachegroup: "mid0", Parents: []int{2}},
+				{Cachegroup: "mid1", Parents: []int{3}},
+				{Cachegroup: "mid2", Parents: []int{1}},
+			},
+			err: "cycle: mid0 -> mid1 -> mid2 -> mid0",
+		},
+		{
+			name: "secondary parent cycle",
+			nodes: []tc.TopologyNode{
+				{Cachegroup: "edge0", Parents: []int{1}},
+				{Cachegroup: "mid0", Parents: []int{2, 0}},
+				{Cachegroup: "mid1"},
+			},
+			err: "cycle: edge0 -> mid0 -> edge0",
+		},
+	}
+
+	for _, c := range testCases {
+		err := util.JoinErrs(ValidateNodes(c.nodes))
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("ValidateNodes %s expected: nil error, actual: %v", c.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("ValidateNodes %s expected: error containing '%s', actual: nil", c.name, c.err)
+		} else if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("ValidateNodes %s expected: error containing '%s', actual: %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestMakeTopologies(t *testing.T) {
+	id := func(i int64) *int64 { return &i }
+	lastUpdated := tc.TimeNoMod{}
+	rows := []topologyRow{
+		{Name: "empty", Description: "no nodes", LastUpdated: lastUpdated},
+		{Name: "two-tier", LastUpdated: lastUpdated, NodeID: id(10), Cachegroup: util.StrPtr("edge0"), ParentIDs: []int64{12, 11}},
+		{Name: "two-tier", LastUpdated: lastUpdated, NodeID: id(11), Cachegroup: util.StrPtr("mid0"), ParentIDs: []int64{}},
+		{Name: "two-tier", LastUpdated: lastUpdated, NodeID: id(12), Cachegroup: util.StrPtr("mid1"), ParentIDs: []int64{}},
+	}
+
+	topologies, err := makeTopologies(rows)
+	if err != nil {
+		t.Fatalf("makeTopologies expected: nil error, actual: %v", err)
+	}
+	if len(topologies) != 2 {
+		t.Fatalf("makeTopologies expected: 2 topologies, actual: %+v", topologies)
+	}
+	if topologies[0].Name != "empty" || topologies[0].Description != "no nodes" || len(topologies[0].Nodes) != 0 {
+		t.Errorf("makeTopologies expected: empty topology, actual: %+v", topologies[0])
+	}
+	expectedNodes := []tc.TopologyNode{
+		{Cachegroup: "edge0", Parents: []int{2, 1}},
+		{Cachegroup: "mid0", Parents: []int{}},
+		{Cachegroup: "mid1", Parents: []int{}},
+	}
+	if !reflect.DeepEqual(topologies[1].Nodes, expectedNodes) {
+		t.Errorf("makeTopologies expected: nodes %+v, actual: %+v", expectedNodes, topologies[1].Nodes)
+	}
+
+	rows = append(rows, topologyRow{Name: "zzz", LastUpdated: lastUpdated, NodeID: id(20), Cachegroup: util.StrPtr("edge0"), ParentIDs: []int64{11}})
+	if _, err := makeTopologies(rows); err == nil {
+		t.Errorf("makeTopologies with parent in another topology expected: error, actual: nil")
+	}
+}
+
+func TestReadOrderByAndPagination(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT t.name FROM topology t\nORDER BY t.name DESC\nLIMIT 2\nOFFSET 2\n)\nORDER BY t.name DESC, tc.id")).WillReturnRows(sqlmock.NewRows([]string{"name", "description", "last_updated", "id", "cachegroup", "parents"}))
+
+	reqInfo := api.APIInfo{Tx: db.MustBegin(), Params: map[string]string{"orderby": "name", "sortOrder": "desc", "limit": "2", "page": "2"}}
+	topology := TOTopology{APIInfoImpl: api.APIInfoImpl{ReqInfo: &reqInfo}}
+	if _, userErr, sysErr, _ := topology.Read(); userErr != nil || sysErr != nil {
+		t.Fatalf("Read expected: no errors, actual: %v %v", userErr, sysErr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Read expected: ordered and paginated query, actual: %v", err)
+	}
+
+	reqInfo.Params = map[string]string{"limit": "none"}
+	if _, userErr, _, code := topology.Read(); userErr == nil || code != http.StatusBadRequest {
+		t.Errorf("Read with invalid limit expected: user error and %v, actual: %v and %v", http.StatusBadRequest, userErr, code)
+	}
+}
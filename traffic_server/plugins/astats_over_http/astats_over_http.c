@@ -297,13 +297,79 @@ static int getSpeed(char *inf, char *buffer, int bufferSize) {
 	return speed;
 }
 
+static char * getOperState(char *inf, char *buffer, int bufferSize) {
+	char* str;
+	char* end;
+	char b[256];
+
+	snprintf(b, sizeof(b), "/sys/class/net/%s/operstate", inf);
+	str = getFile(b, buffer, bufferSize - 1);
+	end = strstr(str, "\n");
+	if (end)
+		*end = 0;
+
+	return str;
+}
+
+static char * getNetDev(char *inf, char *buffer, int bufferSize) {
+	char* str;
+	char* end;
+
+	str = getFile("/proc/net/dev", buffer, bufferSize - 1);
+	str = strstr(str, inf);
+	if (str) {
+		end = strstr(str, "\n");
+		if (end)
+			*end = 0;
+	}
+
+	return str;
+}
+
+#define MAX_INTERFACES 16
+
+static void appendInterfacesState(stats_state *my_state, char **interfaces, int interfaces_cnt) {
+	char buffer[16384];
+	char operstate[64];
+	char b[3048];
+	char *str;
+	int speed;
+	int nbytes;
+	int i;
+
+	APPEND("   \"interfaces\": {\n");
+	for (i = 0; i < interfaces_cnt; i++) {
+		getOperState(interfaces[i], operstate, sizeof(operstate));
+		speed = getSpeed(interfaces[i], buffer, sizeof(buffer));
+		str = getNetDev(interfaces[i], buffer, sizeof(buffer));
+		nbytes = snprintf(b, sizeof(b), "    \"%s\": { \"operstate\": \"%s\", \"speed\": %d, \"proc.net.dev\": \"%s\" }%s\n",
+				interfaces[i], operstate, speed, str ? str : "", i < interfaces_cnt - 1 ? "," : "");
+		if (0 < nbytes && nbytes < (int)sizeof(b))
+			APPEND(b);
+	}
+	APPEND("   },\n");
+}
+
 static void appendSystemState(stats_state *my_state) {
 	char *interface = my_state->interfaceName;
+	char interfaceNames[STR_BUFFER_SIZE];
+	char *interfaces[MAX_INTERFACES];
+	int interfaces_cnt = 0;
 	char buffer[16384];
 	char *str;
 	char *end;
+	char *p;
 	int speed = 0;
 
+	// inf.name may be a comma-separated list of interfaces. The first is reported as inf.name, and all of them in the interfaces object.
+	if (interface && strchr(interface, ',')) {
+		snprintf(interfaceNames, sizeof(interfaceNames), "%s", interface);
+		p = interfaceNames;
+		while (interfaces_cnt < MAX_INTERFACES && (str = strtok_r(p, ",", &p)))
+			interfaces[interfaces_cnt++] = str;
+		interface = interfaces_cnt > 0 ? interfaces[0] : NULL;
+	}
+
 	APPEND_STAT("inf.name", "\"%s\"", interface);
 
 	speed = getSpeed(interface, buffer, sizeof(buffer));
@@ -321,6 +387,9 @@ static void appendSystemState(stats_state *my_state) {
 		}
 	}
 
+	if (interfaces_cnt > 0)
+		appendInterfacesState(my_state, interfaces, interfaces_cnt);
+
 	str = getFile("/proc/loadavg", buffer, sizeof(buffer));
 	if (str) {
 		end = strstr(str, "\n");